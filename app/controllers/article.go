@@ -4,10 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
-	"strconv"
 	"websays/app/models"
 	"websays/config"
 	"websays/database/basefunctions"
+	"websays/database/baseids"
 	"websays/database/basetypes"
 	"websays/httpHandler/basecontrollers/baseinterfaces"
 	"websays/httpHandler/baserouter"
@@ -25,6 +25,7 @@ type Article struct {
 	baseinterfaces.BaseControllerFactory
 	basefunctions.BaseFucntionsInterface
 	basevalidators.ValidatorInterface
+	baseids.IDGenerator
 }
 
 // GetDBName returns the database name associated with the Article controller.
@@ -51,15 +52,13 @@ func (art *Article) GetCollectionName() basetypes.CollectionName {
 
 // DoIndexing performs indexing-related operations associated with the Article controller.
 //
-// This method is currently implemented to return nil, indicating that no specific
-// indexing operations are performed by the Article controller. Implementations of
-// this method can include custom logic for indexing if needed in the future for memory controllers.
+// This method registers the article model with the underlying memory controller, which builds no index
+// but checks the fields of the filters on the articles against the fields of the model.
 //
 // Returns:
 //   - error: An error is returned if there are any issues with indexing operations.
-//             In the current implementation, it always returns nil.
 func (art *Article) DoIndexing() error {
-	return nil
+	return art.EnsureIndex(art.GetDBName(), art.GetCollectionName(), models.Article{})
 }
 
 // SetBaseFunctions sets the implementation of the BaseFunctionsInterface for the Article controller.
@@ -67,12 +66,22 @@ func (art *Article) DoIndexing() error {
 // This method allows assigning a specific implementation of the BaseFunctionsInterface
 // to the Article controller. The BaseFunctionsInterface provides functionality related
 // to basic CRUD (Create, Read, Update, Delete) operations on data.
+// The sequence of the storage becomes the ID generator, until SetIDGenerator sets another one.
 //
 // Parameters:
 //   - inter: An instance of basefunctions.BaseFucntionsInterface to be set as the
 //            implementation for the Article controller.
 func (art *Article) SetBaseFunctions(inter basefunctions.BaseFucntionsInterface) {
 	art.BaseFucntionsInterface = inter
+	art.IDGenerator = baseids.NewSequence(inter)
+}
+
+// SetIDGenerator sets the generator of the IDs of the articles created by the Article controller.
+//
+// Parameters:
+//   - generator: The IDGenerator of the strategy configured for the Article controller.
+func (art *Article) SetIDGenerator(generator baseids.IDGenerator) {
+	art.IDGenerator = generator
 }
 
 // HandleAddArticle handles the creation of a new article based on the JSON data provided in the request body.
@@ -89,9 +98,10 @@ func (art *Article) SetBaseFunctions(inter basefunctions.BaseFucntionsInterface)
 // Behavior:
 //   - Decodes the JSON data from the request body into an article structure.
 //   - Validates the article data using the article validator.
-//   - Generates a new unique ID for the article.
+//   - Generates a new unique ID for the article with the ID generator of the controller.
 //   - Adds the article to the underlying memory controller using the Add method.
-//   - Responds with a JSON-encoded success message and the created article upon successful addition.
+//   - Reads the article back for its creation metadata, and responds with a JSON-encoded success message
+//     and the created article upon successful addition.
 //   - Responds with an error message if the JSON data is malformed or validation fails.
 func (art *Article) HandleAddArticle(w http.ResponseWriter, r *http.Request) {
 	article := models.Article{}
@@ -112,18 +122,32 @@ func (art *Article) HandleAddArticle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate a new unique ID for the article
-	article.ID = art.GetNextID()
+	// Generate a new unique ID for the article, which starts at version 1
+	article.ID, err = art.NextID(r.Context(), requestDB(r, art), art.GetCollectionName())
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+	article.Version = 1
 
 	// Add the article to the underlying memory controller
-	_, err = art.Add(art.GetDBName(), art.GetCollectionName(), article)
+	article.ID, err = art.AddContext(r.Context(), requestDB(r, art), art.GetCollectionName(), article)
 	if err != nil {
-		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+
+	// Read the article back for the metadata recorded by the storage
+	data, err := art.FindOneContext(r.Context(), requestDB(r, art), art.GetCollectionName(), models.Article{ID: article.ID})
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
 		return
 	}
 
 	// Respond with a JSON-encoded success message and the created article
-	responses.GetInstance().WriteJsonResponse(w, r, responses.ADD_ARTICLE_SUCCESS, nil, article)
+	writeETag(w, data)
+	writeLastModified(w, data)
+	responses.GetInstance().WriteJsonResponse(w, r, responses.ADD_ARTICLE_SUCCESS, nil, data)
 }
 
 // HandleReadArticle handles the retrieval of an article based on the provided ID in the request.
@@ -138,32 +162,41 @@ func (art *Article) HandleAddArticle(w http.ResponseWriter, r *http.Request) {
 //
 // Behavior:
 //   - Extracts the article ID from the route parameters.
-//   - Validates the article ID and converts it to an integer.
+//   - Validates the article ID, an integer or a string key.
 //   - Calls the FindOne method to retrieve the article in the underlying memory controller.
-//   - Responds with a JSON-encoded article data upon successful retrieval.
+//   - Responds with 304 Not Modified if the article wasn't updated since the If-Modified-Since header.
+//   - Responds with a JSON-encoded article data upon successful retrieval, with its version as ETag
+//     and its update time as Last-Modified.
 //   - Responds with an error message if the validation or retrieval operation fails.
 func (art *Article) HandleReadArticle(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 	article := models.Article{}
 
-	idInt, err := strconv.ParseInt(id, 10, 64)
+	parsedID, err := basetypes.ParseID(id)
 
 	if err != nil {
 		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
 		return
 	}
 
-	article.ID = int(idInt)
+	article.ID = parsedID
 
 	// Calling the FindOne method for the memory controller
-	data, err := art.FindOne(art.GetDBName(), art.GetCollectionName(), article)
+	data, err := art.FindOneContext(r.Context(), requestDB(r, art), art.GetCollectionName(), article)
 	if err != nil {
-		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
 		return
 	}
 
-	// Respond with a JSON-encoded article data
+	// Respond with 304 Not Modified if the article wasn't updated since If-Modified-Since
+	if notModified(w, r, data) {
+		return
+	}
+
+	// Respond with a JSON-encoded article data and its version as ETag
+	writeETag(w, data)
+	writeLastModified(w, data)
 	responses.GetInstance().WriteJsonResponse(w, r, responses.READ_ARTICLE_SUCCESS, nil, data)
 }
 
@@ -171,7 +204,7 @@ func (art *Article) HandleReadArticle(w http.ResponseWriter, r *http.Request) {
 //
 // This method expects an article ID as a route parameter in the URL, which is used to identify and
 // delete the corresponding article. The article deletion is performed by calling the DeleteOne method
-// from the underlying memory controller.
+// from the underlying memory controller, which moves the article to the trash.
 //
 // Parameters:
 //   - w:   The http.ResponseWriter for sending the HTTP response.
@@ -179,8 +212,10 @@ func (art *Article) HandleReadArticle(w http.ResponseWriter, r *http.Request) {
 //
 // Behavior:
 //   - Extracts the article ID from the route parameters.
-//   - Validates the article ID and converts it to an integer.
-//   - Calls the DeleteOne method to delete the article in the underlying memory controller.
+//   - Validates the article ID, an integer or a string key.
+//   - Reads the version required by the If-Match header, if any.
+//   - Calls the DeleteOne method to move the article to the trash of the underlying memory controller.
+//   - Responds with a 412 version conflict if the article is no longer at the required version.
 //   - Responds with a JSON-encoded success message upon successful deletion.
 //   - Responds with an error message if the validation or deletion operation fails.
 func (art *Article) HandleDeleteArticle(w http.ResponseWriter, r *http.Request) {
@@ -188,22 +223,29 @@ func (art *Article) HandleDeleteArticle(w http.ResponseWriter, r *http.Request)
 	id := vars["id"]
 	article := models.Article{}
 
-	idInt, err := strconv.ParseInt(id, 10, 64)
+	parsedID, err := basetypes.ParseID(id)
 
 	if err != nil {
 		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
 		return
 	}
 
-	article.ID = int(idInt)
+	article.ID = parsedID
 
-	// Calling the DeleteOne method for the memory controller
-	err = art.DeleteOne(art.GetDBName(), art.GetCollectionName(), article)
+	// An If-Match header requires the article to still be at the version of its ETag
+	article.Version, err = readIfMatch(r)
 	if err != nil {
 		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
 		return
 	}
 
+	// Calling the DeleteOne method for the memory controller
+	err = art.DeleteOneContext(r.Context(), requestDB(r, art), art.GetCollectionName(), article)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+
 	// Respond with a JSON-encoded success message
 	responses.GetInstance().WriteJsonResponse(w, r, responses.DELETE_ARTICLE_SUCCESS, nil, nil)
 }
@@ -221,8 +263,10 @@ func (art *Article) HandleDeleteArticle(w http.ResponseWriter, r *http.Request)
 // Behavior:
 //   - Decodes the JSON data from the request body into an article structure.
 //   - Validates the article data using the Validate method.
+//   - Reads the version required by the If-Match header, or else by the body, if any.
 //   - Calls the UpdateOne method to update the article in the underlying memory controller.
-//   - Responds with a JSON-encoded success message and the updated article upon success.
+//   - Responds with a 412 version conflict if the article is no longer at the required version.
+//   - Responds with a JSON-encoded success message and the updated article, with its new version as ETag, upon success.
 //   - Responds with an error message if decoding, validation, or the update operation fails.
 func (art *Article) HandleUpdateArticle(w http.ResponseWriter, r *http.Request) {
 	article := models.Article{}
@@ -241,15 +285,344 @@ func (art *Article) HandleUpdateArticle(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Calling the UpdateOne method for the underlying memory controller
-	err = art.UpdateOne(art.GetDBName(), art.GetCollectionName(), "", article, false)
+	// An If-Match header requires the article to still be at the version of its ETag, overriding the version of the body
+	version, err := readIfMatch(r)
 	if err != nil {
 		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
 		return
 	}
+	if version != 0 {
+		article.Version = version
+	}
+
+	// Calling the UpdateOne method for the underlying memory controller
+	err = art.UpdateOneContext(r.Context(), requestDB(r, art), art.GetCollectionName(), "", article, false)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+
+	// Read the article back for the version given by the update
+	data, err := art.FindOneContext(r.Context(), requestDB(r, art), art.GetCollectionName(), models.Article{ID: article.ID})
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
 
 	// Respond with a JSON-encoded success message and the updated article
-	responses.GetInstance().WriteJsonResponse(w, r, responses.UPDATE_ARTICLE_SUCCESS, nil, article)
+	writeETag(w, data)
+	writeLastModified(w, data)
+	responses.GetInstance().WriteJsonResponse(w, r, responses.UPDATE_ARTICLE_SUCCESS, nil, data)
+}
+
+// HandleReplaceArticle handles the creation or replacement of the article with the ID given in the route parameters.
+//
+// This method expects a JSON-encoded article object in the request body, which is stored under the ID of the URL
+// whether or not a article with that ID exists yet. It is performed by calling the UpdateOne method with upsert.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+//
+// Behavior:
+//   - Extracts the article ID from the route parameters and decodes the article from the request body.
+//   - Rejects a body carrying an ID other than the one of the URL.
+//   - Validates the article data using the Validate method.
+//   - Reads the version required by the If-Match header, or else by the body, if any.
+//   - Calls the UpdateOne method with upsert to create or replace the article in the underlying memory controller.
+//   - Responds with a 412 version conflict if the article is no longer at the required version.
+//   - Responds with a JSON-encoded success message containing the stored article, with its version as ETag.
+//   - Responds with an error message if the ID, the JSON data, the validation or the operation fails.
+func (art *Article) HandleReplaceArticle(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	parsedID, err := basetypes.ParseID(id)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	article := models.Article{}
+	decoder := json.NewDecoder(r.Body)
+	err = decoder.Decode(&article)
+	defer r.Body.Close()
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.MALFORMED_JSON, errors.New("illegal json format"), nil)
+		return
+	}
+	if article.ID != "" && article.ID != parsedID {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, errors.New("Article ID doesn't match the URL"), nil)
+		return
+	}
+	article.ID = parsedID
+
+	err = art.Validate("/api/articles/{id}", article)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	// An If-Match header requires the article to still be at the version of its ETag, overriding the version of the body
+	version, err := readIfMatch(r)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+	if version != 0 {
+		article.Version = version
+	}
+
+	// Calling the UpdateOne method with upsert for the underlying memory controller
+	err = art.UpdateOneContext(r.Context(), requestDB(r, art), art.GetCollectionName(), "", article, true)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+
+	// Read the article back for the version it was stored at
+	data, err := art.FindOneContext(r.Context(), requestDB(r, art), art.GetCollectionName(), models.Article{ID: article.ID})
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+	writeETag(w, data)
+	writeLastModified(w, data)
+	responses.GetInstance().WriteJsonResponse(w, r, responses.UPDATE_ARTICLE_SUCCESS, nil, data)
+}
+
+// HandleListArticles handles the retrieval of a page of articles.
+//
+// This method reads the paging options (limit, offset or cursor) and the optional JSON "filter"
+// from the query string and retrieves the matching page by calling the FindMany method from the underlying memory controller.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+//
+// Behavior:
+//   - Reads the paging options and the filter from the query string.
+//   - Calls the FindMany method to retrieve the page in the underlying memory controller.
+//   - Responds with a JSON-encoded page containing the articles, the total count and the next cursor.
+//   - Responds with an error message if the paging options or the filter are invalid, or the retrieval fails.
+func (art *Article) HandleListArticles(w http.ResponseWriter, r *http.Request) {
+	options, err := readFindOptions(r)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	filter, err := readFilter(r)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	// Calling the FindMany method for the memory controller
+	result, err := art.FindManyContext(r.Context(), requestDB(r, art), art.GetCollectionName(), filter, options)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+
+	// Respond with a JSON-encoded page of articles
+	responses.GetInstance().WriteJsonResponse(w, r, responses.LIST_ARTICLE_SUCCESS, nil, result)
+}
+
+// HandleListArticleTrash handles the retrieval of a page of the trashed articles.
+//
+// This method reads the paging options and the optional JSON "filter" from the query string, like HandleListArticles,
+// and retrieves the matching page of the trash by calling the FindTrash method of the underlying memory controller.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+//
+// Behavior:
+//   - Responds with a JSON-encoded page containing the trashed articles, the total count and the next cursor.
+//   - Responds with an error message if the paging options or the filter are invalid, or the retrieval fails.
+func (art *Article) HandleListArticleTrash(w http.ResponseWriter, r *http.Request) {
+	writeTrashPage(w, r, art, requestDB(r, art), art.GetCollectionName())
+}
+
+// HandleRestoreArticle handles moving the trashed article with the ID given in the route parameters out of the trash.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+//
+// Behavior:
+//   - Extracts the article ID from the route parameters and validates it.
+//   - Calls the Restore method of the underlying memory controller.
+//   - Responds with a JSON-encoded success message containing the restored article, with its version as ETag.
+//   - Responds with an error message if the ID is invalid, the article is not in the trash or the operation fails.
+func (art *Article) HandleRestoreArticle(w http.ResponseWriter, r *http.Request) {
+	parsedID, err := basetypes.ParseID(mux.Vars(r)["id"])
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+	writeRestored(w, r, art, requestDB(r, art), art.GetCollectionName(), models.Article{ID: parsedID})
+}
+
+// HandlePurgeArticle handles the permanent removal of the trashed article with the ID given in the route parameters.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+//
+// Behavior:
+//   - Extracts the article ID from the route parameters and validates it.
+//   - Calls the Purge method of the underlying memory controller, which only removes articles in the trash.
+//   - Responds with a JSON-encoded success message containing the ID of the purged article.
+//   - Responds with an error message if the ID is invalid, the article is not in the trash or the operation fails.
+func (art *Article) HandlePurgeArticle(w http.ResponseWriter, r *http.Request) {
+	parsedID, err := basetypes.ParseID(mux.Vars(r)["id"])
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+	article := models.Article{ID: parsedID}
+	writePurged(w, r, art, requestDB(r, art), art.GetCollectionName(), article, article)
+}
+
+// HandleBulkAddArticles handles the creation of several articles based on the JSON array provided in the request body.
+//
+// The articles are added independently, so the response reports the outcome of each article in the order of the request.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+//
+// Behavior:
+//   - Decodes the JSON array from the request body into article structures, accepting up to 1000 articles.
+//   - Validates each article like a single creation, rejecting the invalid ones without adding them.
+//   - Generates a new unique ID for each valid article.
+//   - Adds the valid articles to the underlying memory controller using the AddMany method.
+//   - Responds with the outcome of each article, with the ID of the created ones, and a partial failure code if any failed.
+//   - Responds with an error message if the JSON data is malformed or the request has no or too many articles.
+func (art *Article) HandleBulkAddArticles(w http.ResponseWriter, r *http.Request) {
+	articles := []models.Article{}
+
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(&articles)
+	defer r.Body.Close()
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.MALFORMED_JSON, errors.New("illegal json format"), nil)
+		return
+	}
+	if err = checkBulkSize(len(articles)); err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	batch := newBulkBatch(len(articles))
+	data := make([]interface{}, 0, len(articles))
+	for i, article := range articles {
+		if err := art.Validate("/api/articles/bulk", article); err != nil {
+			batch.reject(i, err)
+			continue
+		}
+		if article.ID, err = art.NextID(r.Context(), requestDB(r, art), art.GetCollectionName()); err != nil {
+			batch.reject(i, err)
+			continue
+		}
+		batch.accept(i)
+		data = append(data, article)
+	}
+
+	result, err := art.AddManyContext(r.Context(), requestDB(r, art), art.GetCollectionName(), data)
+	batch.write(w, r, responses.BULK_ADD_SUCCESS, result, err)
+}
+
+// HandleBulkUpdateArticles handles the update of several articles based on the JSON array provided in the request body.
+//
+// Each article is updated by its ID like a single update, a version in the article requiring it to still be at that version.
+// The articles are updated independently, so the response reports the outcome of each article in the order of the request.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+//
+// Behavior:
+//   - Decodes the JSON array from the request body into article structures, accepting up to 1000 articles.
+//   - Validates each article like a single update, rejecting the invalid ones without updating them.
+//   - Updates the valid articles in the underlying memory controller using the UpdateMany method.
+//   - Responds with the outcome of each article, and a partial failure code if any failed.
+//   - Responds with an error message if the JSON data is malformed or the request has no or too many articles.
+func (art *Article) HandleBulkUpdateArticles(w http.ResponseWriter, r *http.Request) {
+	articles := []models.Article{}
+
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(&articles)
+	defer r.Body.Close()
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.MALFORMED_JSON, errors.New("illegal json format"), nil)
+		return
+	}
+	if err = checkBulkSize(len(articles)); err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	batch := newBulkBatch(len(articles))
+	updates := make([]basetypes.BulkUpdate, 0, len(articles))
+	for i, article := range articles {
+		if err := art.Validate("/api/articles/{id}", article); err != nil {
+			batch.reject(i, err)
+			continue
+		}
+		batch.accept(i)
+		updates = append(updates, basetypes.BulkUpdate{Query: "", Data: article})
+	}
+
+	result, err := art.UpdateManyContext(r.Context(), requestDB(r, art), art.GetCollectionName(), updates)
+	batch.write(w, r, responses.BULK_UPDATE_SUCCESS, result, err)
+}
+
+// HandleBulkDeleteArticles handles the deletion of several articles identified in the JSON array provided in the request body.
+//
+// Each item of the array carries the "id" of an article, and optionally the "version" it is required to still be at.
+// The articles are moved to the trash independently, so the response reports the outcome of each article in the order of the request.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+//
+// Behavior:
+//   - Decodes the JSON array from the request body, accepting up to 1000 items.
+//   - Rejects the items without a valid ID.
+//   - Moves the articles to the trash of the underlying memory controller using the DeleteMany method.
+//   - Responds with the outcome of each article, and a partial failure code if any failed.
+//   - Responds with an error message if the JSON data is malformed or the request has no or too many items.
+func (art *Article) HandleBulkDeleteArticles(w http.ResponseWriter, r *http.Request) {
+	keys := []bulkKey{}
+
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(&keys)
+	defer r.Body.Close()
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.MALFORMED_JSON, errors.New("illegal json format"), nil)
+		return
+	}
+	if err = checkBulkSize(len(keys)); err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	batch := newBulkBatch(len(keys))
+	queries := make([]interface{}, 0, len(keys))
+	for i, key := range keys {
+		if !key.ID.Valid() {
+			batch.reject(i, errors.New("ID is not valid"))
+			continue
+		}
+		batch.accept(i)
+		queries = append(queries, models.Article{ID: key.ID, Version: key.Version})
+	}
+
+	result, err := art.DeleteManyContext(r.Context(), requestDB(r, art), art.GetCollectionName(), queries)
+	batch.write(w, r, responses.BULK_DELETE_SUCCESS, result, err)
 }
 
 // RegisterApis registers the API endpoints associated with the Article controller.
@@ -259,16 +632,31 @@ func (art *Article) HandleUpdateArticle(w http.ResponseWriter, r *http.Request)
 //   - /api/readArticle/{id}: Handles the retrieval of an article by ID (HTTP GET).
 //   - /api/deleteArticle/{id}: Handles the deletion of an article by ID (HTTP DELETE).
 //   - /api/updateArticle:   Handles the update of an existing article (HTTP PUT).
+//   - /api/articles:        Handles the retrieval of a page of articles (HTTP GET).
+//   - /api/articles/{id}:   Handles the creation or replacement of an article by ID (HTTP PUT).
+//   - /api/articles/trash:  Handles the retrieval of a page of trashed articles (HTTP GET).
+//   - /api/articles/trash/{id}/restore: Handles moving an article out of the trash by ID (HTTP POST).
+//   - /api/articles/trash/{id}: Handles the permanent removal of a trashed article by ID (HTTP DELETE).
+//   - /api/articles/bulk:   Handles the creation (HTTP POST), update (HTTP PATCH) and deletion (HTTP DELETE) of several articles.
 //
 // Parameters:
 //   - None
 //
 // Behavior:
 //   - Configures the routes and handlers for the specified API endpoints.
+//   - Lets every endpoint work on another database, named by the X-Database header, see inDatabase.
 func (art *Article) RegisterApis() {
 	// Register API endpoints with their respective handlers and HTTP methods
-	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/createArticle", art.HandleAddArticle).Methods("POST")
-	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/readArticle/{id}", art.HandleReadArticle).Methods("GET")
-	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/deleteArticle/{id}", art.HandleDeleteArticle).Methods("DELETE")
-	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/updateArticle", art.HandleUpdateArticle).Methods("PUT")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/createArticle", inDatabase(art, art.HandleAddArticle)).Methods("POST")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/readArticle/{id}", inDatabase(art, art.HandleReadArticle)).Methods("GET")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/deleteArticle/{id}", inDatabase(art, art.HandleDeleteArticle)).Methods("DELETE")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/updateArticle", inDatabase(art, art.HandleUpdateArticle)).Methods("PUT")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/articles", inDatabase(art, art.HandleListArticles)).Methods("GET")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/articles/{id}", inDatabase(art, art.HandleReplaceArticle)).Methods("PUT")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/articles/trash", inDatabase(art, art.HandleListArticleTrash)).Methods("GET")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/articles/trash/{id}/restore", inDatabase(art, art.HandleRestoreArticle)).Methods("POST")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/articles/trash/{id}", inDatabase(art, art.HandlePurgeArticle)).Methods("DELETE")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/articles/bulk", inDatabase(art, art.HandleBulkAddArticles)).Methods("POST")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/articles/bulk", inDatabase(art, art.HandleBulkUpdateArticles)).Methods("PATCH")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/articles/bulk", inDatabase(art, art.HandleBulkDeleteArticles)).Methods("DELETE")
 }
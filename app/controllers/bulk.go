@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"websays/database/basetypes"
+	"websays/httpHandler/responses"
+)
+
+// maxBulkItems is the largest number of items accepted by a bulk request.
+const maxBulkItems = 1000
+
+// bulkKey identifies a record deleted by a bulk delete request, with the version it is required to be at, if any.
+type bulkKey struct {
+	ID      basetypes.ID `json:"id"`      // ID of the record, an integer or a string key.
+	Version int          `json:"version"` // Version the record must still be at, 0 for any.
+}
+
+// checkBulkSize rejects a bulk request of size items that is empty or has more than maxBulkItems items.
+func checkBulkSize(size int) error {
+	if size == 0 {
+		return errors.New("Bulk request has no items")
+	}
+	if size > maxBulkItems {
+		return errors.New("Bulk request has more than " + strconv.Itoa(maxBulkItems) + " items")
+	}
+	return nil
+}
+
+// bulkBatch tracks which items of a bulk request are sent to the storage and which are rejected beforehand,
+// so the outcome of the storage can be reported against the positions of the request.
+type bulkBatch struct {
+	rejected  []error // Why each item of the request was rejected, nil for the items sent to the storage.
+	positions []int   // Position in the request of each item sent to the storage, in order.
+}
+
+// newBulkBatch returns the batch of a bulk request of size items.
+func newBulkBatch(size int) *bulkBatch {
+	return &bulkBatch{rejected: make([]error, size), positions: make([]int, 0, size)}
+}
+
+// reject reports the item at index as failed with err, without sending it to the storage.
+func (u *bulkBatch) reject(index int, err error) {
+	u.rejected[index] = err
+}
+
+// accept reports the item at index as sent to the storage, after the items accepted before it.
+func (u *bulkBatch) accept(index int) {
+	u.positions = append(u.positions, index)
+}
+
+// write responds with the outcome of every item of the request, the rejected ones and those of the storage result.
+//
+// Behavior:
+//   - Responds with code, or BULK_PARTIAL_FAILURE if any item failed, and the outcome of each item.
+//   - Responds with an error message and the outcome of each item if the storage gave up on the operation.
+func (u *bulkBatch) write(w http.ResponseWriter, r *http.Request, code int, result basetypes.BulkResult, err error) {
+	merged := basetypes.NewBulkResult(len(u.rejected))
+	for i, rejected := range u.rejected {
+		if rejected != nil {
+			merged.Set(i, "", rejected)
+		}
+	}
+	for i, item := range result.Items {
+		if i >= len(u.positions) {
+			break
+		}
+		item.Index = u.positions[i]
+		merged.Items[item.Index] = item
+		if item.Error != "" {
+			merged.Failed++
+		} else {
+			merged.Succeeded++
+		}
+	}
+
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.ADDING_DB_FAILED), err, merged)
+		return
+	}
+	if merged.Failed > 0 {
+		code = responses.BULK_PARTIAL_FAILURE
+	}
+	responses.GetInstance().WriteJsonResponse(w, r, code, nil, merged)
+}
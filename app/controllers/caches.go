@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"net/http"
+	"websays/database/basefunctions"
+	"websays/database/basetypes"
+	"websays/httpHandler/baserouter"
+	"websays/httpHandler/responses"
+)
+
+// Caches is the controller exposing the statistics of the read-through caches of the controllers,
+// enabled per controller by the caches section of the config.
+type Caches struct {
+	Caches map[string]basefunctions.CacheInterface // The cache of each controller with one, by controller name.
+}
+
+// HandleCacheStats handles the retrieval of the statistics of the caches.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+//
+// Behavior:
+//   - Calls the CacheStats method of the cache of every controller with one.
+//   - Responds with the hits, misses, evictions and entries of each cache, by controller name.
+func (u *Caches) HandleCacheStats(w http.ResponseWriter, r *http.Request) {
+	stats := make(map[string]basetypes.CacheStats, len(u.Caches))
+	for name, cache := range u.Caches {
+		stats[name] = cache.CacheStats()
+	}
+	responses.GetInstance().WriteJsonResponse(w, r, responses.CACHE_STATS_SUCCESS, nil, stats)
+}
+
+// RegisterApis registers the API endpoints of the caches.
+// The endpoints registered are:
+//   - "/api/cacheStats" (GET): Reads the statistics of the caches.
+func (u *Caches) RegisterApis() {
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/cacheStats", u.HandleCacheStats).Methods("GET")
+}
@@ -1,13 +1,16 @@
 package controllers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
-	"strconv"
+	"strings"
 	"websays/app/models"
 	"websays/config"
 	"websays/database/basefunctions"
+	"websays/database/basehooks"
+	"websays/database/baseids"
 	"websays/database/basetypes"
 	"websays/httpHandler/basecontrollers/baseinterfaces"
 	"websays/httpHandler/baserouter"
@@ -25,6 +28,7 @@ type Category struct {
 	baseinterfaces.BaseControllerFactory
 	basefunctions.BaseFucntionsInterface
 	basevalidators.ValidatorInterface
+	baseids.IDGenerator
 }
 
 // GetDBName returns the database name associated with the Category controller.
@@ -56,14 +60,41 @@ func (cat *Category) GetCollectionName() basetypes.CollectionName {
 
 // DoIndexing performs any indexing operations required for the Category controller.
 //
-// This method is a no-op and returns nil, as no specific indexing operations are needed.
+// This method registers the category model with the underlying file controller, which builds no index
+// but checks the fields of the filters on the categories against the fields of the model.
+// It also registers the hooks trimming the names of the categories added or updated, in every database,
+// so the categories of the databases named by the X-Database header are trimmed too.
 //
 // Parameters:
 //   - None
 //
 // Returns:
-//   - error: Always returns nil.
+//   - error: An error if the registration fails.
 func (cat *Category) DoIndexing() error {
+	basehooks.GetInstance().Before(basehooks.AllDatabases, cat.GetCollectionName(), basehooks.ADD, "trimName", trimCategoryName)
+	basehooks.GetInstance().Before(basehooks.AllDatabases, cat.GetCollectionName(), basehooks.UPDATE, "trimName", trimCategoryName)
+	return cat.EnsureIndex(cat.GetDBName(), cat.GetCollectionName(), models.Category{})
+}
+
+// trimCategoryName is a before-hook removing the spaces around the name of a category written,
+// so categories differing only by them aren't stored under different names.
+//
+// Parameters:
+//   - ctx:   The context of the write.
+//   - event: The write of the category, whose data is replaced by the category with its name trimmed.
+//
+// Returns:
+//   - error: An error aborting the write if nothing but spaces is left of the name.
+func trimCategoryName(ctx context.Context, event *basehooks.Event) error {
+	category, ok := event.Data.(models.Category)
+	if !ok {
+		return nil
+	}
+	category.Name = strings.TrimSpace(category.Name)
+	if category.Name == "" {
+		return errors.New("Category Name can't be empty")
+	}
+	event.Data = category
 	return nil
 }
 
@@ -72,6 +103,8 @@ func (cat *Category) DoIndexing() error {
 // This method allows the Category controller to set its BaseFunctionsInterface to
 // facilitate interactions with the underlying data storage or controller.
 //
+// The sequence of the storage becomes the ID generator, until SetIDGenerator sets another one.
+//
 // Parameters:
 //   - inter: An instance of the BaseFunctionsInterface.
 //
@@ -79,6 +112,18 @@ func (cat *Category) DoIndexing() error {
 //   - None
 func (cat *Category) SetBaseFunctions(inter basefunctions.BaseFucntionsInterface) {
 	cat.BaseFucntionsInterface = inter
+	cat.IDGenerator = baseids.NewSequence(inter)
+}
+
+// SetIDGenerator sets the generator of the IDs of the categories created by the Category controller.
+//
+// Parameters:
+//   - generator: The IDGenerator of the strategy configured for the Category controller.
+//
+// Returns:
+//   - None
+func (cat *Category) SetIDGenerator(generator baseids.IDGenerator) {
+	cat.IDGenerator = generator
 }
 
 // HandleCreateCategory handles the creation of a new category based on the provided JSON data in the request body.
@@ -94,8 +139,9 @@ func (cat *Category) SetBaseFunctions(inter basefunctions.BaseFucntionsInterface
 // Behavior:
 //   - Decodes the JSON data from the request body into a Category struct.
 //   - Validates the category data using the Validate method.
-//   - Assigns a unique ID to the category using the GetNextID method.
+//   - Assigns a unique ID to the category using the ID generator of the controller.
 //   - Calls the Add method to add the category to the underlying data storage.
+//   - Reads the category back for its creation metadata.
 //   - Responds with a JSON-encoded success message upon successful creation.
 //   - Responds with an error message if the JSON is malformed, validation fails, or the addition operation fails.
 func (cat *Category) HandleCreateCategory(w http.ResponseWriter, r *http.Request) {
@@ -115,16 +161,31 @@ func (cat *Category) HandleCreateCategory(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	category.ID = cat.GetNextID()
+	category.ID, err = cat.NextID(r.Context(), requestDB(r, cat), cat.GetCollectionName())
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+	// A new category starts at version 1
+	category.Version = 1
 
 	// Call the underlying file controller method
-	_, err = cat.Add(cat.GetDBName(), cat.GetCollectionName(), category)
+	category.ID, err = cat.AddContext(r.Context(), requestDB(r, cat), cat.GetCollectionName(), category)
 	if err != nil {
-		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
 		return
 	}
 
-	responses.GetInstance().WriteJsonResponse(w, r, responses.ADD_CATEGORY_SUCCESS, nil, category)
+	// Read the category back for the metadata recorded by the storage
+	data, err := cat.FindOneContext(r.Context(), requestDB(r, cat), cat.GetCollectionName(), models.Category{ID: category.ID})
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+
+	writeETag(w, data)
+	writeLastModified(w, data)
+	responses.GetInstance().WriteJsonResponse(w, r, responses.ADD_CATEGORY_SUCCESS, nil, data)
 }
 
 // HandleReadCategory handles the retrieval of a category based on the provided ID in the request parameters.
@@ -138,31 +199,40 @@ func (cat *Category) HandleCreateCategory(w http.ResponseWriter, r *http.Request
 //
 // Behavior:
 //   - Extracts the category ID from the route parameters.
-//   - Validates the category ID and converts it to an integer.
+//   - Validates the category ID, an integer or a string key.
 //   - Calls the FindOne method to retrieve the category data from the underlying data storage.
-//   - Responds with a JSON-encoded success message containing the retrieved data upon successful retrieval.
+//   - Responds with 304 Not Modified if the category wasn't updated since the If-Modified-Since header.
+//   - Responds with a JSON-encoded success message containing the retrieved data upon successful retrieval,
+//     with its version as ETag and its update time as Last-Modified.
 //   - Responds with an error message if the validation or retrieval operation fails.
 func (cat *Category) HandleReadCategory(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 	category := models.Category{}
 
-	idInt, err := strconv.ParseInt(id, 10, 64)
+	parsedID, err := basetypes.ParseID(id)
 
 	if err != nil {
 		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
 		return
 	}
 
-	category.ID = int(idInt)
+	category.ID = parsedID
 
 	// Call the underlying file controller find
-	data, err := cat.FindOne(cat.GetDBName(), cat.GetCollectionName(), category)
+	data, err := cat.FindOneContext(r.Context(), requestDB(r, cat), cat.GetCollectionName(), category)
 	if err != nil {
-		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+
+	// Respond with 304 Not Modified if the category wasn't updated since If-Modified-Since
+	if notModified(w, r, data) {
 		return
 	}
 
+	writeETag(w, data)
+	writeLastModified(w, data)
 	responses.GetInstance().WriteJsonResponse(w, r, responses.READ_CATEGORY_SUCCESS, nil, data)
 }
 
@@ -178,8 +248,12 @@ func (cat *Category) HandleReadCategory(w http.ResponseWriter, r *http.Request)
 // Behavior:
 //   - Parses the JSON-encoded category data from the request body.
 //   - Validates the category data using the Validate method.
+//   - Reads the version required by the If-Match header, or else by the body, if any.
 //   - Calls the UpdateOne method to update the category in the underlying data storage.
-//   - Responds with a JSON-encoded success message containing the updated category data upon successful update.
+//   - Responds with a 412 version conflict if the category is no longer at the required version,
+//     so two clients updating the same category can't overwrite each other.
+//   - Responds with a JSON-encoded success message containing the updated category data upon successful update,
+//     with its new version as ETag.
 //   - Responds with an error message if the validation or update operation fails.
 func (cat *Category) HandleUpdateCategory(w http.ResponseWriter, r *http.Request) {
 	category := models.Category{}
@@ -199,13 +273,32 @@ func (cat *Category) HandleUpdateCategory(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Call the underlying file controller update method
-	err = cat.UpdateOne(cat.GetDBName(), cat.GetCollectionName(), "", category, false)
+	// An If-Match header requires the category to still be at the version of its ETag, overriding the version of the body
+	version, err := readIfMatch(r)
 	if err != nil {
 		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
 		return
 	}
-	responses.GetInstance().WriteJsonResponse(w, r, responses.UPDATE_CATEGORY_SUCCESS, nil, category)
+	if version != 0 {
+		category.Version = version
+	}
+
+	// Call the underlying file controller update method
+	err = cat.UpdateOneContext(r.Context(), requestDB(r, cat), cat.GetCollectionName(), "", category, false)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+
+	// Read the category back for the version given by the update
+	data, err := cat.FindOneContext(r.Context(), requestDB(r, cat), cat.GetCollectionName(), models.Category{ID: category.ID})
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+	writeETag(w, data)
+	writeLastModified(w, data)
+	responses.GetInstance().WriteJsonResponse(w, r, responses.UPDATE_CATEGORY_SUCCESS, nil, data)
 }
 
 // HandleDeleteCategory handles the deletion of a category based on the provided ID in the request.
@@ -219,8 +312,10 @@ func (cat *Category) HandleUpdateCategory(w http.ResponseWriter, r *http.Request
 //
 // Behavior:
 //   - Extracts the category ID from the route parameters.
-//   - Validates the category ID and converts it to an integer.
-//   - Calls the DeleteOne method to delete the category in the underlying data storage.
+//   - Validates the category ID, an integer or a string key.
+//   - Reads the version required by the If-Match header, if any.
+//   - Calls the DeleteOne method to move the category to the trash of the underlying data storage.
+//   - Responds with a 412 version conflict if the category is no longer at the required version.
 //   - Responds with a JSON-encoded success message upon successful deletion.
 //   - Responds with an error message if the validation or deletion operation fails.
 func (cat *Category) HandleDeleteCategory(w http.ResponseWriter, r *http.Request) {
@@ -230,24 +325,340 @@ func (cat *Category) HandleDeleteCategory(w http.ResponseWriter, r *http.Request
 
 	category := models.Category{}
 
-	idInt, err := strconv.ParseInt(id, 10, 64)
+	parsedID, err := basetypes.ParseID(id)
 
 	if err != nil {
 		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
 		return
 	}
 
-	category.ID = int(idInt)
+	category.ID = parsedID
 
-	// Call the underlying file delete controller
-	err = cat.DeleteOne(cat.GetDBName(), cat.GetCollectionName(), category)
+	// An If-Match header requires the category to still be at the version of its ETag
+	category.Version, err = readIfMatch(r)
 	if err != nil {
 		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
 		return
 	}
+
+	// Call the underlying file delete controller
+	err = cat.DeleteOneContext(r.Context(), requestDB(r, cat), cat.GetCollectionName(), category)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
 	responses.GetInstance().WriteJsonResponse(w, r, responses.DELETE_CATEGORY_SUCCESS, nil, category)
 }
 
+// HandleReplaceCategory handles the creation or replacement of the category with the ID given in the route parameters.
+//
+// This method expects a JSON-encoded category object in the request body, which is stored under the ID of the URL
+// whether or not a category with that ID exists yet. It is performed by calling the UpdateOne method with upsert.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+//
+// Behavior:
+//   - Extracts the category ID from the route parameters and decodes the category from the request body.
+//   - Rejects a body carrying an ID other than the one of the URL.
+//   - Validates the category data using the Validate method.
+//   - Reads the version required by the If-Match header, or else by the body, if any.
+//   - Calls the UpdateOne method with upsert to create or replace the category in the underlying data storage.
+//   - Responds with a 412 version conflict if the category is no longer at the required version.
+//   - Responds with a JSON-encoded success message containing the stored category, with its version as ETag.
+//   - Responds with an error message if the ID, the JSON data, the validation or the operation fails.
+func (cat *Category) HandleReplaceCategory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	parsedID, err := basetypes.ParseID(id)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	category := models.Category{}
+	decoder := json.NewDecoder(r.Body)
+	err = decoder.Decode(&category)
+	defer r.Body.Close()
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.MALFORMED_JSON, errors.New("illegal json format"), nil)
+		return
+	}
+	if category.ID != "" && category.ID != parsedID {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, errors.New("Category ID doesn't match the URL"), nil)
+		return
+	}
+	category.ID = parsedID
+
+	err = cat.Validate("/api/categories/{id}", category)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	// An If-Match header requires the category to still be at the version of its ETag, overriding the version of the body
+	version, err := readIfMatch(r)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+	if version != 0 {
+		category.Version = version
+	}
+
+	// Call the underlying file controller update method with upsert
+	err = cat.UpdateOneContext(r.Context(), requestDB(r, cat), cat.GetCollectionName(), "", category, true)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+
+	// Read the category back for the version it was stored at
+	data, err := cat.FindOneContext(r.Context(), requestDB(r, cat), cat.GetCollectionName(), models.Category{ID: category.ID})
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+	writeETag(w, data)
+	writeLastModified(w, data)
+	responses.GetInstance().WriteJsonResponse(w, r, responses.UPDATE_CATEGORY_SUCCESS, nil, data)
+}
+
+// HandleListCategories handles the retrieval of a page of categories.
+//
+// This method reads the paging options (limit, offset or cursor) and the optional JSON "filter"
+// from the query string and retrieves the matching page by calling the FindMany method from the underlying data controller.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+//
+// Behavior:
+//   - Reads the paging options and the filter from the query string.
+//   - Calls the FindMany method to retrieve the page from the underlying data storage.
+//   - Responds with a JSON-encoded page containing the categories, the total count and the next cursor.
+//   - Responds with an error message if the paging options or the filter are invalid, or the retrieval fails.
+func (cat *Category) HandleListCategories(w http.ResponseWriter, r *http.Request) {
+	options, err := readFindOptions(r)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	filter, err := readFilter(r)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	// Call the underlying file controller list
+	result, err := cat.FindManyContext(r.Context(), requestDB(r, cat), cat.GetCollectionName(), filter, options)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+
+	responses.GetInstance().WriteJsonResponse(w, r, responses.LIST_CATEGORY_SUCCESS, nil, result)
+}
+
+// HandleListCategoryTrash handles the retrieval of a page of the trashed categories.
+//
+// This method reads the paging options and the optional JSON "filter" from the query string, like HandleListCategories,
+// and retrieves the matching page of the trash by calling the FindTrash method of the underlying file controller.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+//
+// Behavior:
+//   - Responds with a JSON-encoded page containing the trashed categories, the total count and the next cursor.
+//   - Responds with an error message if the paging options or the filter are invalid, or the retrieval fails.
+func (cat *Category) HandleListCategoryTrash(w http.ResponseWriter, r *http.Request) {
+	writeTrashPage(w, r, cat, requestDB(r, cat), cat.GetCollectionName())
+}
+
+// HandleRestoreCategory handles moving the trashed category with the ID given in the route parameters out of the trash.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+//
+// Behavior:
+//   - Extracts the category ID from the route parameters and validates it.
+//   - Calls the Restore method of the underlying file controller.
+//   - Responds with a JSON-encoded success message containing the restored category, with its version as ETag.
+//   - Responds with an error message if the ID is invalid, the category is not in the trash or the operation fails.
+func (cat *Category) HandleRestoreCategory(w http.ResponseWriter, r *http.Request) {
+	parsedID, err := basetypes.ParseID(mux.Vars(r)["id"])
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+	writeRestored(w, r, cat, requestDB(r, cat), cat.GetCollectionName(), models.Category{ID: parsedID})
+}
+
+// HandlePurgeCategory handles the permanent removal of the trashed category with the ID given in the route parameters.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+//
+// Behavior:
+//   - Extracts the category ID from the route parameters and validates it.
+//   - Calls the Purge method of the underlying file controller, which only removes categories in the trash.
+//   - Responds with a JSON-encoded success message containing the ID of the purged category.
+//   - Responds with an error message if the ID is invalid, the category is not in the trash or the operation fails.
+func (cat *Category) HandlePurgeCategory(w http.ResponseWriter, r *http.Request) {
+	parsedID, err := basetypes.ParseID(mux.Vars(r)["id"])
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+	category := models.Category{ID: parsedID}
+	writePurged(w, r, cat, requestDB(r, cat), cat.GetCollectionName(), category, category)
+}
+
+// HandleBulkAddCategories handles the creation of several categories based on the JSON array provided in the request body.
+//
+// The categories are added independently, so the response reports the outcome of each category in the order of the request.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+//
+// Behavior:
+//   - Decodes the JSON array from the request body into category structures, accepting up to 1000 categories.
+//   - Validates each category like a single creation, rejecting the invalid ones without adding them.
+//   - Generates a new unique ID for each valid category.
+//   - Adds the valid categories to the underlying file controller using the AddMany method.
+//   - Responds with the outcome of each category, with the ID of the created ones, and a partial failure code if any failed.
+//   - Responds with an error message if the JSON data is malformed or the request has no or too many categories.
+func (cat *Category) HandleBulkAddCategories(w http.ResponseWriter, r *http.Request) {
+	categories := []models.Category{}
+
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(&categories)
+	defer r.Body.Close()
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.MALFORMED_JSON, errors.New("illegal json format"), nil)
+		return
+	}
+	if err = checkBulkSize(len(categories)); err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	batch := newBulkBatch(len(categories))
+	data := make([]interface{}, 0, len(categories))
+	for i, category := range categories {
+		if err := cat.Validate("/api/categories/bulk", category); err != nil {
+			batch.reject(i, err)
+			continue
+		}
+		if category.ID, err = cat.NextID(r.Context(), requestDB(r, cat), cat.GetCollectionName()); err != nil {
+			batch.reject(i, err)
+			continue
+		}
+		batch.accept(i)
+		data = append(data, category)
+	}
+
+	result, err := cat.AddManyContext(r.Context(), requestDB(r, cat), cat.GetCollectionName(), data)
+	batch.write(w, r, responses.BULK_ADD_SUCCESS, result, err)
+}
+
+// HandleBulkUpdateCategories handles the update of several categories based on the JSON array provided in the request body.
+//
+// Each category is updated by its ID like a single update, a version in the category requiring it to still be at that version.
+// The categories are updated independently, so the response reports the outcome of each category in the order of the request.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+//
+// Behavior:
+//   - Decodes the JSON array from the request body into category structures, accepting up to 1000 categories.
+//   - Validates each category like a single update, rejecting the invalid ones without updating them.
+//   - Updates the valid categories in the underlying file controller using the UpdateMany method.
+//   - Responds with the outcome of each category, and a partial failure code if any failed.
+//   - Responds with an error message if the JSON data is malformed or the request has no or too many categories.
+func (cat *Category) HandleBulkUpdateCategories(w http.ResponseWriter, r *http.Request) {
+	categories := []models.Category{}
+
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(&categories)
+	defer r.Body.Close()
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.MALFORMED_JSON, errors.New("illegal json format"), nil)
+		return
+	}
+	if err = checkBulkSize(len(categories)); err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	batch := newBulkBatch(len(categories))
+	updates := make([]basetypes.BulkUpdate, 0, len(categories))
+	for i, category := range categories {
+		if err := cat.Validate("/api/categories/{id}", category); err != nil {
+			batch.reject(i, err)
+			continue
+		}
+		batch.accept(i)
+		updates = append(updates, basetypes.BulkUpdate{Query: "", Data: category})
+	}
+
+	result, err := cat.UpdateManyContext(r.Context(), requestDB(r, cat), cat.GetCollectionName(), updates)
+	batch.write(w, r, responses.BULK_UPDATE_SUCCESS, result, err)
+}
+
+// HandleBulkDeleteCategories handles the deletion of several categories identified in the JSON array provided in the request body.
+//
+// Each item of the array carries the "id" of a category, and optionally the "version" it is required to still be at.
+// The categories are moved to the trash independently, so the response reports the outcome of each category in the order of the request.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+//
+// Behavior:
+//   - Decodes the JSON array from the request body, accepting up to 1000 items.
+//   - Rejects the items without a valid ID.
+//   - Moves the categories to the trash of the underlying file controller using the DeleteMany method.
+//   - Responds with the outcome of each category, and a partial failure code if any failed.
+//   - Responds with an error message if the JSON data is malformed or the request has no or too many items.
+func (cat *Category) HandleBulkDeleteCategories(w http.ResponseWriter, r *http.Request) {
+	keys := []bulkKey{}
+
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(&keys)
+	defer r.Body.Close()
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.MALFORMED_JSON, errors.New("illegal json format"), nil)
+		return
+	}
+	if err = checkBulkSize(len(keys)); err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	batch := newBulkBatch(len(keys))
+	queries := make([]interface{}, 0, len(keys))
+	for i, key := range keys {
+		if !key.ID.Valid() {
+			batch.reject(i, errors.New("ID is not valid"))
+			continue
+		}
+		batch.accept(i)
+		queries = append(queries, models.Category{ID: key.ID, Version: key.Version})
+	}
+
+	result, err := cat.DeleteManyContext(r.Context(), requestDB(r, cat), cat.GetCollectionName(), queries)
+	batch.write(w, r, responses.BULK_DELETE_SUCCESS, result, err)
+}
+
 // RegisterApis registers the API endpoints associated with category operations.
 //
 // This method configures the routing for category-related API endpoints using the provided base router.
@@ -256,13 +667,30 @@ func (cat *Category) HandleDeleteCategory(w http.ResponseWriter, r *http.Request
 //   - GET    -> /api/readCategory/{id}: HandleReadCategory
 //   - PUT    -> /api/updateCategory: HandleUpdateCategory
 //   - DELETE -> /api/deleteCategory/{id}: HandleDeleteCategory
+//   - GET    -> /api/categories: HandleListCategories
+//   - PUT    -> /api/categories/{id}: HandleReplaceCategory
+//   - GET    -> /api/categories/trash: HandleListCategoryTrash
+//   - POST   -> /api/categories/trash/{id}/restore: HandleRestoreCategory
+//   - DELETE -> /api/categories/trash/{id}: HandlePurgeCategory
+//   - POST   -> /api/categories/bulk: HandleBulkAddCategories
+//   - PATCH  -> /api/categories/bulk: HandleBulkUpdateCategories
+//   - DELETE -> /api/categories/bulk: HandleBulkDeleteCategories
 //
 // Behavior:
 //   - Configures HTTP routes for category-related operations.
 //   - Associates each route with its respective handler function.
+//   - Lets every endpoint work on another database, named by the X-Database header, see inDatabase.
 func (cat *Category) RegisterApis() {
-	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/createCategory", cat.HandleCreateCategory).Methods("POST")
-	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/readCategory/{id}", cat.HandleReadCategory).Methods("GET")
-	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/updateCategory", cat.HandleUpdateCategory).Methods("PUT")
-	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/deleteCategory/{id}", cat.HandleDeleteCategory).Methods("DELETE")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/createCategory", inDatabase(cat, cat.HandleCreateCategory)).Methods("POST")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/readCategory/{id}", inDatabase(cat, cat.HandleReadCategory)).Methods("GET")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/updateCategory", inDatabase(cat, cat.HandleUpdateCategory)).Methods("PUT")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/deleteCategory/{id}", inDatabase(cat, cat.HandleDeleteCategory)).Methods("DELETE")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/categories", inDatabase(cat, cat.HandleListCategories)).Methods("GET")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/categories/{id}", inDatabase(cat, cat.HandleReplaceCategory)).Methods("PUT")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/categories/trash", inDatabase(cat, cat.HandleListCategoryTrash)).Methods("GET")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/categories/trash/{id}/restore", inDatabase(cat, cat.HandleRestoreCategory)).Methods("POST")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/categories/trash/{id}", inDatabase(cat, cat.HandlePurgeCategory)).Methods("DELETE")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/categories/bulk", inDatabase(cat, cat.HandleBulkAddCategories)).Methods("POST")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/categories/bulk", inDatabase(cat, cat.HandleBulkUpdateCategories)).Methods("PATCH")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/categories/bulk", inDatabase(cat, cat.HandleBulkDeleteCategories)).Methods("DELETE")
 }
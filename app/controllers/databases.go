@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"websays/config"
+	"websays/database/basefunctions"
+	"websays/database/basetypes"
+	"websays/httpHandler/baserouter"
+	"websays/httpHandler/responses"
+
+	"github.com/gorilla/mux"
+)
+
+// databaseHeader is the request header naming the database a request works on, instead of the one of its controller.
+const databaseHeader = "X-Database"
+
+// databaseContextKey is the key of the database selected by a request in the request context.
+type databaseContextKey struct{}
+
+// databaseScoped is a controller whose requests can select the database they work on.
+type databaseScoped interface {
+	basefunctions.BaseFucntionsInterface
+	GetDBName() basetypes.DBName
+}
+
+// requestDB returns the database a request works on, the one selected by inDatabase or else the one of the controller.
+func requestDB(r *http.Request, controller databaseScoped) basetypes.DBName {
+	if dbName, ok := r.Context().Value(databaseContextKey{}).(basetypes.DBName); ok {
+		return dbName
+	}
+	return controller.GetDBName()
+}
+
+// inDatabase wraps a handler of a controller so it works on the database named by the X-Database header, if any.
+//
+// Behavior:
+//   - Runs the handler on the database of the controller when the header is missing or names it.
+//   - Responds with API_NOT_AVAILABLE if the storage of the controller doesn't keep databases apart.
+//   - Responds with DATABASE_NOT_FOUND if the named database doesn't exist in the storage of the controller.
+func inDatabase(controller databaseScoped, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dbName := basetypes.DBName(r.Header.Get(databaseHeader))
+		if dbName == "" || dbName == controller.GetDBName() {
+			handler(w, r)
+			return
+		}
+
+		storage, ok := controller.GetFunctions().(basefunctions.DatabaseInterface)
+		if !ok {
+			responses.GetInstance().WriteJsonResponse(w, r, responses.API_NOT_AVAILABLE, errors.New("Storage doesn't keep databases apart"), nil)
+			return
+		}
+		exists, err := storage.HasDatabase(dbName)
+		if err == nil && !exists {
+			err = basefunctions.ErrDatabaseNotFound
+		}
+		if err != nil {
+			responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+			return
+		}
+		handler(w, r.WithContext(context.WithValue(r.Context(), databaseContextKey{}, dbName)))
+	}
+}
+
+// Databases is the controller listing, creating and dropping the databases of the storages keeping them apart,
+// so several datasets, such as a test and a staging one, can be hosted in one process.
+// Every operation applies to all of its storages, a database exists once for all the controllers using them.
+type Databases struct {
+	Storages []basefunctions.DatabaseInterface // The storages of the registered controllers keeping databases apart.
+}
+
+// databaseRequest is the body of a request creating a database.
+type databaseRequest struct {
+	Name basetypes.DBName `json:"name"` // Name of the database.
+}
+
+// HandleListDatabases handles the retrieval of the names of the databases.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+//
+// Behavior:
+//   - Calls the ListDatabases method of every storage.
+//   - Responds with the names of the databases of any storage, in alphabetical order.
+//   - Responds with an error message if listing the databases of a storage fails.
+func (u *Databases) HandleListDatabases(w http.ResponseWriter, r *http.Request) {
+	seen := make(map[basetypes.DBName]bool)
+	names := make([]basetypes.DBName, 0)
+	for _, storage := range u.Storages {
+		listed, err := storage.ListDatabasesContext(r.Context())
+		if err != nil {
+			responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+			return
+		}
+		for _, dbName := range listed {
+			if !seen[dbName] {
+				seen[dbName] = true
+				names = append(names, dbName)
+			}
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	responses.GetInstance().WriteJsonResponse(w, r, responses.LIST_DATABASES_SUCCESS, nil, names)
+}
+
+// HandleCreateDatabase handles the creation of the database named in the JSON body, {"name": "staging"}.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+//
+// Behavior:
+//   - Decodes the JSON body and checks the name of the database.
+//   - Calls the CreateDatabase method of every storage not having the database yet.
+//   - Responds with the name of the created database.
+//   - Responds with DATABASE_EXISTS if every storage already has the database.
+//   - Responds with an error message if the JSON is malformed, the name is invalid or the creation fails.
+func (u *Databases) HandleCreateDatabase(w http.ResponseWriter, r *http.Request) {
+	var request databaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.MALFORMED_JSON, err, nil)
+		return
+	}
+	if err := basefunctions.CheckDBName(request.Name); err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	created := false
+	for _, storage := range u.Storages {
+		err := storage.CreateDatabaseContext(r.Context(), request.Name)
+		if errors.Is(err, basefunctions.ErrDatabaseExists) {
+			continue
+		}
+		if err != nil {
+			responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.ADDING_DB_FAILED), err, nil)
+			return
+		}
+		created = true
+	}
+	if !created {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.DATABASE_EXISTS, basefunctions.ErrDatabaseExists, nil)
+		return
+	}
+	responses.GetInstance().WriteJsonResponse(w, r, responses.CREATE_DATABASE_SUCCESS, nil, request)
+}
+
+// HandleDropDatabase handles the removal of the database named in the route parameters, with all of its records.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+//
+// Behavior:
+//   - Refuses to drop the configured database, which the controllers work on by default.
+//   - Calls the DropDatabase method of every storage having the database.
+//   - Responds with the name of the dropped database.
+//   - Responds with DATABASE_NOT_FOUND if no storage has the database.
+//   - Responds with an error message if the removal fails.
+func (u *Databases) HandleDropDatabase(w http.ResponseWriter, r *http.Request) {
+	dbName := basetypes.DBName(mux.Vars(r)["name"])
+	if dbName == basetypes.DBName(config.GetInstance().Database.DBName) {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, errors.New("The configured database can't be dropped"), nil)
+		return
+	}
+
+	dropped := false
+	for _, storage := range u.Storages {
+		err := storage.DropDatabaseContext(r.Context(), dbName)
+		if errors.Is(err, basefunctions.ErrDatabaseNotFound) {
+			continue
+		}
+		if err != nil {
+			responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+			return
+		}
+		dropped = true
+	}
+	if !dropped {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.DATABASE_NOT_FOUND, basefunctions.ErrDatabaseNotFound, nil)
+		return
+	}
+	responses.GetInstance().WriteJsonResponse(w, r, responses.DROP_DATABASE_SUCCESS, nil, databaseRequest{Name: dbName})
+}
+
+// RegisterApis registers the API endpoints of the databases.
+// The endpoints registered are:
+//   - "/api/databases" (GET): Lists the databases.
+//   - "/api/databases" (POST): Creates a database.
+//   - "/api/databases/{name}" (DELETE): Drops a database with all of its records.
+func (u *Databases) RegisterApis() {
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/databases", u.HandleListDatabases).Methods("GET")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/databases", u.HandleCreateDatabase).Methods("POST")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/databases/{name}", u.HandleDropDatabase).Methods("DELETE")
+}
@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"websays/database/basefunctions"
+	"websays/httpHandler/responses"
+)
+
+// failureCode returns the response code of a failed storage call.
+// A call stopped by the deadline of the request responds with REQUEST_TIMEOUT, a stale version with VERSION_CONFLICT,
+// a missing or already existing database with DATABASE_NOT_FOUND or DATABASE_EXISTS, any other failure with code.
+func failureCode(err error, code int) int {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return responses.REQUEST_TIMEOUT
+	}
+	if errors.Is(err, basefunctions.ErrVersionConflict) {
+		return responses.VERSION_CONFLICT
+	}
+	if errors.Is(err, basefunctions.ErrDatabaseNotFound) {
+		return responses.DATABASE_NOT_FOUND
+	}
+	if errors.Is(err, basefunctions.ErrDatabaseExists) {
+		return responses.DATABASE_EXISTS
+	}
+	return code
+}
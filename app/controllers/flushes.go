@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"net/http"
+	"websays/database/basefunctions"
+	"websays/database/basetypes"
+	"websays/httpHandler/baserouter"
+	"websays/httpHandler/responses"
+)
+
+// Flushes is the controller exposing the persistence of the writes of the write-behind storages of the controllers,
+// enabled per controller by the writeBehind section of the config.
+type Flushes struct {
+	Storages map[string]basefunctions.FlushInterface // The write-behind storage of each controller with one, by controller name.
+}
+
+// HandleFlushStats handles the retrieval of the statistics of the write-behind storages.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+//
+// Behavior:
+//   - Calls the FlushStats method of the write-behind storage of every controller with one.
+//   - Responds with the pending writes, the lag of the oldest one and the flushes of each storage, by controller name.
+func (u *Flushes) HandleFlushStats(w http.ResponseWriter, r *http.Request) {
+	stats := make(map[string]basetypes.FlushStats, len(u.Storages))
+	for name, storage := range u.Storages {
+		stats[name] = storage.FlushStats()
+	}
+	responses.GetInstance().WriteJsonResponse(w, r, responses.FLUSH_STATS_SUCCESS, nil, stats)
+}
+
+// RegisterApis registers the API endpoints of the write-behind storages.
+// The endpoints registered are:
+//   - "/api/flushStats" (GET): Reads the statistics of the write-behind storages.
+func (u *Flushes) RegisterApis() {
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/flushStats", u.HandleFlushStats).Methods("GET")
+}
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"websays/database/basefilters"
+	"websays/database/basetypes"
+)
+
+// readFindOptions reads the paging options of a list request from its query string.
+//
+// Supported query parameters:
+//   - limit:  The maximum number of records to return.
+//   - offset: The number of records to skip.
+//   - cursor: The opaque cursor returned as nextCursor by a previous list request.
+//
+// Returns:
+//   - basetypes.FindOptions: The paging options to pass to FindMany.
+//   - error: An error if limit or offset is not a valid number.
+func readFindOptions(r *http.Request) (basetypes.FindOptions, error) {
+	options := basetypes.FindOptions{}
+	values := r.URL.Query()
+
+	if limit := values.Get("limit"); limit != "" {
+		limitInt, err := strconv.Atoi(limit)
+		if err != nil || limitInt < 0 {
+			return options, errors.New("limit is not valid")
+		}
+		options.Limit = limitInt
+	}
+
+	if offset := values.Get("offset"); offset != "" {
+		offsetInt, err := strconv.Atoi(offset)
+		if err != nil || offsetInt < 0 {
+			return options, errors.New("offset is not valid")
+		}
+		options.Offset = offsetInt
+	}
+
+	options.Cursor = values.Get("cursor")
+	return options, nil
+}
+
+// readFilter reads the optional JSON encoded filter of a list request from the "filter" query parameter.
+//
+// Example:
+//
+//	/api/categories?filter={"op":"like","field":"name","value":"book%"}
+//
+// Returns:
+//   - interface{}: The decoded basefilters.Filter, or nil if no filter was sent.
+//   - error: An error if the filter is malformed or uses an unknown operator or invalid field.
+func readFilter(r *http.Request) (interface{}, error) {
+	encoded := r.URL.Query().Get("filter")
+	if encoded == "" {
+		return nil, nil
+	}
+
+	filter := basefilters.Filter{}
+	err := json.Unmarshal([]byte(encoded), &filter)
+	if err != nil {
+		return nil, errors.New("filter is not valid json")
+	}
+
+	err = filter.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
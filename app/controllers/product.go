@@ -4,12 +4,11 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
-	"log"
 	"net/http"
-	"strconv"
 	"websays/app/models"
 	"websays/config"
 	"websays/database/basefunctions"
+	"websays/database/baseids"
 	"websays/database/basetypes"
 	"websays/httpHandler/basecontrollers/baseinterfaces"
 	"websays/httpHandler/baserouter"
@@ -27,6 +26,7 @@ type Product struct {
 	baseinterfaces.BaseControllerFactory
 	basefunctions.BaseFucntionsInterface
 	basevalidators.ValidatorInterface
+	baseids.IDGenerator
 	isIndexed bool
 }
 
@@ -68,11 +68,23 @@ func (pro *Product) DoIndexing() error {
 // SetBaseFunctions sets the base functions interface for product-related operations.
 //
 // This method assigns the provided base functions interface to the product controller.
+// The sequence becomes the ID generator, leaving the IDs to the AUTO_INCREMENT column, until SetIDGenerator sets another one.
 //
 // Parameters:
 //   - inter: The base functions interface to be assigned.
 func (pro *Product) SetBaseFunctions(inter basefunctions.BaseFucntionsInterface) {
 	pro.BaseFucntionsInterface = inter
+	pro.IDGenerator = baseids.NewSequence(inter)
+}
+
+// SetIDGenerator sets the generator of the IDs of the products created by the product controller.
+// The strategies other than the sequence generate the IDs themselves, so the id column must hold them,
+// a BIGINT for snowflake IDs and a VARCHAR for the string keys.
+//
+// Parameters:
+//   - generator: The IDGenerator of the strategy configured for the product controller.
+func (pro *Product) SetIDGenerator(generator baseids.IDGenerator) {
+	pro.IDGenerator = generator
 }
 
 // HandleCreateProduct handles the creation of a new product based on the JSON data provided in the request body.
@@ -81,7 +93,9 @@ func (pro *Product) SetBaseFunctions(inter basefunctions.BaseFucntionsInterface)
 //   - Checks if the product data is indexed; if not, it triggers the indexing process.
 //   - Decodes the JSON data from the request body into a product struct.
 //   - Validates the product data using the product validator.
+//   - Generates the ID of the product with the ID generator of the controller, if its strategy doesn't leave it to the database.
 //   - Adds the product to the database using the specified MySQL controller.
+//   - Reads the product back for its version and creation metadata.
 //   - Responds with a JSON-encoded success message upon successful product creation.
 //   - Responds with an error message if the validation or creation operation fails.
 //
@@ -110,15 +124,31 @@ func (pro *Product) HandleCreateProduct(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// The sequence leaves the ID to the AUTO_INCREMENT column, the other strategies generate it
+	product.ID, err = pro.NextID(r.Context(), requestDB(r, pro), pro.GetCollectionName())
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+
 	// Calling the Add method for the MySQL controller
-	product.ID, err = pro.Add(pro.GetDBName(), pro.GetCollectionName(), product)
+	product.ID, err = pro.AddContext(r.Context(), requestDB(r, pro), pro.GetCollectionName(), product)
 	if err != nil {
-		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
 		return
 	}
 
-	// Respond with a JSON-encoded success message
-	responses.GetInstance().WriteJsonResponse(w, r, responses.ADD_PRODUCT_SUCCESS, nil, product)
+	// Read the product back for its version and the metadata recorded by the storage
+	data, err := pro.FindOneContext(r.Context(), requestDB(r, pro), pro.GetCollectionName(), map[string]interface{}{"id": product.ID})
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+
+	// Respond with a JSON-encoded success message and the created product
+	writeETag(w, data)
+	writeLastModified(w, data)
+	responses.GetInstance().WriteJsonResponse(w, r, responses.ADD_PRODUCT_SUCCESS, nil, data)
 }
 
 // HandleReadProduct retrieves product information based on the provided product ID in the URL route parameter.
@@ -126,10 +156,12 @@ func (pro *Product) HandleCreateProduct(w http.ResponseWriter, r *http.Request)
 // This method performs the following steps:
 //   - Extracts the product ID from the route parameters and validates it.
 //   - Constructs a query to find a product with the specified ID.
-//   - Calls the FindOne method for the MySQL controller to retrieve product data.
-//   - Parses and extracts product details from the SQL result rows.
-//   - Responds with a JSON-encoded success message containing the product information.
-//   - Responds with an error message if the validation, query execution, or product not found.
+//   - Calls the FindOne method for the MySQL controller to retrieve the product model, filled through its `db` tags.
+//   - Responds with 304 Not Modified if the product wasn't updated since the If-Modified-Since header.
+//   - Responds with a JSON-encoded success message containing the product information, with its version as ETag
+//     and its update time as Last-Modified.
+//   - Responds with a no product found message if no product has the ID.
+//   - Responds with an error message if the validation or query execution fails.
 //
 // Parameters:
 //   - w:   The http.ResponseWriter for sending the HTTP response.
@@ -138,45 +170,34 @@ func (pro *Product) HandleReadProduct(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 	query := make(map[string]interface{})
-	idInt, err := strconv.ParseInt(id, 10, 64)
+	parsedID, err := basetypes.ParseID(id)
 
 	if err != nil {
 		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
 		return
 	}
 
-	query["id"] = int(idInt)
+	query["id"] = parsedID
 
-	// Calling the FindOne method for the MySQL controller
-	data, err := pro.FindOne(pro.GetDBName(), pro.GetCollectionName(), query)
-	if err != nil {
-		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+	// Calling the FindOne method for the MySQL controller, which returns the product model
+	product, err := pro.FindOneContext(r.Context(), requestDB(r, pro), pro.GetCollectionName(), query)
+	if errors.Is(err, sql.ErrNoRows) {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.NO_PRDUCT_FOUND, errors.New("No Product found"), nil)
 		return
 	}
-
-	rows := data.(*sql.Rows)
-
-	var product models.Product
-	rowsCount := 0
-
-	// Reading and parsing rows
-	for rows.Next() {
-		err = rows.Scan(&product.ID, &product.Name)
-		rowsCount++
-	}
-	log.Println(err)
 	if err != nil {
-		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
 		return
 	}
 
-	log.Println(rowsCount)
-	if rowsCount == 0 {
-		responses.GetInstance().WriteJsonResponse(w, r, responses.NO_PRDUCT_FOUND, errors.New("No Product found"), nil)
+	// Respond with 304 Not Modified if the product wasn't updated since If-Modified-Since
+	if notModified(w, r, product) {
 		return
 	}
 
-	// Respond with a JSON-encoded success message
+	// Respond with a JSON-encoded success message and the version of the product as ETag
+	writeETag(w, product)
+	writeLastModified(w, product)
 	responses.GetInstance().WriteJsonResponse(w, r, responses.READ_PRODUCT_SUCCESS, nil, product)
 }
 
@@ -186,8 +207,10 @@ func (pro *Product) HandleReadProduct(w http.ResponseWriter, r *http.Request) {
 //   - Decodes the JSON data from the request body into a product struct.
 //   - Calls the Validate method to validate the product data.
 //   - Constructs conditions and data maps for updating the product.
+//   - Reads the version required by the If-Match header, or else by the body, if any.
 //   - Calls the UpdateOne method for the underlying database controller to update the product.
-//   - Responds with a JSON-encoded success message containing the updated product information.
+//   - Responds with a 412 version conflict if the product is no longer at the required version, or NO_PRDUCT_FOUND if there is no such product.
+//   - Responds with a JSON-encoded success message containing the updated product, with its new version as ETag.
 //   - Responds with an error message if the JSON decoding, validation, or database update fails.
 //
 // Parameters:
@@ -217,24 +240,56 @@ func (pro *Product) HandleUpdateProduct(w http.ResponseWriter, r *http.Request)
 	data := make(map[string]interface{})
 	data["name"] = product.Name
 
-	// Calling the UpdateOne method for the underlying database controller
-	err = pro.UpdateOne(pro.GetDBName(), pro.GetCollectionName(), conditions, data, false)
+	// An If-Match header requires the product to still be at the version of its ETag, overriding the version of the body
+	version, err := readIfMatch(r)
 	if err != nil {
 		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
 		return
 	}
+	if version != 0 {
+		product.Version = version
+	}
+	if product.Version != 0 {
+		data["version"] = product.Version
+	}
+
+	// Calling the UpdateOne method for the underlying database controller
+	err = pro.UpdateOneContext(r.Context(), requestDB(r, pro), pro.GetCollectionName(), conditions, data, false)
+	if errors.Is(err, sql.ErrNoRows) {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.NO_PRDUCT_FOUND, errors.New("No Product found"), nil)
+		return
+	}
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+
+	// Read the product back for the version given by the update
+	updated, err := pro.FindOneContext(r.Context(), requestDB(r, pro), pro.GetCollectionName(), conditions)
+	if errors.Is(err, sql.ErrNoRows) {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.NO_PRDUCT_FOUND, errors.New("No Product found"), nil)
+		return
+	}
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
 
 	// Respond with a JSON-encoded success message
-	responses.GetInstance().WriteJsonResponse(w, r, responses.UPDATE_PRODUCT_SUCCESS, nil, product)
+	writeETag(w, updated)
+	writeLastModified(w, updated)
+	responses.GetInstance().WriteJsonResponse(w, r, responses.UPDATE_PRODUCT_SUCCESS, nil, updated)
 }
 
 // HandleDeleteProduct handles the deletion of a product based on the provided product ID in the route parameters.
 //
 // This method performs the following steps:
 //   - Extracts the product ID from the route parameters.
-//   - Validates the product ID and converts it to an integer.
+//   - Validates the product ID, an integer or a string key.
 //   - Constructs a conditions map for specifying the product to delete.
-//   - Calls the DeleteOne method for the underlying database controller to delete the product.
+//   - Reads the version required by the If-Match header, if any.
+//   - Calls the DeleteOne method for the underlying database controller to move the product to the trash.
+//   - Responds with a 412 version conflict if the product is no longer at the required version.
 //   - Responds with a JSON-encoded success message upon successful deletion.
 //   - Responds with an error message if the validation or deletion operation fails.
 //
@@ -245,7 +300,7 @@ func (pro *Product) HandleDeleteProduct(w http.ResponseWriter, r *http.Request)
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	idInt, err := strconv.ParseInt(id, 10, 64)
+	parsedID, err := basetypes.ParseID(id)
 
 	if err != nil {
 		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
@@ -253,19 +308,388 @@ func (pro *Product) HandleDeleteProduct(w http.ResponseWriter, r *http.Request)
 	}
 
 	conditions := make(map[string]interface{})
-	conditions["id"] = idInt
+	conditions["id"] = parsedID
 
-	// Calling the DeleteOne method for the underlying database controller
-	err = pro.DeleteOne(pro.GetDBName(), pro.GetCollectionName(), conditions)
+	// An If-Match header requires the product to still be at the version of its ETag
+	version, err := readIfMatch(r)
 	if err != nil {
 		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
 		return
 	}
+	if version != 0 {
+		conditions["version"] = version
+	}
+
+	// Calling the DeleteOne method for the underlying database controller
+	err = pro.DeleteOneContext(r.Context(), requestDB(r, pro), pro.GetCollectionName(), conditions)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
 
 	// Respond with a JSON-encoded success message
 	responses.GetInstance().WriteJsonResponse(w, r, responses.DELETE_PRODUCT_SUCCESS, nil, nil)
 }
 
+// HandleReplaceProduct handles the creation or replacement of the product with the ID given in the route parameters.
+//
+// This method expects a JSON-encoded product object in the request body, which is stored under the ID of the URL
+// whether or not a product with that ID exists yet. It is performed by calling the UpdateOne method with upsert.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+//
+// Behavior:
+//   - Checks if the product data is indexed; if not, it triggers the indexing process.
+//   - Extracts the product ID from the route parameters and decodes the product from the request body.
+//   - Rejects a body carrying an ID other than the one of the URL.
+//   - Validates the product data using the Validate method.
+//   - Reads the version required by the If-Match header, or else by the body, if any.
+//   - Calls the UpdateOne method with upsert to create or replace the product in the underlying database controller.
+//   - Responds with a 412 version conflict if the product is no longer at the required version.
+//   - Responds with a JSON-encoded success message containing the stored product, with its version as ETag.
+//   - Responds with an error message if the ID, the JSON data, the validation or the operation fails.
+func (pro *Product) HandleReplaceProduct(w http.ResponseWriter, r *http.Request) {
+	if !pro.isIndexed {
+		pro.DoIndexing()
+	}
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	parsedID, err := basetypes.ParseID(id)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	product := models.Product{}
+	decoder := json.NewDecoder(r.Body)
+	err = decoder.Decode(&product)
+	defer r.Body.Close()
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.MALFORMED_JSON, errors.New("illegal json format"), nil)
+		return
+	}
+	if product.ID != "" && product.ID != parsedID {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, errors.New("Product ID doesn't match the URL"), nil)
+		return
+	}
+	product.ID = parsedID
+
+	err = pro.Validate("/api/products/{id}", product)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	// An If-Match header requires the product to still be at the version of its ETag, overriding the version of the body
+	version, err := readIfMatch(r)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+	if version != 0 {
+		product.Version = version
+	}
+
+	conditions := make(map[string]interface{})
+	conditions["id"] = product.ID
+
+	// Calling the UpdateOne method with upsert, which MySQL runs as INSERT ... ON DUPLICATE KEY UPDATE
+	err = pro.UpdateOneContext(r.Context(), requestDB(r, pro), pro.GetCollectionName(), conditions, product, true)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+
+	// Read the product back for the version it was stored at
+	data, err := pro.FindOneContext(r.Context(), requestDB(r, pro), pro.GetCollectionName(), conditions)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+	writeETag(w, data)
+	writeLastModified(w, data)
+	responses.GetInstance().WriteJsonResponse(w, r, responses.UPDATE_PRODUCT_SUCCESS, nil, data)
+}
+
+// HandleListProducts retrieves a page of products.
+//
+// This method performs the following steps:
+//   - Checks if the product data is indexed; if not, it triggers the indexing process.
+//   - Reads the paging options (limit, offset or cursor) and the optional JSON "filter" from the query string.
+//   - Calls the FindMany method for the MySQL controller to retrieve the page.
+//   - Responds with a JSON-encoded page containing the products, the total count and the next cursor.
+//   - Responds with an error message if the paging options or the filter are invalid, or the query fails.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+func (pro *Product) HandleListProducts(w http.ResponseWriter, r *http.Request) {
+	if !pro.isIndexed {
+		pro.DoIndexing()
+	}
+
+	options, err := readFindOptions(r)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	filter, err := readFilter(r)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	// Calling the FindMany method for the MySQL controller
+	result, err := pro.FindManyContext(r.Context(), requestDB(r, pro), pro.GetCollectionName(), filter, options)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+
+	// Respond with a JSON-encoded page of products
+	responses.GetInstance().WriteJsonResponse(w, r, responses.LIST_PRODUCT_SUCCESS, nil, result)
+}
+
+// HandleListProductTrash retrieves a page of the trashed products.
+//
+// This method performs the following steps:
+//   - Checks if the product data is indexed; if not, it triggers the indexing process.
+//   - Reads the paging options and the optional JSON "filter" from the query string, like HandleListProducts.
+//   - Calls the FindTrash method for the MySQL controller to retrieve the page of the trash.
+//   - Responds with a JSON-encoded page containing the trashed products, the total count and the next cursor.
+//   - Responds with an error message if the paging options or the filter are invalid, or the query fails.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+func (pro *Product) HandleListProductTrash(w http.ResponseWriter, r *http.Request) {
+	if !pro.isIndexed {
+		pro.DoIndexing()
+	}
+	writeTrashPage(w, r, pro, requestDB(r, pro), pro.GetCollectionName())
+}
+
+// HandleRestoreProduct moves the trashed product with the ID given in the route parameters out of the trash.
+//
+// This method performs the following steps:
+//   - Checks if the product data is indexed; if not, it triggers the indexing process.
+//   - Extracts the product ID from the route parameters and validates it.
+//   - Calls the Restore method for the MySQL controller.
+//   - Responds with a JSON-encoded success message containing the restored product, with its version as ETag.
+//   - Responds with an error message if the ID is invalid, the product is not in the trash or the query fails.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+func (pro *Product) HandleRestoreProduct(w http.ResponseWriter, r *http.Request) {
+	if !pro.isIndexed {
+		pro.DoIndexing()
+	}
+
+	parsedID, err := basetypes.ParseID(mux.Vars(r)["id"])
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	conditions := make(map[string]interface{})
+	conditions["id"] = parsedID
+	writeRestored(w, r, pro, requestDB(r, pro), pro.GetCollectionName(), conditions)
+}
+
+// HandlePurgeProduct permanently removes the trashed product with the ID given in the route parameters.
+//
+// This method performs the following steps:
+//   - Checks if the product data is indexed; if not, it triggers the indexing process.
+//   - Extracts the product ID from the route parameters and validates it.
+//   - Calls the Purge method for the MySQL controller, which only removes products in the trash.
+//   - Responds with a JSON-encoded success message containing the ID of the purged product.
+//   - Responds with an error message if the ID is invalid, the product is not in the trash or the query fails.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+func (pro *Product) HandlePurgeProduct(w http.ResponseWriter, r *http.Request) {
+	if !pro.isIndexed {
+		pro.DoIndexing()
+	}
+
+	parsedID, err := basetypes.ParseID(mux.Vars(r)["id"])
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	conditions := make(map[string]interface{})
+	conditions["id"] = parsedID
+	writePurged(w, r, pro, requestDB(r, pro), pro.GetCollectionName(), conditions, conditions)
+}
+
+// HandleBulkAddProducts creates several products based on the JSON array provided in the request body.
+//
+// This method performs the following steps:
+//   - Checks if the product data is indexed; if not, it triggers the indexing process.
+//   - Decodes the JSON array from the request body into product structs, accepting up to 1000 products.
+//   - Validates each product like a single creation, rejecting the invalid ones without adding them.
+//   - Calls the AddMany method for the MySQL controller, which inserts the valid products with multi-row INSERT statements.
+//   - Responds with the outcome of each product in the order of the request, with the ID generated for the created ones,
+//     and a partial failure code if any failed.
+//   - Responds with an error message if the JSON data is malformed or the request has no or too many products.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+func (pro *Product) HandleBulkAddProducts(w http.ResponseWriter, r *http.Request) {
+	if !pro.isIndexed {
+		pro.DoIndexing()
+	}
+
+	products := []models.Product{}
+
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(&products)
+	defer r.Body.Close()
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.MALFORMED_JSON, errors.New("illegal json format"), nil)
+		return
+	}
+	if err = checkBulkSize(len(products)); err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	batch := newBulkBatch(len(products))
+	data := make([]interface{}, 0, len(products))
+	for i, product := range products {
+		if err := pro.Validate("/api/products/bulk", product); err != nil {
+			batch.reject(i, err)
+			continue
+		}
+		if product.ID, err = pro.NextID(r.Context(), requestDB(r, pro), pro.GetCollectionName()); err != nil {
+			batch.reject(i, err)
+			continue
+		}
+		batch.accept(i)
+		data = append(data, product)
+	}
+
+	result, err := pro.AddManyContext(r.Context(), requestDB(r, pro), pro.GetCollectionName(), data)
+	batch.write(w, r, responses.BULK_ADD_SUCCESS, result, err)
+}
+
+// HandleBulkUpdateProducts updates several products based on the JSON array provided in the request body.
+//
+// This method performs the following steps:
+//   - Checks if the product data is indexed; if not, it triggers the indexing process.
+//   - Decodes the JSON array from the request body into product structs, accepting up to 1000 products.
+//   - Validates each product like a single update, rejecting the invalid ones without updating them.
+//   - Calls the UpdateMany method for the MySQL controller, updating the name of each product by its ID,
+//     restricted to the version of the product if it carries one.
+//   - Responds with the outcome of each product in the order of the request, and a partial failure code if any failed.
+//   - Responds with an error message if the JSON data is malformed or the request has no or too many products.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+func (pro *Product) HandleBulkUpdateProducts(w http.ResponseWriter, r *http.Request) {
+	if !pro.isIndexed {
+		pro.DoIndexing()
+	}
+
+	products := []models.Product{}
+
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(&products)
+	defer r.Body.Close()
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.MALFORMED_JSON, errors.New("illegal json format"), nil)
+		return
+	}
+	if err = checkBulkSize(len(products)); err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	batch := newBulkBatch(len(products))
+	updates := make([]basetypes.BulkUpdate, 0, len(products))
+	for i, product := range products {
+		if err := pro.Validate("/api/products/{id}", product); err != nil {
+			batch.reject(i, err)
+			continue
+		}
+
+		data := make(map[string]interface{})
+		data["name"] = product.Name
+		if product.Version != 0 {
+			data["version"] = product.Version
+		}
+		batch.accept(i)
+		updates = append(updates, basetypes.BulkUpdate{Query: map[string]interface{}{"id": product.ID}, Data: data})
+	}
+
+	result, err := pro.UpdateManyContext(r.Context(), requestDB(r, pro), pro.GetCollectionName(), updates)
+	batch.write(w, r, responses.BULK_UPDATE_SUCCESS, result, err)
+}
+
+// HandleBulkDeleteProducts deletes several products identified in the JSON array provided in the request body.
+//
+// This method performs the following steps:
+//   - Checks if the product data is indexed; if not, it triggers the indexing process.
+//   - Decodes the JSON array from the request body, each item carrying the "id" of a product and optionally
+//     the "version" it is required to still be at, accepting up to 1000 items.
+//   - Rejects the items without a valid ID.
+//   - Calls the DeleteMany method for the MySQL controller to move the products to the trash.
+//   - Responds with the outcome of each product in the order of the request, and a partial failure code if any failed.
+//   - Responds with an error message if the JSON data is malformed or the request has no or too many items.
+//
+// Parameters:
+//   - w:   The http.ResponseWriter for sending the HTTP response.
+//   - r:   The http.Request containing the incoming HTTP request.
+func (pro *Product) HandleBulkDeleteProducts(w http.ResponseWriter, r *http.Request) {
+	if !pro.isIndexed {
+		pro.DoIndexing()
+	}
+
+	keys := []bulkKey{}
+
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(&keys)
+	defer r.Body.Close()
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.MALFORMED_JSON, errors.New("illegal json format"), nil)
+		return
+	}
+	if err = checkBulkSize(len(keys)); err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	batch := newBulkBatch(len(keys))
+	queries := make([]interface{}, 0, len(keys))
+	for i, key := range keys {
+		if !key.ID.Valid() {
+			batch.reject(i, errors.New("ID is not proper"))
+			continue
+		}
+
+		conditions := make(map[string]interface{})
+		conditions["id"] = key.ID
+		if key.Version != 0 {
+			conditions["version"] = key.Version
+		}
+		batch.accept(i)
+		queries = append(queries, conditions)
+	}
+
+	result, err := pro.DeleteManyContext(r.Context(), requestDB(r, pro), pro.GetCollectionName(), queries)
+	batch.write(w, r, responses.BULK_DELETE_SUCCESS, result, err)
+}
+
 // RegisterApis registers the API endpoints for product-related operations.
 //
 // This method associates the HTTP handlers for creating, reading, updating, and deleting products
@@ -274,14 +698,31 @@ func (pro *Product) HandleDeleteProduct(w http.ResponseWriter, r *http.Request)
 //   - GET /api/readProduct/{id}: Read the details of a product by its ID.
 //   - DELETE /api/deleteProduct/{id}: Delete a product by its ID.
 //   - PUT /api/updateProduct: Update the details of a product.
+//   - GET /api/products: List a page of products.
+//   - PUT /api/products/{id}: Create or replace a product by its ID.
+//   - GET /api/products/trash: List a page of trashed products.
+//   - POST /api/products/trash/{id}/restore: Move a product out of the trash by its ID.
+//   - DELETE /api/products/trash/{id}: Permanently remove a trashed product by its ID.
+//   - POST /api/products/bulk: Create several products.
+//   - PATCH /api/products/bulk: Update several products.
+//   - DELETE /api/products/bulk: Delete several products.
 //
 // Each API route is associated with a corresponding HTTP handler method in the Product controller.
+// The X-Database header can only name the configured database, as the MySQL storage doesn't keep databases apart.
 //
 // Usage:
 //   Call this method during the application setup to define the product-related API endpoints.
 func (pro *Product) RegisterApis() {
-	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/createProduct", pro.HandleCreateProduct).Methods("POST")
-	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/readProduct/{id}", pro.HandleReadProduct).Methods("GET")
-	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/deleteProduct/{id}", pro.HandleDeleteProduct).Methods("DELETE")
-	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/updateProduct", pro.HandleUpdateProduct).Methods("PUT")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/createProduct", inDatabase(pro, pro.HandleCreateProduct)).Methods("POST")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/readProduct/{id}", inDatabase(pro, pro.HandleReadProduct)).Methods("GET")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/deleteProduct/{id}", inDatabase(pro, pro.HandleDeleteProduct)).Methods("DELETE")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/updateProduct", inDatabase(pro, pro.HandleUpdateProduct)).Methods("PUT")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/products", inDatabase(pro, pro.HandleListProducts)).Methods("GET")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/products/{id}", inDatabase(pro, pro.HandleReplaceProduct)).Methods("PUT")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/products/trash", inDatabase(pro, pro.HandleListProductTrash)).Methods("GET")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/products/trash/{id}/restore", inDatabase(pro, pro.HandleRestoreProduct)).Methods("POST")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/products/trash/{id}", inDatabase(pro, pro.HandlePurgeProduct)).Methods("DELETE")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/products/bulk", inDatabase(pro, pro.HandleBulkAddProducts)).Methods("POST")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/products/bulk", inDatabase(pro, pro.HandleBulkUpdateProducts)).Methods("PATCH")
+	baserouter.GetInstance().GetBaseRouter().HandleFunc("/api/products/bulk", inDatabase(pro, pro.HandleBulkDeleteProducts)).Methods("DELETE")
 }
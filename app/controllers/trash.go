@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"websays/database/basefunctions"
+	"websays/database/basetypes"
+	"websays/httpHandler/responses"
+)
+
+// trashOf returns the trash of the storage of a controller, or an error if the storage doesn't keep one.
+func trashOf(functions basefunctions.BaseFucntionsInterface) (basefunctions.TrashInterface, error) {
+	trash, ok := functions.GetFunctions().(basefunctions.TrashInterface)
+	if !ok {
+		return nil, errors.New("Storage doesn't keep a trash")
+	}
+	return trash, nil
+}
+
+// writeTrashPage responds with a page of the trash of a collection.
+//
+// Behavior:
+//   - Reads the paging options and the filter from the query string, like the list endpoints.
+//   - Calls the FindTrash method to retrieve the page of trashed records from the storage.
+//   - Responds with a JSON-encoded page containing the trashed records, the total count and the next cursor.
+//   - Responds with API_NOT_AVAILABLE if the storage doesn't keep a trash.
+//   - Responds with an error message if the paging options or the filter are invalid, or the retrieval fails.
+func writeTrashPage(w http.ResponseWriter, r *http.Request, functions basefunctions.BaseFucntionsInterface, dbName basetypes.DBName, collectionName basetypes.CollectionName) {
+	trash, err := trashOf(functions)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.API_NOT_AVAILABLE, err, nil)
+		return
+	}
+
+	options, err := readFindOptions(r)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	filter, err := readFilter(r)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.VALIDATION_FAILED, err, nil)
+		return
+	}
+
+	result, err := trash.FindTrashContext(r.Context(), dbName, collectionName, filter, options)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+	responses.GetInstance().WriteJsonResponse(w, r, responses.LIST_TRASH_SUCCESS, nil, result)
+}
+
+// writeRestored moves the trashed record matching a condition out of the trash and responds with it.
+//
+// Behavior:
+//   - Calls the Restore method to move the record out of the trash of the storage.
+//   - Reads the record back by the same condition, and responds with it and its new version as ETag.
+//   - Responds with API_NOT_AVAILABLE if the storage doesn't keep a trash.
+//   - Responds with an error message if no trashed record matches or the operation fails.
+func writeRestored(w http.ResponseWriter, r *http.Request, functions basefunctions.BaseFucntionsInterface, dbName basetypes.DBName, collectionName basetypes.CollectionName, condition interface{}) {
+	trash, err := trashOf(functions)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.API_NOT_AVAILABLE, err, nil)
+		return
+	}
+
+	err = trash.RestoreContext(r.Context(), dbName, collectionName, condition)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+
+	data, err := functions.FindOneContext(r.Context(), dbName, collectionName, condition)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+	writeETag(w, data)
+	writeLastModified(w, data)
+	responses.GetInstance().WriteJsonResponse(w, r, responses.RESTORE_SUCCESS, nil, data)
+}
+
+// writePurged permanently removes the trashed record matching a condition and responds with data.
+//
+// Behavior:
+//   - Calls the Purge method to remove the record from the trash of the storage.
+//   - Responds with API_NOT_AVAILABLE if the storage doesn't keep a trash.
+//   - Responds with an error message if no trashed record matches or the operation fails.
+func writePurged(w http.ResponseWriter, r *http.Request, functions basefunctions.BaseFucntionsInterface, dbName basetypes.DBName, collectionName basetypes.CollectionName, condition interface{}, data interface{}) {
+	trash, err := trashOf(functions)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, responses.API_NOT_AVAILABLE, err, nil)
+		return
+	}
+
+	err = trash.PurgeContext(r.Context(), dbName, collectionName, condition)
+	if err != nil {
+		responses.GetInstance().WriteJsonResponse(w, r, failureCode(err, responses.VALIDATION_FAILED), err, nil)
+		return
+	}
+	responses.GetInstance().WriteJsonResponse(w, r, responses.PURGE_SUCCESS, nil, data)
+}
@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"websays/database/basefunctions"
+)
+
+// readIfMatch returns the version required by the If-Match header of a request, as sent in the ETag of a read.
+// It returns 0, requiring no version, when the header is missing or "*".
+func readIfMatch(r *http.Request) (int, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(strings.Trim(header, `"`))
+	if err != nil || version < 1 || !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) {
+		return 0, errors.New("If-Match must be a single ETag of the record")
+	}
+	return version, nil
+}
+
+// writeETag sets the ETag header of a response to the version of a record, if it has one.
+// It must be called before the response is written.
+func writeETag(w http.ResponseWriter, record interface{}) {
+	if version := basefunctions.RecordVersion(record); version != 0 {
+		w.Header().Set("ETag", `"`+strconv.Itoa(version)+`"`)
+	}
+}
+
+// writeLastModified sets the Last-Modified header of a response to the update time of a record, if it has one.
+// It must be called before the response is written.
+func writeLastModified(w http.ResponseWriter, record interface{}) {
+	if updatedAt := basefunctions.RecordMetadata(record).UpdatedAt; updatedAt != 0 {
+		w.Header().Set("Last-Modified", time.Unix(updatedAt, 0).UTC().Format(http.TimeFormat))
+	}
+}
+
+// notModified responds with 304 Not Modified, and returns true, if a record wasn't updated since the time
+// in the If-Modified-Since header of a request. A record without update time, or a malformed header, never matches.
+func notModified(w http.ResponseWriter, r *http.Request, record interface{}) bool {
+	updatedAt := basefunctions.RecordMetadata(record).UpdatedAt
+	header := r.Header.Get("If-Modified-Since")
+	if updatedAt == 0 || header == "" {
+		return false
+	}
+	since, err := http.ParseTime(header)
+	if err != nil || updatedAt > since.Unix() {
+		return false
+	}
+	writeETag(w, record)
+	writeLastModified(w, record)
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
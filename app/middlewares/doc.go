@@ -5,5 +5,6 @@
 // Middleware components in this package are designed to intercept, modify, or enhance
 // HTTP requests and responses universally, ensuring consistency and common functionality
 // across all API routes. For instance, CORS (Cross-Origin Resource Sharing) middleware
-// is one such component responsible for handling cross-origin requests.
+// is one such component responsible for handling cross-origin requests, and the principal
+// middleware puts the principal making a request in its context.
 package middlewares
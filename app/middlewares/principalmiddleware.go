@@ -0,0 +1,65 @@
+package middlewares
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"websays/config"
+	"websays/database/basetypes"
+)
+
+// PrincipalMiddleware is a middleware putting the principal making each request in the request's context.
+// The server doesn't authenticate requests itself, so the principal is read from the header set by the
+// authenticating proxy in front of it. The storages record it as the creator or last updater of the records written.
+type PrincipalMiddleware struct {
+}
+
+// GetHandlerFunc returns an HTTP handler function for the principal middleware.
+// The principal is read from the header named by the principalHeader of the server config, and only
+// accepted from a peer listed in its trustedProxies, as any other client could set the header itself.
+// Without a configured header, from an untrusted peer, or when the request doesn't carry it, the request has no principal.
+//
+// Parameters:
+//   - next: The next HTTP handler in the middleware chain.
+//
+// Returns:
+//   - http.Handler: An HTTP handler function that wraps the provided 'next' handler and runs it
+//     with a context carrying the principal of the request.
+func (c *PrincipalMiddleware) GetHandlerFunc(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := config.GetInstance().Server.PrincipalHeader
+		if header == "" || !trustedProxy(r.RemoteAddr) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		principal := strings.TrimSpace(r.Header.Get(header))
+		if principal == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(basetypes.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// trustedProxy reports whether the peer at remoteAddr is one of the trustedProxies of the server config,
+// listed by address or by CIDR range.
+func trustedProxy(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, proxy := range config.GetInstance().Server.TrustedProxies {
+		if _, network, err := net.ParseCIDR(proxy); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+		} else if trusted := net.ParseIP(proxy); trusted != nil && trusted.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
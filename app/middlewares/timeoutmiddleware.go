@@ -0,0 +1,54 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"time"
+	"websays/config"
+
+	"github.com/gorilla/mux"
+)
+
+// TimeoutMiddleware is a middleware setting the deadline of each request's context.
+// The controllers pass the context to the storage, so a slow query is cancelled once the deadline passes
+// or the client disconnects, instead of running to completion for nobody.
+type TimeoutMiddleware struct {
+}
+
+// GetHandlerFunc returns an HTTP handler function for the timeout middleware.
+// The deadline is read from the routeTimeouts of the server config for the path template of the matched route,
+// falling back to requestTimeout. A timeout of 0 leaves the request without a deadline.
+//
+// Parameters:
+//   - next: The next HTTP handler in the middleware chain.
+//
+// Returns:
+//   - http.Handler: An HTTP handler function that wraps the provided 'next' handler and runs it
+//     with a context bounded by the timeout of the route.
+func (c *TimeoutMiddleware) GetHandlerFunc(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout := c.timeout(r)
+		if timeout <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// timeout returns the timeout configured for the route matched by the request.
+func (c *TimeoutMiddleware) timeout(r *http.Request) time.Duration {
+	server := config.GetInstance().Server
+	seconds := server.RequestTimeout
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			if routeSeconds, ok := server.RouteTimeouts[template]; ok {
+				seconds = routeSeconds
+			}
+		}
+	}
+	return time.Duration(seconds) * time.Second
+}
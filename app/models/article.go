@@ -1,14 +1,62 @@
 package models
 
+import "websays/database/basetypes"
+
 // Article is a simple data model representing an article entity with essential attributes.
 type Article struct {
-	ID    int    `json:"id"`    // ID uniquely identifies the article.
-	Title string `json:"title"` // Title is the title or headline of the article.
-	Body  string `json:"body"`  // Body contains the main content of the article.
+	ID        basetypes.ID `json:"id"`                  // ID uniquely identifies the article.
+	Title     string       `json:"title"`               // Title is the title or headline of the article.
+	Body      string       `json:"body"`                // Body contains the main content of the article.
+	Version   int          `json:"version"`             // Version is incremented on every update of the article.
+	DeletedAt int64        `json:"deletedAt,omitempty"` // DeletedAt is the Unix time the article was trashed at, 0 while it is not.
+	CreatedAt int64        `json:"createdAt,omitempty"` // CreatedAt is the Unix time the article was created at.
+	UpdatedAt int64        `json:"updatedAt,omitempty"` // UpdatedAt is the Unix time the article was last updated at.
+	CreatedBy string       `json:"createdBy,omitempty"` // CreatedBy is the principal that created the article, if known.
+	UpdatedBy string       `json:"updatedBy,omitempty"` // UpdatedBy is the principal that last updated the article, if known.
 }
 
 // GetID is a method that implements part of the basemodel interface.
 // It returns the unique identifier (ID) of the article.
-func (art Article) GetID() int {
+func (art Article) GetID() basetypes.ID {
 	return art.ID
 }
+
+// GetVersion is a method that implements part of the versioned model interface.
+// It returns the version of the article.
+func (art Article) GetVersion() int {
+	return art.Version
+}
+
+// WithVersion is a method that implements part of the versioned model interface.
+// It returns a copy of the article at the given version.
+func (art Article) WithVersion(version int) interface{} {
+	art.Version = version
+	return art
+}
+
+// GetDeletedAt is a method that implements part of the soft deletable model interface.
+// It returns the Unix time the article was trashed at.
+func (art Article) GetDeletedAt() int64 {
+	return art.DeletedAt
+}
+
+// WithDeletedAt is a method that implements part of the soft deletable model interface.
+// It returns a copy of the article trashed at the given Unix time.
+func (art Article) WithDeletedAt(deletedAt int64) interface{} {
+	art.DeletedAt = deletedAt
+	return art
+}
+
+// GetMetadata is a method that implements part of the timestamped model interface.
+// It returns when and by whom the article was created and last updated.
+func (art Article) GetMetadata() basetypes.Metadata {
+	return basetypes.Metadata{CreatedAt: art.CreatedAt, UpdatedAt: art.UpdatedAt, CreatedBy: art.CreatedBy, UpdatedBy: art.UpdatedBy}
+}
+
+// WithMetadata is a method that implements part of the timestamped model interface.
+// It returns a copy of the article with the given creation and last update.
+func (art Article) WithMetadata(metadata basetypes.Metadata) interface{} {
+	art.CreatedAt, art.UpdatedAt = metadata.CreatedAt, metadata.UpdatedAt
+	art.CreatedBy, art.UpdatedBy = metadata.CreatedBy, metadata.UpdatedBy
+	return art
+}
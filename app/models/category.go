@@ -1,13 +1,61 @@
 package models
 
+import "websays/database/basetypes"
+
 // Category represents a data model for categorizing items with an ID and a name.
 type Category struct {
-	ID   int    `json:"id"`   // ID uniquely identifies the category.
-	Name string `json:"name"` // Name is the descriptive name of the category.
+	ID        basetypes.ID `json:"id"`                  // ID uniquely identifies the category.
+	Name      string       `json:"name"`                // Name is the descriptive name of the category.
+	Version   int          `json:"version"`             // Version is incremented on every update of the category.
+	DeletedAt int64        `json:"deletedAt,omitempty"` // DeletedAt is the Unix time the category was trashed at, 0 while it is not.
+	CreatedAt int64        `json:"createdAt,omitempty"` // CreatedAt is the Unix time the category was created at.
+	UpdatedAt int64        `json:"updatedAt,omitempty"` // UpdatedAt is the Unix time the category was last updated at.
+	CreatedBy string       `json:"createdBy,omitempty"` // CreatedBy is the principal that created the category, if known.
+	UpdatedBy string       `json:"updatedBy,omitempty"` // UpdatedBy is the principal that last updated the category, if known.
 }
 
 // GetID is a method that implements part of the basemodel interface.
 // It returns the unique identifier (ID) of the category.
-func (cat Category) GetID() int {
+func (cat Category) GetID() basetypes.ID {
 	return cat.ID
 }
+
+// GetVersion is a method that implements part of the versioned model interface.
+// It returns the version of the category.
+func (cat Category) GetVersion() int {
+	return cat.Version
+}
+
+// WithVersion is a method that implements part of the versioned model interface.
+// It returns a copy of the category at the given version.
+func (cat Category) WithVersion(version int) interface{} {
+	cat.Version = version
+	return cat
+}
+
+// GetDeletedAt is a method that implements part of the soft deletable model interface.
+// It returns the Unix time the category was trashed at.
+func (cat Category) GetDeletedAt() int64 {
+	return cat.DeletedAt
+}
+
+// WithDeletedAt is a method that implements part of the soft deletable model interface.
+// It returns a copy of the category trashed at the given Unix time.
+func (cat Category) WithDeletedAt(deletedAt int64) interface{} {
+	cat.DeletedAt = deletedAt
+	return cat
+}
+
+// GetMetadata is a method that implements part of the timestamped model interface.
+// It returns when and by whom the category was created and last updated.
+func (cat Category) GetMetadata() basetypes.Metadata {
+	return basetypes.Metadata{CreatedAt: cat.CreatedAt, UpdatedAt: cat.UpdatedAt, CreatedBy: cat.CreatedBy, UpdatedBy: cat.UpdatedBy}
+}
+
+// WithMetadata is a method that implements part of the timestamped model interface.
+// It returns a copy of the category with the given creation and last update.
+func (cat Category) WithMetadata(metadata basetypes.Metadata) interface{} {
+	cat.CreatedAt, cat.UpdatedAt = metadata.CreatedAt, metadata.UpdatedAt
+	cat.CreatedBy, cat.UpdatedBy = metadata.CreatedBy, metadata.UpdatedBy
+	return cat
+}
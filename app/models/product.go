@@ -1,7 +1,61 @@
 package models
 
+import "websays/database/basetypes"
+
 // Product represents a data model for products with essential attributes.
 type Product struct {
-	ID   int    `db:"id,INT,AUTO_INCREMENT,PRIMARY KEY" json:"id"` // ID uniquely identifies the product.
-	Name string `db:"name,VARCHAR(255),NOT NULL" json:"name"`      // Name is the name of the product.
+	ID        basetypes.ID `db:"id,VARCHAR(64),PRIMARY KEY" json:"id"`                                   // ID uniquely identifies the product, an integer or a string key.
+	Name      string       `db:"name,VARCHAR(255),NOT NULL" json:"name"`                                 // Name is the name of the product.
+	Version   int          `db:"version,INT,NOT NULL,DEFAULT 1" json:"version"`                          // Version is incremented on every update of the product.
+	DeletedAt int64        `db:"deleted_at,BIGINT,NOT NULL,DEFAULT 0" json:"deletedAt,omitempty"`        // DeletedAt is the Unix time the product was trashed at, 0 while it is not.
+	CreatedAt int64        `db:"created_at,BIGINT,NOT NULL,DEFAULT 0" json:"createdAt,omitempty"`        // CreatedAt is the Unix time the product was created at.
+	UpdatedAt int64        `db:"updated_at,BIGINT,NOT NULL,DEFAULT 0" json:"updatedAt,omitempty"`        // UpdatedAt is the Unix time the product was last updated at.
+	CreatedBy string       `db:"created_by,VARCHAR(255),NOT NULL,DEFAULT ''" json:"createdBy,omitempty"` // CreatedBy is the principal that created the product, if known.
+	UpdatedBy string       `db:"updated_by,VARCHAR(255),NOT NULL,DEFAULT ''" json:"updatedBy,omitempty"` // UpdatedBy is the principal that last updated the product, if known.
+}
+
+// GetID is a method that implements part of the basemodel interface.
+// It returns the unique identifier (ID) of the product.
+func (pro Product) GetID() basetypes.ID {
+	return pro.ID
+}
+
+// GetVersion is a method that implements part of the versioned model interface.
+// It returns the version of the product.
+func (pro Product) GetVersion() int {
+	return pro.Version
+}
+
+// WithVersion is a method that implements part of the versioned model interface.
+// It returns a copy of the product at the given version.
+func (pro Product) WithVersion(version int) interface{} {
+	pro.Version = version
+	return pro
+}
+
+// GetDeletedAt is a method that implements part of the soft deletable model interface.
+// It returns the Unix time the product was trashed at.
+func (pro Product) GetDeletedAt() int64 {
+	return pro.DeletedAt
+}
+
+// WithDeletedAt is a method that implements part of the soft deletable model interface.
+// It returns a copy of the product trashed at the given Unix time.
+func (pro Product) WithDeletedAt(deletedAt int64) interface{} {
+	pro.DeletedAt = deletedAt
+	return pro
+}
+
+// GetMetadata is a method that implements part of the timestamped model interface.
+// It returns when and by whom the product was created and last updated.
+func (pro Product) GetMetadata() basetypes.Metadata {
+	return basetypes.Metadata{CreatedAt: pro.CreatedAt, UpdatedAt: pro.UpdatedAt, CreatedBy: pro.CreatedBy, UpdatedBy: pro.UpdatedBy}
+}
+
+// WithMetadata is a method that implements part of the timestamped model interface.
+// It returns a copy of the product with the given creation and last update.
+func (pro Product) WithMetadata(metadata basetypes.Metadata) interface{} {
+	pro.CreatedAt, pro.UpdatedAt = metadata.CreatedAt, metadata.UpdatedAt
+	pro.CreatedBy, pro.UpdatedBy = metadata.CreatedBy, metadata.UpdatedBy
+	return pro
 }
@@ -0,0 +1,6 @@
+package models
+
+// Tables maps the MySQL tables to the models their migrations follow, compared against the live tables by `migrate diff`.
+var Tables = map[string]interface{}{
+	"products": Product{},
+}
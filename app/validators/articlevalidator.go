@@ -27,7 +27,7 @@ func (art *ArticleValidator) Validate(apiName string, data interface{}) error {
 
 	// Apply validation rules based on the API name
 	switch apiName {
-	case "/api/addArticle":
+	case "/api/addArticle", "/api/articles/bulk":
 		// Validate for adding an article
 		if articleData.Title == "" {
 			return errors.New("Title can't be empty")
@@ -36,9 +36,9 @@ func (art *ArticleValidator) Validate(apiName string, data interface{}) error {
 		if articleData.Body == "" {
 			return errors.New("Body can't be empty")
 		}
-	case "/api/upateArticle":
+	case "/api/upateArticle", "/api/articles/{id}":
 		// Validate for updating an article
-		if articleData.ID <= 0 {
+		if !articleData.ID.Valid() {
 			return errors.New("ID is not valid")
 		}
 
@@ -2,6 +2,7 @@ package validators
 
 import (
 	"errors"
+	"strings"
 	"websays/app/models"
 )
 
@@ -27,17 +28,17 @@ func (cat *CategoryValidator) Validate(apiName string, data interface{}) error {
 
 	// Apply validation rules based on the API name
 	switch apiName {
-	case "/api/createCategory":
+	case "/api/createCategory", "/api/categories/bulk":
 		// Validate for creating a category
-		if categoryData.Name == "" {
+		if strings.TrimSpace(categoryData.Name) == "" {
 			return errors.New("Category Name can't be empty")
 		}
-	case "/api/updateCategory":
+	case "/api/updateCategory", "/api/categories/{id}":
 		// Validate for updating a category
-		if categoryData.ID <= 0 {
+		if !categoryData.ID.Valid() {
 			return errors.New("Category ID is not correct")
 		}
-		if categoryData.Name == "" {
+		if strings.TrimSpace(categoryData.Name) == "" {
 			return errors.New("Category Name can't be empty")
 		}
 	}
@@ -27,14 +27,14 @@ func (pro *ProductValidator) Validate(apiName string, data interface{}) error {
 
 	// Apply validation rules based on the API name
 	switch apiName {
-	case "/api/addProduct":
+	case "/api/addProduct", "/api/products/bulk":
 		// Validate for adding a product
 		if proData.Name == "" {
 			return errors.New("Product Name can't be empty")
 		}
-	case "/api/updateProduct":
+	case "/api/updateProduct", "/api/products/{id}":
 		// Validate for updating a product
-		if proData.ID <= 0 {
+		if !proData.ID.Valid() {
 			return errors.New("ID is not proper")
 		}
 		if proData.Name == "" {
@@ -11,11 +11,19 @@ import (
 
 // config is a singleton struct that holds configuration values for the application.
 type config struct {
-	Server          configModels.ServerConfig   `json:"server"`
-	Database        configModels.DatabaseConfig `json:"database"`
-	FilePath        string                      `json:"filesPath"`
-	RunningFileName string                      `json:"runningFileName"`
-	Controllers     []string                    `json:"controllers"`
+	Server          configModels.ServerConfig           `json:"server"`
+	Database        configModels.DatabaseConfig         `json:"database"`
+	Memory          configModels.MemoryConfig           `json:"memory"`
+	Sqlite          configModels.SqliteConfig           `json:"sqlite"`
+	Migrations      configModels.MigrationsConfig       `json:"migrations"`
+	Trash           configModels.TrashConfig            `json:"trash"`
+	IDs             configModels.IDsConfig              `json:"ids"`
+	Caches          map[string]configModels.CacheConfig `json:"caches"`
+	WriteBehind     configModels.WriteBehindConfig      `json:"writeBehind"`
+	FilePath        string                              `json:"filesPath"`
+	RunningFileName string                              `json:"runningFileName"`
+	FileShardLength int                                 `json:"fileShardLength"`
+	Controllers     []string                            `json:"controllers"`
 }
 
 var (
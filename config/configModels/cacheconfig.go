@@ -0,0 +1,8 @@
+package configModels
+
+//Structure for reading the read-through cache of the storage of a controller
+type CacheConfig struct {
+	Size    int `json:"size"`    // Maximum number of records cached, 0 disables the cache of the controller
+	TTL     int `json:"ttl"`     // Seconds a record read is served from the cache
+	MissTTL int `json:"missTtl"` // Seconds a read finding no record is served from the cache, the ttl when 0
+}
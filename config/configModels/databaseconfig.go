@@ -1,10 +1,18 @@
 package configModels
 
-//Structure for reading database config
+// Structure for reading database config
 type DatabaseConfig struct {
-	Host     string `json:"host"`
-	Port     string `json:"port"`
-	Username string `json:"username"`
-	Password string `json:"password"`
-	DBName   string `json:"dbname"`
+	Host            string `json:"host"`
+	Port            string `json:"port"`
+	Username        string `json:"username"`
+	Password        string `json:"password"`
+	DBName          string `json:"dbname"`
+	MaxOpenConns    int    `json:"maxOpenConns"`    // Maximum open connections of the pool, 0 for unlimited
+	MaxIdleConns    int    `json:"maxIdleConns"`    // Maximum idle connections kept in the pool, 0 for the driver default of 2
+	ConnMaxLifetime int    `json:"connMaxLifetime"` // Seconds a connection is reused before it is reopened, 0 to reuse it forever
+	ConnectTimeout  int    `json:"connectTimeout"`  // Seconds to wait for a connection to be established, 0 for the system default
+	ReadTimeout     int    `json:"readTimeout"`     // Seconds to wait for the result of a query, 0 to wait forever
+	ConnectRetries  int    `json:"connectRetries"`  // Extra pings on startup before giving up on the database
+	ConnectBackoff  int    `json:"connectBackoff"`  // Milliseconds before the first retry, doubled after each one
+	StartupTimeout  int    `json:"startupTimeout"`  // Seconds to wait for the database on startup before giving up, 0 to only bound it by connectRetries
 }
@@ -0,0 +1,7 @@
+package configModels
+
+//Structure for reading the ID strategies of the controllers
+type IDsConfig struct {
+	Strategies map[string]string `json:"strategies"` // ID strategy of each controller by controller name: sequence, uuidv4, uuidv7, ulid or snowflake, sequence when missing
+	NodeID     int               `json:"nodeId"`     // Node ID of this process in the snowflake IDs, between 0 and 1023, unique per node of a cluster
+}
@@ -0,0 +1,9 @@
+package configModels
+
+//Structure for reading the persistence config of the memory storage
+type MemoryConfig struct {
+	Persistence      bool   `json:"persistence"`      // Log every write and restore the data on startup
+	WalFileName      string `json:"walFileName"`      // Name of the write-ahead log inside filesPath
+	SnapshotFileName string `json:"snapshotFileName"` // Name of the snapshot inside filesPath
+	SnapshotInterval int    `json:"snapshotInterval"` // Seconds between snapshots compacting the log, 0 disables them
+}
@@ -0,0 +1,7 @@
+package configModels
+
+//Structure for reading the schema migrations config
+type MigrationsConfig struct {
+	Path           string `json:"path"`           // Directory of the .up.sql and .down.sql migration files
+	ApplyOnStartup bool   `json:"applyOnStartup"` // Apply the pending migrations before the server starts
+}
@@ -1,7 +1,11 @@
 package configModels
 
-//Structure for reading server config
+// Structure for reading server config
 type ServerConfig struct {
-	Address string `json:"address"`
-	Port    string `json:"port"`
+	Address         string         `json:"address"`
+	Port            string         `json:"port"`
+	RequestTimeout  int            `json:"requestTimeout"`  // Seconds a request may run before its storage calls are cancelled, 0 for no deadline
+	RouteTimeouts   map[string]int `json:"routeTimeouts"`   // Seconds overriding requestTimeout per route path template, like "/api/products"
+	PrincipalHeader string         `json:"principalHeader"` // Header naming the principal of a request, set by the authenticating proxy, empty for none
+	TrustedProxies  []string       `json:"trustedProxies"`  // Addresses or CIDR ranges of the proxies whose principalHeader is accepted, none trusts no request
 }
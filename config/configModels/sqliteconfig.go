@@ -0,0 +1,6 @@
+package configModels
+
+//Structure for reading the sqlite config
+type SqliteConfig struct {
+	FileName string `json:"fileName"` // Path of the database file, ":memory:" keeps the database in memory
+}
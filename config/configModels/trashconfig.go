@@ -0,0 +1,7 @@
+package configModels
+
+//Structure for reading the retention of the trash keeping soft deleted records
+type TrashConfig struct {
+	Retention     int `json:"retention"`     // Seconds a trashed record is kept before it is purged automatically, 0 keeps it until it is purged by hand
+	PurgeInterval int `json:"purgeInterval"` // Seconds between the automatic purges of the expired trash
+}
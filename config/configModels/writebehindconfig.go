@@ -0,0 +1,10 @@
+package configModels
+
+//Structure for reading the write-behind mode of the controllers
+type WriteBehindConfig struct {
+	Controllers   []string `json:"controllers"`   // Controllers whose MySQL storage is written behind a memory front
+	FlushInterval int      `json:"flushInterval"` // Milliseconds between flushes of the pending writes, 1000 when 0
+	BatchSize     int      `json:"batchSize"`     // Records persisted per sql transaction, a flush starts early once as many are pending, 500 when 0
+	RetryBackoff  int      `json:"retryBackoff"`  // Milliseconds before retrying a failed flush, doubled on each failure, 500 when 0
+	MaxBackoff    int      `json:"maxBackoff"`    // Longest milliseconds before retrying a failed flush, 30000 when 0
+}
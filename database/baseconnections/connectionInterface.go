@@ -12,4 +12,8 @@ type ConnectionInterface interface {
 	// GetDB returns the underlying database instance based on the specified database type.
 	// It takes a DbType parameter and returns the corresponding database interface.
 	GetDB(dbType basetypes.DbType) interface{}
+
+	// Close releases the underlying storage connection on shutdown.
+	// It may return an error if the connection cannot be closed cleanly.
+	Close() error
 }
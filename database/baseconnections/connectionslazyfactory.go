@@ -1,13 +1,24 @@
 package baseconnections
 
 import (
+	"errors"
 	"sync"
 	"websays/database/basetypes"
 )
 
 // dbConnections is a struct representing the database connections manager.
 type dbConnections struct {
+	lock          sync.Mutex
 	dbconnections map[basetypes.DbType]*ConnectionInterface
+	connecting    map[basetypes.DbType]*connectionAttempt // The connections being created, shared by the callers asking for them meanwhile.
+	attempted     map[basetypes.DbType]bool               // The types connected once already, whose startup retries are spent.
+}
+
+// connectionAttempt is the creation of a connection, done once for every caller waiting for it.
+type connectionAttempt struct {
+	done       chan struct{}       // Closed once the creation finished.
+	connection ConnectionInterface // The connection created, nil if it failed.
+	err        error               // The error of the creation.
 }
 
 var instance *dbConnections
@@ -19,47 +30,77 @@ func GetInstance() *dbConnections {
 	once.Do(func() {
 		instance = &dbConnections{}
 		instance.dbconnections = make(map[basetypes.DbType]*ConnectionInterface)
+		instance.connecting = make(map[basetypes.DbType]*connectionAttempt)
+		instance.attempted = make(map[basetypes.DbType]bool)
 	})
 	return instance
 }
 
 // GetConnection retrieves or creates a connection based on the specified database type.
-// It returns the corresponding connection interface.
-func (u *dbConnections) GetConnection(dbType basetypes.DbType) ConnectionInterface {
+// It returns the corresponding connection interface, or the error of creating it.
+// A failed connection isn't kept, so the next call tries to connect again.
+// The connection is created outside the lock, so the lookups of the other types don't wait for it,
+// and the callers asking for the same type meanwhile wait for that creation instead of starting their own.
+// Only the first creation of a type waits for the database with the startup retries, the later ones try once.
+func (u *dbConnections) GetConnection(dbType basetypes.DbType) (ConnectionInterface, error) {
+	u.lock.Lock()
 	if connection, ok := u.dbconnections[dbType]; ok {
-		return *connection
+		u.lock.Unlock()
+		return *connection, nil
 	}
+	if attempt, ok := u.connecting[dbType]; ok {
+		u.lock.Unlock()
+		<-attempt.done
+		return attempt.connection, attempt.err
+	}
+
+	var connection ConnectionInterface
 	switch dbType {
 	case basetypes.MYSQL:
-		{
-			connection := MysqlConnection{}
-			mysqlconnector, err := connection.CreateConnection()
-			if err != nil {
-				return nil
-			}
-			u.dbconnections[dbType] = &mysqlconnector
-			return *u.dbconnections[dbType]
-		}
-	case basetypes.FILE:
-		{ // Allowing file connections
-			connection := FileConnection{}
-			fileconnector, err := connection.CreateConnection()
-			if err != nil {
-				return nil
-			}
-			u.dbconnections[dbType] = &fileconnector
-			return *u.dbconnections[dbType]
-		}
-	case basetypes.MEMORY:
-		{ // Allowing memory connection
-			connection := MemoryConnection{}
-			memoryconnector, err := connection.CreateConnection()
-			if err != nil {
-				return nil
+		connection = &MysqlConnection{startup: !u.attempted[dbType]}
+	case basetypes.FILE: // Allowing file connections
+		connection = &FileConnection{}
+	case basetypes.MEMORY: // Allowing memory connection
+		connection = &MemoryConnection{}
+	case basetypes.SQLITE:
+		connection = &SqliteConnection{}
+	default:
+		u.lock.Unlock()
+		return nil, errors.New("Unknown database type")
+	}
+	attempt := &connectionAttempt{done: make(chan struct{})}
+	u.connecting[dbType] = attempt
+	u.attempted[dbType] = true
+	u.lock.Unlock()
+
+	attempt.connection, attempt.err = connection.CreateConnection()
+
+	u.lock.Lock()
+	delete(u.connecting, dbType)
+	if attempt.err == nil {
+		u.dbconnections[dbType] = &attempt.connection
+	} else {
+		attempt.connection = nil
+	}
+	u.lock.Unlock()
+	close(attempt.done)
+	return attempt.connection, attempt.err
+}
+
+// Close closes every open connection on shutdown and forgets them, so a later GetConnection connects again.
+// It returns the first error encountered, after trying to close every connection.
+func (u *dbConnections) Close() error {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	var firstErr error
+	for dbType, connection := range u.dbconnections {
+		if *connection != nil {
+			err := (*connection).Close()
+			if err != nil && firstErr == nil {
+				firstErr = err
 			}
-			u.dbconnections[dbType] = &memoryconnector
-			return *u.dbconnections[dbType]
 		}
+		delete(u.dbconnections, dbType)
 	}
-	return nil
+	return firstErr
 }
@@ -1,2 +1,2 @@
-//The package baseconnection contains connections for different type of storages. Like memory, file and mysql
+//The package baseconnection contains connections for different type of storages. Like memory, file, mysql and sqlite
 package baseconnections
@@ -14,3 +14,7 @@ func (u *FileConnection) CreateConnection() (ConnectionInterface, error) {
 func (u *FileConnection) GetDB(dbType basetypes.DbType) interface{} {
 	return nil
 }
+
+func (u *FileConnection) Close() error {
+	return nil
+}
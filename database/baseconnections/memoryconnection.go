@@ -14,3 +14,7 @@ func (u *MemoryConnection) CreateConnection() (ConnectionInterface, error) {
 func (u *MemoryConnection) GetDB(dbType basetypes.DbType) interface{} {
 	return nil
 }
+
+func (u *MemoryConnection) Close() error {
+	return nil
+}
@@ -2,33 +2,138 @@ package baseconnections
 
 import (
 	"database/sql"
+	"fmt"
+	"log"
+	"time"
 	"websays/config"
+	"websays/database/basedialects"
 	"websays/database/basetypes"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
+// maxConnectBackoff caps the wait between two startup pings.
+const maxConnectBackoff = 30 * time.Second
+
 // MysqlConnection represents a MySQL database connection.
 type MysqlConnection struct {
-	dbName string
-	db     *sql.DB
+	dbName  string
+	db      *sql.DB
+	startup bool // Wait for the server with the startup retries, instead of pinging it once.
 }
 
-// CreateConnection creates a MySQL database connection using the configuration values from the singleton config instance.
+// CreateConnection creates a MySQL database connection pool using the configuration values from the singleton config instance.
+// It first connects to the server without a schema, waiting for it to become reachable, creates the configured database
+// if it is missing, and then opens the pool on that database. So a fresh MySQL container needs no manual setup.
+// Connections broken later are replaced by the pool, connMaxLifetime also recycles the ones a proxy or server closes silently.
+// Only a connection created at startup waits for the server with the retries of the config, a later one pings it once,
+// so the requests asking for it while the server is down fail fast instead of waiting for the whole retry loop.
 // It returns the created connection and any error encountered during connection setup.
 func (u *MysqlConnection) CreateConnection() (ConnectionInterface, error) {
-	dsn := config.GetInstance().Database.Username + ":" + config.GetInstance().Database.Password + "@tcp(" + config.GetInstance().Database.Host + ":" + config.GetInstance().Database.Port + ")/" + config.GetInstance().Database.DBName
-	db, err := sql.Open("mysql", dsn)
+	settings := config.GetInstance().Database
+	retries := 0
+	var deadline time.Time
+	if u.startup {
+		retries = settings.ConnectRetries
+		if settings.StartupTimeout > 0 {
+			deadline = time.Now().Add(time.Duration(settings.StartupTimeout) * time.Second)
+		}
+	}
+
+	err := u.createDatabase(retries, deadline)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := u.open(settings.DBName)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(settings.MaxOpenConns)
+	if settings.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(settings.MaxIdleConns)
+	}
+	db.SetConnMaxLifetime(time.Duration(settings.ConnMaxLifetime) * time.Second)
 
+	err = pingWithRetry(db, retries, time.Duration(settings.ConnectBackoff)*time.Millisecond, deadline)
 	if err != nil {
+		db.Close()
 		return nil, err
 	}
 
+	u.dbName = settings.DBName
 	u.db = db
 	return u, nil
 }
 
+// createDatabase connects to the server without a schema, retrying retries times until the deadline,
+// and creates the configured database if it doesn't exist yet.
+// Checking first lets users without the CREATE privilege connect to an existing database.
+func (u *MysqlConnection) createDatabase(retries int, deadline time.Time) error {
+	settings := config.GetInstance().Database
+	server, err := u.open("")
+	if err != nil {
+		return err
+	}
+	defer server.Close()
+
+	err = pingWithRetry(server, retries, time.Duration(settings.ConnectBackoff)*time.Millisecond, deadline)
+	if err != nil {
+		return err
+	}
+
+	var count int
+	err = server.QueryRow("SELECT COUNT(*) FROM information_schema.SCHEMATA WHERE SCHEMA_NAME = ?", settings.DBName).Scan(&count)
+	if err != nil || count > 0 {
+		return err
+	}
+	log.Println("Creating database", settings.DBName)
+	_, err = server.Exec("CREATE DATABASE IF NOT EXISTS " + basedialects.MySQL{}.Quote(settings.DBName))
+	return err
+}
+
+// open opens a connection pool on a database of the configured server, or on no database if dbName is empty.
+func (u *MysqlConnection) open(dbName string) (*sql.DB, error) {
+	settings := config.GetInstance().Database
+	mysqlConfig := mysql.NewConfig()
+	mysqlConfig.User = settings.Username
+	mysqlConfig.Passwd = settings.Password
+	mysqlConfig.Net = "tcp"
+	mysqlConfig.Addr = settings.Host + ":" + settings.Port
+	mysqlConfig.DBName = dbName
+	mysqlConfig.Timeout = time.Duration(settings.ConnectTimeout) * time.Second
+	mysqlConfig.ReadTimeout = time.Duration(settings.ReadTimeout) * time.Second
+	return sql.Open("mysql", mysqlConfig.FormatDSN())
+}
+
 // GetDB returns the MySQL database instance associated with this connection.
 func (u *MysqlConnection) GetDB(dbType basetypes.DbType) interface{} {
 	return u.db
 }
+
+// Close closes the connection pool, waiting for the running queries to finish.
+func (u *MysqlConnection) Close() error {
+	return u.db.Close()
+}
+
+// pingWithRetry pings the database until it answers, retrying retries times and doubling backoff after each failure.
+// It gives up early when waiting for the next retry would pass a non zero deadline.
+func pingWithRetry(db *sql.DB, retries int, backoff time.Duration, deadline time.Time) error {
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		err = db.Ping()
+		if err == nil {
+			return nil
+		}
+		if attempt == retries || (!deadline.IsZero() && time.Now().Add(backoff).After(deadline)) {
+			return fmt.Errorf("Database unreachable after %d attempts: %v", attempt+1, err)
+		}
+		log.Println("Error pinging database, retrying in", backoff, ":", err)
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > maxConnectBackoff {
+			backoff = maxConnectBackoff
+		}
+	}
+	return err
+}
@@ -0,0 +1,49 @@
+package baseconnections
+
+import (
+	"database/sql"
+	"websays/config"
+	"websays/database/basetypes"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteMemory is the file name keeping a SQLite database in memory.
+const sqliteMemory = ":memory:"
+
+// SqliteConnection represents a SQLite database connection.
+type SqliteConnection struct {
+	fileName string
+	db       *sql.DB
+}
+
+// CreateConnection opens the SQLite database file configured in the singleton config instance, creating it if needed.
+// It returns the created connection and any error encountered during connection setup.
+// An in-memory database only lives as long as its connection, so the pool is limited to a single connection for it.
+func (u *SqliteConnection) CreateConnection() (ConnectionInterface, error) {
+	u.fileName = config.GetInstance().Sqlite.FileName
+	if u.fileName == "" {
+		u.fileName = sqliteMemory
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+u.fileName+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, err
+	}
+	if u.fileName == sqliteMemory {
+		db.SetMaxOpenConns(1)
+	}
+
+	u.db = db
+	return u, nil
+}
+
+// GetDB returns the SQLite database instance associated with this connection.
+func (u *SqliteConnection) GetDB(dbType basetypes.DbType) interface{} {
+	return u.db
+}
+
+// Close closes the SQLite database, an in-memory database is lost.
+func (u *SqliteConnection) Close() error {
+	return u.db.Close()
+}
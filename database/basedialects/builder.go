@@ -0,0 +1,269 @@
+package basedialects
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"websays/database/basefilters"
+)
+
+// Statement is a generated sql statement and the values of its placeholders.
+type Statement struct {
+	Query     string        // The sql text.
+	Values    []interface{} // The values of the placeholders in order.
+	ReturnsID bool          // The statement returns the generated ID as a row instead of through LastInsertId.
+}
+
+// Increment is an UpdateOne value adding to the current value of its column instead of replacing it,
+// so concurrent updates can't lose an increment.
+type Increment int
+
+// Builder generates the statements of the sql storages in the syntax of its Dialect.
+// Filters are expected to be validated, models are structs with `db` tagged fields.
+type Builder struct {
+	Dialect Dialect // The dialect of the generated statements.
+}
+
+// CreateTable returns the CREATE TABLE IF NOT EXISTS statement of the db tagged fields of a model.
+func (u Builder) CreateTable(table string, model interface{}) (Statement, error) {
+	columns, err := Columns(model)
+	if err != nil {
+		return Statement{}, err
+	}
+	definitions := make([]string, 0, len(columns))
+	for _, column := range columns {
+		definitions = append(definitions, u.Dialect.ColumnDefinition(column))
+	}
+	return Statement{Query: "CREATE TABLE IF NOT EXISTS " + u.Dialect.Quote(table) + " (" + strings.Join(definitions, ", ") + ")", Values: []interface{}{}}, nil
+}
+
+// Insert returns the INSERT statement of the db tagged fields of a model.
+// A zero auto increment column is left out so the database generates it, and returned if the dialect needs it.
+func (u Builder) Insert(table string, model interface{}) (Statement, error) {
+	columns, values, err := u.columnValues(model, true)
+	if err != nil {
+		return Statement{}, err
+	}
+	statement := u.insert(table, columns, values)
+	u.returnGenerated(&statement, model, columns)
+	return statement, nil
+}
+
+// InsertMany returns the multi-row INSERT statement of the db tagged fields of several models.
+// The models must set the same columns, a zero auto increment column being left out of every row as by Insert,
+// and the generated IDs are returned if the dialect needs it, in the order of the models.
+func (u Builder) InsertMany(table string, models []interface{}) (Statement, error) {
+	if len(models) == 0 {
+		return Statement{}, errors.New("Nothing to insert")
+	}
+	var columns []string
+	rows := make([]string, 0, len(models))
+	values := make([]interface{}, 0)
+	for i, model := range models {
+		rowColumns, rowValues, err := u.columnValues(model, true)
+		if err != nil {
+			return Statement{}, err
+		}
+		if i == 0 {
+			columns = rowColumns
+		} else if strings.Join(rowColumns, ",") != strings.Join(columns, ",") {
+			return Statement{}, errors.New("Inserted models set different columns")
+		}
+		rows = append(rows, u.placeholders(len(values), len(rowValues)))
+		values = append(values, rowValues...)
+	}
+	statement := Statement{Query: "INSERT INTO " + u.Dialect.Quote(table) + " (" + strings.Join(columns, ", ") + ") VALUES " + strings.Join(rows, ", "), Values: values}
+	u.returnGenerated(&statement, models[0], columns)
+	return statement, nil
+}
+
+// Upsert returns the statement inserting a model, or updating the record with the same primary key if it exists.
+// The incremented columns are increased by 1 when the record exists instead of taking the value of the model.
+func (u Builder) Upsert(table string, model interface{}, incremented ...string) (Statement, error) {
+	return u.UpsertKeeping(table, model, nil, incremented...)
+}
+
+// UpsertKeeping is Upsert, except that the kept columns are only set when the model is inserted,
+// keeping their stored values when the record exists.
+func (u Builder) UpsertKeeping(table string, model interface{}, kept []string, incremented ...string) (Statement, error) {
+	columns, values, err := u.columnValues(model, false)
+	if err != nil {
+		return Statement{}, err
+	}
+
+	keys := make([]string, 0)
+	updated := make([]string, 0)
+	increments := make([]string, 0)
+	for _, column := range u.mustColumns(model) {
+		switch {
+		case column.PrimaryKey:
+			keys = append(keys, u.Dialect.Quote(column.Name))
+		case contains(kept, column.Name):
+			// Left out of the update
+		case contains(incremented, column.Name):
+			increments = append(increments, u.Dialect.Quote(column.Name))
+		default:
+			updated = append(updated, u.Dialect.Quote(column.Name))
+		}
+	}
+	if len(keys) == 0 {
+		return Statement{}, errors.New("Upsert requires a primary key")
+	}
+
+	statement := u.insert(table, columns, values)
+	statement.Query += u.Dialect.UpsertClause(u.Dialect.Quote(table), keys, updated, increments)
+	return statement, nil
+}
+
+// Where returns the WHERE clause of a filter and its values, or an empty clause for a nil filter.
+// offset is the number of values preceding the clause in the statement.
+func (u Builder) Where(filter *basefilters.Filter, offset int) (string, []interface{}) {
+	if filter == nil {
+		return "", []interface{}{}
+	}
+	condition, values := filter.ToSQL(u.Dialect, offset)
+	return " WHERE " + condition, values
+}
+
+// Select returns the statement selecting every record matching a filter.
+func (u Builder) Select(table string, filter *basefilters.Filter) Statement {
+	whereClause, values := u.Where(filter, 0)
+	return Statement{Query: "SELECT * FROM " + u.Dialect.Quote(table) + whereClause, Values: values}
+}
+
+// Count returns the statement counting the records matching a filter.
+func (u Builder) Count(table string, filter *basefilters.Filter) Statement {
+	whereClause, values := u.Where(filter, 0)
+	return Statement{Query: "SELECT COUNT(*) FROM " + u.Dialect.Quote(table) + whereClause, Values: values}
+}
+
+// Page returns the statement selecting one page of the records matching a filter, ordered by the first column.
+func (u Builder) Page(table string, filter *basefilters.Filter, limit int, offset int) Statement {
+	whereClause, values := u.Where(filter, 0)
+	query := "SELECT * FROM " + u.Dialect.Quote(table) + whereClause +
+		" ORDER BY 1 LIMIT " + u.Dialect.Placeholder(len(values)+1) + " OFFSET " + u.Dialect.Placeholder(len(values)+2)
+	return Statement{Query: query, Values: append(values, limit, offset)}
+}
+
+// PageAfter returns the statement selecting the first records whose column is greater than after, ordered by the column,
+// or the first records of the table for a nil after. Unlike an offset, paging by a unique column skips no record
+// when records are removed between two pages.
+func (u Builder) PageAfter(table string, column string, after interface{}, limit int) Statement {
+	var filter *basefilters.Filter
+	if after != nil {
+		greater := basefilters.Gt(column, after)
+		filter = &greater
+	}
+	whereClause, values := u.Where(filter, 0)
+	query := "SELECT * FROM " + u.Dialect.Quote(table) + whereClause +
+		" ORDER BY " + u.Dialect.Quote(column) + " LIMIT " + u.Dialect.Placeholder(len(values)+1)
+	return Statement{Query: query, Values: append(values, limit)}
+}
+
+// UpdateOne returns the statement setting the columns of a data map on the first record matching a filter.
+// The columns are set in alphabetical order, so the statement doesn't depend on the map order.
+func (u Builder) UpdateOne(table string, filter *basefilters.Filter, data map[string]interface{}) (Statement, error) {
+	if len(data) == 0 {
+		return Statement{}, errors.New("Nothing to update")
+	}
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	assignments := make([]string, 0, len(keys))
+	values := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		if increment, ok := data[key].(Increment); ok {
+			values = append(values, int(increment))
+			assignments = append(assignments, u.Dialect.Quote(key)+" = "+u.Dialect.Quote(key)+" + "+u.Dialect.Placeholder(len(values)))
+			continue
+		}
+		values = append(values, data[key])
+		assignments = append(assignments, u.Dialect.Quote(key)+" = "+u.Dialect.Placeholder(len(values)))
+	}
+
+	whereClause, whereValues := u.Where(filter, len(values))
+	query := "UPDATE " + u.Dialect.Quote(table) + " SET " + strings.Join(assignments, ", ") + u.Dialect.LimitOne(u.Dialect.Quote(table), whereClause)
+	return Statement{Query: query, Values: append(values, whereValues...)}, nil
+}
+
+// DeleteOne returns the statement deleting the first record matching a filter.
+func (u Builder) DeleteOne(table string, filter *basefilters.Filter) Statement {
+	whereClause, values := u.Where(filter, 0)
+	return Statement{Query: "DELETE FROM " + u.Dialect.Quote(table) + u.Dialect.LimitOne(u.Dialect.Quote(table), whereClause), Values: values}
+}
+
+// Delete returns the statement deleting every record matching a filter.
+func (u Builder) Delete(table string, filter *basefilters.Filter) Statement {
+	whereClause, values := u.Where(filter, 0)
+	return Statement{Query: "DELETE FROM " + u.Dialect.Quote(table) + whereClause, Values: values}
+}
+
+// insert returns the INSERT statement of quoted columns and their values.
+func (u Builder) insert(table string, columns []string, values []interface{}) Statement {
+	query := "INSERT INTO " + u.Dialect.Quote(table) + " (" + strings.Join(columns, ", ") + ") VALUES " + u.placeholders(0, len(values))
+	return Statement{Query: query, Values: values}
+}
+
+// placeholders returns the parenthesized placeholders of a row of count values, following offset values of the statement.
+func (u Builder) placeholders(offset int, count int) string {
+	placeholders := make([]string, 0, count)
+	for i := 1; i <= count; i++ {
+		placeholders = append(placeholders, u.Dialect.Placeholder(offset+i))
+	}
+	return "(" + strings.Join(placeholders, ", ") + ")"
+}
+
+// returnGenerated appends the clause returning the generated ID of the auto increment column of a model
+// left out of the quoted columns of an INSERT statement, if the dialect needs it.
+func (u Builder) returnGenerated(statement *Statement, model interface{}, columns []string) {
+	for _, column := range u.mustColumns(model) {
+		if column.AutoIncrement && !contains(columns, u.Dialect.Quote(column.Name)) {
+			if returning := u.Dialect.ReturningID(u.Dialect.Quote(column.Name)); returning != "" {
+				statement.Query += returning
+				statement.ReturnsID = true
+			}
+		}
+	}
+}
+
+// columnValues returns the quoted columns of a model and their values.
+// skipGenerated leaves out the auto increment columns holding a zero value.
+func (u Builder) columnValues(model interface{}, skipGenerated bool) ([]string, []interface{}, error) {
+	columns, err := Columns(model)
+	if err != nil {
+		return nil, nil, err
+	}
+	modelValue := reflect.ValueOf(model)
+
+	names := make([]string, 0, len(columns))
+	values := make([]interface{}, 0, len(columns))
+	for _, column := range columns {
+		field := modelValue.Field(column.field)
+		if skipGenerated && column.AutoIncrement && field.IsZero() {
+			continue
+		}
+		names = append(names, u.Dialect.Quote(column.Name))
+		values = append(values, field.Interface())
+	}
+	return names, values, nil
+}
+
+// mustColumns returns the columns of a model already checked by columnValues.
+func (u Builder) mustColumns(model interface{}) []Column {
+	columns, _ := Columns(model)
+	return columns
+}
+
+// contains reports whether a list of strings contains value.
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package basedialects
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// Column is a column described by a `db:"name,TYPE,CONSTRAINT..."` struct tag, like `db:"id,INT,AUTO_INCREMENT,PRIMARY KEY"`.
+type Column struct {
+	Name          string   // Name of the column.
+	Type          string   // MySQL type of the column, like INT or VARCHAR(255).
+	Constraints   []string // Remaining constraints, like NOT NULL, without AUTO_INCREMENT and PRIMARY KEY.
+	AutoIncrement bool     // The database generates the value of the column.
+	PrimaryKey    bool     // The column identifies the record.
+	field         int      // Index of the struct field tagged with the column.
+}
+
+// Columns returns the columns of the db tagged fields of a struct in field order.
+func Columns(model interface{}) ([]Column, error) {
+	modelType := reflect.TypeOf(model)
+	if modelType == nil || modelType.Kind() != reflect.Struct {
+		return nil, errors.New("Required a struct for data")
+	}
+
+	columns := make([]Column, 0, modelType.NumField())
+	for i := 0; i < modelType.NumField(); i++ {
+		tags := strings.Split(modelType.Field(i).Tag.Get("db"), ",")
+		if tags[0] == "" {
+			continue
+		}
+
+		column := Column{Name: tags[0], Constraints: []string{}, field: i}
+		if len(tags) < 2 {
+			columns = append(columns, column)
+			continue
+		}
+		column.Type = strings.TrimSpace(tags[1])
+		for _, tag := range tags[2:] {
+			switch strings.ToUpper(strings.TrimSpace(tag)) {
+			case "AUTO_INCREMENT":
+				column.AutoIncrement = true
+			case "PRIMARY KEY":
+				column.PrimaryKey = true
+			default:
+				column.Constraints = append(column.Constraints, strings.TrimSpace(tag))
+			}
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// FieldIndex returns the index of the struct field tagged with the column, for reflect.Value.Field.
+func (u Column) FieldIndex() int {
+	return u.field
+}
+
+// definition joins the quoted name, the type and the constraints of a column.
+func (u Column) definition(dialect Dialect, columnType string, constraints ...string) string {
+	parts := []string{dialect.Quote(u.Name)}
+	if columnType != "" {
+		parts = append(parts, columnType)
+	}
+	parts = append(parts, u.Constraints...)
+	parts = append(parts, constraints...)
+	return strings.Join(parts, " ")
+}
+
+// FieldColumns maps the JSON name of each db tagged field of a struct to its column,
+// resolving the fields of a filter written against the JSON form of the model, like in the other storages.
+func FieldColumns(model interface{}) (map[string]string, error) {
+	columns, err := Columns(model)
+	if err != nil {
+		return nil, err
+	}
+	modelType := reflect.TypeOf(model)
+	fields := make(map[string]string, len(columns))
+	for _, column := range columns {
+		if name := JSONName(modelType.Field(column.field)); name != "" {
+			fields[name] = column.Name
+		}
+	}
+	return fields, nil
+}
+
+// JSONName returns the name of a struct field in its JSON form, empty if it is left out of it.
+func JSONName(field reflect.StructField) string {
+	if field.PkgPath != "" {
+		return ""
+	}
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
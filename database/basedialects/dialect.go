@@ -0,0 +1,29 @@
+package basedialects
+
+import "websays/database/basefilters"
+
+// Dialect describes the syntax of a database that the Builder depends on.
+type Dialect interface {
+	basefilters.SQLDialect
+
+	// ColumnDefinition returns the definition of a column in a CREATE TABLE statement,
+	// mapping the MySQL types and constraints used in the `db` tags to the types of the database.
+	ColumnDefinition(column Column) string
+
+	// UpsertClause returns the clause appended to an INSERT statement on the quoted table turning it into an upsert.
+	// keys are the quoted columns identifying the record, columns the quoted columns updated when it exists,
+	// and incremented the quoted columns increased by 1 when it exists instead of being replaced.
+	UpsertClause(table string, keys []string, columns []string, incremented []string) string
+
+	// ReturningID returns the clause appended to an INSERT statement returning the generated ID of the quoted column,
+	// or an empty string if the driver reports it through sql.Result.LastInsertId.
+	ReturningID(column string) string
+
+	// FirstInsertID returns the ID generated for the first row of a multi-row INSERT statement of rows rows
+	// from the sql.Result.LastInsertId of the statement, the IDs of the other rows following it.
+	FirstInsertID(lastInsertID int64, rows int) int64
+
+	// LimitOne returns the condition restricting an UPDATE or DELETE statement on the quoted table
+	// to the first row matching whereClause, which is empty or starts with " WHERE".
+	LimitOne(table string, whereClause string) string
+}
@@ -0,0 +1,5 @@
+// Package basedialects generates the sql statements of the sql storages for each supported database.
+// A Dialect describes the syntax that differs between databases, placeholders, identifier quoting,
+// upserts, returning generated IDs and column types, and a Builder turns models and filters into statements
+// of a dialect. The statements are plain strings, so they can be checked without a live database.
+package basedialects
@@ -0,0 +1,65 @@
+package basedialects
+
+import "strings"
+
+// MySQL is the dialect of MySQL, the `db` tags are written in its syntax.
+type MySQL struct{}
+
+// Placeholder returns the ? placeholder.
+func (u MySQL) Placeholder(n int) string {
+	return "?"
+}
+
+// Quote quotes an identifier with backticks.
+func (u MySQL) Quote(identifier string) string {
+	return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+}
+
+// Like returns LIKE, which ignores case with the default collations.
+func (u MySQL) Like() string {
+	return "LIKE"
+}
+
+// ColumnDefinition returns the column as written in its tag.
+func (u MySQL) ColumnDefinition(column Column) string {
+	constraints := []string{}
+	if column.AutoIncrement {
+		constraints = append(constraints, "AUTO_INCREMENT")
+	}
+	if column.PrimaryKey {
+		constraints = append(constraints, "PRIMARY KEY")
+	}
+	return column.definition(u, column.Type, constraints...)
+}
+
+// UpsertClause returns an ON DUPLICATE KEY UPDATE clause.
+func (u MySQL) UpsertClause(table string, keys []string, columns []string, incremented []string) string {
+	if len(columns) == 0 && len(incremented) == 0 {
+		// Updating a key to itself turns the duplicate into a no-op
+		return " ON DUPLICATE KEY UPDATE " + keys[0] + " = " + keys[0]
+	}
+	assignments := make([]string, 0, len(columns)+len(incremented))
+	for _, column := range columns {
+		assignments = append(assignments, column+" = VALUES("+column+")")
+	}
+	for _, column := range incremented {
+		assignments = append(assignments, column+" = "+column+" + 1")
+	}
+	return " ON DUPLICATE KEY UPDATE " + strings.Join(assignments, ", ")
+}
+
+// ReturningID returns an empty string, the MySQL driver reports generated IDs through LastInsertId.
+func (u MySQL) ReturningID(column string) string {
+	return ""
+}
+
+// FirstInsertID returns lastInsertID, MySQL reports the ID generated for the first row of a multi-row INSERT.
+// The IDs of a single INSERT statement are consecutive in every InnoDB auto-increment lock mode.
+func (u MySQL) FirstInsertID(lastInsertID int64, rows int) int64 {
+	return lastInsertID
+}
+
+// LimitOne appends LIMIT 1 to the condition.
+func (u MySQL) LimitOne(table string, whereClause string) string {
+	return whereClause + " LIMIT 1"
+}
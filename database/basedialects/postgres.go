@@ -0,0 +1,98 @@
+package basedialects
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PostgreSQL is the dialect of PostgreSQL.
+type PostgreSQL struct{}
+
+// postgresTypes maps the MySQL types of the `db` tags that PostgreSQL doesn't know.
+var postgresTypes = map[string]string{
+	"INT":        "INTEGER",
+	"TINYINT(1)": "BOOLEAN",
+	"TINYINT":    "SMALLINT",
+	"MEDIUMINT":  "INTEGER",
+	"DOUBLE":     "DOUBLE PRECISION",
+	"FLOAT":      "REAL",
+	"DATETIME":   "TIMESTAMP",
+	"TINYTEXT":   "TEXT",
+	"MEDIUMTEXT": "TEXT",
+	"LONGTEXT":   "TEXT",
+	"BLOB":       "BYTEA",
+	"MEDIUMBLOB": "BYTEA",
+	"LONGBLOB":   "BYTEA",
+}
+
+// Placeholder returns the numbered $n placeholder.
+func (u PostgreSQL) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// Quote quotes an identifier with double quotes.
+func (u PostgreSQL) Quote(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+// Like returns ILIKE, as LIKE is case sensitive in PostgreSQL.
+func (u PostgreSQL) Like() string {
+	return "ILIKE"
+}
+
+// ColumnDefinition maps the MySQL type of the column, an AUTO_INCREMENT column becomes a SERIAL or BIGSERIAL.
+func (u PostgreSQL) ColumnDefinition(column Column) string {
+	// PostgreSQL has no unsigned integers
+	columnType := strings.TrimSpace(strings.Replace(column.Type, " UNSIGNED", "", 1))
+	if mapped, ok := postgresTypes[strings.ToUpper(columnType)]; ok {
+		columnType = mapped
+	}
+	if column.AutoIncrement {
+		columnType = "SERIAL"
+		if strings.HasPrefix(strings.ToUpper(column.Type), "BIGINT") {
+			columnType = "BIGSERIAL"
+		}
+	}
+
+	constraints := []string{}
+	if column.PrimaryKey {
+		constraints = append(constraints, "PRIMARY KEY")
+	}
+	return column.definition(u, columnType, constraints...)
+}
+
+// UpsertClause returns an ON CONFLICT clause updating the columns from EXCLUDED.
+func (u PostgreSQL) UpsertClause(table string, keys []string, columns []string, incremented []string) string {
+	return onConflict(table, keys, columns, incremented)
+}
+
+// ReturningID returns a RETURNING clause, PostgreSQL drivers don't support LastInsertId.
+func (u PostgreSQL) ReturningID(column string) string {
+	return " RETURNING " + column
+}
+
+// FirstInsertID returns lastInsertID, which isn't used as the generated IDs are returned by the RETURNING clause.
+func (u PostgreSQL) FirstInsertID(lastInsertID int64, rows int) int64 {
+	return lastInsertID
+}
+
+// LimitOne picks the first matching row by its ctid.
+func (u PostgreSQL) LimitOne(table string, whereClause string) string {
+	return " WHERE ctid IN (SELECT ctid FROM " + table + whereClause + " LIMIT 1)"
+}
+
+// onConflict returns the ON CONFLICT upsert clause shared by PostgreSQL and SQLite.
+func onConflict(table string, keys []string, columns []string, incremented []string) string {
+	clause := " ON CONFLICT (" + strings.Join(keys, ", ") + ")"
+	if len(columns) == 0 && len(incremented) == 0 {
+		return clause + " DO NOTHING"
+	}
+	assignments := make([]string, 0, len(columns)+len(incremented))
+	for _, column := range columns {
+		assignments = append(assignments, column+" = EXCLUDED."+column)
+	}
+	for _, column := range incremented {
+		assignments = append(assignments, column+" = "+table+"."+column+" + 1")
+	}
+	return clause + " DO UPDATE SET " + strings.Join(assignments, ", ")
+}
@@ -0,0 +1,54 @@
+package basedialects
+
+import "strings"
+
+// SQLite is the dialect of SQLite.
+type SQLite struct{}
+
+// Placeholder returns the ? placeholder.
+func (u SQLite) Placeholder(n int) string {
+	return "?"
+}
+
+// Quote quotes an identifier with double quotes.
+func (u SQLite) Quote(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+// Like returns LIKE, which ignores the case of ASCII letters.
+func (u SQLite) Like() string {
+	return "LIKE"
+}
+
+// ColumnDefinition keeps the MySQL type, which SQLite accepts, but an AUTO_INCREMENT primary key
+// becomes INTEGER PRIMARY KEY AUTOINCREMENT, the only column SQLite generates IDs for.
+func (u SQLite) ColumnDefinition(column Column) string {
+	if column.AutoIncrement && column.PrimaryKey {
+		return column.definition(u, "INTEGER", "PRIMARY KEY AUTOINCREMENT")
+	}
+	constraints := []string{}
+	if column.PrimaryKey {
+		constraints = append(constraints, "PRIMARY KEY")
+	}
+	return column.definition(u, column.Type, constraints...)
+}
+
+// UpsertClause returns an ON CONFLICT clause updating the columns from excluded.
+func (u SQLite) UpsertClause(table string, keys []string, columns []string, incremented []string) string {
+	return onConflict(table, keys, columns, incremented)
+}
+
+// ReturningID returns an empty string, the SQLite driver reports generated IDs through LastInsertId.
+func (u SQLite) ReturningID(column string) string {
+	return ""
+}
+
+// FirstInsertID counts back from lastInsertID, SQLite reports the rowid of the last row of a multi-row INSERT.
+func (u SQLite) FirstInsertID(lastInsertID int64, rows int) int64 {
+	return lastInsertID - int64(rows) + 1
+}
+
+// LimitOne picks the first matching row by its rowid, SQLite has no LIMIT on UPDATE and DELETE.
+func (u SQLite) LimitOne(table string, whereClause string) string {
+	return " WHERE rowid IN (SELECT rowid FROM " + table + whereClause + " LIMIT 1)"
+}
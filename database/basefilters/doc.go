@@ -0,0 +1,3 @@
+// Package basefilters defines a backend agnostic filter language for querying collections.
+// Memory and file storages evaluate the filters in Go, while sql storages translate them into parameterized queries.
+package basefilters
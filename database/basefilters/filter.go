@@ -0,0 +1,369 @@
+package basefilters
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Operator is the comparison or logical operator of a filter.
+type Operator string
+
+const (
+	EQ   Operator = "eq"   // Field is equal to Value.
+	NE   Operator = "ne"   // Field is not equal to Value.
+	LT   Operator = "lt"   // Field is less than Value.
+	GT   Operator = "gt"   // Field is greater than Value.
+	IN   Operator = "in"   // Field is one of the values in the Value slice.
+	LIKE Operator = "like" // Field matches the SQL LIKE pattern in Value, using % and _ as wildcards, ignoring case.
+	AND  Operator = "and"  // Every filter in Filters matches.
+	OR   Operator = "or"   // At least one filter in Filters matches.
+	NOT  Operator = "not"  // The single filter in Filters does not match.
+)
+
+// identifierPattern restricts field names so they can be used safely as sql columns.
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Filter is a node of a filter tree. Comparison nodes use Field and Value,
+// logical nodes (and, or, not) use Filters. Filters can be decoded from JSON, e.g.
+//
+//	{"op":"and","filters":[{"op":"eq","field":"name","value":"books"},{"op":"gt","field":"id","value":10}]}
+type Filter struct {
+	Op      Operator       `json:"op"`
+	Field   string         `json:"field,omitempty"`
+	Value   interface{}    `json:"value,omitempty"`
+	Filters []Filter       `json:"filters,omitempty"`
+	like    *regexp.Regexp // The pattern of a like filter compiled by Compile, nil until then.
+}
+
+// Eq returns a filter matching records whose field equals value.
+func Eq(field string, value interface{}) Filter {
+	return Filter{Op: EQ, Field: field, Value: value}
+}
+
+// Ne returns a filter matching records whose field does not equal value.
+func Ne(field string, value interface{}) Filter {
+	return Filter{Op: NE, Field: field, Value: value}
+}
+
+// Lt returns a filter matching records whose field is less than value.
+func Lt(field string, value interface{}) Filter {
+	return Filter{Op: LT, Field: field, Value: value}
+}
+
+// Gt returns a filter matching records whose field is greater than value.
+func Gt(field string, value interface{}) Filter {
+	return Filter{Op: GT, Field: field, Value: value}
+}
+
+// In returns a filter matching records whose field equals one of values.
+func In(field string, values ...interface{}) Filter {
+	return Filter{Op: IN, Field: field, Value: values}
+}
+
+// Like returns a filter matching records whose field matches the SQL LIKE pattern, ignoring case.
+func Like(field string, pattern string) Filter {
+	return Filter{Op: LIKE, Field: field, Value: pattern}
+}
+
+// And returns a filter matching records matched by every filter.
+func And(filters ...Filter) Filter {
+	return Filter{Op: AND, Filters: filters}
+}
+
+// Or returns a filter matching records matched by at least one filter.
+func Or(filters ...Filter) Filter {
+	return Filter{Op: OR, Filters: filters}
+}
+
+// Not returns a filter matching records not matched by filter.
+func Not(filter Filter) Filter {
+	return Filter{Op: NOT, Filters: []Filter{filter}}
+}
+
+// FromMap converts an equality condition map into an and filter.
+// An empty map results in an empty and filter which matches everything.
+func FromMap(condition map[string]interface{}) Filter {
+	filters := make([]Filter, 0, len(condition))
+	for key, val := range condition {
+		filters = append(filters, Eq(key, val))
+	}
+	return And(filters...)
+}
+
+// Validate checks the structure of the filter tree and the field names.
+func (u Filter) Validate() error {
+	switch u.Op {
+	case EQ, NE, LT, GT, LIKE:
+		if !identifierPattern.MatchString(u.Field) {
+			return fmt.Errorf("Invalid filter field %q", u.Field)
+		}
+		if u.Op == LIKE {
+			if _, ok := u.Value.(string); !ok {
+				return errors.New("Like filter requires a string pattern")
+			}
+		}
+	case IN:
+		if !identifierPattern.MatchString(u.Field) {
+			return fmt.Errorf("Invalid filter field %q", u.Field)
+		}
+		if u.Value == nil || reflect.TypeOf(u.Value).Kind() != reflect.Slice {
+			return errors.New("In filter requires a list of values")
+		}
+	case AND, OR:
+		for _, filter := range u.Filters {
+			if err := filter.Validate(); err != nil {
+				return err
+			}
+		}
+	case NOT:
+		if len(u.Filters) != 1 {
+			return errors.New("Not filter requires exactly one filter")
+		}
+		return u.Filters[0].Validate()
+	default:
+		return fmt.Errorf("Unknown filter operator %q", u.Op)
+	}
+	return nil
+}
+
+// Compile validates the filter and returns a copy with the patterns of its like filters compiled,
+// so evaluating it against many records doesn't compile them again for every record.
+func (u Filter) Compile() (Filter, error) {
+	if err := u.Validate(); err != nil {
+		return u, err
+	}
+	return u.compile(), nil
+}
+
+// compile returns a copy of a valid filter with the patterns of its like filters compiled.
+func (u Filter) compile() Filter {
+	if u.Op == LIKE {
+		u.like = likeToRegexp(u.Value.(string))
+	}
+	if len(u.Filters) > 0 {
+		filters := make([]Filter, len(u.Filters))
+		for i, filter := range u.Filters {
+			filters[i] = filter.compile()
+		}
+		u.Filters = filters
+	}
+	return u
+}
+
+// Resolve returns a copy of the filter with every field replaced by the name fields maps it to,
+// like the JSON name of a model field by its sql column. It fails for a field that fields doesn't map.
+func (u Filter) Resolve(fields map[string]string) (Filter, error) {
+	switch u.Op {
+	case EQ, NE, LT, GT, LIKE, IN:
+		name, ok := fields[u.Field]
+		if !ok {
+			return u, fmt.Errorf("Unknown filter field %q", u.Field)
+		}
+		u.Field = name
+	case AND, OR, NOT:
+		filters := make([]Filter, len(u.Filters))
+		for i, filter := range u.Filters {
+			resolved, err := filter.Resolve(fields)
+			if err != nil {
+				return u, err
+			}
+			filters[i] = resolved
+		}
+		u.Filters = filters
+	}
+	return u, nil
+}
+
+// Values returns the elements of an in filter value as a slice of interfaces.
+func (u Filter) Values() []interface{} {
+	value := reflect.ValueOf(u.Value)
+	values := make([]interface{}, 0, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		values = append(values, value.Index(i).Interface())
+	}
+	return values
+}
+
+// Evaluate reports whether the document, a record decoded into its JSON field map, matches the filter.
+func (u Filter) Evaluate(document map[string]interface{}) (bool, error) {
+	switch u.Op {
+	case EQ:
+		return equal(document[u.Field], u.Value), nil
+	case NE:
+		return !equal(document[u.Field], u.Value), nil
+	case LT, GT:
+		result, ok := compare(document[u.Field], u.Value)
+		if !ok {
+			return false, nil
+		}
+		if u.Op == LT {
+			return result < 0, nil
+		}
+		return result > 0, nil
+	case IN:
+		for _, value := range u.Values() {
+			if equal(document[u.Field], value) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case LIKE:
+		text, ok := document[u.Field].(string)
+		if !ok {
+			return false, nil
+		}
+		like := u.like
+		if like == nil {
+			like = likeToRegexp(u.Value.(string))
+		}
+		return like.MatchString(text), nil
+	case AND:
+		for _, filter := range u.Filters {
+			matched, err := filter.Evaluate(document)
+			if err != nil || !matched {
+				return false, err
+			}
+		}
+		return true, nil
+	case OR:
+		for _, filter := range u.Filters {
+			matched, err := filter.Evaluate(document)
+			if err != nil || matched {
+				return matched, err
+			}
+		}
+		return false, nil
+	case NOT:
+		matched, err := u.Filters[0].Evaluate(document)
+		return !matched, err
+	}
+	return false, fmt.Errorf("Unknown filter operator %q", u.Op)
+}
+
+// SQLDialect is the part of an sql dialect needed to translate filters.
+type SQLDialect interface {
+	Placeholder(n int) string       // Placeholder returns the placeholder of the n-th (1 based) value of a query.
+	Quote(identifier string) string // Quote returns the quoted form of a column or table name.
+	Like() string                   // Like returns the operator matching a LIKE pattern ignoring case, like the like filters of the other storages.
+}
+
+// ToSQL translates the filter into a parameterized sql condition of the dialect.
+// offset is the number of values that already precede this condition in the query.
+func (u Filter) ToSQL(dialect SQLDialect, offset int) (string, []interface{}) {
+	values := make([]interface{}, 0)
+	next := func(value interface{}) string {
+		values = append(values, value)
+		return dialect.Placeholder(offset + len(values))
+	}
+
+	var build func(filter Filter) string
+	build = func(filter Filter) string {
+		switch filter.Op {
+		case EQ:
+			return dialect.Quote(filter.Field) + " = " + next(filter.Value)
+		case NE:
+			return dialect.Quote(filter.Field) + " <> " + next(filter.Value)
+		case LT:
+			return dialect.Quote(filter.Field) + " < " + next(filter.Value)
+		case GT:
+			return dialect.Quote(filter.Field) + " > " + next(filter.Value)
+		case LIKE:
+			return dialect.Quote(filter.Field) + " " + dialect.Like() + " " + next(filter.Value)
+		case IN:
+			items := filter.Values()
+			if len(items) == 0 {
+				return "1 = 0"
+			}
+			placeholders := make([]string, 0, len(items))
+			for _, item := range items {
+				placeholders = append(placeholders, next(item))
+			}
+			return dialect.Quote(filter.Field) + " IN (" + strings.Join(placeholders, ", ") + ")"
+		case AND, OR:
+			if len(filter.Filters) == 0 {
+				if filter.Op == AND {
+					return "1 = 1"
+				}
+				return "1 = 0"
+			}
+			parts := make([]string, 0, len(filter.Filters))
+			for _, child := range filter.Filters {
+				parts = append(parts, build(child))
+			}
+			return "(" + strings.Join(parts, " "+strings.ToUpper(string(filter.Op))+" ") + ")"
+		case NOT:
+			return "NOT (" + build(filter.Filters[0]) + ")"
+		}
+		return "1 = 0"
+	}
+	return build(u), values
+}
+
+// equal compares two JSON values, treating every number kind as the same number.
+func equal(a interface{}, b interface{}) bool {
+	if result, ok := compare(a, b); ok {
+		return result == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// compare orders two numbers or two strings. ok is false if the values can't be ordered.
+func compare(a interface{}, b interface{}) (int, bool) {
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			}
+			return 0, true
+		}
+		return 0, false
+	}
+	x, ok := a.(string)
+	if !ok {
+		return 0, false
+	}
+	y, ok := b.(string)
+	if !ok {
+		return 0, false
+	}
+	return strings.Compare(x, y), true
+}
+
+// toFloat converts any numeric value to float64.
+func toFloat(value interface{}) (float64, bool) {
+	if value == nil {
+		return 0, false
+	}
+	number := reflect.ValueOf(value)
+	switch number.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(number.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(number.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return number.Float(), true
+	}
+	return 0, false
+}
+
+// likeToRegexp converts a case insensitive SQL LIKE pattern into a regular expression.
+func likeToRegexp(pattern string) *regexp.Regexp {
+	expression := "(?is)^"
+	for _, char := range pattern {
+		switch char {
+		case '%':
+			expression += ".*"
+		case '_':
+			expression += "."
+		default:
+			expression += regexp.QuoteMeta(string(char))
+		}
+	}
+	return regexp.MustCompile(expression + "$")
+}
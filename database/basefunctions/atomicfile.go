@@ -0,0 +1,66 @@
+package basefunctions
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// temporarySuffix ends the name of every temporary file, so files left behind by a crash can be found on recovery.
+const temporarySuffix = ".tmp"
+
+// writeFileAtomic replaces filePath with content without ever exposing a partially written file.
+// The content is written to a temporary file in the same directory, flushed to disk and renamed
+// over filePath, then the directory is flushed so the rename itself survives a crash.
+// Missing parent directories are created.
+func writeFileAtomic(filePath string, content []byte) error {
+	directory := filepath.Dir(filePath)
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return errors.New("Error opening file path")
+	}
+	file, err := os.CreateTemp(directory, filepath.Base(filePath)+".*"+temporarySuffix)
+	if err != nil {
+		return errors.New("Error opening file path")
+	}
+	temporaryPath := file.Name()
+
+	_, err = file.Write(content)
+	if err == nil {
+		err = file.Sync()
+	}
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(temporaryPath, 0644)
+	}
+	if err == nil {
+		err = os.Rename(temporaryPath, filePath)
+	}
+	if err != nil {
+		os.Remove(temporaryPath)
+		return errors.New("Error writing file")
+	}
+	return syncDirectory(directory)
+}
+
+// writeJSONFile encodes data and writes it atomically to filePath.
+func writeJSONFile(filePath string, data interface{}) error {
+	content, err := json.Marshal(data)
+	if err != nil {
+		return errors.New("Error encoding JSON")
+	}
+	return writeFileAtomic(filePath, append(content, '\n'))
+}
+
+// syncDirectory flushes the entries of a directory, making renames and removals in it durable.
+func syncDirectory(directory string) error {
+	dir, err := os.Open(directory)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
@@ -1,12 +1,15 @@
 package basefunctions
 
 import (
+	"context"
 	"websays/database/basetypes"
 )
 
 /*
  * BaseFucntionsInterface is an interface that defines the common database operations
  * to be implemented by different types of connections. Flyweight in nature
+ * Every operation has a Context variant that gives up with the context's error once it is cancelled
+ * or past its deadline, the plain operations run with context.Background().
  */
 type BaseFucntionsInterface interface {
 	// GetFunctions returns the BaseFucntionsInterface instance.
@@ -25,8 +28,9 @@ type BaseFucntionsInterface interface {
 	//   - dbName: The name of the database.
 	//   - collectionName: The name of the collection.
 	//   - data: The data to be inserted.
-	// Returns an error if the operation fails.
-	Add(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (int, error)
+	// Returns the ID of the inserted document, the one it carries or the one generated by the database,
+	// and an error if the operation fails.
+	Add(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (basetypes.ID, error)
 
 	// FindOne retrieves a single document from a collection in the database based on the provided query.
 	// Parameters:
@@ -36,17 +40,27 @@ type BaseFucntionsInterface interface {
 	// Returns the retrieved document and an error if the operation fails.
 	FindOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) (interface{}, error)
 
+	// FindMany retrieves a page of documents from a collection in the database based on the provided query.
+	// Parameters:
+	//   - dbName: The name of the database.
+	//   - collectionName: The name of the collection.
+	//   - query: The query to filter the documents, nil matches every document.
+	//   - options: The limit, offset or cursor of the requested page.
+	// Returns the page with the total count and next cursor, and an error if the operation fails.
+	FindMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, options basetypes.FindOptions) (basetypes.FindResult, error)
+
 	// UpdateOne updates a document in a collection in the database based on the provided query.
 	// Parameters:
 	//   - dbName: The name of the database.
 	//   - collectionName: The name of the collection.
 	//   - query: The query to filter the document to be updated.
 	//   - data: The data to update the document with.
-	//   - upsert: Whether to perform an upsert (insert if not found) operation.
+	//   - upsert: Whether to insert data, a model carrying its ID, when no document matches the query.
 	// Returns an error if the operation fails.
 	UpdateOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error
 
 	// DeleteOne deletes a document from a collection in the database based on the provided query.
+	// Storages implementing TrashInterface move the documents of soft deletable models to the trash instead.
 	// Parameters:
 	//   - dbName: The name of the database.
 	//   - collectionName: The name of the collection.
@@ -54,6 +68,57 @@ type BaseFucntionsInterface interface {
 	// Returns an error if the operation fails.
 	DeleteOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) error
 
-	// GetNextID returns the next available ID for document insertion.
-	GetNextID() int
+	// AddMany inserts several documents into a collection in the database.
+	// The documents are inserted independently, so some can fail while the others are inserted.
+	// Parameters:
+	//   - dbName: The name of the database.
+	//   - collectionName: The name of the collection.
+	//   - data: The data to be inserted.
+	// Returns the outcome of each document, and an error if the whole operation fails or is cut short.
+	AddMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, data []interface{}) (basetypes.BulkResult, error)
+
+	// UpdateMany updates several documents in a collection in the database, each like UpdateOne.
+	// The updates are applied independently, so some can fail while the others are applied.
+	// Parameters:
+	//   - dbName: The name of the database.
+	//   - collectionName: The name of the collection.
+	//   - updates: The query, data and upsert flag of each update.
+	// Returns the outcome of each update, and an error if the whole operation fails or is cut short.
+	UpdateMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, updates []basetypes.BulkUpdate) (basetypes.BulkResult, error)
+
+	// DeleteMany deletes several documents from a collection in the database, each like DeleteOne.
+	// The deletions are applied independently, so some can fail while the others are applied.
+	// Parameters:
+	//   - dbName: The name of the database.
+	//   - collectionName: The name of the collection.
+	//   - queries: The query of each document to be deleted.
+	// Returns the outcome of each deletion, and an error if the whole operation fails or is cut short.
+	DeleteMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, queries []interface{}) (basetypes.BulkResult, error)
+
+	// EnsureIndexContext is EnsureIndex, giving up once ctx is done.
+	EnsureIndexContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, indexData interface{}) error
+
+	// AddContext is Add, giving up once ctx is done.
+	AddContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (basetypes.ID, error)
+
+	// FindOneContext is FindOne, giving up once ctx is done.
+	FindOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) (interface{}, error)
+
+	// FindManyContext is FindMany, giving up once ctx is done.
+	FindManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, options basetypes.FindOptions) (basetypes.FindResult, error)
+
+	// UpdateOneContext is UpdateOne, giving up once ctx is done.
+	UpdateOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error
+
+	// DeleteOneContext is DeleteOne, giving up once ctx is done.
+	DeleteOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) error
+
+	// AddManyContext is AddMany, giving up once ctx is done. The documents not inserted by then fail with the error of ctx.
+	AddManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data []interface{}) (basetypes.BulkResult, error)
+
+	// UpdateManyContext is UpdateMany, giving up once ctx is done. The updates not applied by then fail with the error of ctx.
+	UpdateManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, updates []basetypes.BulkUpdate) (basetypes.BulkResult, error)
+
+	// DeleteManyContext is DeleteMany, giving up once ctx is done. The deletions not applied by then fail with the error of ctx.
+	DeleteManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, queries []interface{}) (basetypes.BulkResult, error)
 }
@@ -1,8 +1,11 @@
 package basefunctions
 
 import (
+	"context"
 	"errors"
 	"sync"
+	"time"
+	"websays/config"
 	"websays/database/basetypes"
 )
 
@@ -57,9 +60,65 @@ func (u *baseFunctions) GetFunctions(dbType basetypes.DbType, dbName basetypes.D
 			connection := MemoryFunctions{}
 			functionsInterface := connection.GetFunctions()
 
+			u.dbfunctions[dbType] = &functionsInterface
+			return u.dbfunctions[dbType], nil
+		}
+	case basetypes.WRITEBEHIND:
+		{
+			back, err := u.GetFunctions(basetypes.MYSQL, dbName)
+			if err != nil {
+				return nil, err
+			}
+			writeBehindConfig := config.GetInstance().WriteBehind
+			connection, err := NewWriteBehindFunctions(*back, WriteBehindOptions{
+				FlushInterval: time.Duration(writeBehindConfig.FlushInterval) * time.Millisecond,
+				BatchSize:     writeBehindConfig.BatchSize,
+				RetryBackoff:  time.Duration(writeBehindConfig.RetryBackoff) * time.Millisecond,
+				MaxBackoff:    time.Duration(writeBehindConfig.MaxBackoff) * time.Millisecond,
+			})
+			if err != nil {
+				return nil, err
+			}
+			functionsInterface := connection.GetFunctions()
+
+			u.dbfunctions[dbType] = &functionsInterface
+			return u.dbfunctions[dbType], nil
+		}
+	case basetypes.SQLITE:
+		{
+			connection := SqliteFunctions{}
+			functionsInterface := connection.GetFunctions()
+
 			u.dbfunctions[dbType] = &functionsInterface
 			return u.dbfunctions[dbType], nil
 		}
 	}
 	return nil, errors.New("Not configured for this db")
 }
+
+// closableFunctions is implemented by the storages running background work of their own, like the snapshots
+// of the memory storage, stopped by Close on shutdown.
+type closableFunctions interface {
+	Close() error
+}
+
+// Drain stops the background flushes of the storages created by the factory that persist their writes later,
+// like the write-behind storage, and persists their pending writes, giving up once ctx is done.
+// The background work of the other storages, like the snapshots of the memory storage, is stopped too.
+// It returns the first error, after trying every storage.
+func (u *baseFunctions) Drain(ctx context.Context) error {
+	var drainErr error
+	for _, functions := range u.dbfunctions {
+		var err error
+		switch storage := (*functions).(type) {
+		case FlushInterface:
+			err = storage.Close(ctx)
+		case closableFunctions:
+			err = storage.Close()
+		}
+		if err != nil && drainErr == nil {
+			drainErr = err
+		}
+	}
+	return drainErr
+}
@@ -0,0 +1,37 @@
+package basefunctions
+
+import (
+	"context"
+	"websays/database/basetypes"
+	"websays/httpHandler/basemodels"
+)
+
+// bulkApply calls apply for each of size items in order, reporting the ID or the error it returns in the result.
+// Once ctx is done the remaining items fail with its error, which is returned too.
+func bulkApply(ctx context.Context, size int, apply func(index int) (basetypes.ID, error)) (basetypes.BulkResult, error) {
+	result := basetypes.NewBulkResult(size)
+	for i := 0; i < size; i++ {
+		if err := ctx.Err(); err != nil {
+			result.FailFrom(i, err)
+			return result, err
+		}
+		id, err := apply(i)
+		result.Set(i, id, err)
+	}
+	return result, nil
+}
+
+// recordID returns the ID carried by a model, the empty ID for any other data.
+func recordID(data interface{}) basetypes.ID {
+	if model, ok := data.(basemodels.BaseModels); ok {
+		return model.GetID()
+	}
+	return ""
+}
+
+// bulkFailed returns the result of a bulk operation on size items that failed as a whole with err, and err.
+func bulkFailed(size int, err error) (basetypes.BulkResult, error) {
+	result := basetypes.NewBulkResult(size)
+	result.FailFrom(0, err)
+	return result, err
+}
@@ -0,0 +1,14 @@
+package basefunctions
+
+import (
+	"websays/database/basetypes"
+)
+
+/*
+ * CacheInterface is implemented by the caching wrappers of the storages, see NewCachedFunctions.
+ * It is kept apart from BaseFucntionsInterface like TrashInterface, as the storages themselves don't cache.
+ */
+type CacheInterface interface {
+	// CacheStats returns the hits, misses and evictions of the cache since it was created.
+	CacheStats() basetypes.CacheStats
+}
@@ -0,0 +1,383 @@
+package basefunctions
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+	"websays/database/basetypes"
+)
+
+/*
+ * CachedFunctions is a read-through cache around any BaseFucntionsInterface, see NewCachedFunctions.
+ * FindOne is answered from an LRU cache of the records read by query, misses included, which expire after a TTL.
+ * Every write to a collection through the wrapper invalidates the cached reads of the collection, so the cache
+ * never serves a record older than the last write made through it. The writes made to the storage around
+ * the wrapper are only seen once the cached reads expire.
+ * The cached records are shared between the callers of FindOne, which must not change them.
+ */
+type CachedFunctions struct {
+	storage BaseFucntionsInterface // The storage the reads are passed on to.
+	cache   *recordCache           // The LRU cache of the reads.
+	ttl     time.Duration          // How long a record read is served from the cache.
+	missTTL time.Duration          // How long a miss, a read finding no record, is served from the cache.
+}
+
+// cachedDatabaseFunctions is a CachedFunctions around a storage keeping databases apart and sequences,
+// like the memory and file storages, so the wrapper keeps both interfaces of the storage.
+type cachedDatabaseFunctions struct {
+	*CachedFunctions
+	SequenceInterface
+	databases DatabaseInterface
+}
+
+// cachedSequenceFunctions is a CachedFunctions around a storage keeping sequences but a single database,
+// like the write-behind storage, so the wrapper keeps the SequenceInterface of the storage.
+type cachedSequenceFunctions struct {
+	*CachedFunctions
+	SequenceInterface
+}
+
+// cachedIterableFunctions is a CachedFunctions around a storage streaming its records, like the sql storages,
+// so the wrapper keeps the IterableInterface of the storage. The iterations aren't cached.
+type cachedIterableFunctions struct {
+	*CachedFunctions
+	IterableInterface
+}
+
+// cachedTransaction is a transaction on the storage of a CachedFunctions,
+// invalidating the cached reads of the collections it wrote to once it is committed.
+type cachedTransaction struct {
+	TransactionInterface
+	functions *CachedFunctions
+	written   []cacheCollection // The collections written by the transaction.
+}
+
+// NewCachedFunctions returns a read-through cache of up to size records around storage.
+// The records read are served for ttl and the misses for missTTL, ttl as well when missTTL is 0.
+// The wrapper keeps the TrashInterface and TransactionalInterface of the storage, and its DatabaseInterface
+// and SequenceInterface, its SequenceInterface alone, or its IterableInterface, so the controllers see the same storage through it.
+func NewCachedFunctions(storage BaseFucntionsInterface, size int, ttl time.Duration, missTTL time.Duration) BaseFucntionsInterface {
+	if missTTL <= 0 {
+		missTTL = ttl
+	}
+	cached := &CachedFunctions{storage: storage, cache: newRecordCache(size), ttl: ttl, missTTL: missTTL}
+
+	databases, isDatabases := storage.(DatabaseInterface)
+	sequences, isSequences := storage.(SequenceInterface)
+	if isDatabases && isSequences {
+		return &cachedDatabaseFunctions{CachedFunctions: cached, SequenceInterface: sequences, databases: databases}
+	}
+	if isSequences {
+		return &cachedSequenceFunctions{CachedFunctions: cached, SequenceInterface: sequences}
+	}
+	if iterable, ok := storage.(IterableInterface); ok {
+		return &cachedIterableFunctions{CachedFunctions: cached, IterableInterface: iterable}
+	}
+	return cached
+}
+
+// isMiss reports whether err is the error of a storage finding no record for a query.
+func isMiss(err error) bool {
+	return errors.Is(err, sql.ErrNoRows) || errors.Is(err, errDataNotFound) || errors.Is(err, errIDNotFound)
+}
+
+// GetFunctions returns the CachedFunctions instance as a BaseFucntionsInterface.
+func (u *CachedFunctions) GetFunctions() BaseFucntionsInterface {
+	return u
+}
+
+// CacheStats returns the hits, misses and evictions of the cache since it was created.
+func (u *CachedFunctions) CacheStats() basetypes.CacheStats {
+	return u.cache.snapshot()
+}
+
+// invalidate invalidates the cached reads of a collection, after a write to it.
+func (u *CachedFunctions) invalidate(dbName basetypes.DBName, collectionName basetypes.CollectionName) {
+	u.cache.invalidate(cacheCollection{dbName: dbName, collectionName: collectionName})
+}
+
+// EnsureIndex passes the index on to the storage.
+func (u *CachedFunctions) EnsureIndex(dbName basetypes.DBName, collectionName basetypes.CollectionName, indexData interface{}) error {
+	return u.EnsureIndexContext(context.Background(), dbName, collectionName, indexData)
+}
+
+// EnsureIndexContext is EnsureIndex, giving up once ctx is done.
+func (u *CachedFunctions) EnsureIndexContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, indexData interface{}) error {
+	return u.storage.EnsureIndexContext(ctx, dbName, collectionName, indexData)
+}
+
+// Add inserts data into the storage and invalidates the cached reads of the collection, the cached misses among them.
+func (u *CachedFunctions) Add(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (basetypes.ID, error) {
+	return u.AddContext(context.Background(), dbName, collectionName, data)
+}
+
+// AddContext is Add, giving up once ctx is done.
+func (u *CachedFunctions) AddContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (basetypes.ID, error) {
+	defer u.invalidate(dbName, collectionName)
+	return u.storage.AddContext(ctx, dbName, collectionName, data)
+}
+
+// FindOne returns the record of a query from the cache, or else reads it from the storage and caches it.
+// A query finding no record is cached as well, and answered with the error of the storage until it expires.
+// The queries that can't be encoded in JSON aren't cached.
+func (u *CachedFunctions) FindOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) (interface{}, error) {
+	return u.FindOneContext(context.Background(), dbName, collectionName, query)
+}
+
+// FindOneContext is FindOne, giving up once ctx is done.
+func (u *CachedFunctions) FindOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(query)
+	if err != nil {
+		return u.storage.FindOneContext(ctx, dbName, collectionName, query)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	key := cacheKey{cacheCollection: cacheCollection{dbName: dbName, collectionName: collectionName}, query: string(encoded)}
+	entry, generation, ok := u.cache.get(key, time.Now())
+	if ok {
+		return entry.record, entry.err
+	}
+
+	record, err := u.storage.FindOneContext(ctx, dbName, collectionName, query)
+	switch {
+	case err == nil:
+		u.cache.put(cacheEntry{key: key, record: record, generation: generation, expires: time.Now().Add(u.ttl)})
+	case isMiss(err):
+		u.cache.put(cacheEntry{key: key, err: err, generation: generation, expires: time.Now().Add(u.missTTL)})
+	}
+	return record, err
+}
+
+// FindMany reads a page of records from the storage, the pages aren't cached.
+func (u *CachedFunctions) FindMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	return u.FindManyContext(context.Background(), dbName, collectionName, query, options)
+}
+
+// FindManyContext is FindMany, giving up once ctx is done.
+func (u *CachedFunctions) FindManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	return u.storage.FindManyContext(ctx, dbName, collectionName, query, options)
+}
+
+// UpdateOne updates a record in the storage and invalidates the cached reads of the collection.
+func (u *CachedFunctions) UpdateOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error {
+	return u.UpdateOneContext(context.Background(), dbName, collectionName, query, data, upsert)
+}
+
+// UpdateOneContext is UpdateOne, giving up once ctx is done.
+func (u *CachedFunctions) UpdateOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error {
+	defer u.invalidate(dbName, collectionName)
+	return u.storage.UpdateOneContext(ctx, dbName, collectionName, query, data, upsert)
+}
+
+// DeleteOne deletes a record from the storage and invalidates the cached reads of the collection.
+func (u *CachedFunctions) DeleteOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) error {
+	return u.DeleteOneContext(context.Background(), dbName, collectionName, query)
+}
+
+// DeleteOneContext is DeleteOne, giving up once ctx is done.
+func (u *CachedFunctions) DeleteOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) error {
+	defer u.invalidate(dbName, collectionName)
+	return u.storage.DeleteOneContext(ctx, dbName, collectionName, query)
+}
+
+// AddMany inserts several records into the storage and invalidates the cached reads of the collection.
+func (u *CachedFunctions) AddMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, data []interface{}) (basetypes.BulkResult, error) {
+	return u.AddManyContext(context.Background(), dbName, collectionName, data)
+}
+
+// AddManyContext is AddMany, giving up once ctx is done.
+func (u *CachedFunctions) AddManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data []interface{}) (basetypes.BulkResult, error) {
+	defer u.invalidate(dbName, collectionName)
+	return u.storage.AddManyContext(ctx, dbName, collectionName, data)
+}
+
+// UpdateMany updates several records in the storage and invalidates the cached reads of the collection.
+func (u *CachedFunctions) UpdateMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, updates []basetypes.BulkUpdate) (basetypes.BulkResult, error) {
+	return u.UpdateManyContext(context.Background(), dbName, collectionName, updates)
+}
+
+// UpdateManyContext is UpdateMany, giving up once ctx is done.
+func (u *CachedFunctions) UpdateManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, updates []basetypes.BulkUpdate) (basetypes.BulkResult, error) {
+	defer u.invalidate(dbName, collectionName)
+	return u.storage.UpdateManyContext(ctx, dbName, collectionName, updates)
+}
+
+// DeleteMany deletes several records from the storage and invalidates the cached reads of the collection.
+func (u *CachedFunctions) DeleteMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, queries []interface{}) (basetypes.BulkResult, error) {
+	return u.DeleteManyContext(context.Background(), dbName, collectionName, queries)
+}
+
+// DeleteManyContext is DeleteMany, giving up once ctx is done.
+func (u *CachedFunctions) DeleteManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, queries []interface{}) (basetypes.BulkResult, error) {
+	defer u.invalidate(dbName, collectionName)
+	return u.storage.DeleteManyContext(ctx, dbName, collectionName, queries)
+}
+
+// trash returns the trash of the storage, or an error if it doesn't keep one.
+func (u *CachedFunctions) trash() (TrashInterface, error) {
+	trash, ok := u.storage.(TrashInterface)
+	if !ok {
+		return nil, errors.New("Storage doesn't keep a trash")
+	}
+	return trash, nil
+}
+
+// FindTrash reads a page of the trash of the storage, the trash isn't cached.
+func (u *CachedFunctions) FindTrash(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	return u.FindTrashContext(context.Background(), dbName, collectionName, query, options)
+}
+
+// FindTrashContext is FindTrash, giving up once ctx is done.
+func (u *CachedFunctions) FindTrashContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	trash, err := u.trash()
+	if err != nil {
+		return basetypes.FindResult{}, err
+	}
+	return trash.FindTrashContext(ctx, dbName, collectionName, query, options)
+}
+
+// Restore moves a record of the storage out of the trash and invalidates the cached reads of the collection,
+// the cached miss of the record among them.
+func (u *CachedFunctions) Restore(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) error {
+	return u.RestoreContext(context.Background(), dbName, collectionName, query)
+}
+
+// RestoreContext is Restore, giving up once ctx is done.
+func (u *CachedFunctions) RestoreContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) error {
+	trash, err := u.trash()
+	if err != nil {
+		return err
+	}
+	defer u.invalidate(dbName, collectionName)
+	return trash.RestoreContext(ctx, dbName, collectionName, query)
+}
+
+// Purge removes a record from the trash of the storage. FindOne doesn't see the trashed records, so no cached read is invalidated.
+func (u *CachedFunctions) Purge(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) error {
+	return u.PurgeContext(context.Background(), dbName, collectionName, query)
+}
+
+// PurgeContext is Purge, giving up once ctx is done.
+func (u *CachedFunctions) PurgeContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) error {
+	trash, err := u.trash()
+	if err != nil {
+		return err
+	}
+	return trash.PurgeContext(ctx, dbName, collectionName, query)
+}
+
+// PurgeTrash removes the records trashed before a time from the trash of the storage, without invalidating any cached read.
+func (u *CachedFunctions) PurgeTrash(dbName basetypes.DBName, collectionName basetypes.CollectionName, before time.Time) (int, error) {
+	return u.PurgeTrashContext(context.Background(), dbName, collectionName, before)
+}
+
+// PurgeTrashContext is PurgeTrash, giving up once ctx is done.
+func (u *CachedFunctions) PurgeTrashContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, before time.Time) (int, error) {
+	trash, err := u.trash()
+	if err != nil {
+		return 0, err
+	}
+	return trash.PurgeTrashContext(ctx, dbName, collectionName, before)
+}
+
+// Begin starts a transaction on the storage, invalidating the cached reads of the collections it writes once it is committed.
+// The reads of the transaction aren't cached, as they see its own writes.
+func (u *CachedFunctions) Begin() (TransactionInterface, error) {
+	return u.BeginContext(context.Background())
+}
+
+// BeginContext is Begin, with the transaction run under ctx.
+func (u *CachedFunctions) BeginContext(ctx context.Context) (TransactionInterface, error) {
+	transactional, ok := u.storage.(TransactionalInterface)
+	if !ok {
+		return nil, errors.New("Storage doesn't support transactions")
+	}
+	tx, err := transactional.BeginContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &cachedTransaction{TransactionInterface: tx, functions: u}, nil
+}
+
+// Add inserts a new document into a collection as part of the transaction.
+func (t *cachedTransaction) Add(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (basetypes.ID, error) {
+	t.written = append(t.written, cacheCollection{dbName: dbName, collectionName: collectionName})
+	return t.TransactionInterface.Add(dbName, collectionName, data)
+}
+
+// UpdateOne updates a document in a collection as part of the transaction.
+func (t *cachedTransaction) UpdateOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error {
+	t.written = append(t.written, cacheCollection{dbName: dbName, collectionName: collectionName})
+	return t.TransactionInterface.UpdateOne(dbName, collectionName, query, data, upsert)
+}
+
+// DeleteOne deletes a document from a collection as part of the transaction.
+func (t *cachedTransaction) DeleteOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) error {
+	t.written = append(t.written, cacheCollection{dbName: dbName, collectionName: collectionName})
+	return t.TransactionInterface.DeleteOne(dbName, collectionName, query)
+}
+
+// Commit applies every write of the transaction and invalidates the cached reads of the collections it wrote to.
+func (t *cachedTransaction) Commit() error {
+	defer func() {
+		for _, collection := range t.written {
+			t.functions.cache.invalidate(collection)
+		}
+	}()
+	return t.TransactionInterface.Commit()
+}
+
+// GetFunctions returns the cachedDatabaseFunctions instance as a BaseFucntionsInterface.
+func (u *cachedDatabaseFunctions) GetFunctions() BaseFucntionsInterface {
+	return u
+}
+
+// ListDatabases lists the databases of the storage.
+func (u *cachedDatabaseFunctions) ListDatabases() ([]basetypes.DBName, error) {
+	return u.databases.ListDatabases()
+}
+
+// CreateDatabase creates an empty database in the storage.
+func (u *cachedDatabaseFunctions) CreateDatabase(dbName basetypes.DBName) error {
+	return u.databases.CreateDatabase(dbName)
+}
+
+// DropDatabase drops a database of the storage and invalidates the cached reads of all of its collections.
+func (u *cachedDatabaseFunctions) DropDatabase(dbName basetypes.DBName) error {
+	return u.DropDatabaseContext(context.Background(), dbName)
+}
+
+// HasDatabase reports whether the storage has a database.
+func (u *cachedDatabaseFunctions) HasDatabase(dbName basetypes.DBName) (bool, error) {
+	return u.databases.HasDatabase(dbName)
+}
+
+// ListDatabasesContext is ListDatabases, giving up once ctx is done.
+func (u *cachedDatabaseFunctions) ListDatabasesContext(ctx context.Context) ([]basetypes.DBName, error) {
+	return u.databases.ListDatabasesContext(ctx)
+}
+
+// CreateDatabaseContext is CreateDatabase, giving up once ctx is done.
+func (u *cachedDatabaseFunctions) CreateDatabaseContext(ctx context.Context, dbName basetypes.DBName) error {
+	return u.databases.CreateDatabaseContext(ctx, dbName)
+}
+
+// DropDatabaseContext is DropDatabase, giving up once ctx is done.
+func (u *cachedDatabaseFunctions) DropDatabaseContext(ctx context.Context, dbName basetypes.DBName) error {
+	defer u.cache.invalidateDatabase(dbName)
+	return u.databases.DropDatabaseContext(ctx, dbName)
+}
+
+// GetFunctions returns the cachedSequenceFunctions instance as a BaseFucntionsInterface.
+func (u *cachedSequenceFunctions) GetFunctions() BaseFucntionsInterface {
+	return u
+}
+
+// GetFunctions returns the cachedIterableFunctions instance as a BaseFucntionsInterface.
+func (u *cachedIterableFunctions) GetFunctions() BaseFucntionsInterface {
+	return u
+}
@@ -0,0 +1,145 @@
+package basefunctions
+
+import (
+	"container/list"
+	"sync"
+	"time"
+	"websays/database/basetypes"
+)
+
+// cacheCollection names a collection of a database in the cache.
+type cacheCollection struct {
+	dbName         basetypes.DBName
+	collectionName basetypes.CollectionName
+}
+
+// cacheKey names the read of a query on a collection in the cache.
+type cacheKey struct {
+	cacheCollection
+	query string // The query encoded in JSON.
+}
+
+// cacheEntry is the outcome of a read held by the cache, a record or the error of a miss.
+type cacheEntry struct {
+	key        cacheKey
+	record     interface{} // The record read, nil for a miss.
+	err        error       // The error of a miss, nil for a record.
+	generation uint64      // The generation of the collection the read was done in.
+	expires    time.Time   // When the entry stops being served.
+}
+
+// recordCache is an LRU cache of the reads of a storage, holding up to size entries.
+// Each collection has a generation, moved on by every write to it, and an entry is only served
+// while the collection is at the generation it was read in, so a write invalidates every read of its collection at once.
+// The stale entries are dropped when they are looked up or reach the end of the LRU list.
+type recordCache struct {
+	size        int                        // Maximum number of entries.
+	lock        sync.Mutex                 // Guards the fields below.
+	entries     map[cacheKey]*list.Element // Entries by key, the elements of order.
+	order       *list.List                 // Entries from the most to the least recently used.
+	generations map[cacheCollection]uint64 // Generation of each collection read or written through the cache.
+	stats       basetypes.CacheStats       // Activity of the cache.
+}
+
+// newRecordCache returns an empty cache holding up to size entries.
+func newRecordCache(size int) *recordCache {
+	return &recordCache{
+		size:        size,
+		entries:     make(map[cacheKey]*list.Element),
+		order:       list.New(),
+		generations: make(map[cacheCollection]uint64),
+		stats:       basetypes.CacheStats{Size: size},
+	}
+}
+
+// get returns the fresh entry of key, counting a hit, or false counting a miss.
+// It also returns the generation of the collection, which the read done on a miss must be stored with.
+func (c *recordCache) get(key cacheKey, now time.Time) (cacheEntry, uint64, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	generation := c.generation(key.cacheCollection)
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(cacheEntry)
+		if entry.generation == generation && now.Before(entry.expires) {
+			c.order.MoveToFront(element)
+			c.stats.Hits++
+			if entry.err != nil {
+				c.stats.NegativeHits++
+			}
+			return entry, generation, true
+		}
+		c.remove(element)
+	}
+	c.stats.Misses++
+	return cacheEntry{}, generation, false
+}
+
+// put stores an entry, unless its collection was written since the entry was read.
+// The least recently used entries are evicted to keep the cache within its size.
+func (c *recordCache) put(entry cacheEntry) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if entry.generation != c.generation(entry.key.cacheCollection) {
+		return
+	}
+	if element, ok := c.entries[entry.key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[entry.key] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+// invalidate moves the generation of a collection on, so none of its cached reads is served anymore.
+func (c *recordCache) invalidate(collection cacheCollection) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.generations[collection]++
+	c.stats.Invalidations++
+}
+
+// invalidateDatabase moves the generation of every collection of a database on.
+func (c *recordCache) invalidateDatabase(dbName basetypes.DBName) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for collection := range c.generations {
+		if collection.dbName == dbName {
+			c.generations[collection]++
+		}
+	}
+	c.stats.Invalidations++
+}
+
+// snapshot returns the activity of the cache.
+func (c *recordCache) snapshot() basetypes.CacheStats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	stats := c.stats
+	stats.Entries = c.order.Len()
+	return stats
+}
+
+// generation returns the generation of a collection, registering it so invalidateDatabase moves it on.
+// The caller must hold the lock.
+func (c *recordCache) generation(collection cacheCollection) uint64 {
+	generation, ok := c.generations[collection]
+	if !ok {
+		c.generations[collection] = 0
+	}
+	return generation
+}
+
+// remove drops an entry. The caller must hold the lock.
+func (c *recordCache) remove(element *list.Element) {
+	delete(c.entries, element.Value.(cacheEntry).key)
+	c.order.Remove(element)
+}
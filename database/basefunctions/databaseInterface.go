@@ -0,0 +1,60 @@
+package basefunctions
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"websays/database/basetypes"
+)
+
+// ErrDatabaseExists is returned by CreateDatabase when the database already exists.
+var ErrDatabaseExists = errors.New("Database already exists")
+
+// ErrDatabaseNotFound is returned by DropDatabase when the database doesn't exist.
+var ErrDatabaseNotFound = errors.New("Database not found")
+
+// dbNamePattern matches the valid database names, which are also directory names of the file storage.
+var dbNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// CheckDBName returns an error if dbName isn't a valid database name: 1 to 64 letters, digits, underscores or hyphens.
+func CheckDBName(dbName basetypes.DBName) error {
+	if !dbNamePattern.MatchString(string(dbName)) {
+		return errors.New("Invalid database name")
+	}
+	return nil
+}
+
+/*
+ * DatabaseInterface is implemented by the storages keeping the logical databases apart, so several of them,
+ * such as a test and a staging dataset, can be hosted in one process. A database is created by its first write
+ * or by CreateDatabase, and DropDatabase removes it with every collection and record it holds.
+ * It is kept apart from BaseFucntionsInterface like TrashInterface, the Context variants give up once ctx is done.
+ */
+type DatabaseInterface interface {
+	// ListDatabases returns the names of the databases of the storage in alphabetical order.
+	ListDatabases() ([]basetypes.DBName, error)
+
+	// CreateDatabase creates an empty database.
+	// Parameters:
+	//   - dbName: The name of the database, see CheckDBName.
+	// Returns ErrDatabaseExists if the database already exists, or an error if the operation fails.
+	CreateDatabase(dbName basetypes.DBName) error
+
+	// DropDatabase removes a database with all of its collections and records, including the trashed ones.
+	// Parameters:
+	//   - dbName: The name of the database.
+	// Returns ErrDatabaseNotFound if the database doesn't exist, or an error if the operation fails.
+	DropDatabase(dbName basetypes.DBName) error
+
+	// HasDatabase reports whether a database exists.
+	HasDatabase(dbName basetypes.DBName) (bool, error)
+
+	// ListDatabasesContext is ListDatabases, giving up once ctx is done.
+	ListDatabasesContext(ctx context.Context) ([]basetypes.DBName, error)
+
+	// CreateDatabaseContext is CreateDatabase, giving up once ctx is done.
+	CreateDatabaseContext(ctx context.Context, dbName basetypes.DBName) error
+
+	// DropDatabaseContext is DropDatabase, giving up once ctx is done.
+	DropDatabaseContext(ctx context.Context, dbName basetypes.DBName) error
+}
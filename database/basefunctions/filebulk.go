@@ -0,0 +1,82 @@
+package basefunctions
+
+import (
+	"context"
+	"websays/database/basetypes"
+)
+
+// AddMany adds several records to the file-based storage, taking the layout and storage process locks once for the batch.
+// Each record is still written under its own lock, so the batch runs alongside the other writers of the collection,
+// and the written IDs are added to the index of the collection at once afterwards.
+func (u *FileFunctions) AddMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, data []interface{}) (basetypes.BulkResult, error) {
+	return u.AddManyContext(context.Background(), dbName, collectionName, data)
+}
+
+// AddManyContext is AddMany, giving up once ctx is done. The context is checked before each record.
+func (u *FileFunctions) AddManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data []interface{}) (basetypes.BulkResult, error) {
+	u.layoutLock.RLock()
+	defer u.layoutLock.RUnlock()
+	unlockStorage, err := u.lockStorage(false)
+	if err != nil {
+		return bulkFailed(len(data), err)
+	}
+	defer unlockStorage()
+
+	written := make([]basetypes.ID, 0, len(data))
+	result, err := bulkApply(ctx, len(data), func(index int) (basetypes.ID, error) {
+		id, err := u.writeNew(ctx, dbName, collectionName, data[index])
+		if err != nil {
+			return "", err
+		}
+		written = append(written, id)
+		return id, nil
+	})
+	// Records written without their index entry are found again by the recovery pass
+	if indexErr := u.addToIndex(dbName, collectionName, written...); indexErr != nil {
+		return result, indexErr
+	}
+	return result, err
+}
+
+// UpdateMany applies several updates to the file-based storage, each like UpdateOne,
+// taking the layout and storage process locks once for the batch.
+func (u *FileFunctions) UpdateMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, updates []basetypes.BulkUpdate) (basetypes.BulkResult, error) {
+	return u.UpdateManyContext(context.Background(), dbName, collectionName, updates)
+}
+
+// UpdateManyContext is UpdateMany, giving up once ctx is done. The context is checked before each update.
+func (u *FileFunctions) UpdateManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, updates []basetypes.BulkUpdate) (basetypes.BulkResult, error) {
+	u.layoutLock.RLock()
+	defer u.layoutLock.RUnlock()
+	unlockStorage, err := u.lockStorage(false)
+	if err != nil {
+		return bulkFailed(len(updates), err)
+	}
+	defer unlockStorage()
+
+	return bulkApply(ctx, len(updates), func(index int) (basetypes.ID, error) {
+		update := updates[index]
+		return "", u.updateOne(ctx, dbName, collectionName, update.Query, update.Data, update.Upsert)
+	})
+}
+
+// DeleteMany deletes several records from the file-based storage, each like DeleteOne,
+// taking the layout and storage process locks once for the batch.
+func (u *FileFunctions) DeleteMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, queries []interface{}) (basetypes.BulkResult, error) {
+	return u.DeleteManyContext(context.Background(), dbName, collectionName, queries)
+}
+
+// DeleteManyContext is DeleteMany, giving up once ctx is done. The context is checked before each deletion.
+func (u *FileFunctions) DeleteManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, queries []interface{}) (basetypes.BulkResult, error) {
+	u.layoutLock.RLock()
+	defer u.layoutLock.RUnlock()
+	unlockStorage, err := u.lockStorage(false)
+	if err != nil {
+		return bulkFailed(len(queries), err)
+	}
+	defer unlockStorage()
+
+	return bulkApply(ctx, len(queries), func(index int) (basetypes.ID, error) {
+		return "", u.deleteOne(ctx, dbName, collectionName, queries[index])
+	})
+}
@@ -0,0 +1,131 @@
+package basefunctions
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"websays/config"
+	"websays/database/basetypes"
+)
+
+// databaseDir returns the directory of a database, holding the directories of its collections.
+func (u *FileFunctions) databaseDir(dbName basetypes.DBName) string {
+	return filepath.Join(config.GetInstance().FilePath, string(dbName))
+}
+
+// ListDatabases returns the names of the databases of the file-based storage in alphabetical order,
+// the directories of the files path named like a database.
+func (u *FileFunctions) ListDatabases() ([]basetypes.DBName, error) {
+	return u.ListDatabasesContext(context.Background())
+}
+
+// ListDatabasesContext is ListDatabases, giving up once ctx is done.
+func (u *FileFunctions) ListDatabasesContext(ctx context.Context) ([]basetypes.DBName, error) {
+	u.layoutLock.RLock()
+	defer u.layoutLock.RUnlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	names := make([]basetypes.DBName, 0)
+	entries, err := os.ReadDir(config.GetInstance().FilePath)
+	if os.IsNotExist(err) {
+		return names, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		dbName := basetypes.DBName(entry.Name())
+		if entry.IsDir() && CheckDBName(dbName) == nil {
+			names = append(names, dbName)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names, nil
+}
+
+// HasDatabase reports whether the directory of a database exists.
+func (u *FileFunctions) HasDatabase(dbName basetypes.DBName) (bool, error) {
+	if err := CheckDBName(dbName); err != nil {
+		return false, nil
+	}
+	info, err := os.Stat(u.databaseDir(dbName))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return info.IsDir(), nil
+}
+
+// CreateDatabase creates the empty directory of a database.
+func (u *FileFunctions) CreateDatabase(dbName basetypes.DBName) error {
+	return u.CreateDatabaseContext(context.Background(), dbName)
+}
+
+// CreateDatabaseContext is CreateDatabase, giving up before it takes effect once ctx is done.
+func (u *FileFunctions) CreateDatabaseContext(ctx context.Context, dbName basetypes.DBName) error {
+	if err := CheckDBName(dbName); err != nil {
+		return err
+	}
+
+	u.layoutLock.Lock()
+	defer u.layoutLock.Unlock()
+	unlock, err := u.lockStorage(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := os.Mkdir(u.databaseDir(dbName), 0755); err != nil {
+		if os.IsExist(err) {
+			return ErrDatabaseExists
+		}
+		return err
+	}
+	return syncDirectory(config.GetInstance().FilePath)
+}
+
+// DropDatabase removes the directory of a database with the collections and records in it.
+// It takes the storage lock exclusively, so it waits for the running writes and transaction commits of every process.
+// The IDs of its records stay taken, as the running number is shared by the databases.
+func (u *FileFunctions) DropDatabase(dbName basetypes.DBName) error {
+	return u.DropDatabaseContext(context.Background(), dbName)
+}
+
+// DropDatabaseContext is DropDatabase, giving up before it takes effect once ctx is done.
+func (u *FileFunctions) DropDatabaseContext(ctx context.Context, dbName basetypes.DBName) error {
+	// An invalid name could point outside of the files path
+	if err := CheckDBName(dbName); err != nil {
+		return ErrDatabaseNotFound
+	}
+
+	u.layoutLock.Lock()
+	defer u.layoutLock.Unlock()
+	unlock, err := u.lockStorage(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(u.databaseDir(dbName))
+	if os.IsNotExist(err) || (err == nil && !info.IsDir()) {
+		return ErrDatabaseNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(u.databaseDir(dbName)); err != nil {
+		return err
+	}
+	return syncDirectory(config.GetInstance().FilePath)
+}
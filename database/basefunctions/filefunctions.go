@@ -1,203 +1,503 @@
 package basefunctions
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
-	"io/ioutil"
+	"log"
 	"os"
-	"strconv"
 	"sync"
+	"time"
 	"websays/config"
+	"websays/database/basefilters"
 	"websays/database/basetypes"
 	"websays/httpHandler/basemodels"
 )
 
+// errIDNotFound is returned when no record of the file-based storage matches a condition.
+var errIDNotFound = errors.New("ID not found")
+
 // FileFunctions implements the BaseFucntionsInterface for file-based storage.
 // It provides methods for ensuring indexes, adding, finding, updating, and deleting data.
 type FileFunctions struct {
-	runningLock sync.Mutex // Mutex for ensuring thread safety when accessing running number
-	filesLock   sync.Mutex // Mutex for ensuring thread safety when accessing files
-	id          int        // The running ID
+	sequenceLock sync.Mutex        // Mutex for ensuring thread safety when accessing the collection sequences
+	layoutLock   sync.RWMutex      // Held for reading by record operations, for writing by recovery, migration and transaction commits
+	recordLocks  stripedLocks      // Locks of the record files, picked by the hash of the record key
+	indexLocks   stripedLocks      // Locks of the collection indexes, picked by the hash of the collection
+	recoverOnce  sync.Once         // Ensures the recovery pass only runs once
+	models       collectionModels  // Models of the collections registered by EnsureIndex, whose fields the filters may use
+	layouts      collectionLayouts // Layouts of the collection directories, read from their layout files
 }
 
 // GetFunctions returns the FileFunctions instance as a BaseFucntionsInterface.
+// On first use it moves records of the former flat layout into the configured database
+// and runs the recovery pass repairing what a crash may have left behind.
 func (u *FileFunctions) GetFunctions() BaseFucntionsInterface {
+	u.recoverOnce.Do(func() {
+		migrated, err := u.MigrateFlatLayout(basetypes.DBName(config.GetInstance().Database.DBName))
+		if err != nil {
+			log.Println("Error migrating file storage layout:", err)
+		} else if migrated > 0 {
+			log.Println("Migrated", migrated, "records to the collection directories")
+		}
+
+		report, err := u.Recover()
+		if err != nil {
+			log.Println("Error recovering file storage:", err)
+		} else if report.HasRepairs() {
+			log.Printf("Recovered file storage: %+v\n", report)
+		}
+	})
 	return u
 }
 
+// Begin starts a transaction buffering its writes until Commit applies them through staging files and a journal.
+func (u *FileFunctions) Begin() (TransactionInterface, error) {
+	return u.BeginContext(context.Background())
+}
+
+// BeginContext is Begin, with the writes of the transaction stamped with the principal of ctx.
+// The transaction gives up once ctx is done.
+func (u *FileFunctions) BeginContext(ctx context.Context) (TransactionInterface, error) {
+	return &FileTransaction{functions: u, ctx: ctx}, nil
+}
+
 // EnsureIndex ensures an index for the specified database and collection.
 // File storage does not require index creation, so this method does nothing.
 func (u *FileFunctions) EnsureIndex(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) error {
-	return nil
+	return u.EnsureIndexContext(context.Background(), dbName, collectionName, data)
 }
 
-// readRunningNumber reads the running number from a file.
-// It takes the filePath as a parameter and returns the running number and any error encountered.
-func (u *FileFunctions) readRunningNumber(filePath string) (int, error) {
-	// Read the content of the file
-	content, err := ioutil.ReadFile(filePath)
-	if err != nil {
-		return 0, err
+// EnsureIndexContext is EnsureIndex, giving up before it takes effect once ctx is done.
+// It registers the model of the collection, so the filters on its records are checked against the fields of the model.
+func (u *FileFunctions) EnsureIndexContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
 	}
+	u.models.register(collectionName, data)
+	return nil
+}
+
+// Add adds data to the file-based storage.
+// It takes the dbName, collectionName, and data to be added as parameters and returns any error encountered.
+// The existence check and the write happen under the lock of the record, so two writers can't both claim an ID,
+// whether they run in this process or in another one sharing the files path.
+func (u *FileFunctions) Add(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (basetypes.ID, error) {
+	return u.AddContext(context.Background(), dbName, collectionName, data)
+}
 
-	// Convert the content to an integer
-	runningNumber, err := strconv.Atoi(string(content))
+// AddContext is Add, giving up before it takes effect once ctx is done.
+func (u *FileFunctions) AddContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (basetypes.ID, error) {
+	u.layoutLock.RLock()
+	defer u.layoutLock.RUnlock()
+	unlockStorage, err := u.lockStorage(false)
 	if err != nil {
-		return 0, err
+		return "", err
 	}
-
-	return runningNumber, nil
+	defer unlockStorage()
+	if err := u.add(ctx, dbName, collectionName, data); err != nil {
+		return "", err
+	}
+	return recordID(data), nil
 }
 
-// writeRunningNumber writes the running number to a file.
-// It takes the filePath and the number to be written as parameters and returns any error encountered.
-func (u *FileFunctions) writeRunningNumber(filePath string, number int) error {
-	// Convert the number to a string
-	numberStr := strconv.Itoa(number)
-
-	// Write the string to the file
-	err := ioutil.WriteFile(filePath, []byte(numberStr), 0644)
+// add writes data under its own ID, holding the lock of the record, and adds the ID to the index of the collection.
+// An integer ID moves the sequence of the collection past it, so NextSequence never hands it out again.
+// The caller must hold the layout lock for reading and the storage process lock.
+func (u *FileFunctions) add(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) error {
+	id, err := u.writeNew(ctx, dbName, collectionName, data)
 	if err != nil {
 		return err
 	}
-
-	return nil
-}
-
-// GetNextID returns the next available ID for file-based storage.
-// It reads and increments the running number stored in a file and returns the updated ID.
-func (u *FileFunctions) GetNextID() int {
-	u.runningLock.Lock()
-	defer u.runningLock.Unlock()
-	filePath := config.GetInstance().FilePath + "/" + config.GetInstance().RunningFileName
-	u.id, _ = u.readRunningNumber(filePath)
-	u.id++
-	u.writeRunningNumber(filePath, u.id)
-	return u.id
+	return u.addToIndex(dbName, collectionName, id)
 }
 
-// Add adds data to the file-based storage.
-// It takes the dbName, collectionName, and data to be added as parameters and returns any error encountered.
-func (u *FileFunctions) Add(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (int, error) {
-	idData := data.(basemodels.BaseModels)
+// writeNew writes data under its own ID like add, leaving the index of the collection to the caller,
+// and returns the ID. The caller must hold the layout lock for reading and the storage process lock.
+func (u *FileFunctions) writeNew(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (basetypes.ID, error) {
+	id, err := fileRecordID(data)
+	if err != nil {
+		return "", err
+	}
+	lock := u.recordLock(dbName, collectionName, id)
+	lock.Lock()
+	defer lock.Unlock()
+	unlockRecord, err := u.lockRecordFile(dbName, collectionName, id)
+	if err != nil {
+		return "", err
+	}
+	defer unlockRecord()
 
-	filePath := config.GetInstance().FilePath + "/" + strconv.FormatInt(int64(idData.GetID()), 10) + "_" + string(collectionName)
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	filePath, err := u.recordPath(dbName, collectionName, id)
+	if err != nil {
+		return "", err
+	}
 
 	// Check if the file with the same ID already exists
-	_, err := os.Stat(filePath)
+	_, err = os.Stat(filePath)
 
 	if err == nil {
-		return 0, errors.New("ID already exists")
+		return "", errors.New("ID already exists")
 	}
 
-	u.filesLock.Lock()
-	defer u.filesLock.Unlock()
-
-	file, err := os.Create(filePath)
+	// Write to a temporary file and rename it, so a crash never leaves a partial record
+	err = writeJSONFile(filePath, liveRecord(stampCreated(ctx, firstVersion(data))))
 	if err != nil {
-		return 0, errors.New("Error opening file path")
+		return "", err
 	}
-	defer file.Close()
-
-	// Create a JSON encoder
-	encoder := json.NewEncoder(file)
+	return id, u.reserveID(dbName, collectionName, id)
+}
 
-	err = encoder.Encode(data)
-	if err != nil {
-		return 0, errors.New("Error encoding JSON")
+// fileRecordID returns the ID of a model, or an error if data isn't a model or its ID isn't valid.
+// Only valid IDs are turned into file names, so an ID can't point outside of the collection directory.
+func fileRecordID(data interface{}) (basetypes.ID, error) {
+	idData, ok := data.(basemodels.BaseModels)
+	if !ok {
+		return "", errors.New("Required a model with an ID")
+	}
+	if !idData.GetID().Valid() {
+		return "", errors.New("Invalid ID")
 	}
-	return 0, nil
+	return idData.GetID(), nil
 }
 
-// FindOne finds data in the file-based storage by ID.
-// It takes the dbName, collectionName, and a data structure to store the result as parameters.
+// FindOne finds data in the file-based storage by ID, or the first record matching a filter.
+// It takes the dbName, collectionName, and either a model carrying the ID or a filter query as parameters.
 // It returns the found data and any error encountered.
 func (u *FileFunctions) FindOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (interface{}, error) {
-	idData := data.(basemodels.BaseModels)
-
-	filePath := config.GetInstance().FilePath + "/" + strconv.FormatInt(int64(idData.GetID()), 10) + "_" + string(collectionName)
+	return u.FindOneContext(context.Background(), dbName, collectionName, data)
+}
 
-	// Check if the file with the specified ID exists
-	_, err := os.Stat(filePath)
+// FindOneContext is FindOne, giving up once ctx is done. The context is checked before reading each record.
+func (u *FileFunctions) FindOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (interface{}, error) {
+	u.layoutLock.RLock()
+	defer u.layoutLock.RUnlock()
 
+	var found map[string]interface{}
+	err := u.withRecord(ctx, dbName, collectionName, data, false, false, func(filePath string, id basetypes.ID, record map[string]interface{}) error {
+		found = record
+		return nil
+	})
 	if err != nil {
-		return nil, errors.New("ID not found")
+		return nil, err
 	}
+	return found, nil
+}
 
-	u.filesLock.Lock()
-	defer u.filesLock.Unlock()
+// FindMany finds a page of data of a collection in the file-based storage, ordered by ID.
+// It reads the IDs from the index of the collection and decodes the matching records,
+// each under its read lock, so it runs alongside other readers and writers of the collection.
+func (u *FileFunctions) FindMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	return u.FindManyContext(context.Background(), dbName, collectionName, query, options)
+}
 
-	file, err := os.Open(filePath)
+// FindManyContext is FindMany, giving up once ctx is done. The context is checked before reading each record.
+func (u *FileFunctions) FindManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	filter, err := requireFilter(query, documentFields(u.models.get(collectionName)))
 	if err != nil {
-		return nil, errors.New("Error opening file path")
+		return basetypes.FindResult{}, err
 	}
-	defer file.Close()
 
-	// Create a JSON decoder
-	decoder := json.NewDecoder(file)
+	u.layoutLock.RLock()
+	defer u.layoutLock.RUnlock()
+	return u.page(ctx, dbName, collectionName, filter, false, options)
+}
 
-	err = decoder.Decode(&data)
+// page returns the page of the records of a collection matching the filter,
+// among the trashed records when trashed is set and the live ones otherwise.
+// The caller must hold the layout lock for reading.
+func (u *FileFunctions) page(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, filter basefilters.Filter, trashed bool, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	ids, err := u.indexedIDs(dbName, collectionName)
 	if err != nil {
-		return nil, errors.New("Error decoding JSON")
+		return basetypes.FindResult{}, err
 	}
 
-	return data, nil
+	records := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return basetypes.FindResult{}, err
+		}
+		data, err := u.readLocked(dbName, collectionName, id)
+		if err != nil {
+			return basetypes.FindResult{}, err
+		}
+		if data == nil || isTrashed(data) != trashed {
+			// Deleted since the index was read, or on the other side of the trash
+			continue
+		}
+		matched, err := matchesQuery(data, filter)
+		if err != nil {
+			return basetypes.FindResult{}, err
+		}
+		if matched {
+			records = append(records, data)
+		}
+	}
+	return paginate(records, options)
 }
 
-// UpdateOne updates data in the file-based storage by ID.
+// UpdateOne updates data in the file-based storage by ID, or the first record matching a filter query.
 // It takes the dbName, collectionName, query, data, and upsert flag as parameters and returns any error encountered.
+// With upsert, data is written under its own ID when no record matches.
 func (u *FileFunctions) UpdateOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error {
-	idData := data.(basemodels.BaseModels)
-
-	filePath := config.GetInstance().FilePath + "/" + strconv.FormatInt(int64(idData.GetID()), 10) + "_" + string(collectionName)
-
-	// Check if the file with the specified ID exists
-	_, err := os.Stat(filePath)
+	return u.UpdateOneContext(context.Background(), dbName, collectionName, query, data, upsert)
+}
 
+// UpdateOneContext is UpdateOne, giving up before it takes effect once ctx is done.
+func (u *FileFunctions) UpdateOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error {
+	u.layoutLock.RLock()
+	defer u.layoutLock.RUnlock()
+	unlockStorage, err := u.lockStorage(false)
 	if err != nil {
-		return errors.New("ID not found")
+		return err
 	}
+	defer unlockStorage()
+	return u.updateOne(ctx, dbName, collectionName, query, data, upsert)
+}
 
-	u.filesLock.Lock()
-	defer u.filesLock.Unlock()
+// updateOne updates the record identified by ID, or the first record matching a filter query,
+// writing data under its own ID with upsert when no record matches.
+// The caller must hold the layout lock for reading and the storage process lock.
+func (u *FileFunctions) updateOne(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error {
+	condition := query
+	if _, ok, _ := toFilter(query); !ok || query == nil {
+		condition = data
+	}
+	err := u.withRecord(ctx, dbName, collectionName, condition, true, false, func(filePath string, id basetypes.ID, record map[string]interface{}) error {
+		updated, err := nextVersion(record, data)
+		if err != nil {
+			return err
+		}
+		// Write to a temporary file and rename it, so a crash never leaves a partial record
+		return writeJSONFile(filePath, liveRecord(stampUpdated(ctx, record, updated)))
+	})
+	if !upsert || !errors.Is(err, errIDNotFound) {
+		return err
+	}
+	return u.upsertRecord(ctx, dbName, collectionName, data)
+}
 
-	file, err := os.Create(filePath)
+// upsertRecord writes data under its own ID once an upsert matched no record.
+// The record is looked up again under its lock, so a record created in the meantime, or a trashed record with the same ID,
+// is replaced like by an update instead of being overwritten blindly. The sequence of the collection is moved past
+// a new integer ID, so NextSequence never hands it out.
+// The caller must hold the layout lock for reading and the storage process lock.
+func (u *FileFunctions) upsertRecord(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) error {
+	id, err := fileRecordID(data)
 	if err != nil {
-		return errors.New("Error opening file path")
+		return err
+	}
+	unlock, err := u.lockRecord(dbName, collectionName, id, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := ctx.Err(); err != nil {
+		return err
 	}
-	defer file.Close()
-
-	// Create a JSON encoder
-	encoder := json.NewEncoder(file)
 
-	err = encoder.Encode(data)
+	filePath, err := u.recordPath(dbName, collectionName, id)
 	if err != nil {
-		return errors.New("Error encoding JSON")
+		return err
 	}
-	return nil
+	var stored map[string]interface{}
+	_, err = os.Stat(filePath)
+	exists := err == nil
+	if exists {
+		stored, err = u.readRecord(filePath)
+		if err != nil {
+			return err
+		}
+	}
+	data, err = nextVersion(stored, data)
+	if err != nil {
+		return err
+	}
+	if exists {
+		data = stampUpdated(ctx, stored, data)
+	} else {
+		data = stampCreated(ctx, data)
+	}
+	if err = writeJSONFile(filePath, liveRecord(data)); err != nil || exists {
+		return err
+	}
+	if err = u.reserveID(dbName, collectionName, id); err != nil {
+		return err
+	}
+	return u.addToIndex(dbName, collectionName, id)
 }
 
-// DeleteOne deletes data from the file-based storage by ID.
+// DeleteOne deletes data from the file-based storage by ID, or the first record matching a filter.
 // It takes the dbName, collectionName, and data to be deleted as parameters and returns any error encountered.
+// A record deleted by a soft deletable model is moved to the trash instead, keeping its file.
 func (u *FileFunctions) DeleteOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) error {
-	idData := data.(basemodels.BaseModels)
+	return u.DeleteOneContext(context.Background(), dbName, collectionName, data)
+}
 
-	filePath := config.GetInstance().FilePath + "/" + strconv.FormatInt(int64(idData.GetID()), 10) + "_" + string(collectionName)
+// DeleteOneContext is DeleteOne, giving up before it takes effect once ctx is done.
+func (u *FileFunctions) DeleteOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) error {
+	u.layoutLock.RLock()
+	defer u.layoutLock.RUnlock()
+	unlockStorage, err := u.lockStorage(false)
+	if err != nil {
+		return err
+	}
+	defer unlockStorage()
+	return u.deleteOne(ctx, dbName, collectionName, data)
+}
 
-	// Check if the file with the specified ID exists
-	_, err := os.Stat(filePath)
+// deleteOne deletes the record identified by ID, or the first record matching a filter,
+// moving it to the trash when it is deleted by a soft deletable model.
+// The caller must hold the layout lock for reading and the storage process lock.
+func (u *FileFunctions) deleteOne(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) error {
+	return u.withRecord(ctx, dbName, collectionName, data, true, false, func(filePath string, id basetypes.ID, record map[string]interface{}) error {
+		if err := checkVersion(record, data); err != nil {
+			return err
+		}
+		if softDeletes(record, data) {
+			return writeJSONFile(filePath, withTrash(record, time.Now().Unix()))
+		}
+		return u.removeRecord(dbName, collectionName, filePath, id)
+	})
+}
 
+// withRecord calls fn with the decoded record identified by a model's ID or the first record matching a filter,
+// among the trashed records when trashed is set and the live ones otherwise,
+// holding the record's lock for writing when exclusive is set and for reading otherwise.
+// The existence check happens under the same lock, so the record can't disappear before fn runs.
+// It gives up with the error of ctx once it is done, checking it before each record and before calling fn.
+// The caller must hold the layout lock for reading, and the storage process lock when exclusive is set.
+func (u *FileFunctions) withRecord(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, condition interface{}, exclusive bool, trashed bool, fn func(filePath string, id basetypes.ID, record map[string]interface{}) error) error {
+	if _, ok := condition.(basemodels.BaseModels); ok {
+		id, err := fileRecordID(condition)
+		if err != nil {
+			return err
+		}
+		unlock, err := u.lockRecord(dbName, collectionName, id, exclusive)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		filePath, err := u.recordPath(dbName, collectionName, id)
+		if err != nil {
+			return err
+		}
+
+		// Check if the file with the specified ID exists
+		_, err = os.Stat(filePath)
+		if err != nil {
+			return errIDNotFound
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		record, err := u.readRecord(filePath)
+		if err != nil {
+			return err
+		}
+		if isTrashed(record) != trashed {
+			return errIDNotFound
+		}
+		return fn(filePath, id, record)
+	}
+
+	filter, err := requireFilter(condition, documentFields(u.models.get(collectionName)))
 	if err != nil {
-		return errors.New("ID not found")
+		return err
+	}
+	ids, err := u.indexedIDs(dbName, collectionName)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		found, err := func() (bool, error) {
+			unlock, err := u.lockRecord(dbName, collectionName, id, exclusive)
+			if err != nil {
+				return false, err
+			}
+			defer unlock()
+
+			filePath, err := u.recordPath(dbName, collectionName, id)
+			if err != nil {
+				return false, err
+			}
+			if _, err := os.Stat(filePath); os.IsNotExist(err) {
+				// Deleted since the index was read
+				return false, nil
+			}
+			data, err := u.readRecord(filePath)
+			if err != nil || isTrashed(data) != trashed {
+				return false, err
+			}
+			matched, err := matchesQuery(data, filter)
+			if err != nil || !matched {
+				return false, err
+			}
+			return true, fn(filePath, id, data)
+		}()
+		if found || err != nil {
+			return err
+		}
 	}
+	return errIDNotFound
+}
 
-	u.filesLock.Lock()
-	defer u.filesLock.Unlock()
+// lockRecord takes the lock of a record for writing when exclusive is set and for reading otherwise,
+// and returns the matching unlock. Writing also takes the process lock of the record,
+// readers don't need it as records are only ever replaced by renames.
+func (u *FileFunctions) lockRecord(dbName basetypes.DBName, collectionName basetypes.CollectionName, id basetypes.ID, exclusive bool) (func(), error) {
+	lock := u.recordLock(dbName, collectionName, id)
+	if !exclusive {
+		lock.RLock()
+		return lock.RUnlock, nil
+	}
+	lock.Lock()
+	unlockFile, err := u.lockRecordFile(dbName, collectionName, id)
+	if err != nil {
+		lock.Unlock()
+		return nil, err
+	}
+	return func() {
+		unlockFile()
+		lock.Unlock()
+	}, nil
+}
 
-	err = os.Remove(filePath)
+// readLocked decodes a record under its read lock. It returns nil without an error when the record doesn't exist.
+func (u *FileFunctions) readLocked(dbName basetypes.DBName, collectionName basetypes.CollectionName, id basetypes.ID) (map[string]interface{}, error) {
+	lock := u.recordLock(dbName, collectionName, id)
+	lock.RLock()
+	defer lock.RUnlock()
 
+	filePath, err := u.recordPath(dbName, collectionName, id)
 	if err != nil {
-		return errors.New("File not found")
+		return nil, err
 	}
-	return nil
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return u.readRecord(filePath)
+}
+
+// readRecord decodes the JSON record stored at filePath.
+func (u *FileFunctions) readRecord(filePath string) (map[string]interface{}, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, errors.New("Error opening file path")
+	}
+	defer file.Close()
+
+	data := make(map[string]interface{})
+	err = json.NewDecoder(file).Decode(&data)
+	if err != nil {
+		return nil, errors.New("Error decoding JSON")
+	}
+	return data, nil
 }
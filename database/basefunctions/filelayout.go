@@ -0,0 +1,451 @@
+package basefunctions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"websays/config"
+	"websays/database/basetypes"
+)
+
+// The file storage keeps every collection in its own directory:
+//
+//	<filesPath>/<dbName>/<collection>/[<shard>/]<id>.json
+//	<filesPath>/<dbName>/<collection>/.index
+//	<filesPath>/<dbName>/<collection>/.index.log
+//	<filesPath>/<dbName>/<collection>/.layout
+//
+// The optional shard directory is the hash prefix of the ID. Its length is set by fileShardLength in the config
+// when the collection is created and kept in the layout file, so changing the config only affects new collections.
+// The index lists the IDs of the collection so listing doesn't require walking the directory.
+// Writers append their changes to the index log instead of rewriting the index, which the log is folded into
+// once it grows past indexLogCompactSize.
+
+const (
+	recordExtension     = ".json"      // Extension of the record files.
+	indexFileName       = ".index"     // Name of the ID index of a collection.
+	indexLogFileName    = ".index.log" // Name of the log of the changes to the index of a collection.
+	layoutFileName      = ".layout"    // Name of the layout file of a collection.
+	indexLogCompactSize = 64 << 10     // Size in bytes of the index log past which it is folded into the index.
+)
+
+// errStopWalk ends a directory walk early.
+var errStopWalk = errors.New("Stop walking")
+
+// collectionLayout is the layout the records of a collection are stored in, kept in its layout file.
+type collectionLayout struct {
+	ShardLength int `json:"shardLength"` // Length of the shard directories, 0 when the records aren't sharded.
+}
+
+// collectionLayouts caches the layouts of the collections by directory.
+type collectionLayouts struct {
+	lock    sync.RWMutex                // Guards layouts.
+	layouts map[string]collectionLayout // Layouts read or persisted so far.
+}
+
+// get returns the layout of a collection directory, read from its layout file.
+// A collection without one keeps the layout its record files were written in, or takes the configured one
+// when it has no records yet, and the layout is persisted once the directory exists.
+func (u *collectionLayouts) get(collectionDir string) (collectionLayout, error) {
+	u.lock.RLock()
+	layout, ok := u.layouts[collectionDir]
+	u.lock.RUnlock()
+	if ok {
+		return layout, nil
+	}
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if layout, ok := u.layouts[collectionDir]; ok {
+		return layout, nil
+	}
+	layoutPath := filepath.Join(collectionDir, layoutFileName)
+	content, err := os.ReadFile(layoutPath)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(content, &layout); err != nil {
+			return layout, errors.New("Error decoding layout")
+		}
+	case !os.IsNotExist(err):
+		return layout, errors.New("Error opening layout")
+	default:
+		if _, err := os.Stat(collectionDir); os.IsNotExist(err) {
+			// Nothing is stored yet, the first write settles the layout
+			return collectionLayout{ShardLength: configuredShardLength()}, nil
+		}
+		if layout, err = detectLayout(collectionDir); err != nil {
+			return layout, err
+		}
+		if err := writeJSONFile(layoutPath, layout); err != nil {
+			return layout, err
+		}
+	}
+	if u.layouts == nil {
+		u.layouts = make(map[string]collectionLayout)
+	}
+	u.layouts[collectionDir] = layout
+	return layout, nil
+}
+
+// detectLayout returns the layout the record files of a collection directory were written in before layouts were persisted,
+// sharded when a record sits in a shard directory. A collection without records takes the configured layout.
+func detectLayout(collectionDir string) (collectionLayout, error) {
+	layout := collectionLayout{ShardLength: configuredShardLength()}
+	err := filepath.WalkDir(collectionDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == processLockDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if _, ok := recordFileID(entry.Name()); !ok {
+			return nil
+		}
+		layout.ShardLength = 0
+		if parent := filepath.Dir(path); parent != collectionDir {
+			layout.ShardLength = len(filepath.Base(parent))
+		}
+		return errStopWalk
+	})
+	if err != nil && err != errStopWalk {
+		return layout, errors.New("Error opening file path")
+	}
+	return layout, nil
+}
+
+// collectionDir returns the directory of a collection.
+func (u *FileFunctions) collectionDir(dbName basetypes.DBName, collectionName basetypes.CollectionName) string {
+	return filepath.Join(u.databaseDir(dbName), string(collectionName))
+}
+
+// recordPath returns the path of the file storing the record with the ID, in the layout of its collection.
+func (u *FileFunctions) recordPath(dbName basetypes.DBName, collectionName basetypes.CollectionName, id basetypes.ID) (string, error) {
+	collectionDir := u.collectionDir(dbName, collectionName)
+	layout, err := u.layouts.get(collectionDir)
+	if err != nil {
+		return "", err
+	}
+	name := string(id) + recordExtension
+	if shard := shardOf(id, layout.ShardLength); shard != "" {
+		return filepath.Join(collectionDir, shard, name), nil
+	}
+	return filepath.Join(collectionDir, name), nil
+}
+
+// configuredShardLength returns the shard length of new collections set in the config, at most the length of a hash prefix.
+func configuredShardLength() int {
+	length := config.GetInstance().FileShardLength
+	if length <= 0 {
+		return 0
+	}
+	if length > 8 {
+		return 8
+	}
+	return length
+}
+
+// shardOf returns the shard directory of an ID for a shard length, empty when sharding is disabled.
+func shardOf(id basetypes.ID, length int) string {
+	if length <= 0 {
+		return ""
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(id))
+	prefix := fmt.Sprintf("%08x", hash.Sum32())
+	if length > len(prefix) {
+		length = len(prefix)
+	}
+	return prefix[:length]
+}
+
+// recordFileID returns the ID of a "<id>.json" record file name.
+// ok is false for any other file, including temporary and quarantined files.
+func recordFileID(name string) (basetypes.ID, bool) {
+	if !strings.HasSuffix(name, recordExtension) {
+		return "", false
+	}
+	id := basetypes.ID(strings.TrimSuffix(name, recordExtension))
+	return id, id.Valid()
+}
+
+// flatRecordName returns the ID and collection of a "<id>_<collection>" file name of the former flat layout.
+// ok is false for any other file.
+func flatRecordName(name string) (basetypes.ID, basetypes.CollectionName, bool) {
+	prefix, collection, found := strings.Cut(name, "_")
+	if !found || collection == "" || strings.Contains(collection, ".") {
+		return "", "", false
+	}
+	// The flat layout only ever stored integer IDs
+	id := basetypes.ID(prefix)
+	if !id.IsInt() {
+		return "", "", false
+	}
+	return id, basetypes.CollectionName(collection), true
+}
+
+// indexedIDs returns the IDs of a collection in order, read from its index under the index lock.
+func (u *FileFunctions) indexedIDs(dbName basetypes.DBName, collectionName basetypes.CollectionName) ([]basetypes.ID, error) {
+	lock := u.indexLock(dbName, collectionName)
+	lock.RLock()
+	defer lock.RUnlock()
+	return u.collectionIDs(dbName, collectionName)
+}
+
+// collectionIDs returns the IDs of a collection in order, read from its index and index log.
+// A missing index is rebuilt from the directory. The caller must hold the index lock or the layout lock for writing.
+func (u *FileFunctions) collectionIDs(dbName basetypes.DBName, collectionName basetypes.CollectionName) ([]basetypes.ID, error) {
+	ids, err := readIndex(u.collectionDir(dbName, collectionName))
+	if !os.IsNotExist(err) {
+		return ids, err
+	}
+	ids, err = u.scanCollection(dbName, collectionName)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) > 0 {
+		err = u.writeIndex(dbName, collectionName, ids)
+	}
+	return ids, err
+}
+
+// readIndex returns the IDs of a collection directory in order, read from its index with the changes of its index log applied.
+// The error satisfies os.IsNotExist when the collection has no index.
+func readIndex(collectionDir string) ([]basetypes.ID, error) {
+	content, err := os.ReadFile(filepath.Join(collectionDir, indexFileName))
+	if os.IsNotExist(err) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("Error opening index")
+	}
+	// Indexes written before string keys hold JSON numbers, which decode as integer IDs
+	ids := make([]basetypes.ID, 0)
+	if err := json.Unmarshal(content, &ids); err != nil {
+		return nil, errors.New("Error decoding index")
+	}
+	changes, err := os.ReadFile(filepath.Join(collectionDir, indexLogFileName))
+	if os.IsNotExist(err) {
+		return ids, nil
+	}
+	if err != nil {
+		return nil, errors.New("Error opening index")
+	}
+	return replayIndexLog(ids, changes), nil
+}
+
+// replayIndexLog applies the lines of an index log, "+<id>" adding and "-<id>" removing an ID, to sorted IDs.
+// The last change to an ID wins. A last line cut short by a crash is ignored, the recovery pass rebuilds the index anyway.
+func replayIndexLog(ids []basetypes.ID, changes []byte) []basetypes.ID {
+	lines := strings.Split(string(changes), "\n")
+	added := make(map[basetypes.ID]bool)
+	for _, line := range lines[:len(lines)-1] {
+		if len(line) < 2 || (line[0] != '+' && line[0] != '-') {
+			continue
+		}
+		id := basetypes.ID(line[1:])
+		if !id.Valid() {
+			continue
+		}
+		added[id] = line[0] == '+'
+	}
+	if len(added) == 0 {
+		return ids
+	}
+
+	replayed := make([]basetypes.ID, 0, len(ids)+len(added))
+	for _, id := range ids {
+		if _, changed := added[id]; !changed {
+			replayed = append(replayed, id)
+		}
+	}
+	for id, isAdded := range added {
+		if isAdded {
+			replayed = append(replayed, id)
+		}
+	}
+	sortIDs(replayed)
+	return replayed
+}
+
+// scanCollection walks the directory of a collection and returns the IDs of its record files in order.
+func (u *FileFunctions) scanCollection(dbName basetypes.DBName, collectionName basetypes.CollectionName) ([]basetypes.ID, error) {
+	return scanRecordIDs(u.collectionDir(dbName, collectionName))
+}
+
+// scanRecordIDs walks a collection directory, including its shard directories, and returns the IDs of its record files in order.
+func scanRecordIDs(directory string) ([]basetypes.ID, error) {
+	ids := make([]basetypes.ID, 0)
+	err := filepath.WalkDir(directory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if id, ok := recordFileID(entry.Name()); ok && !entry.IsDir() {
+			ids = append(ids, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.New("Error opening file path")
+	}
+	sortIDs(ids)
+	return ids, nil
+}
+
+// sortIDs sorts IDs in the order of the indexes, integer IDs in numeric order before the string keys.
+func sortIDs(ids []basetypes.ID) {
+	sort.Slice(ids, func(i, j int) bool { return ids[i].Less(ids[j]) })
+}
+
+// searchID returns the position of an ID in sorted IDs, or the position it would be inserted at.
+func searchID(ids []basetypes.ID, id basetypes.ID) int {
+	return sort.Search(len(ids), func(i int) bool { return !ids[i].Less(id) })
+}
+
+// writeIndex atomically replaces the index of a collection.
+func (u *FileFunctions) writeIndex(dbName basetypes.DBName, collectionName basetypes.CollectionName, ids []basetypes.ID) error {
+	return writeJSONFile(filepath.Join(u.collectionDir(dbName, collectionName), indexFileName), ids)
+}
+
+// addToIndex adds IDs to the index of a collection under the index locks, with a single write of the index log.
+func (u *FileFunctions) addToIndex(dbName basetypes.DBName, collectionName basetypes.CollectionName, ids ...basetypes.ID) error {
+	return u.logIndexChanges(dbName, collectionName, '+', ids)
+}
+
+// removeFromIndex removes an ID from the index of a collection under the index locks.
+func (u *FileFunctions) removeFromIndex(dbName basetypes.DBName, collectionName basetypes.CollectionName, id basetypes.ID) error {
+	return u.logIndexChanges(dbName, collectionName, '-', []basetypes.ID{id})
+}
+
+// logIndexChanges appends the change of IDs, '+' adding and '-' removing them, to the index log of a collection
+// under the index locks, so a write costs the same whatever the size of the collection.
+// A collection without an index gets one built from its record files instead, which already reflect the change.
+// The log is folded into the index once it grows past indexLogCompactSize.
+func (u *FileFunctions) logIndexChanges(dbName basetypes.DBName, collectionName basetypes.CollectionName, change byte, ids []basetypes.ID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	lock := u.indexLock(dbName, collectionName)
+	lock.Lock()
+	defer lock.Unlock()
+	unlock, err := u.lockIndexFile(dbName, collectionName)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	collectionDir := u.collectionDir(dbName, collectionName)
+	if _, err := os.Stat(filepath.Join(collectionDir, indexFileName)); os.IsNotExist(err) {
+		_, err = u.collectionIDs(dbName, collectionName)
+		return err
+	}
+
+	lines := make([]byte, 0)
+	for _, id := range ids {
+		lines = append(append(append(lines, change), id...), '\n')
+	}
+	logPath := filepath.Join(collectionDir, indexLogFileName)
+	file, err := os.OpenFile(logPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return errors.New("Error opening index")
+	}
+	_, err = file.Write(lines)
+	if err == nil {
+		err = file.Sync()
+	}
+	info, statErr := file.Stat()
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = statErr
+	}
+	if err != nil {
+		return errors.New("Error writing index")
+	}
+
+	if info.Size() == int64(len(lines)) {
+		// The log was just created, its directory entry must survive a crash too
+		return syncDirectory(collectionDir)
+	}
+	if info.Size() < indexLogCompactSize {
+		return nil
+	}
+	indexed, err := readIndex(collectionDir)
+	if err != nil {
+		return err
+	}
+	return compactIndex(collectionDir, indexed)
+}
+
+// compactIndex replaces the index of a collection directory with ids and removes its index log.
+// A crash in between leaves a log whose changes the index already holds, replaying them again changes nothing.
+func compactIndex(collectionDir string, ids []basetypes.ID) error {
+	if err := writeJSONFile(filepath.Join(collectionDir, indexFileName), ids); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(collectionDir, indexLogFileName)); err != nil && !os.IsNotExist(err) {
+		return errors.New("Error writing index")
+	}
+	return syncDirectory(collectionDir)
+}
+
+// MigrateFlatLayout moves records stored in the former flat layout, "<filesPath>/<id>_<collection>",
+// into the collection directories of dbName and adds them to the collection indexes.
+// It returns the number of migrated records. Running it again once the flat files are gone does nothing.
+func (u *FileFunctions) MigrateFlatLayout(dbName basetypes.DBName) (int, error) {
+	u.layoutLock.Lock()
+	defer u.layoutLock.Unlock()
+	unlock, err := u.lockStorage(true)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	entries, err := os.ReadDir(config.GetInstance().FilePath)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+	for _, entry := range entries {
+		id, collectionName, ok := flatRecordName(entry.Name())
+		if entry.IsDir() || !ok {
+			continue
+		}
+
+		target, err := u.recordPath(dbName, collectionName, id)
+		if err != nil {
+			return migrated, err
+		}
+		if _, err := os.Stat(target); err == nil {
+			return migrated, fmt.Errorf("Can't migrate %s, %s already exists", entry.Name(), target)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return migrated, err
+		}
+		if err := os.Rename(filepath.Join(config.GetInstance().FilePath, entry.Name()), target); err != nil {
+			return migrated, err
+		}
+		if err := u.addToIndex(dbName, collectionName, id); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+	return migrated, syncDirectory(config.GetInstance().FilePath)
+}
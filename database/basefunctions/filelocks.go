@@ -0,0 +1,62 @@
+package basefunctions
+
+import (
+	"fmt"
+	"hash/fnv"
+	"path/filepath"
+	"sync"
+	"websays/config"
+	"websays/database/basetypes"
+)
+
+// lockStripes is the number of locks records and collection indexes are spread over.
+const lockStripes = 256
+
+// stripedLocks is a fixed table of read-write locks picked by the hash of a key.
+// Keys sharing a stripe only contend with each other, and the table never grows with the number of records.
+type stripedLocks struct {
+	stripes [lockStripes]sync.RWMutex
+}
+
+// get returns the lock guarding key.
+func (u *stripedLocks) get(key string) *sync.RWMutex {
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	return &u.stripes[hash.Sum32()%lockStripes]
+}
+
+// recordLock returns the lock guarding the file of a record.
+func (u *FileFunctions) recordLock(dbName basetypes.DBName, collectionName basetypes.CollectionName, id basetypes.ID) *sync.RWMutex {
+	return u.recordLocks.get(string(dbName) + "/" + string(collectionName) + "/" + string(id))
+}
+
+// indexLock returns the lock guarding the index of a collection.
+func (u *FileFunctions) indexLock(dbName basetypes.DBName, collectionName basetypes.CollectionName) *sync.RWMutex {
+	return u.indexLocks.get(string(dbName) + "/" + string(collectionName))
+}
+
+// Lock files coordinating the processes sharing a files path.
+const (
+	storageLockFileName = ".lock"  // Shared by record writes, exclusive for recovery, migration and transaction commits.
+	processLockDir      = ".locks" // Directory of the lock files of a collection.
+	indexLockFileName   = "index"  // Lock file of the collection index in the lock directory.
+	processLockStripes  = 16       // Number of lock files the records of a collection are spread over.
+)
+
+// lockStorage takes the storage-wide process lock, shared for record writes and exclusive for layout changes.
+func (u *FileFunctions) lockStorage(exclusive bool) (func(), error) {
+	return lockFile(filepath.Join(config.GetInstance().FilePath, storageLockFileName), exclusive)
+}
+
+// lockRecordFile takes the process lock of a record for writing.
+func (u *FileFunctions) lockRecordFile(dbName basetypes.DBName, collectionName basetypes.CollectionName, id basetypes.ID) (func(), error) {
+	hash := fnv.New32a()
+	hash.Write([]byte(id))
+	stripe := fmt.Sprintf("%02d", hash.Sum32()%processLockStripes)
+	return lockFile(filepath.Join(u.collectionDir(dbName, collectionName), processLockDir, stripe), true)
+}
+
+// lockIndexFile takes the process lock of a collection index for writing.
+func (u *FileFunctions) lockIndexFile(dbName basetypes.DBName, collectionName basetypes.CollectionName) (func(), error) {
+	return lockFile(filepath.Join(u.collectionDir(dbName, collectionName), processLockDir, indexLockFileName), true)
+}
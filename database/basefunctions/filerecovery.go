@@ -0,0 +1,150 @@
+package basefunctions
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"websays/config"
+)
+
+// corruptSuffix is appended to record files that can't be decoded, taking them out of the collection.
+const corruptSuffix = ".corrupt"
+
+// FileRecoveryReport lists what the recovery pass of the file storage found and repaired.
+type FileRecoveryReport struct {
+	ReplayedJournal       bool     // An interrupted transaction commit was completed.
+	RemovedTemporaryFiles []string // Temporary and staging files left behind by interrupted writes.
+	QuarantinedRecords    []string // Truncated or empty records renamed with the corrupt suffix.
+	RebuiltIndexes        []string // Collection indexes that didn't match the record files.
+	RepairedSequences     []string // Collection sequences that were unreadable or behind the stored IDs.
+}
+
+// HasRepairs reports whether the recovery pass changed anything.
+func (u FileRecoveryReport) HasRepairs() bool {
+	return u.ReplayedJournal || len(u.RemovedTemporaryFiles) > 0 || len(u.QuarantinedRecords) > 0 ||
+		len(u.RebuiltIndexes) > 0 || len(u.RepairedSequences) > 0
+}
+
+// Recover repairs what a crash may have left in the files path. It completes an interrupted transaction
+// commit, removes orphaned temporary and staging files, quarantines records that can't be decoded,
+// rebuilds the collection indexes that don't match their record files and moves the collection sequences
+// past the highest stored integer ID. It runs once on startup and can be called again at any time.
+func (u *FileFunctions) Recover() (FileRecoveryReport, error) {
+	report := FileRecoveryReport{RemovedTemporaryFiles: []string{}, QuarantinedRecords: []string{}, RebuiltIndexes: []string{}, RepairedSequences: []string{}}
+
+	u.layoutLock.Lock()
+	defer u.layoutLock.Unlock()
+	unlockStorage, err := u.lockStorage(true)
+	if err != nil {
+		return report, err
+	}
+	defer unlockStorage()
+
+	directory := config.GetInstance().FilePath
+
+	if _, err := os.Stat(filepath.Join(directory, journalFileName)); err == nil {
+		if err := replayJournal(); err != nil {
+			return report, err
+		}
+		report.ReplayedJournal = true
+	}
+
+	collectionDirs := make([]string, 0)
+	err = filepath.WalkDir(directory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := entry.Name()
+		relative, _ := filepath.Rel(directory, path)
+		if entry.IsDir() {
+			if name == processLockDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Staging files are only left over once their journal is gone, so they were never committed
+		if strings.HasSuffix(name, temporarySuffix) || strings.HasSuffix(name, ".tx") {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			report.RemovedTemporaryFiles = append(report.RemovedTemporaryFiles, relative)
+			return nil
+		}
+		if name == indexFileName {
+			collectionDirs = append(collectionDirs, filepath.Dir(path))
+			return nil
+		}
+		if _, ok := recordFileID(name); !ok {
+			return nil
+		}
+		if _, err := u.readRecord(path); err != nil {
+			if err := os.Rename(path, path+corruptSuffix); err != nil {
+				return err
+			}
+			report.QuarantinedRecords = append(report.QuarantinedRecords, relative)
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	for _, collectionDir := range collectionDirs {
+		relative, _ := filepath.Rel(directory, collectionDir)
+		rebuilt, err := rebuildIndex(collectionDir)
+		if err != nil {
+			return report, err
+		}
+		if rebuilt {
+			report.RebuiltIndexes = append(report.RebuiltIndexes, relative)
+		}
+		repaired, err := repairSequence(collectionDir)
+		if err != nil {
+			return report, err
+		}
+		if repaired {
+			report.RepairedSequences = append(report.RepairedSequences, relative)
+		}
+	}
+	if err := syncDirectory(directory); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// rebuildIndex rewrites the index of a collection directory from its record files if they don't match,
+// and folds its index log into it. It reports whether the index didn't match.
+func rebuildIndex(collectionDir string) (bool, error) {
+	ids, err := scanRecordIDs(collectionDir)
+	if err != nil {
+		return false, err
+	}
+	indexed, err := readIndex(collectionDir)
+	matches := err == nil && reflect.DeepEqual(indexed, ids)
+	if _, err := os.Stat(filepath.Join(collectionDir, indexLogFileName)); matches && os.IsNotExist(err) {
+		return false, nil
+	}
+	return !matches, compactIndex(collectionDir, ids)
+}
+
+// repairSequence moves the sequence of a collection directory past the highest integer ID of its record files
+// if it is unreadable or behind them. A missing sequence is left alone, it starts from the index on first use.
+// It reports whether the sequence was rewritten.
+func repairSequence(collectionDir string) (bool, error) {
+	sequencePath := filepath.Join(collectionDir, sequenceFileName)
+	last, err := readSequence(sequencePath)
+	if err == nil && last < 0 {
+		return false, nil
+	}
+	ids, scanErr := scanRecordIDs(collectionDir)
+	if scanErr != nil {
+		return false, scanErr
+	}
+	maxID := maxIntID(ids)
+	if err == nil && last >= maxID {
+		return false, nil
+	}
+	return true, writeSequence(sequencePath, maxID)
+}
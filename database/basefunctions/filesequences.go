@@ -0,0 +1,148 @@
+package basefunctions
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"websays/config"
+	"websays/database/basetypes"
+)
+
+// Every collection keeps its own sequence next to its index:
+//
+//	<filesPath>/<dbName>/<collection>/.sequence
+//
+// The sequence file holds the last integer ID handed out or stored in the collection.
+// A missing sequence starts from the highest integer ID of the collection index, and never below the
+// running number file shared by every collection before the sequences were kept apart.
+
+const (
+	sequenceFileName     = ".sequence" // Name of the sequence file of a collection.
+	sequenceLockFileName = "sequence"  // Lock file of the collection sequence in the lock directory.
+)
+
+// NextSequence returns the next integer ID of the sequence of a collection of the file-based storage.
+// The sequence file is locked while it is incremented, so processes sharing the files path never hand out the same ID.
+func (u *FileFunctions) NextSequence(dbName basetypes.DBName, collectionName basetypes.CollectionName) (int64, error) {
+	return u.NextSequenceContext(context.Background(), dbName, collectionName)
+}
+
+// NextSequenceContext is NextSequence, giving up once ctx is done.
+func (u *FileFunctions) NextSequenceContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName) (int64, error) {
+	u.layoutLock.RLock()
+	defer u.layoutLock.RUnlock()
+	unlockStorage, err := u.lockStorage(false)
+	if err != nil {
+		return 0, err
+	}
+	defer unlockStorage()
+
+	var next int64
+	err = u.withSequence(dbName, collectionName, func(last int64) (int64, error) {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		next = last + 1
+		return next, nil
+	})
+	return next, err
+}
+
+// reserveID moves the sequence of a collection past an integer ID stored in it, so NextSequence never hands it out.
+// String keys leave the sequence alone.
+// The caller must hold the layout lock and the storage process lock.
+func (u *FileFunctions) reserveID(dbName basetypes.DBName, collectionName basetypes.CollectionName, id basetypes.ID) error {
+	value, ok := id.Int()
+	if !ok {
+		return nil
+	}
+	return u.withSequence(dbName, collectionName, func(last int64) (int64, error) {
+		if value > last {
+			return value, nil
+		}
+		return last, nil
+	})
+}
+
+// withSequence calls fn with the last value of the sequence of a collection under the sequence locks,
+// and writes the value it returns if it moved the sequence.
+// The caller must hold the layout lock and the storage process lock.
+func (u *FileFunctions) withSequence(dbName basetypes.DBName, collectionName basetypes.CollectionName, fn func(last int64) (int64, error)) error {
+	u.sequenceLock.Lock()
+	defer u.sequenceLock.Unlock()
+	unlock, err := lockFile(filepath.Join(u.collectionDir(dbName, collectionName), processLockDir, sequenceLockFileName), true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	filePath := u.sequencePath(dbName, collectionName)
+	last, err := readSequence(filePath)
+	if err != nil {
+		log.Println("Error reading sequence, recovering it from the records:", err)
+	}
+	if err != nil || last < 0 {
+		if last, err = u.initialSequence(dbName, collectionName); err != nil {
+			return err
+		}
+	}
+	next, err := fn(last)
+	if err != nil || next == last {
+		return err
+	}
+	return writeSequence(filePath, next)
+}
+
+// initialSequence returns the value a missing sequence of a collection starts from, the highest integer ID
+// of its index and the legacy running number, whichever is higher.
+func (u *FileFunctions) initialSequence(dbName basetypes.DBName, collectionName basetypes.CollectionName) (int64, error) {
+	ids, err := u.indexedIDs(dbName, collectionName)
+	if err != nil {
+		return 0, err
+	}
+	last := maxIntID(ids)
+	if config.GetInstance().RunningFileName != "" {
+		runningNumber, err := readSequence(filepath.Join(config.GetInstance().FilePath, config.GetInstance().RunningFileName))
+		if err == nil && runningNumber > last {
+			last = runningNumber
+		}
+	}
+	return last, nil
+}
+
+// sequencePath returns the path of the sequence file of a collection.
+func (u *FileFunctions) sequencePath(dbName basetypes.DBName, collectionName basetypes.CollectionName) string {
+	return filepath.Join(u.collectionDir(dbName, collectionName), sequenceFileName)
+}
+
+// readSequence reads the last value of a sequence from a file. A missing file reads as -1, so it can be told apart from 0.
+func readSequence(filePath string) (int64, error) {
+	content, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return -1, nil
+	}
+	if err != nil {
+		return -1, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(content)), 10, 64)
+}
+
+// writeSequence writes the last value of a sequence to a file.
+// The value is written atomically and flushed to disk, so a crash never leaves a truncated sequence.
+func writeSequence(filePath string, last int64) error {
+	return writeFileAtomic(filePath, []byte(strconv.FormatInt(last, 10)))
+}
+
+// maxIntID returns the highest integer ID among IDs, 0 when there is none.
+func maxIntID(ids []basetypes.ID) int64 {
+	var last int64
+	for _, id := range ids {
+		if value, ok := id.Int(); ok && value > last {
+			last = value
+		}
+	}
+	return last
+}
@@ -0,0 +1,433 @@
+package basefunctions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+	"websays/config"
+	"websays/database/basetypes"
+	"websays/httpHandler/basemodels"
+)
+
+// journalFileName is the name of the commit journal in the files path.
+// It exists only while a transaction is being applied, and is replayed on startup after a crash.
+const journalFileName = ".journal"
+
+// fileWrite is a write buffered by a FileTransaction.
+type fileWrite struct {
+	add        bool                     // The write creates a new record file.
+	id         basetypes.ID             // The ID of the record created by an add.
+	delete     bool                     // The write removes the record file.
+	dbName     basetypes.DBName         // The database of the record.
+	collection basetypes.CollectionName // The collection of the record, whose index is updated on commit.
+	filePath   string                   // The path of the record file.
+	data       map[string]interface{}   // The JSON document written, nil for deletes.
+	version    int                      // The version written by an update of a versioned record, checked again on commit.
+}
+
+// fileJournal lists the renames and removals of a commit so a partially applied commit can be completed.
+type fileJournal struct {
+	Renames [][2]string `json:"renames"` // Pairs of staging file and record file.
+	Removes []string    `json:"removes"` // Record files to remove.
+}
+
+// FileTransaction buffers the writes done on a FileFunctions storage.
+// On commit the records are first written to staging files, then a journal is written
+// and the staging files are renamed over the records, so a commit is either fully applied or not at all.
+// The records it writes carry the principal of the context it was begun with in their metadata.
+type FileTransaction struct {
+	functions *FileFunctions  // The storage the writes are applied to.
+	writes    []fileWrite     // The buffered writes in order.
+	finished  bool            // Set once the transaction is committed or rolled back.
+	ctx       context.Context // The context the transaction gives up under, whose principal its writes carry.
+}
+
+// latest returns the last buffered write of a record file, if any.
+func (u *FileTransaction) latest(filePath string) *fileWrite {
+	for i := len(u.writes) - 1; i >= 0; i-- {
+		if u.writes[i].filePath == filePath {
+			return &u.writes[i]
+		}
+	}
+	return nil
+}
+
+// read reads a record file including the writes of the transaction.
+// The caller must hold the layout lock.
+func (u *FileTransaction) read(filePath string) (map[string]interface{}, error) {
+	if write := u.latest(filePath); write != nil {
+		if write.delete {
+			return nil, errors.New("ID not found")
+		}
+		return write.data, nil
+	}
+	return u.functions.readRecord(filePath)
+}
+
+// findPath returns the live record file identified by a model's ID or the first one matching a filter,
+// including the writes of the transaction. The caller must hold the layout lock.
+func (u *FileTransaction) findPath(dbName basetypes.DBName, collectionName basetypes.CollectionName, condition interface{}) (string, error) {
+	if u.finished {
+		return "", errors.New("Transaction already finished")
+	}
+	if err := u.ctx.Err(); err != nil {
+		return "", err
+	}
+	if _, ok := condition.(basemodels.BaseModels); ok {
+		id, err := fileRecordID(condition)
+		if err != nil {
+			return "", err
+		}
+		filePath, err := u.functions.recordPath(dbName, collectionName, id)
+		if err != nil {
+			return "", err
+		}
+		if data, err := u.read(filePath); err != nil || isTrashed(data) {
+			return "", errIDNotFound
+		}
+		return filePath, nil
+	}
+
+	filter, err := requireFilter(condition, documentFields(u.functions.models.get(collectionName)))
+	if err != nil {
+		return "", err
+	}
+	ids, err := u.functions.collectionIDs(dbName, collectionName)
+	if err != nil {
+		return "", err
+	}
+	for _, write := range u.writes {
+		if write.add {
+			ids = append(ids, write.id)
+		}
+	}
+	sortIDs(ids)
+
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		filePath, err := u.functions.recordPath(dbName, collectionName, id)
+		if err != nil {
+			return "", err
+		}
+		if seen[filePath] {
+			continue
+		}
+		seen[filePath] = true
+		data, err := u.read(filePath)
+		if err != nil || isTrashed(data) {
+			continue
+		}
+		matched, err := matchesQuery(data, filter)
+		if err != nil {
+			return "", err
+		}
+		if matched {
+			return filePath, nil
+		}
+	}
+	return "", errIDNotFound
+}
+
+// Add buffers the creation of a record file for data.
+func (u *FileTransaction) Add(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (basetypes.ID, error) {
+	id, err := fileRecordID(data)
+	if err != nil {
+		return "", err
+	}
+	document, err := toDocument(liveRecord(stampCreated(u.ctx, firstVersion(data))))
+	if err != nil {
+		return "", err
+	}
+
+	u.functions.layoutLock.Lock()
+	defer u.functions.layoutLock.Unlock()
+
+	// A trashed record keeps its ID until it is purged
+	filePath, err := u.functions.recordPath(dbName, collectionName, id)
+	if err != nil {
+		return "", err
+	}
+	if _, err := u.read(filePath); err == nil {
+		return "", errors.New("ID already exists")
+	}
+	if u.finished {
+		return "", errors.New("Transaction already finished")
+	}
+	if err := u.ctx.Err(); err != nil {
+		return "", err
+	}
+	u.writes = append(u.writes, fileWrite{add: true, id: id, dbName: dbName, collection: collectionName, filePath: filePath, data: document})
+	return id, nil
+}
+
+// FindOne retrieves data by ID or filter, including the writes of the transaction.
+func (u *FileTransaction) FindOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, condition interface{}) (interface{}, error) {
+	u.functions.layoutLock.Lock()
+	defer u.functions.layoutLock.Unlock()
+
+	filePath, err := u.findPath(dbName, collectionName, condition)
+	if err != nil {
+		return nil, err
+	}
+	return u.read(filePath)
+}
+
+// UpdateOne buffers the update of the record identified by data's ID or by a filter query.
+// With upsert, the creation of a record file for data is buffered when no record matches.
+func (u *FileTransaction) UpdateOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error {
+	u.functions.layoutLock.Lock()
+	defer u.functions.layoutLock.Unlock()
+
+	condition := query
+	if _, ok, _ := toFilter(query); !ok || query == nil {
+		condition = data
+	}
+	filePath, err := u.findPath(dbName, collectionName, condition)
+	if errors.Is(err, errIDNotFound) && upsert {
+		return u.bufferUpsert(dbName, collectionName, data)
+	}
+	if err != nil {
+		return err
+	}
+	stored, err := u.read(filePath)
+	if err != nil {
+		return err
+	}
+	data, err = nextVersion(stored, data)
+	if err != nil {
+		return err
+	}
+	return u.bufferUpdate(dbName, collectionName, filePath, liveRecord(stampUpdated(u.ctx, stored, data)))
+}
+
+// bufferUpdate buffers the replacement of a record file by data, the version of a versioned model being checked again on commit.
+// The caller must hold the layout lock.
+func (u *FileTransaction) bufferUpdate(dbName basetypes.DBName, collectionName basetypes.CollectionName, filePath string, data interface{}) error {
+	document, err := toDocument(data)
+	if err != nil {
+		return err
+	}
+	version := 0
+	if _, ok := data.(basemodels.VersionedModels); ok {
+		version = RecordVersion(data)
+	}
+	u.writes = append(u.writes, fileWrite{dbName: dbName, collection: collectionName, filePath: filePath, data: document, version: version})
+	return nil
+}
+
+// bufferUpsert buffers the creation of a record file for data once an upsert matched no record.
+// A trashed record with the same ID is replaced like by an update instead, which restores it.
+// The caller must hold the layout lock.
+func (u *FileTransaction) bufferUpsert(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) error {
+	id, err := fileRecordID(data)
+	if err != nil {
+		return err
+	}
+	filePath, err := u.functions.recordPath(dbName, collectionName, id)
+	if err != nil {
+		return err
+	}
+	stored, err := u.read(filePath)
+	if err == nil {
+		if !isTrashed(stored) {
+			return errors.New("ID already exists")
+		}
+		data, err = nextVersion(stored, data)
+		if err != nil {
+			return err
+		}
+		return u.bufferUpdate(dbName, collectionName, filePath, liveRecord(stampUpdated(u.ctx, stored, data)))
+	}
+	data, err = nextVersion(nil, data)
+	if err != nil {
+		return err
+	}
+	document, err := toDocument(liveRecord(stampCreated(u.ctx, data)))
+	if err != nil {
+		return err
+	}
+	u.writes = append(u.writes, fileWrite{add: true, id: id, dbName: dbName, collection: collectionName, filePath: filePath, data: document})
+	return nil
+}
+
+// DeleteOne buffers the removal of the record identified by ID or by a filter.
+// A record deleted by a soft deletable model is moved to the trash instead, through an update of the record.
+func (u *FileTransaction) DeleteOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, condition interface{}) error {
+	u.functions.layoutLock.Lock()
+	defer u.functions.layoutLock.Unlock()
+
+	filePath, err := u.findPath(dbName, collectionName, condition)
+	if err != nil {
+		return err
+	}
+	stored, err := u.read(filePath)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(stored, condition); err != nil {
+		return err
+	}
+	if softDeletes(stored, condition) {
+		trashed := withTrash(stored, time.Now().Unix()).(map[string]interface{})
+		u.writes = append(u.writes, fileWrite{dbName: dbName, collection: collectionName, filePath: filePath, data: trashed, version: RecordVersion(trashed)})
+		return nil
+	}
+	u.writes = append(u.writes, fileWrite{delete: true, dbName: dbName, collection: collectionName, filePath: filePath})
+	return nil
+}
+
+// Commit validates the buffered writes against the files, writes the new records to staging files
+// and applies them through the journal. If any write can't be applied nothing is changed.
+func (u *FileTransaction) Commit() error {
+	if u.finished {
+		return errors.New("Transaction already finished")
+	}
+	if err := u.ctx.Err(); err != nil {
+		return err
+	}
+	u.finished = true
+
+	u.functions.layoutLock.Lock()
+	defer u.functions.layoutLock.Unlock()
+	unlock, err := u.functions.lockStorage(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	staged := make(map[string]*fileWrite)
+	order := make([]string, 0)
+	exists := func(filePath string) bool {
+		if write, ok := staged[filePath]; ok {
+			return !write.delete
+		}
+		_, err := os.Stat(filePath)
+		return err == nil
+	}
+	for i := range u.writes {
+		write := &u.writes[i]
+		if write.add && exists(write.filePath) {
+			return errors.New("ID already exists")
+		}
+		if !write.add && !exists(write.filePath) {
+			return errors.New("ID not found")
+		}
+		if write.version != 0 {
+			var stored map[string]interface{}
+			if previous, ok := staged[write.filePath]; ok {
+				stored = previous.data
+			} else if stored, err = u.functions.readRecord(write.filePath); err != nil {
+				return err
+			}
+			// The record was updated by someone else since the write was buffered
+			if RecordVersion(stored)+1 != write.version {
+				return ErrVersionConflict
+			}
+		}
+		if _, ok := staged[write.filePath]; !ok {
+			order = append(order, write.filePath)
+		}
+		staged[write.filePath] = write
+	}
+
+	journal := fileJournal{Renames: [][2]string{}, Removes: []string{}}
+	for _, filePath := range order {
+		write := staged[filePath]
+		if write.delete {
+			journal.Removes = append(journal.Removes, filePath)
+			continue
+		}
+		stagingPath := filePath + ".tx"
+		if err := writeJSONFile(stagingPath, write.data); err != nil {
+			u.discardStaging(journal)
+			return err
+		}
+		journal.Renames = append(journal.Renames, [2]string{stagingPath, filePath})
+	}
+
+	journalPath := filepath.Join(config.GetInstance().FilePath, journalFileName)
+	if err := writeJSONFile(journalPath, journal); err != nil {
+		u.discardStaging(journal)
+		return err
+	}
+	if err := applyJournal(journalPath, journal); err != nil {
+		return err
+	}
+	return u.updateIndexes(staged)
+}
+
+// updateIndexes adds the created records to and removes the deleted records from their collection indexes.
+// An index left behind by a crash at this point is rebuilt by the recovery pass. The caller must hold the layout lock.
+func (u *FileTransaction) updateIndexes(staged map[string]*fileWrite) error {
+	for filePath, write := range staged {
+		id, _ := recordFileID(filepath.Base(filePath))
+		var err error
+		if write.delete {
+			err = u.functions.removeFromIndex(write.dbName, write.collection, id)
+		} else if err = u.functions.addToIndex(write.dbName, write.collection, id); err == nil {
+			// Records created by adds and upserts may carry IDs NextSequence didn't hand out
+			err = u.functions.reserveID(write.dbName, write.collection, id)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// discardStaging removes the staging files written by a failed commit.
+func (u *FileTransaction) discardStaging(journal fileJournal) {
+	for _, rename := range journal.Renames {
+		os.Remove(rename[0])
+	}
+}
+
+// Rollback discards the buffered writes.
+func (u *FileTransaction) Rollback() error {
+	if u.finished {
+		return errors.New("Transaction already finished")
+	}
+	u.finished = true
+	u.writes = nil
+	return nil
+}
+
+// applyJournal renames the staging files over the records, removes the deleted records and removes the journal.
+// Renames whose staging file is gone were already applied, so the journal can be replayed safely.
+func applyJournal(journalPath string, journal fileJournal) error {
+	for _, rename := range journal.Renames {
+		if _, err := os.Stat(rename[0]); err != nil {
+			continue
+		}
+		if err := os.Rename(rename[0], rename[1]); err != nil {
+			return errors.New("Error applying transaction")
+		}
+	}
+	for _, filePath := range journal.Removes {
+		if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+			return errors.New("Error applying transaction")
+		}
+	}
+	return os.Remove(journalPath)
+}
+
+// replayJournal completes a commit that was interrupted after its journal was written.
+func replayJournal() error {
+	journalPath := filepath.Join(config.GetInstance().FilePath, journalFileName)
+	content, err := os.ReadFile(journalPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	journal := fileJournal{}
+	if err := json.Unmarshal(content, &journal); err != nil {
+		// The journal itself was not fully written, so none of its renames happened
+		return os.Remove(journalPath)
+	}
+	return applyJournal(journalPath, journal)
+}
@@ -0,0 +1,135 @@
+package basefunctions
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+	"websays/database/basetypes"
+)
+
+// FindTrash finds a page of the trashed data of a collection in the file-based storage, ordered by ID.
+func (u *FileFunctions) FindTrash(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	return u.FindTrashContext(context.Background(), dbName, collectionName, query, options)
+}
+
+// FindTrashContext is FindTrash, giving up once ctx is done. The context is checked before reading each record.
+func (u *FileFunctions) FindTrashContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	filter, err := requireFilter(query, documentFields(u.models.get(collectionName)))
+	if err != nil {
+		return basetypes.FindResult{}, err
+	}
+
+	u.layoutLock.RLock()
+	defer u.layoutLock.RUnlock()
+	return u.page(ctx, dbName, collectionName, filter, true, options)
+}
+
+// Restore moves the trashed record identified by ID, or the first trashed record matching a filter, out of the trash.
+func (u *FileFunctions) Restore(dbName basetypes.DBName, collectionName basetypes.CollectionName, condition interface{}) error {
+	return u.RestoreContext(context.Background(), dbName, collectionName, condition)
+}
+
+// RestoreContext is Restore, giving up before it takes effect once ctx is done.
+func (u *FileFunctions) RestoreContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, condition interface{}) error {
+	u.layoutLock.RLock()
+	defer u.layoutLock.RUnlock()
+	unlockStorage, err := u.lockStorage(false)
+	if err != nil {
+		return err
+	}
+	defer unlockStorage()
+
+	return u.withRecord(ctx, dbName, collectionName, condition, true, true, func(filePath string, id basetypes.ID, record map[string]interface{}) error {
+		return writeJSONFile(filePath, withTrash(record, 0))
+	})
+}
+
+// Purge permanently removes the trashed record identified by ID, or the first trashed record matching a filter.
+func (u *FileFunctions) Purge(dbName basetypes.DBName, collectionName basetypes.CollectionName, condition interface{}) error {
+	return u.PurgeContext(context.Background(), dbName, collectionName, condition)
+}
+
+// PurgeContext is Purge, giving up before it takes effect once ctx is done.
+func (u *FileFunctions) PurgeContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, condition interface{}) error {
+	u.layoutLock.RLock()
+	defer u.layoutLock.RUnlock()
+	unlockStorage, err := u.lockStorage(false)
+	if err != nil {
+		return err
+	}
+	defer unlockStorage()
+
+	return u.withRecord(ctx, dbName, collectionName, condition, true, true, func(filePath string, id basetypes.ID, record map[string]interface{}) error {
+		return u.removeRecord(dbName, collectionName, filePath, id)
+	})
+}
+
+// PurgeTrash permanently removes every record of a collection trashed before a time.
+// Each record is checked and removed under its own lock, so it runs alongside the other writers of the collection.
+func (u *FileFunctions) PurgeTrash(dbName basetypes.DBName, collectionName basetypes.CollectionName, before time.Time) (int, error) {
+	return u.PurgeTrashContext(context.Background(), dbName, collectionName, before)
+}
+
+// PurgeTrashContext is PurgeTrash, giving up once ctx is done. The context is checked before each record,
+// so the records purged until then stay purged.
+func (u *FileFunctions) PurgeTrashContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, before time.Time) (int, error) {
+	u.layoutLock.RLock()
+	defer u.layoutLock.RUnlock()
+	unlockStorage, err := u.lockStorage(false)
+	if err != nil {
+		return 0, err
+	}
+	defer unlockStorage()
+
+	ids, err := u.indexedIDs(dbName, collectionName)
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return purged, err
+		}
+		removed, err := func() (bool, error) {
+			unlock, err := u.lockRecord(dbName, collectionName, id, true)
+			if err != nil {
+				return false, err
+			}
+			defer unlock()
+
+			filePath, err := u.recordPath(dbName, collectionName, id)
+			if err != nil {
+				return false, err
+			}
+			if _, err := os.Stat(filePath); os.IsNotExist(err) {
+				// Deleted since the index was read
+				return false, nil
+			}
+			record, err := u.readRecord(filePath)
+			if err != nil {
+				return false, err
+			}
+			if deletedAt := trashedAt(record); deletedAt == 0 || deletedAt >= before.Unix() {
+				return false, nil
+			}
+			return true, u.removeRecord(dbName, collectionName, filePath, id)
+		}()
+		if err != nil {
+			return purged, err
+		}
+		if removed {
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// removeRecord removes a record file and its ID from the index of its collection.
+// The caller must hold the record's lock for writing.
+func (u *FileFunctions) removeRecord(dbName basetypes.DBName, collectionName basetypes.CollectionName, filePath string, id basetypes.ID) error {
+	if err := os.Remove(filePath); err != nil {
+		return errors.New("File not found")
+	}
+	return u.removeFromIndex(dbName, collectionName, id)
+}
@@ -0,0 +1,23 @@
+package basefunctions
+
+import (
+	"context"
+	"websays/database/basetypes"
+)
+
+/*
+ * FlushInterface is implemented by the storages acknowledging writes before they are persisted,
+ * like WriteBehindFunctions. It is kept apart from BaseFucntionsInterface like TrashInterface.
+ */
+type FlushInterface interface {
+	// FlushStats returns the pending writes, the lag and the flushes of the storage.
+	FlushStats() basetypes.FlushStats
+
+	// Flush persists every write pending when it is called, giving up once ctx is done.
+	// Returns an error if any of them can't be persisted, they stay pending and are retried.
+	Flush(ctx context.Context) error
+
+	// Close stops the background flushes and persists every pending write, giving up once ctx is done.
+	// Returns an error if any of them can't be persisted.
+	Close(ctx context.Context) error
+}
@@ -0,0 +1,407 @@
+package basefunctions
+
+import (
+	"context"
+	"errors"
+	"time"
+	"websays/database/basehooks"
+	"websays/database/basetypes"
+)
+
+/*
+ * HookedFunctions runs the hooks of a registry around the writes of any BaseFucntionsInterface, see NewHookedFunctions.
+ * The before-hooks of an insert, update or deletion run ahead of it and may change its data or abort it with an error,
+ * the after-hooks run once it succeeded. Every item of a bulk write is hooked on its own: an item aborted by a hook
+ * fails in the result while the others are applied. The writes of a transaction run their before-hooks as they are made
+ * and their after-hooks once it is committed. The reads, restores and purges aren't hooked.
+ */
+type HookedFunctions struct {
+	storage BaseFucntionsInterface // The storage the writes are passed on to.
+	hooks   *basehooks.Registry    // The hooks run around the writes.
+}
+
+// hookedDatabaseFunctions is a HookedFunctions around a storage keeping databases apart and sequences,
+// like the memory and file storages, so the wrapper keeps both interfaces of the storage.
+type hookedDatabaseFunctions struct {
+	*HookedFunctions
+	SequenceInterface
+	DatabaseInterface
+}
+
+// hookedSequenceFunctions is a HookedFunctions around a storage keeping sequences but a single database,
+// like the write-behind storage, so the wrapper keeps the SequenceInterface of the storage.
+type hookedSequenceFunctions struct {
+	*HookedFunctions
+	SequenceInterface
+}
+
+// hookedIterableFunctions is a HookedFunctions around a storage streaming its records, like the sql storages,
+// so the wrapper keeps the IterableInterface of the storage.
+type hookedIterableFunctions struct {
+	*HookedFunctions
+	IterableInterface
+}
+
+// hookedTransaction is a transaction on the storage of a HookedFunctions,
+// running the before-hooks of its writes as they are made and their after-hooks once it is committed.
+type hookedTransaction struct {
+	TransactionInterface
+	hooks   *basehooks.Registry
+	written []basehooks.Event // The writes of the transaction, for their after-hooks.
+	ctx     context.Context   // The context the hooks run under.
+}
+
+// NewHookedFunctions returns storage with the hooks of a registry run around its writes.
+// The wrapper keeps the TrashInterface and TransactionalInterface of the storage, and its DatabaseInterface
+// and SequenceInterface, its SequenceInterface alone, or its IterableInterface, so the controllers see the same storage through it.
+func NewHookedFunctions(storage BaseFucntionsInterface, hooks *basehooks.Registry) BaseFucntionsInterface {
+	hooked := &HookedFunctions{storage: storage, hooks: hooks}
+
+	databases, isDatabases := storage.(DatabaseInterface)
+	sequences, isSequences := storage.(SequenceInterface)
+	if isDatabases && isSequences {
+		return &hookedDatabaseFunctions{HookedFunctions: hooked, SequenceInterface: sequences, DatabaseInterface: databases}
+	}
+	if isSequences {
+		return &hookedSequenceFunctions{HookedFunctions: hooked, SequenceInterface: sequences}
+	}
+	if iterable, ok := storage.(IterableInterface); ok {
+		return &hookedIterableFunctions{HookedFunctions: hooked, IterableInterface: iterable}
+	}
+	return hooked
+}
+
+// GetFunctions returns the HookedFunctions instance as a BaseFucntionsInterface.
+func (u *HookedFunctions) GetFunctions() BaseFucntionsInterface {
+	return u
+}
+
+// EnsureIndex passes the index on to the storage.
+func (u *HookedFunctions) EnsureIndex(dbName basetypes.DBName, collectionName basetypes.CollectionName, indexData interface{}) error {
+	return u.EnsureIndexContext(context.Background(), dbName, collectionName, indexData)
+}
+
+// EnsureIndexContext is EnsureIndex, giving up once ctx is done.
+func (u *HookedFunctions) EnsureIndexContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, indexData interface{}) error {
+	return u.storage.EnsureIndexContext(ctx, dbName, collectionName, indexData)
+}
+
+// Add inserts the data returned by the before-hooks into the storage, then runs the after-hooks with its ID.
+func (u *HookedFunctions) Add(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (basetypes.ID, error) {
+	return u.AddContext(context.Background(), dbName, collectionName, data)
+}
+
+// AddContext is Add, giving up once ctx is done.
+func (u *HookedFunctions) AddContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (basetypes.ID, error) {
+	event := basehooks.Event{Operation: basehooks.ADD, DBName: dbName, CollectionName: collectionName, Data: data}
+	if err := u.hooks.RunBefore(ctx, &event); err != nil {
+		return "", err
+	}
+	id, err := u.storage.AddContext(ctx, dbName, collectionName, event.Data)
+	if err != nil {
+		return id, err
+	}
+	event.ID = id
+	u.hooks.RunAfter(ctx, event)
+	return id, nil
+}
+
+// FindOne reads a record from the storage.
+func (u *HookedFunctions) FindOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) (interface{}, error) {
+	return u.FindOneContext(context.Background(), dbName, collectionName, query)
+}
+
+// FindOneContext is FindOne, giving up once ctx is done.
+func (u *HookedFunctions) FindOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) (interface{}, error) {
+	return u.storage.FindOneContext(ctx, dbName, collectionName, query)
+}
+
+// FindMany reads a page of records from the storage.
+func (u *HookedFunctions) FindMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	return u.FindManyContext(context.Background(), dbName, collectionName, query, options)
+}
+
+// FindManyContext is FindMany, giving up once ctx is done.
+func (u *HookedFunctions) FindManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	return u.storage.FindManyContext(ctx, dbName, collectionName, query, options)
+}
+
+// UpdateOne updates a record of the storage with the data returned by the before-hooks, then runs the after-hooks.
+func (u *HookedFunctions) UpdateOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error {
+	return u.UpdateOneContext(context.Background(), dbName, collectionName, query, data, upsert)
+}
+
+// UpdateOneContext is UpdateOne, giving up once ctx is done.
+func (u *HookedFunctions) UpdateOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error {
+	event := basehooks.Event{Operation: basehooks.UPDATE, DBName: dbName, CollectionName: collectionName, Query: query, Data: data, Upsert: upsert}
+	if err := u.hooks.RunBefore(ctx, &event); err != nil {
+		return err
+	}
+	if err := u.storage.UpdateOneContext(ctx, dbName, collectionName, event.Query, event.Data, event.Upsert); err != nil {
+		return err
+	}
+	u.hooks.RunAfter(ctx, event)
+	return nil
+}
+
+// DeleteOne deletes a record from the storage unless a before-hook aborts it, then runs the after-hooks.
+func (u *HookedFunctions) DeleteOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) error {
+	return u.DeleteOneContext(context.Background(), dbName, collectionName, query)
+}
+
+// DeleteOneContext is DeleteOne, giving up once ctx is done.
+func (u *HookedFunctions) DeleteOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) error {
+	event := basehooks.Event{Operation: basehooks.DELETE, DBName: dbName, CollectionName: collectionName, Query: query}
+	if err := u.hooks.RunBefore(ctx, &event); err != nil {
+		return err
+	}
+	if err := u.storage.DeleteOneContext(ctx, dbName, collectionName, event.Query); err != nil {
+		return err
+	}
+	u.hooks.RunAfter(ctx, event)
+	return nil
+}
+
+// AddMany inserts the records not aborted by the before-hooks into the storage, then runs the after-hooks of those inserted.
+func (u *HookedFunctions) AddMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, data []interface{}) (basetypes.BulkResult, error) {
+	return u.AddManyContext(context.Background(), dbName, collectionName, data)
+}
+
+// AddManyContext is AddMany, giving up once ctx is done.
+func (u *HookedFunctions) AddManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data []interface{}) (basetypes.BulkResult, error) {
+	events := make([]basehooks.Event, len(data))
+	for i := range data {
+		events[i] = basehooks.Event{Operation: basehooks.ADD, DBName: dbName, CollectionName: collectionName, Data: data[i]}
+	}
+	return u.bulk(ctx, events, func(passed []int) (basetypes.BulkResult, error) {
+		records := make([]interface{}, len(passed))
+		for j, i := range passed {
+			records[j] = events[i].Data
+		}
+		return u.storage.AddManyContext(ctx, dbName, collectionName, records)
+	})
+}
+
+// UpdateMany applies the updates not aborted by the before-hooks to the storage, then runs the after-hooks of those applied.
+func (u *HookedFunctions) UpdateMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, updates []basetypes.BulkUpdate) (basetypes.BulkResult, error) {
+	return u.UpdateManyContext(context.Background(), dbName, collectionName, updates)
+}
+
+// UpdateManyContext is UpdateMany, giving up once ctx is done.
+func (u *HookedFunctions) UpdateManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, updates []basetypes.BulkUpdate) (basetypes.BulkResult, error) {
+	events := make([]basehooks.Event, len(updates))
+	for i, update := range updates {
+		events[i] = basehooks.Event{Operation: basehooks.UPDATE, DBName: dbName, CollectionName: collectionName, Query: update.Query, Data: update.Data, Upsert: update.Upsert}
+	}
+	return u.bulk(ctx, events, func(passed []int) (basetypes.BulkResult, error) {
+		hookedUpdates := make([]basetypes.BulkUpdate, len(passed))
+		for j, i := range passed {
+			hookedUpdates[j] = basetypes.BulkUpdate{Query: events[i].Query, Data: events[i].Data, Upsert: events[i].Upsert}
+		}
+		return u.storage.UpdateManyContext(ctx, dbName, collectionName, hookedUpdates)
+	})
+}
+
+// DeleteMany applies the deletions not aborted by the before-hooks to the storage, then runs the after-hooks of those applied.
+func (u *HookedFunctions) DeleteMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, queries []interface{}) (basetypes.BulkResult, error) {
+	return u.DeleteManyContext(context.Background(), dbName, collectionName, queries)
+}
+
+// DeleteManyContext is DeleteMany, giving up once ctx is done.
+func (u *HookedFunctions) DeleteManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, queries []interface{}) (basetypes.BulkResult, error) {
+	events := make([]basehooks.Event, len(queries))
+	for i := range queries {
+		events[i] = basehooks.Event{Operation: basehooks.DELETE, DBName: dbName, CollectionName: collectionName, Query: queries[i]}
+	}
+	return u.bulk(ctx, events, func(passed []int) (basetypes.BulkResult, error) {
+		hookedQueries := make([]interface{}, len(passed))
+		for j, i := range passed {
+			hookedQueries[j] = events[i].Query
+		}
+		return u.storage.DeleteManyContext(ctx, dbName, collectionName, hookedQueries)
+	})
+}
+
+// bulk runs the before-hooks of the items of a bulk write, failing those aborted, applies the others with apply,
+// which gets their positions among events, and runs the after-hooks of those that succeeded.
+// It returns the outcome of every item in the order of events, and the error of apply.
+func (u *HookedFunctions) bulk(ctx context.Context, events []basehooks.Event, apply func(passed []int) (basetypes.BulkResult, error)) (basetypes.BulkResult, error) {
+	result := basetypes.NewBulkResult(len(events))
+	passed := make([]int, 0, len(events))
+	for i := range events {
+		if err := u.hooks.RunBefore(ctx, &events[i]); err != nil {
+			result.Set(i, "", err)
+			continue
+		}
+		passed = append(passed, i)
+	}
+	if len(passed) == 0 {
+		return result, nil
+	}
+
+	applied, err := apply(passed)
+	for j, i := range passed {
+		if j >= len(applied.Items) {
+			result.Set(i, "", errors.New("Item not applied"))
+			continue
+		}
+		if applied.Items[j].Error != "" {
+			result.Set(i, "", errors.New(applied.Items[j].Error))
+			continue
+		}
+		result.Set(i, applied.Items[j].ID, nil)
+		events[i].ID = applied.Items[j].ID
+		u.hooks.RunAfter(ctx, events[i])
+	}
+	return result, err
+}
+
+// trash returns the trash of the storage, or an error if it doesn't keep one.
+func (u *HookedFunctions) trash() (TrashInterface, error) {
+	trash, ok := u.storage.(TrashInterface)
+	if !ok {
+		return nil, errors.New("Storage doesn't keep a trash")
+	}
+	return trash, nil
+}
+
+// FindTrash reads a page of the trash of the storage.
+func (u *HookedFunctions) FindTrash(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	return u.FindTrashContext(context.Background(), dbName, collectionName, query, options)
+}
+
+// FindTrashContext is FindTrash, giving up once ctx is done.
+func (u *HookedFunctions) FindTrashContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	trash, err := u.trash()
+	if err != nil {
+		return basetypes.FindResult{}, err
+	}
+	return trash.FindTrashContext(ctx, dbName, collectionName, query, options)
+}
+
+// Restore moves a record of the storage out of the trash.
+func (u *HookedFunctions) Restore(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) error {
+	return u.RestoreContext(context.Background(), dbName, collectionName, query)
+}
+
+// RestoreContext is Restore, giving up once ctx is done.
+func (u *HookedFunctions) RestoreContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) error {
+	trash, err := u.trash()
+	if err != nil {
+		return err
+	}
+	return trash.RestoreContext(ctx, dbName, collectionName, query)
+}
+
+// Purge removes a record from the trash of the storage.
+func (u *HookedFunctions) Purge(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) error {
+	return u.PurgeContext(context.Background(), dbName, collectionName, query)
+}
+
+// PurgeContext is Purge, giving up once ctx is done.
+func (u *HookedFunctions) PurgeContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) error {
+	trash, err := u.trash()
+	if err != nil {
+		return err
+	}
+	return trash.PurgeContext(ctx, dbName, collectionName, query)
+}
+
+// PurgeTrash removes the records trashed before a time from the trash of the storage.
+func (u *HookedFunctions) PurgeTrash(dbName basetypes.DBName, collectionName basetypes.CollectionName, before time.Time) (int, error) {
+	return u.PurgeTrashContext(context.Background(), dbName, collectionName, before)
+}
+
+// PurgeTrashContext is PurgeTrash, giving up once ctx is done.
+func (u *HookedFunctions) PurgeTrashContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, before time.Time) (int, error) {
+	trash, err := u.trash()
+	if err != nil {
+		return 0, err
+	}
+	return trash.PurgeTrashContext(ctx, dbName, collectionName, before)
+}
+
+// Begin starts a transaction on the storage, running the hooks of the writes made through it.
+func (u *HookedFunctions) Begin() (TransactionInterface, error) {
+	return u.BeginContext(context.Background())
+}
+
+// BeginContext is Begin, with the transaction and its hooks run under ctx.
+func (u *HookedFunctions) BeginContext(ctx context.Context) (TransactionInterface, error) {
+	transactional, ok := u.storage.(TransactionalInterface)
+	if !ok {
+		return nil, errors.New("Storage doesn't support transactions")
+	}
+	tx, err := transactional.BeginContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &hookedTransaction{TransactionInterface: tx, hooks: u.hooks, ctx: ctx}, nil
+}
+
+// Add inserts a new document into a collection as part of the transaction, unless a before-hook aborts it.
+func (t *hookedTransaction) Add(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (basetypes.ID, error) {
+	event := basehooks.Event{Operation: basehooks.ADD, DBName: dbName, CollectionName: collectionName, Data: data}
+	if err := t.hooks.RunBefore(t.ctx, &event); err != nil {
+		return "", err
+	}
+	id, err := t.TransactionInterface.Add(dbName, collectionName, event.Data)
+	if err != nil {
+		return id, err
+	}
+	event.ID = id
+	t.written = append(t.written, event)
+	return id, nil
+}
+
+// UpdateOne updates a document in a collection as part of the transaction, unless a before-hook aborts it.
+func (t *hookedTransaction) UpdateOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error {
+	event := basehooks.Event{Operation: basehooks.UPDATE, DBName: dbName, CollectionName: collectionName, Query: query, Data: data, Upsert: upsert}
+	if err := t.hooks.RunBefore(t.ctx, &event); err != nil {
+		return err
+	}
+	if err := t.TransactionInterface.UpdateOne(dbName, collectionName, event.Query, event.Data, event.Upsert); err != nil {
+		return err
+	}
+	t.written = append(t.written, event)
+	return nil
+}
+
+// DeleteOne deletes a document from a collection as part of the transaction, unless a before-hook aborts it.
+func (t *hookedTransaction) DeleteOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) error {
+	event := basehooks.Event{Operation: basehooks.DELETE, DBName: dbName, CollectionName: collectionName, Query: query}
+	if err := t.hooks.RunBefore(t.ctx, &event); err != nil {
+		return err
+	}
+	if err := t.TransactionInterface.DeleteOne(dbName, collectionName, event.Query); err != nil {
+		return err
+	}
+	t.written = append(t.written, event)
+	return nil
+}
+
+// Commit applies every write of the transaction, then runs their after-hooks in the order they were made.
+func (t *hookedTransaction) Commit() error {
+	if err := t.TransactionInterface.Commit(); err != nil {
+		return err
+	}
+	for _, event := range t.written {
+		t.hooks.RunAfter(t.ctx, event)
+	}
+	return nil
+}
+
+// GetFunctions returns the hookedDatabaseFunctions instance as a BaseFucntionsInterface.
+func (u *hookedDatabaseFunctions) GetFunctions() BaseFucntionsInterface {
+	return u
+}
+
+// GetFunctions returns the hookedSequenceFunctions instance as a BaseFucntionsInterface.
+func (u *hookedSequenceFunctions) GetFunctions() BaseFucntionsInterface {
+	return u
+}
+
+// GetFunctions returns the hookedIterableFunctions instance as a BaseFucntionsInterface.
+func (u *hookedIterableFunctions) GetFunctions() BaseFucntionsInterface {
+	return u
+}
@@ -0,0 +1,18 @@
+package basefunctions
+
+import (
+	"context"
+	"websays/database/basetypes"
+)
+
+/*
+ * IterableInterface is implemented by the storages that can stream the records of a query
+ * instead of loading them at once. It is kept apart from BaseFucntionsInterface like TransactionalInterface.
+ */
+type IterableInterface interface {
+	// Iterate returns an iterator over the records of a collection matching the provided query.
+	// The iterator holds a connection until it is exhausted or closed, so it must always be closed.
+	// The query runs with ctx, cancelling it stops the iteration.
+	// Returns an error if the query fails.
+	Iterate(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) (*RecordIterator, error)
+}
@@ -0,0 +1,75 @@
+package basefunctions
+
+import (
+	"context"
+	"errors"
+	"websays/database/basetypes"
+)
+
+// AddMany adds several records to the in-memory data store under a single hold of the lock.
+// The records are checked first and logged as a single batch, so the batch costs one write of the log
+// and a crash keeps all of them or none.
+func (u *MemoryFunctions) AddMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, data []interface{}) (basetypes.BulkResult, error) {
+	return u.AddManyContext(context.Background(), dbName, collectionName, data)
+}
+
+// AddManyContext is AddMany, giving up once ctx is done. The context is checked before each record.
+func (u *MemoryFunctions) AddManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data []interface{}) (basetypes.BulkResult, error) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	batch := walEntry{Op: walBatch}
+	batched := make(map[string]bool)
+	result, err := bulkApply(ctx, len(data), func(index int) (basetypes.ID, error) {
+		key, err := memoryKey(collectionName, data[index])
+		if err != nil {
+			return "", err
+		}
+		if _, ok := u.data[dbName][key]; ok || batched[key] {
+			return "", errors.New("ID already exists")
+		}
+		batched[key] = true
+		batch.Writes = append(batch.Writes, walEntry{Op: walSet, DB: dbName, Key: key, Data: liveRecord(stampCreated(ctx, firstVersion(data[index])))})
+		return recordID(data[index]), nil
+	})
+	if len(batch.Writes) == 0 {
+		return result, err
+	}
+	if err := u.logEntry(batch); err != nil {
+		return bulkFailed(len(data), err)
+	}
+	for _, write := range batch.Writes {
+		u.database(dbName)[write.Key] = write.Data
+		u.reserveID(dbName, write.Key)
+	}
+	return result, err
+}
+
+// UpdateMany applies several updates to the in-memory data store under a single hold of the lock, each like UpdateOne.
+func (u *MemoryFunctions) UpdateMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, updates []basetypes.BulkUpdate) (basetypes.BulkResult, error) {
+	return u.UpdateManyContext(context.Background(), dbName, collectionName, updates)
+}
+
+// UpdateManyContext is UpdateMany, giving up once ctx is done. The context is checked before each update.
+func (u *MemoryFunctions) UpdateManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, updates []basetypes.BulkUpdate) (basetypes.BulkResult, error) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	return bulkApply(ctx, len(updates), func(index int) (basetypes.ID, error) {
+		update := updates[index]
+		return "", u.updateOne(ctx, dbName, collectionName, update.Query, update.Data, update.Upsert)
+	})
+}
+
+// DeleteMany deletes several records from the in-memory data store under a single hold of the lock, each like DeleteOne.
+func (u *MemoryFunctions) DeleteMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, queries []interface{}) (basetypes.BulkResult, error) {
+	return u.DeleteManyContext(context.Background(), dbName, collectionName, queries)
+}
+
+// DeleteManyContext is DeleteMany, giving up once ctx is done. The context is checked before each deletion.
+func (u *MemoryFunctions) DeleteManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, queries []interface{}) (basetypes.BulkResult, error) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	return bulkApply(ctx, len(queries), func(index int) (basetypes.ID, error) {
+		return "", u.deleteOne(dbName, collectionName, queries[index])
+	})
+}
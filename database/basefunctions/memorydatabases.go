@@ -0,0 +1,85 @@
+package basefunctions
+
+import (
+	"context"
+	"sort"
+	"websays/database/basetypes"
+)
+
+// ListDatabases returns the names of the databases of the in-memory data store in alphabetical order.
+func (u *MemoryFunctions) ListDatabases() ([]basetypes.DBName, error) {
+	return u.ListDatabasesContext(context.Background())
+}
+
+// ListDatabasesContext is ListDatabases, giving up once ctx is done.
+func (u *MemoryFunctions) ListDatabasesContext(ctx context.Context) ([]basetypes.DBName, error) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	names := make([]basetypes.DBName, 0, len(u.data))
+	for dbName := range u.data {
+		names = append(names, dbName)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names, nil
+}
+
+// HasDatabase reports whether a database exists in the in-memory data store.
+func (u *MemoryFunctions) HasDatabase(dbName basetypes.DBName) (bool, error) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	_, ok := u.data[dbName]
+	return ok, nil
+}
+
+// CreateDatabase creates an empty database in the in-memory data store.
+func (u *MemoryFunctions) CreateDatabase(dbName basetypes.DBName) error {
+	return u.CreateDatabaseContext(context.Background(), dbName)
+}
+
+// CreateDatabaseContext is CreateDatabase, giving up before it takes effect once ctx is done.
+func (u *MemoryFunctions) CreateDatabaseContext(ctx context.Context, dbName basetypes.DBName) error {
+	if err := CheckDBName(dbName); err != nil {
+		return err
+	}
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if _, ok := u.data[dbName]; ok {
+		return ErrDatabaseExists
+	}
+	if err := u.logEntry(walEntry{Op: walCreateDatabase, DB: dbName}); err != nil {
+		return err
+	}
+	u.database(dbName)
+	return nil
+}
+
+// DropDatabase removes a database with all of its records from the in-memory data store.
+func (u *MemoryFunctions) DropDatabase(dbName basetypes.DBName) error {
+	return u.DropDatabaseContext(context.Background(), dbName)
+}
+
+// DropDatabaseContext is DropDatabase, giving up before it takes effect once ctx is done.
+func (u *MemoryFunctions) DropDatabaseContext(ctx context.Context, dbName basetypes.DBName) error {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if _, ok := u.data[dbName]; !ok {
+		return ErrDatabaseNotFound
+	}
+	if err := u.logEntry(walEntry{Op: walDropDatabase, DB: dbName}); err != nil {
+		return err
+	}
+	delete(u.data, dbName)
+	delete(u.sequences, dbName)
+	return nil
+}
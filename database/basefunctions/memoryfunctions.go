@@ -1,95 +1,406 @@
 package basefunctions
 
 import (
+	"context"
 	"errors"
-	"strconv"
+	"log"
+	"os"
+	"sort"
+	"strings"
 	"sync"
+	"time"
+	"websays/config"
+	"websays/database/basefilters"
 	"websays/database/basetypes"
 	"websays/httpHandler/basemodels"
 )
 
-var mapInitiater sync.Once
+// errDataNotFound is returned when no record of the in-memory data store matches a condition.
+var errDataNotFound = errors.New("Data not found")
 
 // MemoryFunctions is a concrete implementation of the BaseFucntionsInterface for in-memory storage.
+// Every database has its own map of records, so two databases never see each other's collections.
+// When memory persistence is enabled in the config, every write is appended to a write-ahead log
+// and the data is rebuilt from the last snapshot and the log on startup.
 type MemoryFunctions struct {
-	lock sync.Mutex             // Mutex for locking access to the in-memory data store.
-	data map[string]interface{} // The in-memory data store where data is stored.
-	id   int                    // ID counter for generating unique IDs.
+	lock         sync.Mutex                                                  // Mutex for locking access to the in-memory data store.
+	data         map[basetypes.DBName]map[string]interface{}                 // The records of each database by key.
+	sequences    map[basetypes.DBName]map[basetypes.CollectionName]int64     // The last integer ID of each collection of each database.
+	mapInitiater sync.Once                                                   // Ensures the data store is initialised once.
+	wal          *os.File                                                    // The write-ahead log, nil when persistence is disabled.
+	stop         chan struct{}                                               // Closed by Close to stop the periodic snapshots.
+	closed       bool                                                        // Set by Close, the writes can't be logged anymore.
+	journal      func(dbName basetypes.DBName, key string, data interface{}) // Follows every record set, or removed with nil data, under the lock; nil when nothing follows the writes.
+	models       collectionModels                                            // Models of the collections registered by EnsureIndex, whose fields the filters may use.
 }
 
 // GetFunctions returns the MemoryFunctions instance as a BaseFucntionsInterface.
+// On first use it initialises the data store and restores the persisted data if persistence is enabled.
 func (u *MemoryFunctions) GetFunctions() BaseFucntionsInterface {
-	mapInitiater.Do(func() {
-		u.data = map[string]interface{}{}
+	u.mapInitiater.Do(func() {
+		u.initData()
+		if config.GetInstance().Memory.Persistence {
+			if err := u.startPersistence(); err != nil {
+				log.Println("Error starting memory persistence:", err)
+			}
+		}
 	})
 	return u
 }
 
+// initData initialises an empty data store.
+func (u *MemoryFunctions) initData() {
+	u.data = map[basetypes.DBName]map[string]interface{}{}
+	u.sequences = map[basetypes.DBName]map[basetypes.CollectionName]int64{}
+}
+
 // EnsureIndex ensures an index for the specified database and collection.
 // Memory storage does not require index creation, so this method does nothing.
 func (u *MemoryFunctions) EnsureIndex(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) error {
+	return u.EnsureIndexContext(context.Background(), dbName, collectionName, data)
+}
+
+// EnsureIndexContext is EnsureIndex, giving up before it takes effect once ctx is done.
+// It registers the model of the collection, so the filters on its records are checked against the fields of the model.
+func (u *MemoryFunctions) EnsureIndexContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	u.models.register(collectionName, data)
 	return nil
 }
 
-// GetNextID generates and returns the next available ID for in-memory storage.
-func (u *MemoryFunctions) GetNextID() int {
-	u.id++
-	return u.id
+// NextSequence returns the next integer ID of the sequence of a collection of the in-memory data store.
+func (u *MemoryFunctions) NextSequence(dbName basetypes.DBName, collectionName basetypes.CollectionName) (int64, error) {
+	return u.NextSequenceContext(context.Background(), dbName, collectionName)
+}
+
+// NextSequenceContext is NextSequence, giving up once ctx is done.
+func (u *MemoryFunctions) NextSequenceContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName) (int64, error) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	sequences := u.collectionSequences(dbName)
+	sequences[collectionName]++
+	return sequences[collectionName], nil
+}
+
+// Add adds data to the in-memory data store under its own ID, which it returns.
+func (u *MemoryFunctions) Add(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (basetypes.ID, error) {
+	return u.AddContext(context.Background(), dbName, collectionName, data)
 }
 
-// Add adds data to the in-memory data store.
-func (u *MemoryFunctions) Add(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (int, error) {
-	idData := data.(basemodels.BaseModels)
+// AddContext is Add, giving up before it takes effect once ctx is done.
+// The context is checked once the lock is held, as waiting for the lock can't be cancelled.
+func (u *MemoryFunctions) AddContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (basetypes.ID, error) {
 	u.lock.Lock()
 	defer u.lock.Unlock()
-	key := strconv.FormatInt(int64(idData.GetID()), 10) + "_" + string(collectionName)
-	if _, ok := u.data[key]; ok {
-		return 0, errors.New("ID already exists")
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if err := u.add(ctx, dbName, collectionName, data); err != nil {
+		return "", err
+	}
+	return recordID(data), nil
+}
+
+// add stores a new record under the ID of data. The caller must hold the lock.
+func (u *MemoryFunctions) add(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) error {
+	key, err := memoryKey(collectionName, data)
+	if err != nil {
+		return err
+	}
+	if _, ok := u.data[dbName][key]; ok {
+		return errors.New("ID already exists")
 	}
-	u.data[key] = idData
-	return 0, nil
+	return u.insert(dbName, key, liveRecord(stampCreated(ctx, firstVersion(data))))
 }
 
-// FindOne retrieves data from the in-memory data store by ID.
+// FindOne retrieves data from the in-memory data store by ID, or the first record matching a filter.
 func (u *MemoryFunctions) FindOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, condition interface{}) (interface{}, error) {
-	idData := condition.(basemodels.BaseModels)
+	return u.FindOneContext(context.Background(), dbName, collectionName, condition)
+}
+
+// FindOneContext is FindOne, giving up once ctx is done.
+func (u *MemoryFunctions) FindOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, condition interface{}) (interface{}, error) {
 	u.lock.Lock()
 	defer u.lock.Unlock()
-	key := strconv.FormatInt(int64(idData.GetID()), 10) + "_" + string(collectionName)
-	if data, ok := u.data[key]; ok {
-		return data, nil
-	} else {
-		return nil, errors.New("Not found")
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	key, err := u.findKey(dbName, collectionName, condition, false)
+	if err != nil {
+		return nil, err
 	}
+	return u.data[dbName][key], nil
+}
+
+// FindMany retrieves a page of data of a collection from the in-memory data store, ordered by ID.
+func (u *MemoryFunctions) FindMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	return u.FindManyContext(context.Background(), dbName, collectionName, query, options)
 }
 
-// UpdateOne updates data in the in-memory data store by ID.
+// FindManyContext is FindMany, giving up once ctx is done.
+func (u *MemoryFunctions) FindManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	filter, err := requireFilter(query, documentFields(u.models.get(collectionName)))
+	if err != nil {
+		return basetypes.FindResult{}, err
+	}
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if err := ctx.Err(); err != nil {
+		return basetypes.FindResult{}, err
+	}
+	return u.page(dbName, collectionName, filter, false, options)
+}
+
+// UpdateOne updates data in the in-memory data store by ID, or the first record matching a filter query.
+// With upsert, data is inserted under its own ID when no record matches.
 func (u *MemoryFunctions) UpdateOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error {
+	return u.UpdateOneContext(context.Background(), dbName, collectionName, query, data, upsert)
+}
+
+// UpdateOneContext is UpdateOne, giving up before it takes effect once ctx is done.
+func (u *MemoryFunctions) UpdateOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error {
 	u.lock.Lock()
 	defer u.lock.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return u.updateOne(ctx, dbName, collectionName, query, data, upsert)
+}
 
-	idData := data.(basemodels.BaseModels)
-	key := strconv.FormatInt(int64(idData.GetID()), 10) + "_" + string(collectionName)
-	if _, ok := u.data[key]; ok {
-		u.data[key] = data
-	} else {
-		return errors.New("Data not found")
+// updateOne updates the record identified by ID, or the first record matching a filter query,
+// inserting data under its own ID with upsert when no record matches. The caller must hold the lock.
+func (u *MemoryFunctions) updateOne(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error {
+	condition := query
+	if _, ok, _ := toFilter(query); !ok || query == nil {
+		condition = data
 	}
-	return nil
+	key, err := u.findKey(dbName, collectionName, condition, false)
+	if errors.Is(err, errDataNotFound) && upsert {
+		// The lock is held since the lookup, so nobody can insert the record in between
+		key, err = memoryKey(collectionName, data)
+		if err != nil {
+			return err
+		}
+		// A trashed record with the same ID is replaced, which restores it
+		stored, ok := u.data[dbName][key]
+		if ok && !isTrashed(stored) {
+			return errors.New("ID already exists")
+		}
+		data, err = nextVersion(stored, data)
+		if err != nil {
+			return err
+		}
+		return u.insert(dbName, key, liveRecord(stampUpdated(ctx, stored, data)))
+	}
+	if err != nil {
+		return err
+	}
+	stored := u.data[dbName][key]
+	data, err = nextVersion(stored, data)
+	if err != nil {
+		return err
+	}
+	return u.set(dbName, key, liveRecord(stampUpdated(ctx, stored, data)))
+}
+
+// Begin starts a transaction buffering its writes until they are applied together under the lock on Commit.
+func (u *MemoryFunctions) Begin() (TransactionInterface, error) {
+	return u.BeginContext(context.Background())
+}
+
+// BeginContext is Begin, with the writes of the transaction stamped with the principal of ctx.
+// The transaction gives up once ctx is done.
+func (u *MemoryFunctions) BeginContext(ctx context.Context) (TransactionInterface, error) {
+	return &MemoryTransaction{functions: u, ctx: ctx}, nil
 }
 
-// DeleteOne deletes data from the in-memory data store by ID.
+// DeleteOne deletes data from the in-memory data store by ID, or the first record matching a filter.
+// A record of a soft deletable model is moved to the trash instead.
 func (u *MemoryFunctions) DeleteOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) error {
+	return u.DeleteOneContext(context.Background(), dbName, collectionName, data)
+}
+
+// DeleteOneContext is DeleteOne, giving up before it takes effect once ctx is done.
+func (u *MemoryFunctions) DeleteOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) error {
 	u.lock.Lock()
 	defer u.lock.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return u.deleteOne(dbName, collectionName, data)
+}
 
-	idData := data.(basemodels.BaseModels)
-	key := strconv.FormatInt(int64(idData.GetID()), 10) + "_" + string(collectionName)
-	if _, ok := u.data[key]; ok {
-		delete(u.data, key)
-	} else {
-		return errors.New("Data not found")
+// deleteOne deletes the record identified by ID, or the first record matching a filter,
+// moving a record of a soft deletable model to the trash instead. The caller must hold the lock.
+func (u *MemoryFunctions) deleteOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) error {
+	key, err := u.findKey(dbName, collectionName, data, false)
+	if err != nil {
+		return err
+	}
+	records := u.data[dbName]
+	if err := checkVersion(records[key], data); err != nil {
+		return err
 	}
+	if softDeletes(records[key], data) {
+		return u.set(dbName, key, withTrash(records[key], time.Now().Unix()))
+	}
+	if err := u.logEntry(walEntry{Op: walDelete, DB: dbName, Key: key}); err != nil {
+		return err
+	}
+	delete(records, key)
+	return nil
+}
+
+// insert stores a new record under key and moves the sequence of its collection past its ID,
+// so NextSequence never hands it out again. The caller must hold the lock.
+func (u *MemoryFunctions) insert(dbName basetypes.DBName, key string, data interface{}) error {
+	if err := u.set(dbName, key, data); err != nil {
+		return err
+	}
+	u.reserveID(dbName, key)
+	return nil
+}
 
+// set logs and stores data under key in a database, creating the database on its first write.
+// The caller must hold the lock.
+func (u *MemoryFunctions) set(dbName basetypes.DBName, key string, data interface{}) error {
+	if err := u.logEntry(walEntry{Op: walSet, DB: dbName, Key: key, Data: data}); err != nil {
+		return err
+	}
+	u.database(dbName)[key] = data
 	return nil
 }
+
+// database returns the records of a database, creating it if it doesn't exist. The caller must hold the lock.
+func (u *MemoryFunctions) database(dbName basetypes.DBName) map[string]interface{} {
+	records, ok := u.data[dbName]
+	if !ok {
+		records = map[string]interface{}{}
+		u.data[dbName] = records
+	}
+	return records
+}
+
+// reserveID moves the sequence of the collection of a record key past its ID, if it is an integer ID.
+// The caller must hold the lock.
+func (u *MemoryFunctions) reserveID(dbName basetypes.DBName, key string) {
+	prefix, collectionName, _ := strings.Cut(key, "_")
+	sequences := u.collectionSequences(dbName)
+	if id, ok := basetypes.ID(prefix).Int(); ok && id > sequences[basetypes.CollectionName(collectionName)] {
+		sequences[basetypes.CollectionName(collectionName)] = id
+	}
+}
+
+// collectionSequences returns the sequences of the collections of a database. The caller must hold the lock.
+func (u *MemoryFunctions) collectionSequences(dbName basetypes.DBName) map[basetypes.CollectionName]int64 {
+	sequences, ok := u.sequences[dbName]
+	if !ok {
+		sequences = map[basetypes.CollectionName]int64{}
+		u.sequences[dbName] = sequences
+	}
+	return sequences
+}
+
+// findKey returns the key of the record identified by a model's ID or of the first record matching a filter,
+// among the trashed records when trashed is set and the live ones otherwise. The caller must hold the lock.
+func (u *MemoryFunctions) findKey(dbName basetypes.DBName, collectionName basetypes.CollectionName, condition interface{}, trashed bool) (string, error) {
+	return findMemoryKey(u.data[dbName], collectionName, documentFields(u.models.get(collectionName)), condition, trashed)
+}
+
+// page returns the page of the records of a collection matching the filter,
+// among the trashed records when trashed is set and the live ones otherwise. The caller must hold the lock.
+func (u *MemoryFunctions) page(dbName basetypes.DBName, collectionName basetypes.CollectionName, filter basefilters.Filter, trashed bool, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	stored := u.data[dbName]
+	records := make([]interface{}, 0)
+	for _, key := range u.collectionKeys(dbName, collectionName) {
+		if isTrashed(stored[key]) != trashed {
+			continue
+		}
+		matched, err := matchesQuery(stored[key], filter)
+		if err != nil {
+			return basetypes.FindResult{}, err
+		}
+		if matched {
+			records = append(records, stored[key])
+		}
+	}
+	return paginate(records, options)
+}
+
+// collectionKeys returns the keys of every record of a collection of a database ordered by ID.
+// The caller must hold the lock.
+func (u *MemoryFunctions) collectionKeys(dbName basetypes.DBName, collectionName basetypes.CollectionName) []string {
+	return memoryCollectionKeys(u.data[dbName], collectionName)
+}
+
+// memoryKey returns the key under which a record is stored in the records of its database.
+func memoryKey(collectionName basetypes.CollectionName, data interface{}) (string, error) {
+	idData, ok := data.(basemodels.BaseModels)
+	if !ok {
+		return "", errors.New("Required a model with an ID")
+	}
+	if !idData.GetID().Valid() {
+		return "", errors.New("Invalid ID")
+	}
+	return string(idData.GetID()) + "_" + string(collectionName), nil
+}
+
+// findMemoryKey returns the key of the record identified by a model's ID or of the first record matching a filter in store,
+// among the trashed records when trashed is set and the live ones otherwise. The fields of the filter are checked against fields, see requireFilter.
+func findMemoryKey(store map[string]interface{}, collectionName basetypes.CollectionName, fields map[string]string, condition interface{}, trashed bool) (string, error) {
+	if _, ok := condition.(basemodels.BaseModels); ok {
+		key, _ := memoryKey(collectionName, condition)
+		if record, ok := store[key]; !ok || isTrashed(record) != trashed {
+			return "", errDataNotFound
+		}
+		return key, nil
+	}
+
+	filter, err := requireFilter(condition, fields)
+	if err != nil {
+		return "", err
+	}
+	for _, key := range memoryCollectionKeys(store, collectionName) {
+		if isTrashed(store[key]) != trashed {
+			continue
+		}
+		matched, err := matchesQuery(store[key], filter)
+		if err != nil {
+			return "", err
+		}
+		if matched {
+			return key, nil
+		}
+	}
+	return "", errDataNotFound
+}
+
+// memoryCollectionKeys returns the keys of every record of a collection in store ordered by ID.
+func memoryCollectionKeys(store map[string]interface{}, collectionName basetypes.CollectionName) []string {
+	suffix := "_" + string(collectionName)
+	ids := make([]basetypes.ID, 0)
+	for key := range store {
+		if !strings.HasSuffix(key, suffix) {
+			continue
+		}
+		// A key of another collection may end with the name of this one, like "1_my_categories" for "categories"
+		id := basetypes.ID(strings.TrimSuffix(key, suffix))
+		if !id.Valid() {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].Less(ids[j]) })
+
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, string(id)+suffix)
+	}
+	return keys
+}
@@ -0,0 +1,275 @@
+package basefunctions
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"websays/config"
+	"websays/database/basetypes"
+)
+
+// Operations recorded in the write-ahead log of the memory storage.
+const (
+	walSet            = "set"            // The record under Key of DB is set to Data.
+	walDelete         = "delete"         // The record under Key of DB is removed.
+	walBatch          = "batch"          // Every entry in Writes is applied at once, used by transaction commits.
+	walCreateDatabase = "createDatabase" // The empty database DB is created.
+	walDropDatabase   = "dropDatabase"   // The database DB is removed with all of its records.
+)
+
+// walEntry is a single line of the write-ahead log.
+// Entries without a DB were written before the storage kept databases apart, they belong to the configured database.
+type walEntry struct {
+	Op     string           `json:"op"`
+	DB     basetypes.DBName `json:"db,omitempty"`
+	Key    string           `json:"key,omitempty"`
+	Data   interface{}      `json:"data,omitempty"`
+	Writes []walEntry       `json:"writes,omitempty"`
+}
+
+// memorySnapshot is the compacted state of the memory storage.
+type memorySnapshot struct {
+	Sequences map[basetypes.DBName]map[basetypes.CollectionName]int64 `json:"sequences"`      // The sequence of each collection when the snapshot was taken.
+	Databases map[basetypes.DBName]map[string]interface{}             `json:"databases"`      // Every record by key, for each database.
+	ID        int64                                                   `json:"id,omitempty"`   // The ID counter shared by the collections, in snapshots taken before the sequences.
+	Data      map[string]interface{}                                  `json:"data,omitempty"` // Every record by key, in snapshots taken before databases were kept apart.
+}
+
+// defaultDBName returns the configured database, which owns the records persisted before databases were kept apart.
+func defaultDBName() basetypes.DBName {
+	return basetypes.DBName(config.GetInstance().Database.DBName)
+}
+
+// walPath returns the path of the write-ahead log.
+func walPath() string {
+	return config.GetInstance().FilePath + "/" + config.GetInstance().Memory.WalFileName
+}
+
+// snapshotPath returns the path of the snapshot.
+func snapshotPath() string {
+	return config.GetInstance().FilePath + "/" + config.GetInstance().Memory.SnapshotFileName
+}
+
+// startPersistence restores the data from the snapshot and the write-ahead log,
+// opens the log for appending and starts the periodic snapshots, until Close.
+// If the data can't be restored the store is left empty and nothing is logged, so the files are kept as they are.
+func (u *MemoryFunctions) startPersistence() error {
+	memoryConfig := config.GetInstance().Memory
+	if memoryConfig.WalFileName == "" || memoryConfig.SnapshotFileName == "" {
+		return errors.New("Memory persistence requires walFileName and snapshotFileName")
+	}
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	err := u.restore()
+	if err != nil {
+		u.initData()
+		return err
+	}
+
+	u.wal, err = os.OpenFile(walPath(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	u.stop = make(chan struct{})
+	if memoryConfig.SnapshotInterval > 0 {
+		ticker := time.NewTicker(time.Duration(memoryConfig.SnapshotInterval) * time.Second)
+		go func() {
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					if err := u.Snapshot(); err != nil {
+						log.Println("Error taking memory snapshot:", err)
+					}
+				case <-u.stop:
+					return
+				}
+			}
+		}()
+	}
+	return nil
+}
+
+// Close stops the periodic snapshots and closes the write-ahead log on shutdown.
+// The writes made afterwards fail, as they could no longer be logged.
+func (u *MemoryFunctions) Close() error {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	if u.wal == nil {
+		return nil
+	}
+	close(u.stop)
+	err := u.wal.Close()
+	u.wal = nil
+	u.closed = true
+	return err
+}
+
+// restore rebuilds the data and the sequences from the snapshot and replays the write-ahead log over them.
+// A last log line cut short by a crash is ignored and cut off the log, so the next entry starts on a line of its own.
+// Any other unreadable line fails the restore, as replaying the entries after it would skip a write.
+// The caller must hold the lock.
+func (u *MemoryFunctions) restore() error {
+	content, err := os.ReadFile(snapshotPath())
+	if err == nil {
+		snapshot := memorySnapshot{}
+		if err := json.Unmarshal(content, &snapshot); err != nil {
+			return errors.New("Error decoding memory snapshot")
+		}
+		if snapshot.Databases != nil {
+			u.data = snapshot.Databases
+		}
+		if snapshot.Data != nil {
+			u.data[defaultDBName()] = snapshot.Data
+		}
+		if snapshot.Sequences != nil {
+			u.sequences = snapshot.Sequences
+		}
+		// The former shared counter bounds the sequences of every collection it handed out IDs for
+		if snapshot.ID > 0 {
+			for key := range u.data[defaultDBName()] {
+				_, collectionName, _ := strings.Cut(key, "_")
+				sequences := u.collectionSequences(defaultDBName())
+				if snapshot.ID > sequences[basetypes.CollectionName(collectionName)] {
+					sequences[basetypes.CollectionName(collectionName)] = snapshot.ID
+				}
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	file, err := os.Open(walPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	line, unreadable := 0, 0
+	var readable int64 // The length of the log up to the end of the last readable line.
+	for scanner.Scan() {
+		line++
+		if unreadable > 0 {
+			return fmt.Errorf("Unreadable memory log line %d", unreadable)
+		}
+		entry := walEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			unreadable = line
+			continue
+		}
+		u.applyEntry(entry)
+		readable += int64(len(scanner.Bytes())) + 1
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if unreadable > 0 {
+		log.Println("Ignoring truncated memory log line", unreadable)
+		if err := os.Truncate(walPath(), readable); err != nil {
+			return err
+		}
+	}
+
+	// Never hand out an ID that is already used by a restored record
+	for dbName, records := range u.data {
+		for key := range records {
+			u.reserveID(dbName, key)
+		}
+	}
+	return nil
+}
+
+// applyEntry applies a log entry to the data. The caller must hold the lock.
+func (u *MemoryFunctions) applyEntry(entry walEntry) {
+	dbName := entry.DB
+	if dbName == "" {
+		dbName = defaultDBName()
+	}
+	switch entry.Op {
+	case walSet:
+		u.database(dbName)[entry.Key] = entry.Data
+	case walDelete:
+		delete(u.data[dbName], entry.Key)
+	case walCreateDatabase:
+		u.database(dbName)
+	case walDropDatabase:
+		delete(u.data, dbName)
+		delete(u.sequences, dbName)
+	case walBatch:
+		for _, write := range entry.Writes {
+			u.applyEntry(write)
+		}
+	}
+}
+
+// logEntry appends an entry to the write-ahead log and flushes it to disk before the write is applied,
+// then passes the records it sets or removes on to the journal. The log is skipped when persistence is disabled.
+// The caller must hold the lock.
+func (u *MemoryFunctions) logEntry(entry walEntry) error {
+	if u.closed {
+		return errors.New("Memory storage closed")
+	}
+	if u.wal != nil {
+		encoded, err := json.Marshal(entry)
+		if err != nil {
+			return errors.New("Error encoding JSON")
+		}
+		_, err = u.wal.Write(append(encoded, '\n'))
+		if err != nil {
+			return err
+		}
+		if err = u.wal.Sync(); err != nil {
+			return err
+		}
+	}
+	if u.journal != nil {
+		u.journalEntry(entry)
+	}
+	return nil
+}
+
+// journalEntry passes the records set or removed by a log entry on to the journal.
+// The databases created or dropped aren't followed. The caller must hold the lock.
+func (u *MemoryFunctions) journalEntry(entry walEntry) {
+	switch entry.Op {
+	case walSet:
+		u.journal(entry.DB, entry.Key, entry.Data)
+	case walDelete:
+		u.journal(entry.DB, entry.Key, nil)
+	case walBatch:
+		for _, write := range entry.Writes {
+			u.journalEntry(write)
+		}
+	}
+}
+
+// Snapshot writes the current data and sequences to the snapshot and truncates the write-ahead log.
+// The snapshot is written atomically, so a crash leaves either the old or the new one.
+func (u *MemoryFunctions) Snapshot() error {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	if u.wal == nil {
+		return errors.New("Memory persistence is disabled")
+	}
+
+	err := writeJSONFile(snapshotPath(), memorySnapshot{Sequences: u.sequences, Databases: u.data})
+	if err != nil {
+		return err
+	}
+	return u.wal.Truncate(0)
+}
@@ -0,0 +1,324 @@
+package basefunctions
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"websays/database/basetypes"
+	"websays/httpHandler/basemodels"
+)
+
+// memoryRecord identifies a record of the in-memory data store.
+type memoryRecord struct {
+	db  basetypes.DBName // The database of the record.
+	key string           // The key of the record in the records of its database.
+}
+
+// memoryWrite is a write buffered by a MemoryTransaction.
+type memoryWrite struct {
+	memoryRecord             // The record written.
+	add          bool        // The write inserts a new record.
+	delete       bool        // The write removes the record.
+	data         interface{} // The data written, nil for deletes.
+}
+
+// memoryRead is the state of a record in the store when a MemoryTransaction first used it.
+type memoryRead struct {
+	exists bool        // The record was stored.
+	data   interface{} // The stored data, nil if the record wasn't stored.
+}
+
+// MemoryTransaction buffers the writes done on a MemoryFunctions store.
+// Reads through the transaction see the store with the buffered writes applied.
+// The records it writes carry the principal of the context it was begun with in their metadata.
+type MemoryTransaction struct {
+	functions *MemoryFunctions            // The store the writes are applied to.
+	writes    []memoryWrite               // The buffered writes in order.
+	reads     map[memoryRecord]memoryRead // The records the transaction used, as they were stored when first used.
+	finished  bool                        // Set once the transaction is committed or rolled back.
+	ctx       context.Context             // The context the transaction gives up under, whose principal its writes carry.
+}
+
+// check returns an error once the transaction is finished or its context is done.
+func (u *MemoryTransaction) check() error {
+	if u.finished {
+		return errors.New("Transaction already finished")
+	}
+	return u.ctx.Err()
+}
+
+// pending returns the last buffered write of each record of a database written by the transaction.
+func (u *MemoryTransaction) pending(dbName basetypes.DBName) map[string]memoryWrite {
+	writes := make(map[string]memoryWrite)
+	for _, write := range u.writes {
+		if write.db == dbName {
+			writes[write.key] = write
+		}
+	}
+	return writes
+}
+
+// lookup returns a record as the transaction sees it, with its buffered writes applied,
+// and whether it exists. The stored state of the record is remembered with observe.
+func (u *MemoryTransaction) lookup(dbName basetypes.DBName, key string) (interface{}, bool) {
+	u.functions.lock.Lock()
+	stored, exists := u.functions.data[dbName][key]
+	u.functions.lock.Unlock()
+
+	u.observe(dbName, key, stored, exists)
+	for i := len(u.writes) - 1; i >= 0; i-- {
+		if write := u.writes[i]; write.memoryRecord == (memoryRecord{dbName, key}) {
+			return write.data, !write.delete
+		}
+	}
+	return stored, exists
+}
+
+// find returns the key of the live record identified by a model's ID or of the first live record matching a filter,
+// with its data as the transaction sees it. Only a filter scans the records of the collection, under the lock.
+func (u *MemoryTransaction) find(dbName basetypes.DBName, collectionName basetypes.CollectionName, condition interface{}) (string, interface{}, error) {
+	if _, ok := condition.(basemodels.BaseModels); ok {
+		key, _ := memoryKey(collectionName, condition)
+		data, exists := u.lookup(dbName, key)
+		if !exists || isTrashed(data) {
+			return "", nil, errDataNotFound
+		}
+		return key, data, nil
+	}
+
+	filter, err := requireFilter(condition, documentFields(u.functions.models.get(collectionName)))
+	if err != nil {
+		return "", nil, err
+	}
+	pending := u.pending(dbName)
+
+	u.functions.lock.Lock()
+	defer u.functions.lock.Unlock()
+
+	records := u.functions.data[dbName]
+	keys := memoryCollectionKeys(records, collectionName)
+	// The records added by the transaction are searched too, in the order of their IDs
+	added := make(map[string]interface{})
+	for key, write := range pending {
+		if _, ok := records[key]; !ok && !write.delete {
+			added[key] = write.data
+		}
+	}
+	if len(added) > 0 {
+		suffix := "_" + string(collectionName)
+		keys = append(keys, memoryCollectionKeys(added, collectionName)...)
+		sort.Slice(keys, func(i, j int) bool {
+			return basetypes.ID(strings.TrimSuffix(keys[i], suffix)).Less(basetypes.ID(strings.TrimSuffix(keys[j], suffix)))
+		})
+	}
+
+	for _, key := range keys {
+		stored, exists := records[key]
+		data := stored
+		if write, ok := pending[key]; ok {
+			if write.delete {
+				continue
+			}
+			data = write.data
+		}
+		if isTrashed(data) {
+			continue
+		}
+		matched, err := matchesQuery(data, filter)
+		if err != nil {
+			return "", nil, err
+		}
+		if matched {
+			u.observe(dbName, key, stored, exists)
+			return key, data, nil
+		}
+	}
+	return "", nil, errDataNotFound
+}
+
+// observe remembers the stored state of a record the first time the transaction uses it,
+// so Commit can tell whether another caller changed it in the meantime.
+func (u *MemoryTransaction) observe(dbName basetypes.DBName, key string, stored interface{}, exists bool) {
+	record := memoryRecord{dbName, key}
+	if _, ok := u.reads[record]; ok {
+		return
+	}
+	if u.reads == nil {
+		u.reads = make(map[memoryRecord]memoryRead)
+	}
+	u.reads[record] = memoryRead{exists: exists, data: stored}
+}
+
+// Add buffers the insertion of data.
+func (u *MemoryTransaction) Add(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (basetypes.ID, error) {
+	key, err := memoryKey(collectionName, data)
+	if err != nil {
+		return "", err
+	}
+	if err := u.check(); err != nil {
+		return "", err
+	}
+	if _, exists := u.lookup(dbName, key); exists {
+		return "", errors.New("ID already exists")
+	}
+	u.writes = append(u.writes, memoryWrite{memoryRecord: memoryRecord{dbName, key}, add: true, data: liveRecord(stampCreated(u.ctx, firstVersion(data)))})
+	return recordID(data), nil
+}
+
+// FindOne retrieves data by ID or filter, including the writes of the transaction.
+func (u *MemoryTransaction) FindOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, condition interface{}) (interface{}, error) {
+	if err := u.check(); err != nil {
+		return nil, err
+	}
+	_, data, err := u.find(dbName, collectionName, condition)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// UpdateOne buffers the update of the record identified by data's ID or by a filter query.
+// With upsert, the insertion of data under its own ID is buffered when no record matches.
+func (u *MemoryTransaction) UpdateOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error {
+	if err := u.check(); err != nil {
+		return err
+	}
+	condition := query
+	if _, ok, _ := toFilter(query); !ok || query == nil {
+		condition = data
+	}
+	key, stored, err := u.find(dbName, collectionName, condition)
+	if errors.Is(err, errDataNotFound) && upsert {
+		key, err = memoryKey(collectionName, data)
+		if err != nil {
+			return err
+		}
+		// A trashed record with the same ID is replaced like by an update, which restores it
+		stored, ok := u.lookup(dbName, key)
+		if ok && !isTrashed(stored) {
+			return errors.New("ID already exists")
+		}
+		data, err = nextVersion(stored, data)
+		if err != nil {
+			return err
+		}
+		u.writes = append(u.writes, memoryWrite{memoryRecord: memoryRecord{dbName, key}, add: !ok, data: liveRecord(stampUpdated(u.ctx, stored, data))})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	data, err = nextVersion(stored, data)
+	if err != nil {
+		return err
+	}
+	u.writes = append(u.writes, memoryWrite{memoryRecord: memoryRecord{dbName, key}, data: liveRecord(stampUpdated(u.ctx, stored, data))})
+	return nil
+}
+
+// DeleteOne buffers the deletion of the record identified by ID or by a filter.
+// A record of a soft deletable model is moved to the trash instead, through an update of the record.
+func (u *MemoryTransaction) DeleteOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, condition interface{}) error {
+	if err := u.check(); err != nil {
+		return err
+	}
+	key, stored, err := u.find(dbName, collectionName, condition)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(stored, condition); err != nil {
+		return err
+	}
+	if softDeletes(stored, condition) {
+		u.writes = append(u.writes, memoryWrite{memoryRecord: memoryRecord{dbName, key}, data: withTrash(stored, time.Now().Unix())})
+		return nil
+	}
+	u.writes = append(u.writes, memoryWrite{memoryRecord: memoryRecord{dbName, key}, delete: true})
+	return nil
+}
+
+// Commit validates the buffered writes against the current store and applies all of them under the lock.
+// If another caller changed a record the transaction used since it was first used, nothing is applied
+// and ErrVersionConflict is returned, whether the record is versioned or not.
+func (u *MemoryTransaction) Commit() error {
+	if u.finished {
+		return errors.New("Transaction already finished")
+	}
+	if err := u.ctx.Err(); err != nil {
+		return err
+	}
+	u.finished = true
+
+	u.functions.lock.Lock()
+	defer u.functions.lock.Unlock()
+
+	for record, read := range u.reads {
+		data, exists := u.functions.data[record.db][record.key]
+		if exists != read.exists || !reflect.DeepEqual(data, read.data) {
+			return ErrVersionConflict
+		}
+	}
+
+	staged := make(map[memoryRecord]*memoryWrite)
+	exists := func(record memoryRecord) bool {
+		if write, ok := staged[record]; ok {
+			return !write.delete
+		}
+		_, ok := u.functions.data[record.db][record.key]
+		return ok
+	}
+	current := func(record memoryRecord) interface{} {
+		if write, ok := staged[record]; ok {
+			return write.data
+		}
+		return u.functions.data[record.db][record.key]
+	}
+	for i := range u.writes {
+		write := &u.writes[i]
+		if write.add && exists(write.memoryRecord) {
+			return errors.New("ID already exists")
+		}
+		if !write.add && !exists(write.memoryRecord) {
+			return errors.New("Data not found")
+		}
+		if !write.add && !write.delete && !followsVersion(current(write.memoryRecord), write.data) {
+			return ErrVersionConflict
+		}
+		staged[write.memoryRecord] = write
+	}
+
+	batch := walEntry{Op: walBatch}
+	for record, write := range staged {
+		if write.delete {
+			batch.Writes = append(batch.Writes, walEntry{Op: walDelete, DB: record.db, Key: record.key})
+		} else {
+			batch.Writes = append(batch.Writes, walEntry{Op: walSet, DB: record.db, Key: record.key, Data: write.data})
+		}
+	}
+	if err := u.functions.logEntry(batch); err != nil {
+		return err
+	}
+
+	for record, write := range staged {
+		if write.delete {
+			delete(u.functions.data[record.db], record.key)
+		} else {
+			u.functions.database(record.db)[record.key] = write.data
+			u.functions.reserveID(record.db, record.key)
+		}
+	}
+	return nil
+}
+
+// Rollback discards the buffered writes.
+func (u *MemoryTransaction) Rollback() error {
+	if u.finished {
+		return errors.New("Transaction already finished")
+	}
+	u.finished = true
+	u.writes = nil
+	return nil
+}
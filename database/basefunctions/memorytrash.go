@@ -0,0 +1,104 @@
+package basefunctions
+
+import (
+	"context"
+	"time"
+	"websays/database/basetypes"
+)
+
+// FindTrash retrieves a page of the trashed data of a collection from the in-memory data store, ordered by ID.
+func (u *MemoryFunctions) FindTrash(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	return u.FindTrashContext(context.Background(), dbName, collectionName, query, options)
+}
+
+// FindTrashContext is FindTrash, giving up once ctx is done.
+func (u *MemoryFunctions) FindTrashContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	filter, err := requireFilter(query, documentFields(u.models.get(collectionName)))
+	if err != nil {
+		return basetypes.FindResult{}, err
+	}
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if err := ctx.Err(); err != nil {
+		return basetypes.FindResult{}, err
+	}
+	return u.page(dbName, collectionName, filter, true, options)
+}
+
+// Restore moves the trashed record identified by ID, or the first trashed record matching a filter, out of the trash.
+func (u *MemoryFunctions) Restore(dbName basetypes.DBName, collectionName basetypes.CollectionName, condition interface{}) error {
+	return u.RestoreContext(context.Background(), dbName, collectionName, condition)
+}
+
+// RestoreContext is Restore, giving up before it takes effect once ctx is done.
+func (u *MemoryFunctions) RestoreContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, condition interface{}) error {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	key, err := u.findKey(dbName, collectionName, condition, true)
+	if err != nil {
+		return err
+	}
+	return u.set(dbName, key, withTrash(u.data[dbName][key], 0))
+}
+
+// Purge permanently removes the trashed record identified by ID, or the first trashed record matching a filter.
+func (u *MemoryFunctions) Purge(dbName basetypes.DBName, collectionName basetypes.CollectionName, condition interface{}) error {
+	return u.PurgeContext(context.Background(), dbName, collectionName, condition)
+}
+
+// PurgeContext is Purge, giving up before it takes effect once ctx is done.
+func (u *MemoryFunctions) PurgeContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, condition interface{}) error {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	key, err := u.findKey(dbName, collectionName, condition, true)
+	if err != nil {
+		return err
+	}
+	if err := u.logEntry(walEntry{Op: walDelete, DB: dbName, Key: key}); err != nil {
+		return err
+	}
+	delete(u.data[dbName], key)
+	return nil
+}
+
+// PurgeTrash permanently removes every record of a collection trashed before a time.
+// The removals are logged as a single batch, so a crash purges all of them or none.
+func (u *MemoryFunctions) PurgeTrash(dbName basetypes.DBName, collectionName basetypes.CollectionName, before time.Time) (int, error) {
+	return u.PurgeTrashContext(context.Background(), dbName, collectionName, before)
+}
+
+// PurgeTrashContext is PurgeTrash, giving up before it takes effect once ctx is done.
+func (u *MemoryFunctions) PurgeTrashContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, before time.Time) (int, error) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	batch := walEntry{Op: walBatch}
+	records := u.data[dbName]
+	for _, key := range u.collectionKeys(dbName, collectionName) {
+		if deletedAt := trashedAt(records[key]); deletedAt != 0 && deletedAt < before.Unix() {
+			batch.Writes = append(batch.Writes, walEntry{Op: walDelete, DB: dbName, Key: key})
+		}
+	}
+	if len(batch.Writes) == 0 {
+		return 0, nil
+	}
+	if err := u.logEntry(batch); err != nil {
+		return 0, err
+	}
+	for _, write := range batch.Writes {
+		delete(records, write.Key)
+	}
+	return len(batch.Writes), nil
+}
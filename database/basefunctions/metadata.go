@@ -0,0 +1,101 @@
+package basefunctions
+
+import (
+	"context"
+	"reflect"
+	"time"
+	"websays/database/basetypes"
+	"websays/httpHandler/basemodels"
+)
+
+// The fields of a document holding the metadata of its record.
+const (
+	createdAtField = "createdAt"
+	updatedAtField = "updatedAt"
+	createdByField = "createdBy"
+	updatedByField = "updatedBy"
+)
+
+// The sql columns holding the metadata of a record.
+const (
+	createdAtColumn = "created_at"
+	updatedAtColumn = "updated_at"
+	createdByColumn = "created_by"
+	updatedByColumn = "updated_by"
+)
+
+var timestampedType = reflect.TypeOf((*basemodels.TimestampedModels)(nil)).Elem()
+
+// isTimestamped returns whether the records of a model type carry their metadata.
+func isTimestamped(model reflect.Type) bool {
+	return model != nil && model.Implements(timestampedType)
+}
+
+// RecordMetadata returns the metadata of a stored record, either a timestamped model or a decoded document,
+// the zero metadata if it carries none.
+func RecordMetadata(record interface{}) basetypes.Metadata {
+	switch value := record.(type) {
+	case basemodels.TimestampedModels:
+		return value.GetMetadata()
+	case map[string]interface{}:
+		metadata := basetypes.Metadata{CreatedAt: toUnixTime(value[createdAtField]), UpdatedAt: toUnixTime(value[updatedAtField])}
+		metadata.CreatedBy, _ = value[createdByField].(string)
+		metadata.UpdatedBy, _ = value[updatedByField].(string)
+		return metadata
+	}
+	return basetypes.Metadata{}
+}
+
+// stampCreated returns a timestamped model created and updated now by the principal of ctx,
+// and any other data unchanged.
+func stampCreated(ctx context.Context, data interface{}) interface{} {
+	timestamped, ok := data.(basemodels.TimestampedModels)
+	if !ok {
+		return data
+	}
+	now, principal := time.Now().Unix(), basetypes.PrincipalFrom(ctx)
+	return timestamped.WithMetadata(basetypes.Metadata{CreatedAt: now, UpdatedAt: now, CreatedBy: principal, UpdatedBy: principal})
+}
+
+// stampUpdated returns a timestamped model replacing the stored record, keeping its creation, updated now by the principal of ctx,
+// and any other data unchanged. Without a stored record, as when an upsert inserts, the model is created now.
+func stampUpdated(ctx context.Context, stored interface{}, data interface{}) interface{} {
+	timestamped, ok := data.(basemodels.TimestampedModels)
+	if !ok {
+		return data
+	}
+	if stored == nil {
+		return stampCreated(ctx, data)
+	}
+	created := RecordMetadata(stored)
+	return timestamped.WithMetadata(basetypes.Metadata{
+		CreatedAt: created.CreatedAt,
+		UpdatedAt: time.Now().Unix(),
+		CreatedBy: created.CreatedBy,
+		UpdatedBy: basetypes.PrincipalFrom(ctx),
+	})
+}
+
+// updatedColumns returns the columns set by an update of a record of a timestamped model type
+// with the time and principal of the update added, and the columns unchanged for any other type.
+func updatedColumns(ctx context.Context, model reflect.Type, columns map[string]interface{}) map[string]interface{} {
+	if !isTimestamped(model) {
+		return columns
+	}
+	updated := make(map[string]interface{}, len(columns)+2)
+	for column, value := range columns {
+		updated[column] = value
+	}
+	updated[updatedAtColumn] = time.Now().Unix()
+	updated[updatedByColumn] = basetypes.PrincipalFrom(ctx)
+	return updated
+}
+
+// createdColumns returns the columns holding the creation of a record if data is a timestamped model,
+// which an upsert replacing the record keeps, and nil otherwise.
+func createdColumns(data interface{}) []string {
+	if _, ok := data.(basemodels.TimestampedModels); !ok {
+		return nil
+	}
+	return []string{createdAtColumn, createdByColumn}
+}
@@ -1,18 +1,19 @@
 package basefunctions
 
 import (
+	"context"
 	"database/sql"
-	"errors"
-	"log"
-	"reflect"
-	"strings"
-	"websays/database/baseconnections"
+	"time"
+	"websays/database/basedialects"
+	"websays/database/basemigrations"
 
 	"websays/database/basetypes"
 )
 
 // MySqlFunctions is a concrete implementation of the BaseFucntionsInterface for MySQL database.
+// The statements are generated by a basedialects.Builder with the MySQL dialect.
 type MySqlFunctions struct {
+	models collectionModels // Models of the collections registered by EnsureIndex.
 }
 
 // GetFunctions returns the MySqlFunctions instance as a BaseFucntionsInterface.
@@ -20,172 +21,247 @@ func (u *MySqlFunctions) GetFunctions() BaseFucntionsInterface {
 	return u
 }
 
+// getConn returns the MySQL connection pool, or the error of connecting to it.
+func (u *MySqlFunctions) getConn() (*sql.DB, error) {
+	return sqlConn(basetypes.MYSQL)
+}
+
+// builder returns the statement builder of the MySQL dialect.
+func (u *MySqlFunctions) builder() basedialects.Builder {
+	return basedialects.Builder{Dialect: basedialects.MySQL{}}
+}
+
 // EnsureIndex ensures an index for the specified database and collection in MySQL.
 // It takes the database name, collection name, and a sample data interface for table schema.
-// This function creates a table with the schema based on the data interface.
+// This function creates a table with the schema based on the data interface, unless the tables are left to the migrations,
+// and registers the data interface as the model the migrations diff compares the table against.
 func (u *MySqlFunctions) EnsureIndex(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) error {
-	conn := baseconnections.GetInstance().GetConnection(basetypes.MYSQL).GetDB(basetypes.MYSQL).(*sql.DB)
-	query := `CREATE TABLE IF NOT EXISTS ` + string(collectionName) + ` (`
-	dataValue := reflect.ValueOf(data)
-	dataType := dataValue.Type()
+	return u.EnsureIndexContext(context.Background(), dbName, collectionName, data)
+}
 
-	if dataType.Kind() != reflect.Struct {
-		return errors.New("Required a struct for data")
+// EnsureIndexContext is EnsureIndex, running its statements with ctx, so MySQL cancels them once ctx is done.
+func (u *MySqlFunctions) EnsureIndexContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) error {
+	basemigrations.GetInstance().RegisterModel(string(collectionName), data)
+	u.models.register(collectionName, data)
+	if basemigrations.GetInstance().ManagesTables() {
+		return ctx.Err()
 	}
-
-	columns := ""
-
-	for i := 0; i < dataType.NumField(); i++ {
-		field := dataType.Field(i)
-		tags := strings.Split(field.Tag.Get("db"), ",")
-
-		if columns != "" {
-			columns += ","
-		}
-
-		columns += strings.Join(tags, " ")
+	conn, err := u.getConn()
+	if err != nil {
+		return err
 	}
-
-	query += columns + ");"
-	_, err := conn.Exec(query)
-	return err
-}
-
-// GetNextID returns the next available ID for MySQL storage.
-func (u *MySqlFunctions) GetNextID() int {
-	return 0
+	return sqlEnsureTable(ctx, conn, u.builder(), collectionName, data)
 }
 
 // Add inserts data into the MySQL database.
 // It takes the database name, collection name, and data interface to be inserted.
 // This function dynamically generates an SQL INSERT statement based on the data interface and inserts the data.
-func (u *MySqlFunctions) Add(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (int, error) {
-	conn := baseconnections.GetInstance().GetConnection(basetypes.MYSQL).GetDB(basetypes.MYSQL).(*sql.DB)
-	query := "INSERT INTO " + string(collectionName)
-
-	dataValue := reflect.ValueOf(data)
-	dataType := dataValue.Type()
+func (u *MySqlFunctions) Add(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (basetypes.ID, error) {
+	return u.AddContext(context.Background(), dbName, collectionName, data)
+}
 
-	if dataType.Kind() != reflect.Struct {
-		return 0, errors.New("Required a struct for data")
+// AddContext is Add, running its statements with ctx, so MySQL cancels them once ctx is done.
+func (u *MySqlFunctions) AddContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (basetypes.ID, error) {
+	conn, err := u.getConn()
+	if err != nil {
+		return "", err
 	}
+	return sqlInsert(ctx, conn, u.builder(), collectionName, data)
+}
 
-	var columns []string
-	var placeholders []string
-	values := make([]interface{}, 0)
-
-	for i := 0; i < dataType.NumField(); i++ {
-		field := dataType.Field(i)
-		tag := strings.Split(field.Tag.Get("db"), ",")[0]
+// FindOne retrieves data from the MySQL database based on a condition.
+// It takes the database name, collection name, and a condition map or basefilters.Filter to filter data.
+// This function dynamically generates an SQL SELECT statement based on the condition and retrieves one record,
+// filled into the model registered for the collection by EnsureIndex. It returns sql.ErrNoRows if no record matches.
+func (u *MySqlFunctions) FindOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) (interface{}, error) {
+	return u.FindOneContext(context.Background(), dbName, collectionName, cond)
+}
 
-		if tag == "" {
-			continue
-		}
+// FindOneContext is FindOne, running its statements with ctx, so MySQL cancels them once ctx is done.
+func (u *MySqlFunctions) FindOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) (interface{}, error) {
+	conn, err := u.getConn()
+	if err != nil {
+		return nil, err
+	}
+	return sqlFindOne(ctx, conn, u.builder(), collectionName, u.models.get(collectionName), cond)
+}
 
-		value := dataValue.Field(i).Interface()
-		values = append(values, value)
+// FindMany retrieves a page of data from the MySQL database based on a condition.
+// It takes the database name, collection name, an optional condition map or basefilters.Filter and the paging options.
+// This function counts the matching records and selects one page of them with LIMIT and OFFSET,
+// ordered by the first column of the table.
+func (u *MySqlFunctions) FindMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	return u.FindManyContext(context.Background(), dbName, collectionName, cond, options)
+}
 
-		columns = append(columns, tag)
-		placeholders = append(placeholders, "?")
+// FindManyContext is FindMany, running its statements with ctx, so MySQL cancels them once ctx is done.
+func (u *MySqlFunctions) FindManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	conn, err := u.getConn()
+	if err != nil {
+		return basetypes.FindResult{}, err
 	}
+	return sqlFindMany(ctx, conn, u.builder(), collectionName, u.models.get(collectionName), cond, options)
+}
 
-	query += "(" + strings.Join(columns, ", ") + ")"
-	query += " VALUES(" + strings.Join(placeholders, ", ") + ")"
+// UpdateOne updates data in the MySQL database based on a query condition.
+// It takes the database name, collection name, a query map or basefilters.Filter for filtering, data to update, and an upsert flag.
+// This function dynamically generates an SQL UPDATE statement based on the query condition and updates one record.
+// With upsert, data is a model inserted or replacing the record with the same primary key through INSERT ... ON DUPLICATE KEY UPDATE.
+func (u *MySqlFunctions) UpdateOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error {
+	return u.UpdateOneContext(context.Background(), dbName, collectionName, query, data, upsert)
+}
 
-	res, err := conn.Exec(query, values...)
-	lastId, _ := res.LastInsertId()
-	return int(lastId), err
+// UpdateOneContext is UpdateOne, running its statements with ctx, so MySQL cancels them once ctx is done.
+func (u *MySqlFunctions) UpdateOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error {
+	conn, err := u.getConn()
+	if err != nil {
+		return err
+	}
+	return sqlUpdateOne(ctx, conn, u.builder(), collectionName, u.models.get(collectionName), query, data, upsert)
 }
 
-// FindOne retrieves data from the MySQL database based on a condition.
-// It takes the database name, collection name, and a condition map to filter data.
-// This function dynamically generates an SQL SELECT statement based on the condition and retrieves one record.
-func (u *MySqlFunctions) FindOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) (interface{}, error) {
+// DeleteOne deletes data from the MySQL database based on a query condition.
+// It takes the database name, collection name, and a query map or basefilters.Filter for filtering data to delete.
+// This function dynamically generates an SQL DELETE statement based on the query condition and deletes one record.
+// On the table of a soft deletable model it generates an UPDATE moving the record to the trash instead.
+func (u *MySqlFunctions) DeleteOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) error {
+	return u.DeleteOneContext(context.Background(), dbName, collectionName, cond)
+}
 
-	condition := cond.(map[string]interface{})
-	conn := baseconnections.GetInstance().GetConnection(basetypes.MYSQL).GetDB(basetypes.MYSQL).(*sql.DB)
-	query := "SELECT * FROM " + string(collectionName)
+// DeleteOneContext is DeleteOne, running its statements with ctx, so MySQL cancels them once ctx is done.
+func (u *MySqlFunctions) DeleteOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) error {
+	conn, err := u.getConn()
+	if err != nil {
+		return err
+	}
+	return sqlDeleteOne(ctx, conn, u.builder(), collectionName, u.models.get(collectionName), cond)
+}
 
-	whereClause := ""
-	values := make([]interface{}, 0)
+// AddMany inserts several records into the MySQL database with multi-row INSERT statements of up to 500 rows,
+// and reports the ID of each inserted record. If a statement fails, its rows are inserted one by one,
+// so only the failing records are reported as such.
+func (u *MySqlFunctions) AddMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, data []interface{}) (basetypes.BulkResult, error) {
+	return u.AddManyContext(context.Background(), dbName, collectionName, data)
+}
 
-	for key, val := range condition {
-		if whereClause != "" {
-			whereClause += " AND "
-		} else {
-			whereClause += " WHERE "
-		}
-		whereClause += key + "= ? "
-		values = append(values, val)
+// AddManyContext is AddMany, running its statements with ctx, so MySQL cancels them once ctx is done.
+func (u *MySqlFunctions) AddManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data []interface{}) (basetypes.BulkResult, error) {
+	conn, err := u.getConn()
+	if err != nil {
+		return bulkFailed(len(data), err)
 	}
+	return sqlAddMany(ctx, conn, u.builder(), collectionName, data)
+}
 
-	query += whereClause
-	log.Println(query, values)
-	rows, err := conn.Query(query, values...)
+// UpdateMany applies several updates to the MySQL database, each like UpdateOne, committing them in transactions of up to 500 updates.
+func (u *MySqlFunctions) UpdateMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, updates []basetypes.BulkUpdate) (basetypes.BulkResult, error) {
+	return u.UpdateManyContext(context.Background(), dbName, collectionName, updates)
+}
 
-	return rows, err
+// UpdateManyContext is UpdateMany, running its statements with ctx, so MySQL cancels them once ctx is done.
+func (u *MySqlFunctions) UpdateManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, updates []basetypes.BulkUpdate) (basetypes.BulkResult, error) {
+	conn, err := u.getConn()
+	if err != nil {
+		return bulkFailed(len(updates), err)
+	}
+	return sqlUpdateMany(ctx, conn, u.builder(), collectionName, u.models.get(collectionName), updates)
 }
 
-// UpdateOne updates data in the MySQL database based on a query condition.
-// It takes the database name, collection name, a query map for filtering, data to update, and an upsert flag.
-// This function dynamically generates an SQL UPDATE statement based on the query condition and updates one record.
-func (u *MySqlFunctions) UpdateOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error {
-	conn := baseconnections.GetInstance().GetConnection(basetypes.MYSQL).GetDB(basetypes.MYSQL).(*sql.DB)
-	dbQuery := "UPDATE " + string(collectionName) + " SET "
+// DeleteMany deletes several records from the MySQL database, each like DeleteOne, committing them in transactions of up to 500 deletions.
+func (u *MySqlFunctions) DeleteMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, queries []interface{}) (basetypes.BulkResult, error) {
+	return u.DeleteManyContext(context.Background(), dbName, collectionName, queries)
+}
+
+// DeleteManyContext is DeleteMany, running its statements with ctx, so MySQL cancels them once ctx is done.
+func (u *MySqlFunctions) DeleteManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, queries []interface{}) (basetypes.BulkResult, error) {
+	conn, err := u.getConn()
+	if err != nil {
+		return bulkFailed(len(queries), err)
+	}
+	return sqlDeleteMany(ctx, conn, u.builder(), collectionName, u.models.get(collectionName), queries)
+}
 
-	values := make([]interface{}, 0)
-	dataMap := data.(map[string]interface{})
-	setClause := ""
+// FindTrash retrieves a page of the trashed records of a collection from the MySQL database, ordered by the first column of the table.
+// It fails for the table of a model that isn't soft deletable.
+func (u *MySqlFunctions) FindTrash(dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	return u.FindTrashContext(context.Background(), dbName, collectionName, cond, options)
+}
 
-	for key, val := range dataMap {
-		if setClause != "" {
-			setClause += "," + key + "= ? "
-		} else {
-			setClause += key + "= ?"
-		}
-		values = append(values, val)
+// FindTrashContext is FindTrash, running its statements with ctx, so MySQL cancels once ctx is done.
+func (u *MySqlFunctions) FindTrashContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	conn, err := u.getConn()
+	if err != nil {
+		return basetypes.FindResult{}, err
 	}
+	return sqlFindTrash(ctx, conn, u.builder(), collectionName, u.models.get(collectionName), cond, options)
+}
 
-	condition := query.(map[string]interface{})
-	whereClause := ""
-	for key, val := range condition {
-		if whereClause != "" {
-			whereClause += " AND "
-		} else {
-			whereClause += " WHERE "
-		}
-		whereClause += key + "= ? "
-		values = append(values, val)
+// Restore moves the first trashed record matching a condition in the MySQL database out of the trash.
+func (u *MySqlFunctions) Restore(dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) error {
+	return u.RestoreContext(context.Background(), dbName, collectionName, cond)
+}
+
+// RestoreContext is Restore, running its statements with ctx, so MySQL cancels once ctx is done.
+func (u *MySqlFunctions) RestoreContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) error {
+	conn, err := u.getConn()
+	if err != nil {
+		return err
 	}
+	return sqlRestore(ctx, conn, u.builder(), collectionName, u.models.get(collectionName), cond)
+}
 
-	dbQuery += setClause + whereClause + " LIMIT 1"
-	_, err := conn.Exec(dbQuery, values...)
-	return err
+// Purge permanently deletes the first trashed record matching a condition from the MySQL database.
+func (u *MySqlFunctions) Purge(dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) error {
+	return u.PurgeContext(context.Background(), dbName, collectionName, cond)
 }
 
-// DeleteOne deletes data from the MySQL database based on a query condition.
-// It takes the database name, collection name, and a query map for filtering data to delete.
-// This function dynamically generates an SQL DELETE statement based on the query condition and deletes one record.
-func (u *MySqlFunctions) DeleteOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) error {
-	conn := baseconnections.GetInstance().GetConnection(basetypes.MYSQL).GetDB(basetypes.MYSQL).(*sql.DB)
-	condition := cond.(map[string]interface{})
-	query := "DELETE FROM " + string(collectionName)
-	whereClause := ""
-	values := make([]interface{}, 0)
+// PurgeContext is Purge, running its statements with ctx, so MySQL cancels once ctx is done.
+func (u *MySqlFunctions) PurgeContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) error {
+	conn, err := u.getConn()
+	if err != nil {
+		return err
+	}
+	return sqlPurge(ctx, conn, u.builder(), collectionName, u.models.get(collectionName), cond)
+}
 
-	for key, val := range condition {
-		if whereClause != "" {
-			whereClause += " AND "
-		} else {
-			whereClause += " WHERE "
-		}
-		whereClause += key + "= ? "
-		values = append(values, val)
+// PurgeTrash permanently deletes every record of a collection trashed before a time from the MySQL database in one statement.
+func (u *MySqlFunctions) PurgeTrash(dbName basetypes.DBName, collectionName basetypes.CollectionName, before time.Time) (int, error) {
+	return u.PurgeTrashContext(context.Background(), dbName, collectionName, before)
+}
+
+// PurgeTrashContext is PurgeTrash, running its statements with ctx, so MySQL cancels once ctx is done.
+func (u *MySqlFunctions) PurgeTrashContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, before time.Time) (int, error) {
+	conn, err := u.getConn()
+	if err != nil {
+		return 0, err
 	}
+	return sqlPurgeTrash(ctx, conn, u.builder(), collectionName, u.models.get(collectionName), before)
+}
 
-	query += whereClause + " LIMIT 1"
+// Iterate returns an iterator over the records matching a condition map or basefilters.Filter in the MySQL database,
+// read as models of the collection. The query runs with ctx, cancelling it stops the iteration. The iterator must be closed.
+func (u *MySqlFunctions) Iterate(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) (*RecordIterator, error) {
+	conn, err := u.getConn()
+	if err != nil {
+		return nil, err
+	}
+	return sqlIterate(ctx, conn, u.builder(), collectionName, u.models.get(collectionName), cond)
+}
 
-	_, err := conn.Query(query, values...)
+// Begin starts a MySQL transaction backed by sql.Tx.
+func (u *MySqlFunctions) Begin() (TransactionInterface, error) {
+	return u.BeginContext(context.Background())
+}
 
-	return err
+// BeginContext is Begin, rolling the transaction back if ctx is done before it is committed.
+func (u *MySqlFunctions) BeginContext(ctx context.Context) (TransactionInterface, error) {
+	conn, err := u.getConn()
+	if err != nil {
+		return nil, err
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &MySqlTransaction{functions: u, tx: tx, ctx: ctx}, nil
 }
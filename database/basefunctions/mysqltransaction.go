@@ -0,0 +1,45 @@
+package basefunctions
+
+import (
+	"context"
+	"database/sql"
+	"websays/database/basetypes"
+)
+
+// MySqlTransaction is a MySQL transaction backed by sql.Tx.
+// It runs the same statements as MySqlFunctions on the transaction instead of the connection pool.
+type MySqlTransaction struct {
+	functions *MySqlFunctions // The functions generating the statements.
+	tx        *sql.Tx         // The underlying sql transaction.
+	ctx       context.Context // The context the statements run under.
+}
+
+// Add inserts data into the MySQL database inside the transaction.
+func (u *MySqlTransaction) Add(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (basetypes.ID, error) {
+	return sqlInsert(u.ctx, u.tx, u.functions.builder(), collectionName, data)
+}
+
+// FindOne retrieves data from the MySQL database inside the transaction.
+func (u *MySqlTransaction) FindOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) (interface{}, error) {
+	return sqlFindOne(u.ctx, u.tx, u.functions.builder(), collectionName, u.functions.models.get(collectionName), cond)
+}
+
+// UpdateOne updates data in the MySQL database inside the transaction.
+func (u *MySqlTransaction) UpdateOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error {
+	return sqlUpdateOne(u.ctx, u.tx, u.functions.builder(), collectionName, u.functions.models.get(collectionName), query, data, upsert)
+}
+
+// DeleteOne deletes data from the MySQL database inside the transaction.
+func (u *MySqlTransaction) DeleteOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) error {
+	return sqlDeleteOne(u.ctx, u.tx, u.functions.builder(), collectionName, u.functions.models.get(collectionName), cond)
+}
+
+// Commit commits the sql transaction.
+func (u *MySqlTransaction) Commit() error {
+	return u.tx.Commit()
+}
+
+// Rollback rolls the sql transaction back.
+func (u *MySqlTransaction) Rollback() error {
+	return u.tx.Rollback()
+}
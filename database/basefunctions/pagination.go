@@ -0,0 +1,45 @@
+package basefunctions
+
+import (
+	"encoding/json"
+	"errors"
+	"websays/database/basetypes"
+)
+
+// toDocument converts a record into its JSON field map so memory and file records
+// can be matched against a filter regardless of the model type.
+func toDocument(data interface{}) (map[string]interface{}, error) {
+	if document, ok := data.(map[string]interface{}); ok {
+		return document, nil
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, errors.New("Error encoding JSON")
+	}
+	document := make(map[string]interface{})
+	err = json.Unmarshal(encoded, &document)
+	if err != nil {
+		return nil, errors.New("Error decoding JSON")
+	}
+	return document, nil
+}
+
+// paginate slices the matching records into the page requested by options.
+func paginate(records []interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	offset, err := options.GetOffset()
+	if err != nil {
+		return basetypes.FindResult{}, err
+	}
+	total := len(records)
+	result := basetypes.FindResult{Data: []interface{}{}, Total: total}
+	if offset >= total {
+		return result, nil
+	}
+	end := offset + options.GetLimit()
+	if end > total {
+		end = total
+	}
+	result.Data = records[offset:end]
+	result.NextCursor = options.NextCursor(offset, total)
+	return result, nil
+}
@@ -0,0 +1,87 @@
+package basefunctions
+
+import (
+	"errors"
+	"reflect"
+	"websays/database/basedialects"
+	"websays/database/basefilters"
+)
+
+// toFilter converts the query argument accepted by the storage functions into a filter.
+// It accepts a basefilters.Filter, a pointer to one, an equality condition map or nil, which matches everything.
+// ok is false when the query is of another kind, e.g. a model identified by its ID.
+func toFilter(query interface{}) (filter basefilters.Filter, ok bool, err error) {
+	switch typed := query.(type) {
+	case nil:
+		return basefilters.And(), true, nil
+	case basefilters.Filter:
+		filter = typed
+	case *basefilters.Filter:
+		if typed == nil {
+			return basefilters.And(), true, nil
+		}
+		filter = *typed
+	case map[string]interface{}:
+		filter = basefilters.FromMap(typed)
+	default:
+		return filter, false, nil
+	}
+	return filter, true, filter.Validate()
+}
+
+// matchesQuery reports whether a record matches the filter.
+func matchesQuery(data interface{}, filter basefilters.Filter) (bool, error) {
+	document, err := toDocument(data)
+	if err != nil {
+		return false, err
+	}
+	return filter.Evaluate(document)
+}
+
+// requireFilter converts the query into a compiled filter and fails for queries of another kind.
+// Unless fields is nil, the fields of the filter are resolved through it and a field it doesn't map is rejected.
+func requireFilter(query interface{}, fields map[string]string) (basefilters.Filter, error) {
+	filter, ok, err := toFilter(query)
+	if err != nil {
+		return filter, err
+	}
+	if !ok {
+		return filter, errors.New("Query type not supported")
+	}
+	if fields != nil {
+		filter, err = filter.Resolve(fields)
+		if err != nil {
+			return filter, err
+		}
+	}
+	return filter.Compile()
+}
+
+// documentFields maps the JSON name of each field of a model type to itself, the name its documents keep it under,
+// so a filter on the records of the model can only use its fields. It returns nil for no model, leaving the fields unchecked.
+func documentFields(model reflect.Type) map[string]string {
+	if model == nil {
+		return nil
+	}
+	fields := make(map[string]string, model.NumField())
+	for i := 0; i < model.NumField(); i++ {
+		if name := basedialects.JSONName(model.Field(i)); name != "" {
+			fields[name] = name
+		}
+	}
+	return fields
+}
+
+// columnFields maps the JSON name of each db tagged field of a model type to its sql column,
+// so a filter uses the same field names in the sql storages as in the document storages.
+// It returns nil for no model, leaving the fields of the filter as column names.
+func columnFields(model reflect.Type) map[string]string {
+	if model == nil {
+		return nil
+	}
+	fields, err := basedialects.FieldColumns(reflect.Zero(model).Interface())
+	if err != nil {
+		return nil
+	}
+	return fields
+}
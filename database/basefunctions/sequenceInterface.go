@@ -0,0 +1,26 @@
+package basefunctions
+
+import (
+	"context"
+	"websays/database/basetypes"
+)
+
+/*
+ * SequenceInterface is implemented by the storages handing out integer IDs from a sequence per collection,
+ * used by the sequence ID strategy. The sql storages don't implement it, as they assign the IDs on insert,
+ * from an AUTO_INCREMENT column or from the sequence of a table keyed by a text column.
+ * It is kept apart from BaseFucntionsInterface like TrashInterface.
+ */
+type SequenceInterface interface {
+	// NextSequence returns the next integer ID of the sequence of a collection.
+	// The sequence starts at 1, never hands out an ID twice and moves past the integer IDs stored in the collection,
+	// the inserted and upserted ones included, so a generated ID never collides with a stored one.
+	// Parameters:
+	//   - dbName: The name of the database.
+	//   - collectionName: The name of the collection.
+	// Returns the ID and an error if the operation fails.
+	NextSequence(dbName basetypes.DBName, collectionName basetypes.CollectionName) (int64, error)
+
+	// NextSequenceContext is NextSequence, giving up once ctx is done.
+	NextSequenceContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName) (int64, error)
+}
@@ -0,0 +1,176 @@
+package basefunctions
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"websays/database/basedialects"
+	"websays/database/basetypes"
+)
+
+// sqlBatchSize is the largest number of rows of a multi-row INSERT, and of statements run in one transaction, by the bulk operations.
+const sqlBatchSize = 500
+
+// sqlAddMany inserts data with multi-row INSERT statements of up to sqlBatchSize rows, versioned models at version 1
+// and timestamped models created now.
+// Consecutive models setting the same columns share a statement. If a statement fails, which inserts none of its rows,
+// its rows are inserted one by one, so only the failing ones are reported as such.
+// The ID of each inserted row is the one its model carries, or the one generated by the database or the sequence of its table.
+func sqlAddMany(ctx context.Context, conn sqlExecutor, builder basedialects.Builder, collectionName basetypes.CollectionName, data []interface{}) (basetypes.BulkResult, error) {
+	rows := make([]interface{}, len(data))
+	statements := make([]basedialects.Statement, len(data))
+	ids := make([]basetypes.ID, len(data))
+	errs := make([]error, len(data))
+	for i, item := range data {
+		if item, errs[i] = sqlWithID(ctx, conn, builder, collectionName, item); errs[i] != nil {
+			continue
+		}
+		rows[i] = liveRecord(stampCreated(ctx, firstVersion(item)))
+		statements[i], errs[i] = builder.Insert(string(collectionName), rows[i])
+	}
+
+	var cancelled error
+	for start := 0; start < len(data); {
+		if errs[start] != nil {
+			start++
+			continue
+		}
+		if cancelled = ctx.Err(); cancelled != nil {
+			for i := start; i < len(data); i++ {
+				if errs[i] == nil {
+					errs[i] = cancelled
+				}
+			}
+			break
+		}
+		end := start + 1
+		for end < len(data) && end-start < sqlBatchSize && errs[end] == nil && statements[end].Query == statements[start].Query {
+			end++
+		}
+		sqlInsertRun(ctx, conn, builder, collectionName, rows[start:end], statements[start:end], ids[start:end], errs[start:end])
+		start = end
+	}
+
+	result := basetypes.NewBulkResult(len(data))
+	for i := range data {
+		result.Set(i, ids[i], errs[i])
+	}
+	return result, cancelled
+}
+
+// sqlInsertRun inserts rows setting the same columns with one multi-row INSERT statement, falling back to their single
+// statements if it fails, and fills in the ID of each inserted row or the error it failed with.
+func sqlInsertRun(ctx context.Context, conn sqlExecutor, builder basedialects.Builder, collectionName basetypes.CollectionName, rows []interface{}, statements []basedialects.Statement, ids []basetypes.ID, errs []error) {
+	if len(rows) > 1 {
+		statement, err := builder.InsertMany(string(collectionName), rows)
+		if err == nil {
+			var generated []basetypes.ID
+			generated, err = sqlExecInsert(ctx, conn, builder, statement, len(rows))
+			if err == nil {
+				for i := range rows {
+					ids[i] = sqlInsertedID(rows[i], generated[i])
+				}
+				return
+			}
+		}
+	}
+	for i := range rows {
+		generated, err := sqlExecInsert(ctx, conn, builder, statements[i], 1)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		ids[i] = sqlInsertedID(rows[i], generated[0])
+	}
+}
+
+// sqlExecInsert runs an INSERT statement of rows rows and returns the IDs generated for them in order.
+func sqlExecInsert(ctx context.Context, conn sqlExecutor, builder basedialects.Builder, statement basedialects.Statement, rows int) ([]basetypes.ID, error) {
+	ids := make([]basetypes.ID, 0, rows)
+	if statement.ReturnsID {
+		returned, err := conn.QueryContext(ctx, statement.Query, statement.Values...)
+		if err != nil {
+			return nil, err
+		}
+		defer returned.Close()
+		for returned.Next() {
+			var id basetypes.ID
+			if err := returned.Scan(&id); err != nil {
+				return nil, err
+			}
+			ids = append(ids, id)
+		}
+		return ids, returned.Err()
+	}
+
+	res, err := conn.ExecContext(ctx, statement.Query, statement.Values...)
+	if err != nil {
+		return nil, err
+	}
+	lastID, _ := res.LastInsertId()
+	first := builder.Dialect.FirstInsertID(lastID, rows)
+	for i := 0; i < rows; i++ {
+		ids = append(ids, basetypes.IntID(first+int64(i)))
+	}
+	return ids, nil
+}
+
+// sqlInsertedID returns the ID of an inserted row, the one carried by its model or else the generated one.
+func sqlInsertedID(row interface{}, generated basetypes.ID) basetypes.ID {
+	if id := recordID(row); id != "" {
+		return id
+	}
+	return generated
+}
+
+// sqlUpdateMany runs the statements of several updates, each like sqlUpdateOne, in transactions of up to sqlBatchSize updates.
+func sqlUpdateMany(ctx context.Context, conn *sql.DB, builder basedialects.Builder, collectionName basetypes.CollectionName, model reflect.Type, updates []basetypes.BulkUpdate) (basetypes.BulkResult, error) {
+	return sqlBatches(ctx, conn, len(updates), func(tx sqlExecutor, index int) error {
+		update := updates[index]
+		return sqlUpdateOne(ctx, tx, builder, collectionName, model, update.Query, update.Data, update.Upsert)
+	})
+}
+
+// sqlDeleteMany runs the statements of several deletions, each like sqlDeleteOne, in transactions of up to sqlBatchSize deletions.
+func sqlDeleteMany(ctx context.Context, conn *sql.DB, builder basedialects.Builder, collectionName basetypes.CollectionName, model reflect.Type, queries []interface{}) (basetypes.BulkResult, error) {
+	return sqlBatches(ctx, conn, len(queries), func(tx sqlExecutor, index int) error {
+		return sqlDeleteOne(ctx, tx, builder, collectionName, model, queries[index])
+	})
+}
+
+// sqlBatches calls apply for each of size items in order, in transactions of up to sqlBatchSize items,
+// so a batch is committed at once instead of after every statement. A failing statement only fails its own item,
+// the rest of its transaction is still committed. If a transaction can't be committed, every item of it fails.
+// Once ctx is done the remaining items fail with its error, which is returned too.
+func sqlBatches(ctx context.Context, conn *sql.DB, size int, apply func(tx sqlExecutor, index int) error) (basetypes.BulkResult, error) {
+	result := basetypes.NewBulkResult(size)
+	errs := make([]error, sqlBatchSize)
+	for start := 0; start < size; start += sqlBatchSize {
+		if err := ctx.Err(); err != nil {
+			result.FailFrom(start, err)
+			return result, err
+		}
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			result.FailFrom(start, err)
+			return result, err
+		}
+
+		end := start + sqlBatchSize
+		if end > size {
+			end = size
+		}
+		for i := start; i < end; i++ {
+			errs[i-start] = apply(tx, i)
+		}
+		committed := tx.Commit()
+		for i := start; i < end; i++ {
+			if err := errs[i-start]; err != nil {
+				result.Set(i, "", err)
+			} else {
+				result.Set(i, "", committed)
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,250 @@
+package basefunctions
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"websays/database/basedialects"
+	"websays/database/basetypes"
+)
+
+// SqliteFunctions is a concrete implementation of the BaseFucntionsInterface for SQLite database.
+// It generates its statements with the SQLite dialect from the same models and filters as MySqlFunctions,
+// so a controller can be pointed at a local SQLite file instead of a MySQL server.
+type SqliteFunctions struct {
+	models collectionModels // Models of the collections registered by EnsureIndex.
+}
+
+// GetFunctions returns the SqliteFunctions instance as a BaseFucntionsInterface.
+func (u *SqliteFunctions) GetFunctions() BaseFucntionsInterface {
+	return u
+}
+
+// getConn returns the SQLite connection pool, or the error of connecting to it.
+func (u *SqliteFunctions) getConn() (*sql.DB, error) {
+	return sqlConn(basetypes.SQLITE)
+}
+
+// builder returns the statement builder of the SQLite dialect.
+func (u *SqliteFunctions) builder() basedialects.Builder {
+	return basedialects.Builder{Dialect: basedialects.SQLite{}}
+}
+
+// EnsureIndex ensures a table for the specified collection in SQLite.
+// It reads the same `db` struct tags as MySqlFunctions.EnsureIndex, the dialect translates the MySQL specific parts:
+// an AUTO_INCREMENT primary key becomes an INTEGER PRIMARY KEY AUTOINCREMENT column.
+func (u *SqliteFunctions) EnsureIndex(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) error {
+	return u.EnsureIndexContext(context.Background(), dbName, collectionName, data)
+}
+
+// EnsureIndexContext is EnsureIndex, running its statements with ctx, so SQLite interrupts them once ctx is done.
+func (u *SqliteFunctions) EnsureIndexContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) error {
+	u.models.register(collectionName, data)
+	conn, err := u.getConn()
+	if err != nil {
+		return err
+	}
+	return sqlEnsureTable(ctx, conn, u.builder(), collectionName, data)
+}
+
+// Add inserts data into the SQLite database and returns the ID generated for it.
+func (u *SqliteFunctions) Add(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (basetypes.ID, error) {
+	return u.AddContext(context.Background(), dbName, collectionName, data)
+}
+
+// AddContext is Add, running its statements with ctx, so SQLite interrupts them once ctx is done.
+func (u *SqliteFunctions) AddContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (basetypes.ID, error) {
+	conn, err := u.getConn()
+	if err != nil {
+		return "", err
+	}
+	return sqlInsert(ctx, conn, u.builder(), collectionName, data)
+}
+
+// FindOne retrieves data from the SQLite database based on a condition map or basefilters.Filter.
+// Like MySqlFunctions.FindOne it returns the model registered for the collection, or sql.ErrNoRows if no record matches.
+func (u *SqliteFunctions) FindOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) (interface{}, error) {
+	return u.FindOneContext(context.Background(), dbName, collectionName, cond)
+}
+
+// FindOneContext is FindOne, running its statements with ctx, so SQLite interrupts them once ctx is done.
+func (u *SqliteFunctions) FindOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) (interface{}, error) {
+	conn, err := u.getConn()
+	if err != nil {
+		return nil, err
+	}
+	return sqlFindOne(ctx, conn, u.builder(), collectionName, u.models.get(collectionName), cond)
+}
+
+// FindMany retrieves a page of data from the SQLite database based on an optional condition,
+// ordered by the first column of the table.
+func (u *SqliteFunctions) FindMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	return u.FindManyContext(context.Background(), dbName, collectionName, cond, options)
+}
+
+// FindManyContext is FindMany, running its statements with ctx, so SQLite interrupts them once ctx is done.
+func (u *SqliteFunctions) FindManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	conn, err := u.getConn()
+	if err != nil {
+		return basetypes.FindResult{}, err
+	}
+	return sqlFindMany(ctx, conn, u.builder(), collectionName, u.models.get(collectionName), cond, options)
+}
+
+// UpdateOne updates the first record matching a query condition in the SQLite database with a data map.
+func (u *SqliteFunctions) UpdateOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error {
+	return u.UpdateOneContext(context.Background(), dbName, collectionName, query, data, upsert)
+}
+
+// UpdateOneContext is UpdateOne, running its statements with ctx, so SQLite interrupts them once ctx is done.
+func (u *SqliteFunctions) UpdateOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error {
+	conn, err := u.getConn()
+	if err != nil {
+		return err
+	}
+	return sqlUpdateOne(ctx, conn, u.builder(), collectionName, u.models.get(collectionName), query, data, upsert)
+}
+
+// DeleteOne deletes the first record matching a condition from the SQLite database,
+// or moves it to the trash on the table of a soft deletable model.
+func (u *SqliteFunctions) DeleteOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) error {
+	return u.DeleteOneContext(context.Background(), dbName, collectionName, cond)
+}
+
+// DeleteOneContext is DeleteOne, running its statements with ctx, so SQLite interrupts them once ctx is done.
+func (u *SqliteFunctions) DeleteOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) error {
+	conn, err := u.getConn()
+	if err != nil {
+		return err
+	}
+	return sqlDeleteOne(ctx, conn, u.builder(), collectionName, u.models.get(collectionName), cond)
+}
+
+// AddMany inserts several records into the SQLite database with multi-row INSERT statements of up to 500 rows,
+// and reports the ID of each inserted record. If a statement fails, its rows are inserted one by one,
+// so only the failing records are reported as such.
+func (u *SqliteFunctions) AddMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, data []interface{}) (basetypes.BulkResult, error) {
+	return u.AddManyContext(context.Background(), dbName, collectionName, data)
+}
+
+// AddManyContext is AddMany, running its statements with ctx, so SQLite interrupts them once ctx is done.
+func (u *SqliteFunctions) AddManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, data []interface{}) (basetypes.BulkResult, error) {
+	conn, err := u.getConn()
+	if err != nil {
+		return bulkFailed(len(data), err)
+	}
+	return sqlAddMany(ctx, conn, u.builder(), collectionName, data)
+}
+
+// UpdateMany applies several updates to the SQLite database, each like UpdateOne, committing them in transactions of up to 500 updates.
+func (u *SqliteFunctions) UpdateMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, updates []basetypes.BulkUpdate) (basetypes.BulkResult, error) {
+	return u.UpdateManyContext(context.Background(), dbName, collectionName, updates)
+}
+
+// UpdateManyContext is UpdateMany, running its statements with ctx, so SQLite interrupts them once ctx is done.
+func (u *SqliteFunctions) UpdateManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, updates []basetypes.BulkUpdate) (basetypes.BulkResult, error) {
+	conn, err := u.getConn()
+	if err != nil {
+		return bulkFailed(len(updates), err)
+	}
+	return sqlUpdateMany(ctx, conn, u.builder(), collectionName, u.models.get(collectionName), updates)
+}
+
+// DeleteMany deletes several records from the SQLite database, each like DeleteOne, committing them in transactions of up to 500 deletions.
+func (u *SqliteFunctions) DeleteMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, queries []interface{}) (basetypes.BulkResult, error) {
+	return u.DeleteManyContext(context.Background(), dbName, collectionName, queries)
+}
+
+// DeleteManyContext is DeleteMany, running its statements with ctx, so SQLite interrupts them once ctx is done.
+func (u *SqliteFunctions) DeleteManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, queries []interface{}) (basetypes.BulkResult, error) {
+	conn, err := u.getConn()
+	if err != nil {
+		return bulkFailed(len(queries), err)
+	}
+	return sqlDeleteMany(ctx, conn, u.builder(), collectionName, u.models.get(collectionName), queries)
+}
+
+// FindTrash retrieves a page of the trashed records of a collection from the SQLite database, ordered by the first column of the table.
+// It fails for the table of a model that isn't soft deletable.
+func (u *SqliteFunctions) FindTrash(dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	return u.FindTrashContext(context.Background(), dbName, collectionName, cond, options)
+}
+
+// FindTrashContext is FindTrash, running its statements with ctx, so SQLite interrupts once ctx is done.
+func (u *SqliteFunctions) FindTrashContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	conn, err := u.getConn()
+	if err != nil {
+		return basetypes.FindResult{}, err
+	}
+	return sqlFindTrash(ctx, conn, u.builder(), collectionName, u.models.get(collectionName), cond, options)
+}
+
+// Restore moves the first trashed record matching a condition in the SQLite database out of the trash.
+func (u *SqliteFunctions) Restore(dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) error {
+	return u.RestoreContext(context.Background(), dbName, collectionName, cond)
+}
+
+// RestoreContext is Restore, running its statements with ctx, so SQLite interrupts once ctx is done.
+func (u *SqliteFunctions) RestoreContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) error {
+	conn, err := u.getConn()
+	if err != nil {
+		return err
+	}
+	return sqlRestore(ctx, conn, u.builder(), collectionName, u.models.get(collectionName), cond)
+}
+
+// Purge permanently deletes the first trashed record matching a condition from the SQLite database.
+func (u *SqliteFunctions) Purge(dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) error {
+	return u.PurgeContext(context.Background(), dbName, collectionName, cond)
+}
+
+// PurgeContext is Purge, running its statements with ctx, so SQLite interrupts once ctx is done.
+func (u *SqliteFunctions) PurgeContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) error {
+	conn, err := u.getConn()
+	if err != nil {
+		return err
+	}
+	return sqlPurge(ctx, conn, u.builder(), collectionName, u.models.get(collectionName), cond)
+}
+
+// PurgeTrash permanently deletes every record of a collection trashed before a time from the SQLite database in one statement.
+func (u *SqliteFunctions) PurgeTrash(dbName basetypes.DBName, collectionName basetypes.CollectionName, before time.Time) (int, error) {
+	return u.PurgeTrashContext(context.Background(), dbName, collectionName, before)
+}
+
+// PurgeTrashContext is PurgeTrash, running its statements with ctx, so SQLite interrupts once ctx is done.
+func (u *SqliteFunctions) PurgeTrashContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, before time.Time) (int, error) {
+	conn, err := u.getConn()
+	if err != nil {
+		return 0, err
+	}
+	return sqlPurgeTrash(ctx, conn, u.builder(), collectionName, u.models.get(collectionName), before)
+}
+
+// Iterate returns an iterator over the records matching a condition map or basefilters.Filter in the SQLite database,
+// read as models of the collection. The query runs with ctx, cancelling it stops the iteration. The iterator must be closed.
+func (u *SqliteFunctions) Iterate(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) (*RecordIterator, error) {
+	conn, err := u.getConn()
+	if err != nil {
+		return nil, err
+	}
+	return sqlIterate(ctx, conn, u.builder(), collectionName, u.models.get(collectionName), cond)
+}
+
+// Begin starts a SQLite transaction backed by sql.Tx.
+func (u *SqliteFunctions) Begin() (TransactionInterface, error) {
+	return u.BeginContext(context.Background())
+}
+
+// BeginContext is Begin, rolling the transaction back if ctx is done before it is committed.
+func (u *SqliteFunctions) BeginContext(ctx context.Context) (TransactionInterface, error) {
+	conn, err := u.getConn()
+	if err != nil {
+		return nil, err
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &SqliteTransaction{functions: u, tx: tx, ctx: ctx}, nil
+}
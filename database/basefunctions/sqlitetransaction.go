@@ -0,0 +1,45 @@
+package basefunctions
+
+import (
+	"context"
+	"database/sql"
+	"websays/database/basetypes"
+)
+
+// SqliteTransaction is a SQLite transaction backed by sql.Tx.
+// It runs the same statements as SqliteFunctions on the transaction instead of the connection pool.
+type SqliteTransaction struct {
+	functions *SqliteFunctions // The functions generating the statements.
+	tx        *sql.Tx          // The underlying sql transaction.
+	ctx       context.Context  // The context the statements run under.
+}
+
+// Add inserts data into the SQLite database inside the transaction.
+func (u *SqliteTransaction) Add(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (basetypes.ID, error) {
+	return sqlInsert(u.ctx, u.tx, u.functions.builder(), collectionName, data)
+}
+
+// FindOne retrieves data from the SQLite database inside the transaction.
+func (u *SqliteTransaction) FindOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) (interface{}, error) {
+	return sqlFindOne(u.ctx, u.tx, u.functions.builder(), collectionName, u.functions.models.get(collectionName), cond)
+}
+
+// UpdateOne updates data in the SQLite database inside the transaction.
+func (u *SqliteTransaction) UpdateOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error {
+	return sqlUpdateOne(u.ctx, u.tx, u.functions.builder(), collectionName, u.functions.models.get(collectionName), query, data, upsert)
+}
+
+// DeleteOne deletes data from the SQLite database inside the transaction.
+func (u *SqliteTransaction) DeleteOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, cond interface{}) error {
+	return sqlDeleteOne(u.ctx, u.tx, u.functions.builder(), collectionName, u.functions.models.get(collectionName), cond)
+}
+
+// Commit commits the sql transaction.
+func (u *SqliteTransaction) Commit() error {
+	return u.tx.Commit()
+}
+
+// Rollback rolls the sql transaction back.
+func (u *SqliteTransaction) Rollback() error {
+	return u.tx.Rollback()
+}
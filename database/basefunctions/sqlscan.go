@@ -0,0 +1,179 @@
+package basefunctions
+
+import (
+	"database/sql"
+	"reflect"
+	"sync"
+	"websays/database/basedialects"
+	"websays/database/basetypes"
+)
+
+// collectionModels maps the collections of a storage to the model types registered by EnsureIndex,
+// so the records read from their tables are scanned into models instead of maps.
+type collectionModels struct {
+	lock  sync.RWMutex
+	types map[basetypes.CollectionName]reflect.Type
+}
+
+// register records the struct type of a model as the model of a collection.
+func (u *collectionModels) register(collectionName basetypes.CollectionName, model interface{}) {
+	modelType := reflect.TypeOf(model)
+	if modelType == nil || modelType.Kind() != reflect.Struct {
+		return
+	}
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if u.types == nil {
+		u.types = make(map[basetypes.CollectionName]reflect.Type)
+	}
+	u.types[collectionName] = modelType
+}
+
+// get returns the model type of a collection, nil if none is registered.
+func (u *collectionModels) get(collectionName basetypes.CollectionName) reflect.Type {
+	u.lock.RLock()
+	defer u.lock.RUnlock()
+	return u.types[collectionName]
+}
+
+// RecordIterator iterates over the records of a sql query. Each record is a model of the collection,
+// filled through the `db` tags of its fields, or a map keyed by column name if the collection has no registered model.
+// The iterator holds a connection until it is exhausted or closed, so Close must always be called.
+type RecordIterator struct {
+	rows    *sql.Rows
+	model   reflect.Type // Type of the scanned models, nil to scan maps.
+	columns []string     // Columns of the query.
+	fields  []int        // Field index of each column in the model, -1 for columns without a field.
+	record  interface{}  // The record read by the last call to Next.
+	err     error        // The first error scanning a record.
+}
+
+// newRecordIterator returns an iterator reading the rows as models of type model, or maps if it is nil.
+func newRecordIterator(rows *sql.Rows, model reflect.Type) (*RecordIterator, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	iterator := &RecordIterator{rows: rows, model: model, columns: columns}
+	if model == nil {
+		return iterator, nil
+	}
+
+	fields := make(map[string]int)
+	tagged, err := basedialects.Columns(reflect.Zero(model).Interface())
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	for _, column := range tagged {
+		fields[column.Name] = column.FieldIndex()
+	}
+	iterator.fields = make([]int, len(columns))
+	for i, column := range columns {
+		field, ok := fields[column]
+		if !ok {
+			field = -1
+		}
+		iterator.fields[i] = field
+	}
+	return iterator, nil
+}
+
+// Next reads the next record, returning false when there are no more records or reading failed.
+// The connection is released once it returns false.
+func (u *RecordIterator) Next() bool {
+	if u.err != nil || !u.rows.Next() {
+		u.rows.Close()
+		return false
+	}
+	if u.model == nil {
+		u.record, u.err = u.scanMap()
+	} else {
+		u.record, u.err = u.scanModel()
+	}
+	if u.err != nil {
+		u.rows.Close()
+		return false
+	}
+	return true
+}
+
+// Record returns the record read by the last call to Next.
+func (u *RecordIterator) Record() interface{} {
+	return u.record
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (u *RecordIterator) Err() error {
+	if u.err != nil {
+		return u.err
+	}
+	return u.rows.Err()
+}
+
+// Close releases the connection of the iterator. It can be called more than once.
+func (u *RecordIterator) Close() error {
+	return u.rows.Close()
+}
+
+// All reads the remaining records and closes the iterator.
+func (u *RecordIterator) All() ([]interface{}, error) {
+	defer u.Close()
+	records := make([]interface{}, 0)
+	for u.Next() {
+		records = append(records, u.record)
+	}
+	return records, u.Err()
+}
+
+// scanModel scans the current row into a new model. NULL values leave their field at its zero value,
+// and columns without a tagged field are skipped.
+func (u *RecordIterator) scanModel() (interface{}, error) {
+	model := reflect.New(u.model).Elem()
+	targets := make([]interface{}, len(u.columns))
+	for i, field := range u.fields {
+		if field < 0 {
+			targets[i] = new(interface{})
+			continue
+		}
+		// Scanning into a pointer to a pointer turns NULL into nil instead of failing
+		targets[i] = reflect.New(reflect.PtrTo(model.Field(field).Type())).Interface()
+	}
+	err := u.rows.Scan(targets...)
+	if err != nil {
+		return nil, err
+	}
+	for i, field := range u.fields {
+		if field < 0 {
+			continue
+		}
+		value := reflect.ValueOf(targets[i]).Elem()
+		if !value.IsNil() {
+			model.Field(field).Set(value.Elem())
+		}
+	}
+	return model.Interface(), nil
+}
+
+// scanMap scans the current row into a map keyed by column name.
+func (u *RecordIterator) scanMap() (interface{}, error) {
+	values := make([]interface{}, len(u.columns))
+	pointers := make([]interface{}, len(u.columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	err := u.rows.Scan(pointers...)
+	if err != nil {
+		return nil, err
+	}
+	record := make(map[string]interface{})
+	for i, column := range u.columns {
+		if raw, ok := values[i].([]byte); ok {
+			record[column] = string(raw)
+		} else {
+			record[column] = values[i]
+		}
+	}
+	return record, nil
+}
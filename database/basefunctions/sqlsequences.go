@@ -0,0 +1,156 @@
+package basefunctions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"websays/database/basedialects"
+	"websays/database/basetypes"
+)
+
+// sqlSequencesTable is the table keeping the sequences of the sql tables keyed by a text column,
+// whose IDs the database can't generate like those of an AUTO_INCREMENT column.
+const sqlSequencesTable = "id_sequences"
+
+// sqlSequence is a row of the id_sequences table.
+type sqlSequence struct {
+	Collection string `db:"collection,VARCHAR(255),PRIMARY KEY"` // Table the sequence hands out the IDs of.
+	Last       int64  `db:"last,BIGINT,NOT NULL"`                // Last ID handed out.
+}
+
+// sqlKeyColumn returns the primary key column of a model keyed by a text column without AUTO_INCREMENT,
+// whose IDs the storage hands out from a sequence. ok is false for any other model.
+func sqlKeyColumn(data interface{}) (basedialects.Column, bool) {
+	columns, err := basedialects.Columns(data)
+	if err != nil {
+		return basedialects.Column{}, false
+	}
+	for _, column := range columns {
+		if column.PrimaryKey {
+			kind := reflect.TypeOf(data).Field(column.FieldIndex()).Type.Kind()
+			return column, !column.AutoIncrement && kind == reflect.String
+		}
+	}
+	return basedialects.Column{}, false
+}
+
+// sqlEnsureSequences creates the id_sequences table if the model is keyed by a text column.
+func sqlEnsureSequences(ctx context.Context, conn sqlExecutor, builder basedialects.Builder, data interface{}) error {
+	if _, ok := sqlKeyColumn(data); !ok {
+		return nil
+	}
+	statement, err := builder.CreateTable(sqlSequencesTable, sqlSequence{})
+	if err != nil {
+		return err
+	}
+	_, err = conn.ExecContext(ctx, statement.Query, statement.Values...)
+	return err
+}
+
+// sqlWithID returns a copy of data carrying the next ID of the sequence of its table if its model is keyed
+// by a text column and carries no ID, like the database fills in an AUTO_INCREMENT column, and data unchanged otherwise.
+func sqlWithID(ctx context.Context, conn sqlExecutor, builder basedialects.Builder, collectionName basetypes.CollectionName, data interface{}) (interface{}, error) {
+	column, ok := sqlKeyColumn(data)
+	if !ok || recordID(data) != "" {
+		return data, nil
+	}
+	id, err := sqlNextSequence(ctx, conn, builder, collectionName, column.Name)
+	if err != nil {
+		return nil, err
+	}
+	model := reflect.New(reflect.TypeOf(data)).Elem()
+	model.Set(reflect.ValueOf(data))
+	model.Field(column.FieldIndex()).SetString(string(basetypes.IntID(id)))
+	return model.Interface(), nil
+}
+
+// sqlNextSequence moves the sequence of a table to the next integer not stored in its key column yet and returns it.
+// A table without a sequence starts after its highest integer ID. Unless conn already is a transaction,
+// the statements run in one of their own, so the row of the sequence stays locked until the ID is handed out.
+func sqlNextSequence(ctx context.Context, conn sqlExecutor, builder basedialects.Builder, collectionName basetypes.CollectionName, key string) (int64, error) {
+	db, ok := conn.(*sql.DB)
+	if !ok {
+		return sqlAdvanceSequence(ctx, conn, builder, collectionName, key)
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	id, err := sqlAdvanceSequence(ctx, tx, builder, collectionName, key)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// sqlAdvanceSequence runs the statements of sqlNextSequence on the executor.
+func sqlAdvanceSequence(ctx context.Context, conn sqlExecutor, builder basedialects.Builder, collectionName basetypes.CollectionName, key string) (int64, error) {
+	quote := builder.Dialect.Quote
+	sequence := " FROM " + quote(sqlSequencesTable) + " WHERE " + quote("collection") + " = " + builder.Dialect.Placeholder(1)
+	advance := "UPDATE " + quote(sqlSequencesTable) + " SET " + quote("last") + " = " + quote("last") + " + 1" +
+		" WHERE " + quote("collection") + " = " + builder.Dialect.Placeholder(1)
+	stored := "SELECT COUNT(*) FROM " + quote(string(collectionName)) + " WHERE " + quote(key) + " = " + builder.Dialect.Placeholder(1)
+
+	seeded, seedErr := false, error(nil)
+	for {
+		result, err := conn.ExecContext(ctx, advance, string(collectionName))
+		if err != nil {
+			return 0, err
+		}
+		if advanced, _ := result.RowsAffected(); advanced == 0 {
+			if seeded {
+				if seedErr == nil {
+					seedErr = errors.New("Error seeding sequence")
+				}
+				return 0, seedErr
+			}
+			seeded = true
+			last, err := sqlMaxIntID(ctx, conn, builder, collectionName, key)
+			if err != nil {
+				return 0, err
+			}
+			insert, err := builder.Insert(sqlSequencesTable, sqlSequence{Collection: string(collectionName), Last: last})
+			if err != nil {
+				return 0, err
+			}
+			// A concurrent caller seeding the sequence first makes the insert fail, advancing it again picks its row up
+			_, seedErr = conn.ExecContext(ctx, insert.Query, insert.Values...)
+			continue
+		}
+
+		var next int64
+		if err := conn.QueryRowContext(ctx, "SELECT "+quote("last")+sequence, string(collectionName)).Scan(&next); err != nil {
+			return 0, err
+		}
+		// Integer IDs stored by upserts and explicit inserts are skipped
+		var taken int
+		if err := conn.QueryRowContext(ctx, stored, string(basetypes.IntID(next))).Scan(&taken); err != nil {
+			return 0, err
+		}
+		if taken == 0 {
+			return next, nil
+		}
+	}
+}
+
+// sqlMaxIntID returns the highest integer ID stored in the key column of a table, 0 if it has none.
+func sqlMaxIntID(ctx context.Context, conn sqlExecutor, builder basedialects.Builder, collectionName basetypes.CollectionName, key string) (int64, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT "+builder.Dialect.Quote(key)+" FROM "+builder.Dialect.Quote(string(collectionName)))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	var last int64
+	for rows.Next() {
+		var id basetypes.ID
+		if err := rows.Scan(&id); err != nil {
+			return 0, err
+		}
+		if value, ok := id.Int(); ok && value > last {
+			last = value
+		}
+	}
+	return last, rows.Err()
+}
@@ -0,0 +1,352 @@
+package basefunctions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"time"
+	"websays/database/baseconnections"
+	"websays/database/basedialects"
+	"websays/database/basefilters"
+	"websays/database/basetypes"
+	"websays/httpHandler/basemodels"
+)
+
+// sqlExecutor is the part of *sql.DB and *sql.Tx used to run the generated statements,
+// so the same statements can run on the connection pool or inside a transaction.
+// The statements run with the context of the call, the driver cancels them once it is done.
+type sqlExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// sqlConn returns the connection pool of a sql storage, or the error of connecting to it.
+func sqlConn(dbType basetypes.DbType) (*sql.DB, error) {
+	connection, err := baseconnections.GetInstance().GetConnection(dbType)
+	if err != nil {
+		return nil, err
+	}
+	return connection.GetDB(dbType).(*sql.DB), nil
+}
+
+// sqlCondition converts a condition map or basefilters.Filter into the filter of a statement, nil for no condition.
+// The fields of the condition are the JSON names of the fields of the model, resolved to their columns,
+// or the columns themselves for a collection without a registered model.
+func sqlCondition(cond interface{}, model reflect.Type) (*basefilters.Filter, error) {
+	if cond == nil {
+		return nil, nil
+	}
+	filter, err := requireFilter(cond, columnFields(model))
+	if err != nil {
+		return nil, err
+	}
+	return &filter, nil
+}
+
+// sqlEnsureTable creates the table of the db tagged fields of data if it doesn't exist.
+func sqlEnsureTable(ctx context.Context, conn sqlExecutor, builder basedialects.Builder, collectionName basetypes.CollectionName, data interface{}) error {
+	statement, err := builder.CreateTable(string(collectionName), data)
+	if err != nil {
+		return err
+	}
+	_, err = conn.ExecContext(ctx, statement.Query, statement.Values...)
+	if err != nil {
+		return err
+	}
+	return sqlEnsureSequences(ctx, conn, builder, data)
+}
+
+// sqlInsert runs the INSERT statement for the db tagged fields of data on the executor.
+// It returns the ID carried by data or else the one generated by the database, or handed out by the sequence of a table
+// keyed by a text column. Versioned models are inserted at version 1, and timestamped models created now by the principal of ctx.
+func sqlInsert(ctx context.Context, conn sqlExecutor, builder basedialects.Builder, collectionName basetypes.CollectionName, data interface{}) (basetypes.ID, error) {
+	data, err := sqlWithID(ctx, conn, builder, collectionName, data)
+	if err != nil {
+		return "", err
+	}
+	statement, err := builder.Insert(string(collectionName), liveRecord(stampCreated(ctx, firstVersion(data))))
+	if err != nil {
+		return "", err
+	}
+
+	if statement.ReturnsID {
+		var id basetypes.ID
+		err = conn.QueryRowContext(ctx, statement.Query, statement.Values...).Scan(&id)
+		return sqlInsertedID(data, id), err
+	}
+
+	res, err := conn.ExecContext(ctx, statement.Query, statement.Values...)
+	if err != nil {
+		return "", err
+	}
+	lastId, _ := res.LastInsertId()
+	return sqlInsertedID(data, basetypes.IntID(lastId)), nil
+}
+
+// sqlIterate runs the SELECT statement for the condition on the executor and returns an iterator over its records,
+// read as models of type model, or maps if it is nil. The trashed records of a soft deletable model are left out.
+func sqlIterate(ctx context.Context, conn sqlExecutor, builder basedialects.Builder, collectionName basetypes.CollectionName, model reflect.Type, cond interface{}) (*RecordIterator, error) {
+	filter, err := sqlCondition(cond, model)
+	if err != nil {
+		return nil, err
+	}
+	statement := builder.Select(string(collectionName), sqlScope(filter, model, false))
+	rows, err := conn.QueryContext(ctx, statement.Query, statement.Values...)
+	if err != nil {
+		return nil, err
+	}
+	return newRecordIterator(rows, model)
+}
+
+// sqlFindOne returns the first record matching the condition, read as a model of type model, or a map if it is nil.
+// It returns sql.ErrNoRows if no record matches.
+func sqlFindOne(ctx context.Context, conn sqlExecutor, builder basedialects.Builder, collectionName basetypes.CollectionName, model reflect.Type, cond interface{}) (interface{}, error) {
+	iterator, err := sqlIterate(ctx, conn, builder, collectionName, model, cond)
+	if err != nil {
+		return nil, err
+	}
+	defer iterator.Close()
+	if !iterator.Next() {
+		if iterator.Err() != nil {
+			return nil, iterator.Err()
+		}
+		return nil, sql.ErrNoRows
+	}
+	return iterator.Record(), nil
+}
+
+// sqlFindMany counts the records matching the condition and selects one page of them,
+// ordered by the first column of the table and read as models of type model, or maps if it is nil.
+// The trashed records of a soft deletable model are left out.
+func sqlFindMany(ctx context.Context, conn sqlExecutor, builder basedialects.Builder, collectionName basetypes.CollectionName, model reflect.Type, cond interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	filter, err := sqlCondition(cond, model)
+	if err != nil {
+		return basetypes.FindResult{}, err
+	}
+	return sqlFindPage(ctx, conn, builder, collectionName, model, sqlScope(filter, model, false), options)
+}
+
+// sqlFindPage counts the records matching the filter and selects one page of them,
+// ordered by the first column of the table and read as models of type model, or maps if it is nil.
+func sqlFindPage(ctx context.Context, conn sqlExecutor, builder basedialects.Builder, collectionName basetypes.CollectionName, model reflect.Type, filter *basefilters.Filter, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	offset, err := options.GetOffset()
+	if err != nil {
+		return basetypes.FindResult{}, err
+	}
+
+	result := basetypes.FindResult{Data: []interface{}{}}
+	count := builder.Count(string(collectionName), filter)
+	err = conn.QueryRowContext(ctx, count.Query, count.Values...).Scan(&result.Total)
+	if err != nil {
+		return basetypes.FindResult{}, err
+	}
+
+	page := builder.Page(string(collectionName), filter, options.GetLimit(), offset)
+	rows, err := conn.QueryContext(ctx, page.Query, page.Values...)
+	if err != nil {
+		return basetypes.FindResult{}, err
+	}
+	iterator, err := newRecordIterator(rows, model)
+	if err != nil {
+		return basetypes.FindResult{}, err
+	}
+	result.Data, err = iterator.All()
+	if err != nil {
+		return basetypes.FindResult{}, err
+	}
+	result.NextCursor = options.NextCursor(offset, result.Total)
+	return result, nil
+}
+
+// sqlUpdateOne runs the UPDATE statement setting the values of the data map on the first live record matching the query.
+// On the table of a versioned model the statement also increments the version, and a version in the data map
+// restricts it to the record still at that version, returning ErrVersionConflict if the record moved on.
+// On the table of a timestamped model the statement also sets the time and principal of the update.
+// With upsert, data is a model inserted or replacing the record with the same primary key, see sqlUpsert.
+func sqlUpdateOne(ctx context.Context, conn sqlExecutor, builder basedialects.Builder, collectionName basetypes.CollectionName, model reflect.Type, query interface{}, data interface{}, upsert bool) error {
+	if upsert {
+		return sqlUpsert(ctx, conn, builder, collectionName, data)
+	}
+	dataMap, ok := data.(map[string]interface{})
+	if !ok {
+		return errors.New("Required a map for data")
+	}
+	filter, err := sqlCondition(query, model)
+	if err != nil {
+		return err
+	}
+	filter = sqlScope(filter, model, false)
+	dataMap = updatedColumns(ctx, model, dataMap)
+	if !isVersioned(model) {
+		statement, err := builder.UpdateOne(string(collectionName), filter, dataMap)
+		if err != nil {
+			return err
+		}
+		_, err = conn.ExecContext(ctx, statement.Query, statement.Values...)
+		return err
+	}
+
+	update := make(map[string]interface{}, len(dataMap)+1)
+	for key, value := range dataMap {
+		update[key] = value
+	}
+	version := toVersion(update[versionField])
+	update[versionField] = basedialects.Increment(1)
+	statement, err := builder.UpdateOne(string(collectionName), sqlVersionFilter(filter, version), update)
+	if err != nil {
+		return err
+	}
+	result, err := conn.ExecContext(ctx, statement.Query, statement.Values...)
+	if err != nil || version == 0 {
+		return err
+	}
+	return sqlVersionConflict(ctx, conn, builder, collectionName, filter, result)
+}
+
+// sqlUpsert inserts a model, or replaces the record with the same primary key in the same statement,
+// so concurrent upserts of a missing record can't both insert it. A trashed record is replaced too, which restores it.
+// A versioned model is inserted at version 1 or increments the version of the replaced record. If it supplies a version,
+// only a record at that version is replaced, and ErrVersionConflict is returned if there is none.
+// A timestamped model is created now by the principal of ctx, the replaced record keeping its creation.
+func sqlUpsert(ctx context.Context, conn sqlExecutor, builder basedialects.Builder, collectionName basetypes.CollectionName, data interface{}) error {
+	data = liveRecord(stampCreated(ctx, data))
+	kept := createdColumns(data)
+	versioned, ok := data.(basemodels.VersionedModels)
+	if !ok {
+		statement, err := builder.UpsertKeeping(string(collectionName), data, kept)
+		if err != nil {
+			return err
+		}
+		_, err = conn.ExecContext(ctx, statement.Query, statement.Values...)
+		return err
+	}
+	if versioned.GetVersion() == 0 {
+		statement, err := builder.UpsertKeeping(string(collectionName), versioned.WithVersion(1), kept, versionField)
+		if err != nil {
+			return err
+		}
+		_, err = conn.ExecContext(ctx, statement.Query, statement.Values...)
+		return err
+	}
+
+	// A supplied version can only match an existing record, which is updated in place
+	values, key, err := sqlModelValues(data)
+	if err != nil {
+		return err
+	}
+	for _, column := range kept {
+		delete(values, column)
+	}
+	values[versionField] = basedialects.Increment(1)
+	statement, err := builder.UpdateOne(string(collectionName), sqlVersionFilter(key, versioned.GetVersion()), values)
+	if err != nil {
+		return err
+	}
+	result, err := conn.ExecContext(ctx, statement.Query, statement.Values...)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// sqlModelValues returns the values of the db tagged fields of a model by column without its primary key,
+// and the filter matching the record with its primary key.
+func sqlModelValues(model interface{}) (map[string]interface{}, *basefilters.Filter, error) {
+	columns, err := basedialects.Columns(model)
+	if err != nil {
+		return nil, nil, err
+	}
+	modelValue := reflect.ValueOf(model)
+	values := make(map[string]interface{}, len(columns))
+	keys := make([]basefilters.Filter, 0, 1)
+	for _, column := range columns {
+		value := modelValue.Field(column.FieldIndex()).Interface()
+		if column.PrimaryKey {
+			keys = append(keys, basefilters.Eq(column.Name, value))
+		} else {
+			values[column.Name] = value
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil, errors.New("Upsert requires a primary key")
+	}
+	key := basefilters.And(keys...)
+	return values, &key, nil
+}
+
+// sqlDeleteOne runs the DELETE statement removing the first live record matching the condition.
+// On the table of a soft deletable model it runs an UPDATE moving the record to the trash instead,
+// which also increments the version of a versioned model.
+// On the table of a versioned model a version in a condition map returns ErrVersionConflict if the record moved on.
+func sqlDeleteOne(ctx context.Context, conn sqlExecutor, builder basedialects.Builder, collectionName basetypes.CollectionName, model reflect.Type, cond interface{}) error {
+	version := 0
+	if condMap, ok := cond.(map[string]interface{}); ok && isVersioned(model) {
+		version = toVersion(condMap[versionField])
+		if version != 0 {
+			rest := make(map[string]interface{}, len(condMap))
+			for key, value := range condMap {
+				if key != versionField {
+					rest[key] = value
+				}
+			}
+			cond = rest
+		}
+	}
+	filter, err := sqlCondition(cond, model)
+	if err != nil {
+		return err
+	}
+	filter = sqlScope(filter, model, false)
+	statement := builder.DeleteOne(string(collectionName), sqlVersionFilter(filter, version))
+	if isSoftDeletable(model) {
+		statement, err = builder.UpdateOne(string(collectionName), sqlVersionFilter(filter, version), sqlTrashValues(model, time.Now().Unix()))
+		if err != nil {
+			return err
+		}
+	}
+	result, err := conn.ExecContext(ctx, statement.Query, statement.Values...)
+	if err != nil || version == 0 {
+		return err
+	}
+	return sqlVersionConflict(ctx, conn, builder, collectionName, filter, result)
+}
+
+// sqlVersionFilter restricts a filter to the records at version, leaving it unchanged if version is 0.
+func sqlVersionFilter(filter *basefilters.Filter, version int) *basefilters.Filter {
+	if version == 0 {
+		return filter
+	}
+	versionFilter := basefilters.Eq(versionField, version)
+	if filter != nil {
+		versionFilter = basefilters.And(*filter, versionFilter)
+	}
+	return &versionFilter
+}
+
+// sqlVersionConflict returns ErrVersionConflict if a statement restricted to a version affected no record
+// while a record still matches the filter, meaning it is at another version.
+func sqlVersionConflict(ctx context.Context, conn sqlExecutor, builder basedialects.Builder, collectionName basetypes.CollectionName, filter *basefilters.Filter, result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil || affected > 0 {
+		return err
+	}
+	count := builder.Count(string(collectionName), filter)
+	var total int
+	err = conn.QueryRowContext(ctx, count.Query, count.Values...).Scan(&total)
+	if err != nil {
+		return err
+	}
+	if total > 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package basefunctions
+
+import (
+	"context"
+	"reflect"
+	"time"
+	"websays/database/basedialects"
+	"websays/database/basefilters"
+	"websays/database/basetypes"
+)
+
+// sqlScope restricts a filter to the live records of the table of a soft deletable model,
+// or to its trashed records when trashed is set. The filter of any other table is returned unchanged.
+func sqlScope(filter *basefilters.Filter, model reflect.Type, trashed bool) *basefilters.Filter {
+	if !isSoftDeletable(model) {
+		return filter
+	}
+	scope := basefilters.Eq(deletedColumn, 0)
+	if trashed {
+		scope = basefilters.Gt(deletedColumn, 0)
+	}
+	if filter != nil {
+		scope = basefilters.And(*filter, scope)
+	}
+	return &scope
+}
+
+// sqlTrashValues returns the values of the UPDATE statement moving a record of a model in the trash at deletedAt,
+// or out of it for 0, incrementing the version of a versioned model.
+func sqlTrashValues(model reflect.Type, deletedAt int64) map[string]interface{} {
+	values := map[string]interface{}{deletedColumn: deletedAt}
+	if isVersioned(model) {
+		values[versionField] = basedialects.Increment(1)
+	}
+	return values
+}
+
+// sqlFindTrash counts the trashed records matching the condition and selects one page of them,
+// ordered by the first column of the table and read as models of type model.
+// It returns errNoTrash if model isn't soft deletable.
+func sqlFindTrash(ctx context.Context, conn sqlExecutor, builder basedialects.Builder, collectionName basetypes.CollectionName, model reflect.Type, cond interface{}, options basetypes.FindOptions) (basetypes.FindResult, error) {
+	if !isSoftDeletable(model) {
+		return basetypes.FindResult{}, errNoTrash
+	}
+	filter, err := sqlCondition(cond, model)
+	if err != nil {
+		return basetypes.FindResult{}, err
+	}
+	return sqlFindPage(ctx, conn, builder, collectionName, model, sqlScope(filter, model, true), options)
+}
+
+// sqlRestore runs the UPDATE statement moving the first trashed record matching the condition out of the trash.
+// It returns errNotTrashed if no trashed record matches, and errNoTrash if model isn't soft deletable.
+func sqlRestore(ctx context.Context, conn sqlExecutor, builder basedialects.Builder, collectionName basetypes.CollectionName, model reflect.Type, cond interface{}) error {
+	if !isSoftDeletable(model) {
+		return errNoTrash
+	}
+	filter, err := sqlCondition(cond, model)
+	if err != nil {
+		return err
+	}
+	statement, err := builder.UpdateOne(string(collectionName), sqlScope(filter, model, true), sqlTrashValues(model, 0))
+	if err != nil {
+		return err
+	}
+	return sqlExecTrashed(ctx, conn, statement)
+}
+
+// sqlPurge runs the DELETE statement removing the first trashed record matching the condition.
+// It returns errNotTrashed if no trashed record matches, and errNoTrash if model isn't soft deletable.
+func sqlPurge(ctx context.Context, conn sqlExecutor, builder basedialects.Builder, collectionName basetypes.CollectionName, model reflect.Type, cond interface{}) error {
+	if !isSoftDeletable(model) {
+		return errNoTrash
+	}
+	filter, err := sqlCondition(cond, model)
+	if err != nil {
+		return err
+	}
+	return sqlExecTrashed(ctx, conn, builder.DeleteOne(string(collectionName), sqlScope(filter, model, true)))
+}
+
+// sqlPurgeTrash runs the DELETE statement removing every record trashed before a time, and returns how many it removed.
+// It returns errNoTrash if model isn't soft deletable.
+func sqlPurgeTrash(ctx context.Context, conn sqlExecutor, builder basedialects.Builder, collectionName basetypes.CollectionName, model reflect.Type, before time.Time) (int, error) {
+	if !isSoftDeletable(model) {
+		return 0, errNoTrash
+	}
+	expired := basefilters.Lt(deletedColumn, before.Unix())
+	statement := builder.Delete(string(collectionName), sqlScope(&expired, model, true))
+	result, err := conn.ExecContext(ctx, statement.Query, statement.Values...)
+	if err != nil {
+		return 0, err
+	}
+	purged, err := result.RowsAffected()
+	return int(purged), err
+}
+
+// sqlExecTrashed runs a statement on a trashed record and returns errNotTrashed if it affected none.
+func sqlExecTrashed(ctx context.Context, conn sqlExecutor, statement basedialects.Statement) error {
+	result, err := conn.ExecContext(ctx, statement.Query, statement.Values...)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return errNotTrashed
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package basefunctions
+
+import (
+	"context"
+	"websays/database/basetypes"
+)
+
+/*
+ * TransactionalInterface is implemented by the storages that can change several records atomically.
+ * It is kept apart from BaseFucntionsInterface so wrappers around a storage don't have to support transactions.
+ */
+type TransactionalInterface interface {
+	// Begin starts a new transaction on the storage.
+	// Returns the transaction and an error if it can't be started.
+	Begin() (TransactionInterface, error)
+
+	// BeginContext is Begin, with the reads and writes of the transaction done under ctx.
+	// The transaction gives up once ctx is done, and the records it writes carry the principal of ctx.
+	BeginContext(ctx context.Context) (TransactionInterface, error)
+}
+
+/*
+ * TransactionInterface is a unit of work on a storage. Writes done through it are only
+ * visible to other callers after Commit, and are discarded by Rollback.
+ * Reads done through it see the writes of the transaction itself.
+ */
+type TransactionInterface interface {
+	// Add inserts a new document into a collection as part of the transaction.
+	// Returns the ID of the inserted document, the one it carries or the one generated by the database,
+	// and an error if the operation fails.
+	Add(dbName basetypes.DBName, collectionName basetypes.CollectionName, data interface{}) (basetypes.ID, error)
+
+	// FindOne retrieves a single document from a collection, including the changes of the transaction.
+	// Returns the retrieved document and an error if the operation fails.
+	FindOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) (interface{}, error)
+
+	// UpdateOne updates a document in a collection as part of the transaction.
+	// Returns an error if the operation fails.
+	UpdateOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error
+
+	// DeleteOne deletes a document from a collection as part of the transaction.
+	// Returns an error if the operation fails.
+	DeleteOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) error
+
+	// Commit applies every write of the transaction atomically.
+	// Returns an error, and applies nothing, if any of the writes can't be applied.
+	Commit() error
+
+	// Rollback discards every write of the transaction.
+	Rollback() error
+}
@@ -0,0 +1,101 @@
+package basefunctions
+
+import (
+	"errors"
+	"reflect"
+	"websays/httpHandler/basemodels"
+)
+
+// errNoTrash is returned by the trash operations of a sql storage on the table of a model that isn't soft deletable.
+var errNoTrash = errors.New("Collection has no trash")
+
+// errNotTrashed is returned by Restore and Purge of a sql storage when no trashed record matches the condition.
+var errNotTrashed = errors.New("Trashed record not found")
+
+// deletedField is the field of a document holding the Unix time its record was trashed at.
+const deletedField = "deletedAt"
+
+// deletedColumn is the sql column holding the Unix time a record was trashed at.
+const deletedColumn = "deleted_at"
+
+var softDeletableType = reflect.TypeOf((*basemodels.SoftDeletableModels)(nil)).Elem()
+
+// isSoftDeletable returns whether the records of a model type are moved to the trash when they are deleted.
+func isSoftDeletable(model reflect.Type) bool {
+	return model != nil && model.Implements(softDeletableType)
+}
+
+// trashedAt returns the Unix time a stored record, either a soft deletable model or a decoded document, was trashed at,
+// 0 if it isn't trashed.
+func trashedAt(record interface{}) int64 {
+	switch value := record.(type) {
+	case basemodels.SoftDeletableModels:
+		return value.GetDeletedAt()
+	case map[string]interface{}:
+		return toUnixTime(value[deletedField])
+	}
+	return 0
+}
+
+// toUnixTime converts a Unix time read from a document to an int64, 0 if it isn't a number.
+func toUnixTime(value interface{}) int64 {
+	switch unixTime := value.(type) {
+	case int:
+		return int64(unixTime)
+	case int64:
+		return unixTime
+	case float64:
+		return int64(unixTime)
+	}
+	return 0
+}
+
+// isTrashed returns whether a stored record is in the trash.
+func isTrashed(record interface{}) bool {
+	return trashedAt(record) != 0
+}
+
+// softDeletes returns whether deleting a stored record moves it to the trash,
+// which is the case when either the record or the condition it was deleted by is a soft deletable model.
+func softDeletes(stored interface{}, condition interface{}) bool {
+	_, storedModel := stored.(basemodels.SoftDeletableModels)
+	_, conditionModel := condition.(basemodels.SoftDeletableModels)
+	return storedModel || conditionModel
+}
+
+// withTrash returns a copy of a stored record trashed at the Unix time deletedAt, or restored for 0.
+// Moving a record in or out of the trash changes it, so a versioned record moves to its next version.
+func withTrash(record interface{}, deletedAt int64) interface{} {
+	switch value := record.(type) {
+	case basemodels.SoftDeletableModels:
+		changed := value.WithDeletedAt(deletedAt)
+		if versioned, ok := changed.(basemodels.VersionedModels); ok {
+			return versioned.WithVersion(versioned.GetVersion() + 1)
+		}
+		return changed
+	case map[string]interface{}:
+		document := make(map[string]interface{}, len(value)+1)
+		for key, field := range value {
+			document[key] = field
+		}
+		if deletedAt == 0 {
+			delete(document, deletedField)
+		} else {
+			document[deletedField] = deletedAt
+		}
+		if _, ok := document[versionField]; ok {
+			document[versionField] = RecordVersion(value) + 1
+		}
+		return document
+	}
+	return record
+}
+
+// liveRecord returns a soft deletable model written by Add, UpdateOne or an upsert without a trash mark,
+// so writing a record never trashes it, and any other data unchanged.
+func liveRecord(data interface{}) interface{} {
+	if model, ok := data.(basemodels.SoftDeletableModels); ok {
+		return model.WithDeletedAt(0)
+	}
+	return data
+}
@@ -0,0 +1,60 @@
+package basefunctions
+
+import (
+	"context"
+	"time"
+	"websays/database/basetypes"
+)
+
+/*
+ * TrashInterface is implemented by the storages keeping the records of soft deletable models in a trash.
+ * DeleteOne moves such a record to the trash instead of removing it, and FindOne, FindMany and UpdateOne no longer
+ * see it, until it is restored or purged. An upsert replaces a trashed record with the same ID, restoring it.
+ * It is kept apart from BaseFucntionsInterface like TransactionalInterface, the Context variants give up once ctx is done.
+ */
+type TrashInterface interface {
+	// FindTrash retrieves a page of the trashed documents of a collection, ordered by ID.
+	// Parameters:
+	//   - dbName: The name of the database.
+	//   - collectionName: The name of the collection.
+	//   - query: The query to filter the trashed documents, nil matches every one of them.
+	//   - options: The limit, offset or cursor of the requested page.
+	// Returns the page with the total count and next cursor, and an error if the operation fails.
+	FindTrash(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, options basetypes.FindOptions) (basetypes.FindResult, error)
+
+	// Restore moves a trashed document back out of the trash.
+	// Parameters:
+	//   - dbName: The name of the database.
+	//   - collectionName: The name of the collection.
+	//   - query: The query to filter the trashed document to be restored.
+	// Returns an error if the operation fails or no trashed document matches.
+	Restore(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) error
+
+	// Purge permanently removes a trashed document.
+	// Parameters:
+	//   - dbName: The name of the database.
+	//   - collectionName: The name of the collection.
+	//   - query: The query to filter the trashed document to be purged.
+	// Returns an error if the operation fails or no trashed document matches.
+	Purge(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) error
+
+	// PurgeTrash permanently removes every document of a collection trashed before a time.
+	// Parameters:
+	//   - dbName: The name of the database.
+	//   - collectionName: The name of the collection.
+	//   - before: The documents trashed before this time are purged.
+	// Returns the number of purged documents and an error if the operation fails.
+	PurgeTrash(dbName basetypes.DBName, collectionName basetypes.CollectionName, before time.Time) (int, error)
+
+	// FindTrashContext is FindTrash, giving up once ctx is done.
+	FindTrashContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, options basetypes.FindOptions) (basetypes.FindResult, error)
+
+	// RestoreContext is Restore, giving up once ctx is done.
+	RestoreContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) error
+
+	// PurgeContext is Purge, giving up once ctx is done.
+	PurgeContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) error
+
+	// PurgeTrashContext is PurgeTrash, giving up once ctx is done.
+	PurgeTrashContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, before time.Time) (int, error)
+}
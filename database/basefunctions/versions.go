@@ -0,0 +1,84 @@
+package basefunctions
+
+import (
+	"errors"
+	"reflect"
+	"websays/httpHandler/basemodels"
+)
+
+// ErrVersionConflict is returned by UpdateOne and DeleteOne when the version supplied for a versioned record is stale,
+// meaning the record was changed since it was read.
+var ErrVersionConflict = errors.New("Version conflict, the record was changed since it was read")
+
+// versionField is the field, and sql column, holding the version of a versioned record.
+const versionField = "version"
+
+var versionedType = reflect.TypeOf((*basemodels.VersionedModels)(nil)).Elem()
+
+// isVersioned returns whether the records of a model type carry a version.
+func isVersioned(model reflect.Type) bool {
+	return model != nil && model.Implements(versionedType)
+}
+
+// RecordVersion returns the version of a stored record, either a versioned model or a decoded document.
+func RecordVersion(record interface{}) int {
+	switch value := record.(type) {
+	case basemodels.VersionedModels:
+		return value.GetVersion()
+	case map[string]interface{}:
+		return toVersion(value[versionField])
+	}
+	return 0
+}
+
+// toVersion converts a version read from a document or a condition map to an int, 0 if it isn't a number.
+func toVersion(value interface{}) int {
+	switch version := value.(type) {
+	case int:
+		return version
+	case int64:
+		return int(version)
+	case float64:
+		return int(version)
+	}
+	return 0
+}
+
+// firstVersion returns a versioned model at version 1, the version of a new record, and any other data unchanged.
+func firstVersion(data interface{}) interface{} {
+	if versioned, ok := data.(basemodels.VersionedModels); ok {
+		return versioned.WithVersion(1)
+	}
+	return data
+}
+
+// nextVersion returns a versioned model replacing the stored record at the version following the stored one,
+// and any other data unchanged. It returns ErrVersionConflict if the model supplies a version other than the stored one.
+func nextVersion(stored interface{}, data interface{}) (interface{}, error) {
+	versioned, ok := data.(basemodels.VersionedModels)
+	if !ok {
+		return data, nil
+	}
+	if err := checkVersion(stored, data); err != nil {
+		return nil, err
+	}
+	return versioned.WithVersion(RecordVersion(stored) + 1), nil
+}
+
+// followsVersion returns whether data written by nextVersion still follows the stored record,
+// which a transaction checks on commit in case the record was updated since the write was buffered.
+func followsVersion(stored interface{}, data interface{}) bool {
+	if _, ok := data.(basemodels.VersionedModels); !ok {
+		return true
+	}
+	return RecordVersion(data) == RecordVersion(stored)+1
+}
+
+// checkVersion returns ErrVersionConflict if condition is a versioned model supplying a version other than the stored one.
+func checkVersion(stored interface{}, condition interface{}) error {
+	versioned, ok := condition.(basemodels.VersionedModels)
+	if ok && versioned.GetVersion() != 0 && versioned.GetVersion() != RecordVersion(stored) {
+		return ErrVersionConflict
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package basefunctions
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"time"
+	"websays/database/basefilters"
+	"websays/database/basetypes"
+)
+
+// pendingWrite is the latest write of a record of the memory front not persisted yet.
+type pendingWrite struct {
+	record   memoryRecord
+	data     interface{} // The record to persist, nil to delete it.
+	sequence uint64      // Order of the write among the writes followed.
+	since    time.Time   // When the oldest write not persisted yet was followed.
+}
+
+// follow records a write of the memory front as pending, replacing the former pending write of the record.
+// It is called by the front under its lock, so the writes are followed in the order they are applied.
+func (u *WriteBehindFunctions) follow(dbName basetypes.DBName, key string, data interface{}) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	record := memoryRecord{db: dbName, key: key}
+	since := time.Now()
+	if former, ok := u.pending[record]; ok {
+		since = former.since
+	}
+	u.sequence++
+	u.pending[record] = pendingWrite{record: record, data: data, sequence: u.sequence, since: since}
+	if len(u.pending) >= u.options.BatchSize {
+		select {
+		case u.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run flushes the pending writes every flush interval, or as soon as a batch is pending, until Close is called.
+// A failed flush is retried after a backoff doubled on each failure.
+func (u *WriteBehindFunctions) run() {
+	defer close(u.done)
+
+	backoff := time.Duration(0)
+	timer := time.NewTimer(u.options.FlushInterval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-u.stop:
+			return
+		case <-timer.C:
+		case <-u.wake:
+			if backoff > 0 {
+				continue
+			}
+			if !timer.Stop() {
+				<-timer.C
+			}
+		}
+
+		if err := u.Flush(context.Background()); err != nil {
+			if backoff = backoff * 2; backoff == 0 {
+				backoff = u.options.RetryBackoff
+			}
+			if backoff > u.options.MaxBackoff {
+				backoff = u.options.MaxBackoff
+			}
+			log.Println("Error flushing the write-behind storage, retrying in", backoff, ":", err)
+			timer.Reset(backoff)
+			continue
+		}
+		backoff = 0
+		timer.Reset(u.options.FlushInterval)
+	}
+}
+
+// Flush persists the pending writes in the sql storage, in the order they were followed, by batches of BatchSize records
+// each written in a sql transaction. If a transaction fails its writes are persisted one by one, and the failing ones
+// stay pending unless the record was written again meanwhile. It stops at the first batch failing, keeping the remaining
+// writes pending, and returns its error.
+func (u *WriteBehindFunctions) Flush(ctx context.Context) error {
+	u.flushLock.Lock()
+	defer u.flushLock.Unlock()
+
+	writes := u.take()
+	next := 0
+	var err error
+	for next < len(writes) {
+		end := next + u.options.BatchSize
+		if end > len(writes) {
+			end = len(writes)
+		}
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		var failed []pendingWrite
+		failed, err = u.persist(ctx, writes[next:end])
+		u.settle(failed, end-next-len(failed), writes[end:])
+		next = end
+		if err != nil {
+			break
+		}
+	}
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	u.restore(writes[next:])
+	u.inFlight = nil
+	u.stats.LastError = ""
+	if err != nil {
+		u.stats.LastError = err.Error()
+	}
+	return err
+}
+
+// take moves the pending writes to the running flush, ordered as they were followed.
+func (u *WriteBehindFunctions) take() []pendingWrite {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	writes := make([]pendingWrite, 0, len(u.pending))
+	for _, write := range u.pending {
+		writes = append(writes, write)
+	}
+	sort.Slice(writes, func(i, j int) bool {
+		return writes[i].sequence < writes[j].sequence
+	})
+	u.pending = make(map[memoryRecord]pendingWrite)
+	u.inFlight = writes
+	return writes
+}
+
+// settle counts the flushed and failed writes of a batch and makes the failed ones pending again.
+// remaining are the writes of the running flush not persisted yet.
+func (u *WriteBehindFunctions) settle(failed []pendingWrite, flushed int, remaining []pendingWrite) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	u.restore(failed)
+	if flushed > 0 {
+		u.stats.Flushed += int64(flushed)
+		u.stats.Batches++
+		u.stats.LastFlush = time.Now().Unix()
+	}
+	u.stats.Failures += int64(len(failed))
+	u.inFlight = remaining
+}
+
+// restore makes writes taken by the running flush pending again, unless their record was written since they were taken,
+// in which case the newer write keeps the time of the older one. The caller must hold the lock.
+func (u *WriteBehindFunctions) restore(writes []pendingWrite) {
+	for _, write := range writes {
+		if newer, ok := u.pending[write.record]; ok {
+			newer.since = write.since
+			u.pending[write.record] = newer
+			continue
+		}
+		u.pending[write.record] = write
+	}
+}
+
+// persist writes a batch in a sql transaction. If the transaction fails the writes are persisted one by one,
+// and the ones failing are returned with the last error.
+func (u *WriteBehindFunctions) persist(ctx context.Context, writes []pendingWrite) ([]pendingWrite, error) {
+	conn, err := u.back.getConn()
+	if err != nil {
+		return writes, err
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return writes, err
+	}
+	for _, write := range writes {
+		if err = u.write(ctx, tx, write); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		if err = tx.Commit(); err == nil {
+			return nil, nil
+		}
+	} else {
+		tx.Rollback()
+	}
+
+	failed := make([]pendingWrite, 0)
+	for _, write := range writes {
+		if writeErr := u.write(ctx, conn, write); writeErr != nil {
+			failed = append(failed, write)
+			err = writeErr
+		}
+	}
+	if len(failed) == 0 {
+		return nil, nil
+	}
+	return failed, err
+}
+
+// write persists a write in the table of its collection, upserting every column of the record or deleting it by primary key.
+func (u *WriteBehindFunctions) write(ctx context.Context, conn sqlExecutor, write pendingWrite) error {
+	id, collectionName, _ := strings.Cut(write.record.key, "_")
+	builder := u.back.builder()
+	if write.data != nil {
+		statement, err := builder.Upsert(collectionName, write.data)
+		if err != nil {
+			return err
+		}
+		_, err = conn.ExecContext(ctx, statement.Query, statement.Values...)
+		return err
+	}
+
+	model := u.models.get(basetypes.CollectionName(collectionName))
+	if model == nil {
+		return errors.New("Collection has no model")
+	}
+	filter := basefilters.Eq(primaryColumn(model), basetypes.ID(id))
+	statement := builder.Delete(collectionName, &filter)
+	_, err := conn.ExecContext(ctx, statement.Query, statement.Values...)
+	return err
+}
+
+// FlushStats returns the number of writes not persisted yet, the age of the oldest one and the activity of the flushes.
+func (u *WriteBehindFunctions) FlushStats() basetypes.FlushStats {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	stats := u.stats
+	oldest := time.Time{}
+	for _, write := range u.pending {
+		if oldest.IsZero() || write.since.Before(oldest) {
+			oldest = write.since
+		}
+	}
+	for _, write := range u.inFlight {
+		if _, ok := u.pending[write.record]; ok {
+			continue
+		}
+		stats.Pending++
+		if oldest.IsZero() || write.since.Before(oldest) {
+			oldest = write.since
+		}
+	}
+	stats.Pending += len(u.pending)
+	if !oldest.IsZero() {
+		stats.LagSeconds = time.Since(oldest).Seconds()
+	}
+	return stats
+}
+
+// Close stops the background flushes and persists the pending writes, returning the error of that last flush.
+// The storage still acknowledges writes after Close, but they are only persisted by calls to Flush.
+func (u *WriteBehindFunctions) Close(ctx context.Context) error {
+	u.closeOnce.Do(func() {
+		close(u.stop)
+	})
+	select {
+	case <-u.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return u.Flush(ctx)
+}
@@ -0,0 +1,410 @@
+package basefunctions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+	"websays/database/basedialects"
+	"websays/database/basetypes"
+	"websays/httpHandler/basemodels"
+)
+
+// sqlStorage is a sql storage, the part of MySqlFunctions and SqliteFunctions the write-behind storage persists its records with.
+type sqlStorage interface {
+	BaseFucntionsInterface
+	getConn() (*sql.DB, error)
+	builder() basedialects.Builder
+}
+
+// WriteBehindOptions are the settings of the background flushes of a WriteBehindFunctions.
+type WriteBehindOptions struct {
+	FlushInterval time.Duration // Time between two flushes of the pending writes.
+	BatchSize     int           // Maximum number of records persisted in one sql transaction, a flush starts early once as many are pending, and records read per query when loading a table.
+	RetryBackoff  time.Duration // Wait before retrying a failed flush, doubled after each failure up to MaxBackoff.
+	MaxBackoff    time.Duration // Longest wait before retrying a failed flush.
+}
+
+// withDefaults returns the options with a default for every setting left at 0.
+func (u WriteBehindOptions) withDefaults() WriteBehindOptions {
+	if u.FlushInterval <= 0 {
+		u.FlushInterval = time.Second
+	}
+	if u.BatchSize <= 0 {
+		u.BatchSize = 500
+	}
+	if u.RetryBackoff <= 0 {
+		u.RetryBackoff = 500 * time.Millisecond
+	}
+	if u.MaxBackoff < u.RetryBackoff {
+		u.MaxBackoff = 30 * time.Second
+	}
+	return u
+}
+
+/*
+ * WriteBehindFunctions is a storage with the latency of MemoryFunctions and the durability of a sql storage.
+ * Its reads and writes are served by a memory front, without persistence of its own, filled with the records of each table
+ * by EnsureIndex, which reads them in pages of BatchSize records. Every record the front sets or removes is acknowledged at once and persisted by a background flusher,
+ * which writes the latest state of the pending records in batches, each in a sql transaction, and retries the failing
+ * ones with a backoff. The flushes run one at a time and only persist the latest state of each record, so the writes
+ * of a record reach the table in order. The pending writes are lost if the process dies before they are flushed,
+ * Close persists them on a graceful shutdown.
+ * The front hands out the IDs, so the tables must not be written by other processes. Like the sql storages it keeps
+ * one database, the one of the connection, and it doesn't implement DatabaseInterface.
+ * The columns written by UpdateOne with a map are merged into the stored model, as the sql storages update their columns.
+ */
+type WriteBehindFunctions struct {
+	BaseFucntionsInterface // The memory front serving the reads and writes.
+	TrashInterface         // The trash of the memory front.
+	TransactionalInterface // The transactions of the memory front, writing whole models like the memory storage.
+	SequenceInterface      // The sequences of the memory front, handing out the IDs of the new records.
+
+	front   *MemoryFunctions   // The memory front.
+	back    sqlStorage         // The sql storage the records are persisted to.
+	models  collectionModels   // Models of the collections registered by EnsureIndex.
+	options WriteBehindOptions // Settings of the background flushes.
+
+	lock     sync.Mutex                    // Guards the fields below.
+	pending  map[memoryRecord]pendingWrite // The latest write of each record not taken by a flush yet.
+	inFlight []pendingWrite                // The writes taken by the running flush and not persisted yet.
+	sequence uint64                        // Order of the last write followed.
+	stats    basetypes.FlushStats          // Flushes since the storage was created.
+
+	flushLock sync.Mutex    // Held by the running flush, so the flushes run one at a time.
+	wake      chan struct{} // Starts a flush early once a batch of writes is pending.
+	stop      chan struct{} // Closed by Close to stop the background flushes.
+	done      chan struct{} // Closed once the background flushes stopped.
+	closeOnce sync.Once     // Ensures stop is closed once.
+}
+
+// NewWriteBehindFunctions returns a write-behind storage persisting its records to back, a MySQL or SQLite storage,
+// and starts its background flushes.
+// Returns an error if back isn't a sql storage.
+func NewWriteBehindFunctions(back BaseFucntionsInterface, options WriteBehindOptions) (*WriteBehindFunctions, error) {
+	storage, ok := back.(sqlStorage)
+	if !ok {
+		return nil, errors.New("Write-behind requires a sql storage")
+	}
+
+	u := &WriteBehindFunctions{
+		back:    storage,
+		options: options.withDefaults(),
+		pending: make(map[memoryRecord]pendingWrite),
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	u.front = &MemoryFunctions{journal: u.follow}
+	u.front.mapInitiater.Do(u.front.initData)
+	u.BaseFucntionsInterface = u.front
+	u.TrashInterface = u.front
+	u.TransactionalInterface = u.front
+	u.SequenceInterface = u.front
+
+	go u.run()
+	return u, nil
+}
+
+// GetFunctions returns the WriteBehindFunctions instance as a BaseFucntionsInterface.
+func (u *WriteBehindFunctions) GetFunctions() BaseFucntionsInterface {
+	return u
+}
+
+// EnsureIndex creates the table of a collection in the sql storage and loads its records into the memory front,
+// the trashed ones included. The records already written in the front are kept, as they are newer.
+func (u *WriteBehindFunctions) EnsureIndex(dbName basetypes.DBName, collectionName basetypes.CollectionName, indexData interface{}) error {
+	return u.EnsureIndexContext(context.Background(), dbName, collectionName, indexData)
+}
+
+// EnsureIndexContext is EnsureIndex, giving up once ctx is done.
+func (u *WriteBehindFunctions) EnsureIndexContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, indexData interface{}) error {
+	if err := u.back.EnsureIndexContext(ctx, dbName, collectionName, indexData); err != nil {
+		return err
+	}
+	if err := u.front.EnsureIndexContext(ctx, dbName, collectionName, indexData); err != nil {
+		return err
+	}
+	u.models.register(collectionName, indexData)
+	return u.load(ctx, dbName, collectionName)
+}
+
+// load reads every record of the table of a collection into the memory front, skipping the records written in the front.
+// The table is read in pages of BatchSize records ordered by primary key, each merged into the front before the next
+// is read, so neither the query nor the copy of its records grow with the table.
+func (u *WriteBehindFunctions) load(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName) error {
+	model := u.models.get(collectionName)
+	if model == nil {
+		return nil
+	}
+	key, ok := primaryField(model)
+	if !ok {
+		return errors.New("Write-behind requires a primary key")
+	}
+	conn, err := u.back.getConn()
+	if err != nil {
+		return err
+	}
+
+	var after interface{}
+	for {
+		statement := u.back.builder().PageAfter(string(collectionName), key.Name, after, u.options.BatchSize)
+		records, last, err := u.loadPage(ctx, conn, collectionName, model, key, statement)
+		if err != nil {
+			return err
+		}
+		u.merge(dbName, records)
+		if len(records) < u.options.BatchSize {
+			return nil
+		}
+		after = last
+	}
+}
+
+// loadPage reads the records selected by statement by memory key, with the primary key of the last one
+// in the form its column stores it.
+func (u *WriteBehindFunctions) loadPage(ctx context.Context, conn *sql.DB, collectionName basetypes.CollectionName, model reflect.Type, key basedialects.Column, statement basedialects.Statement) (map[string]interface{}, interface{}, error) {
+	rows, err := conn.QueryContext(ctx, statement.Query, statement.Values...)
+	if err != nil {
+		return nil, nil, err
+	}
+	iterator, err := newRecordIterator(rows, model)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer iterator.Close()
+
+	records := make(map[string]interface{})
+	var last interface{}
+	for iterator.Next() {
+		record := iterator.Record()
+		recordKey, err := memoryKey(collectionName, record)
+		if err != nil {
+			return nil, nil, err
+		}
+		records[recordKey] = record
+		// A text key is bound as text, as compared to a number its column isn't in the order of ORDER BY,
+		// and the next page would skip the keys sorting after "10" but numbering below it
+		if field := reflect.ValueOf(record).Field(key.FieldIndex()); field.Kind() == reflect.String {
+			last = field.String()
+		} else {
+			last = field.Interface()
+		}
+	}
+	return records, last, iterator.Err()
+}
+
+// merge puts the records loaded from a table into the memory front, skipping the records written in the front, as they are newer.
+func (u *WriteBehindFunctions) merge(dbName basetypes.DBName, records map[string]interface{}) {
+	u.front.lock.Lock()
+	defer u.front.lock.Unlock()
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	store := u.front.database(dbName)
+	for key, record := range records {
+		if _, ok := store[key]; ok {
+			continue
+		}
+		if _, ok := u.pending[memoryRecord{db: dbName, key: key}]; ok {
+			continue
+		}
+		store[key] = record
+		u.front.reserveID(dbName, key)
+	}
+}
+
+// FindOne retrieves a record from the memory front. A condition map holding only the primary key is looked up by key.
+// Like the sql storages it returns sql.ErrNoRows if no record matches.
+func (u *WriteBehindFunctions) FindOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) (interface{}, error) {
+	return u.FindOneContext(context.Background(), dbName, collectionName, query)
+}
+
+// FindOneContext is FindOne, giving up once ctx is done.
+func (u *WriteBehindFunctions) FindOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) (interface{}, error) {
+	record, err := u.front.FindOneContext(ctx, dbName, collectionName, u.keyCondition(collectionName, query, false))
+	if errors.Is(err, errDataNotFound) {
+		return nil, sql.ErrNoRows
+	}
+	return record, err
+}
+
+// UpdateOne updates a record in the memory front. With a map of columns and without upsert the columns are merged
+// into the stored model, see updateColumns, any other data replaces the record as in the memory storage.
+func (u *WriteBehindFunctions) UpdateOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error {
+	return u.UpdateOneContext(context.Background(), dbName, collectionName, query, data, upsert)
+}
+
+// UpdateOneContext is UpdateOne, giving up once ctx is done.
+func (u *WriteBehindFunctions) UpdateOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, data interface{}, upsert bool) error {
+	columns, ok := data.(map[string]interface{})
+	if !ok || upsert {
+		return u.front.UpdateOneContext(ctx, dbName, collectionName, query, data, upsert)
+	}
+	return u.updateColumns(ctx, dbName, collectionName, query, columns)
+}
+
+// UpdateMany applies several updates to the memory front, each like UpdateOne.
+func (u *WriteBehindFunctions) UpdateMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, updates []basetypes.BulkUpdate) (basetypes.BulkResult, error) {
+	return u.UpdateManyContext(context.Background(), dbName, collectionName, updates)
+}
+
+// UpdateManyContext is UpdateMany, giving up once ctx is done. The context is checked before each update.
+func (u *WriteBehindFunctions) UpdateManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, updates []basetypes.BulkUpdate) (basetypes.BulkResult, error) {
+	return bulkApply(ctx, len(updates), func(index int) (basetypes.ID, error) {
+		update := updates[index]
+		return "", u.UpdateOneContext(ctx, dbName, collectionName, update.Query, update.Data, update.Upsert)
+	})
+}
+
+// DeleteOne deletes a record from the memory front, moving a record of a soft deletable model to the trash instead.
+// A condition map holding the primary key and a version returns ErrVersionConflict if the record moved on, as in the sql storages.
+func (u *WriteBehindFunctions) DeleteOne(dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) error {
+	return u.DeleteOneContext(context.Background(), dbName, collectionName, query)
+}
+
+// DeleteOneContext is DeleteOne, giving up once ctx is done.
+func (u *WriteBehindFunctions) DeleteOneContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}) error {
+	return u.front.DeleteOneContext(ctx, dbName, collectionName, u.keyCondition(collectionName, query, true))
+}
+
+// DeleteMany deletes several records from the memory front, each like DeleteOne.
+func (u *WriteBehindFunctions) DeleteMany(dbName basetypes.DBName, collectionName basetypes.CollectionName, queries []interface{}) (basetypes.BulkResult, error) {
+	return u.DeleteManyContext(context.Background(), dbName, collectionName, queries)
+}
+
+// DeleteManyContext is DeleteMany, giving up once ctx is done. The context is checked before each deletion.
+func (u *WriteBehindFunctions) DeleteManyContext(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, queries []interface{}) (basetypes.BulkResult, error) {
+	conditions := make([]interface{}, len(queries))
+	for i, query := range queries {
+		conditions[i] = u.keyCondition(collectionName, query, true)
+	}
+	return u.front.DeleteManyContext(ctx, dbName, collectionName, conditions)
+}
+
+// updateColumns merges the columns into the model of the record matching query, which it replaces at its next version.
+// As in the sql storages a version among the columns requires the record to still be at it. No record matching the query
+// returns sql.ErrNoRows, as FindOne does. Without a version the merge is done again if the record changed since it was read.
+func (u *WriteBehindFunctions) updateColumns(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName, query interface{}, columns map[string]interface{}) error {
+	for {
+		stored, err := u.front.FindOneContext(ctx, dbName, collectionName, u.keyCondition(collectionName, query, false))
+		if errors.Is(err, errDataNotFound) {
+			return sql.ErrNoRows
+		}
+		if err != nil {
+			return err
+		}
+		merged, err := mergeColumns(stored, columns)
+		if err != nil {
+			return err
+		}
+		err = u.front.UpdateOneContext(ctx, dbName, collectionName, nil, merged, false)
+		if errors.Is(err, ErrVersionConflict) && toVersion(columns[versionField]) == 0 {
+			continue
+		}
+		if errors.Is(err, errDataNotFound) {
+			return sql.ErrNoRows
+		}
+		return err
+	}
+}
+
+// keyCondition returns a condition map holding only the primary key of the model of a collection as a model with that key,
+// which the memory front looks up by key instead of scanning the collection. With versioned, a version in the condition
+// is set on the model too, so the front checks it. Any other condition is returned unchanged.
+func (u *WriteBehindFunctions) keyCondition(collectionName basetypes.CollectionName, condition interface{}, versioned bool) interface{} {
+	columns, ok := condition.(map[string]interface{})
+	model := u.models.get(collectionName)
+	if !ok || model == nil {
+		return condition
+	}
+	primary := primaryColumn(model)
+	if _, ok := columns[primary]; !ok || primary == "" {
+		return condition
+	}
+	for name := range columns {
+		if name != primary && (!versioned || name != versionField) {
+			return condition
+		}
+	}
+	key, err := mergeColumns(reflect.Zero(model).Interface(), columns)
+	if err != nil {
+		return condition
+	}
+	if _, ok := key.(basemodels.BaseModels); !ok {
+		return condition
+	}
+	return key
+}
+
+// primaryColumn returns the name of the primary key column of a model type, empty if it has none.
+func primaryColumn(model reflect.Type) string {
+	column, _ := primaryField(model)
+	return column.Name
+}
+
+// primaryField returns the primary key column of a model type, ok is false if it has none.
+func primaryField(model reflect.Type) (basedialects.Column, bool) {
+	columns, _ := basedialects.Columns(reflect.Zero(model).Interface())
+	for _, column := range columns {
+		if column.PrimaryKey {
+			return column, true
+		}
+	}
+	return basedialects.Column{}, false
+}
+
+// mergeColumns returns a copy of a model with the fields of the db tagged columns set to their values.
+// Returns an error if the record isn't a model, a column isn't one of its columns or a value doesn't fit its field.
+func mergeColumns(record interface{}, columns map[string]interface{}) (interface{}, error) {
+	modelColumns, err := basedialects.Columns(record)
+	if err != nil {
+		return nil, err
+	}
+	merged := reflect.New(reflect.TypeOf(record)).Elem()
+	merged.Set(reflect.ValueOf(record))
+	for name, value := range columns {
+		found := false
+		for _, column := range modelColumns {
+			if column.Name != name {
+				continue
+			}
+			found = true
+			if err := setColumn(merged.Field(column.FieldIndex()), value); err != nil {
+				return nil, err
+			}
+		}
+		if !found {
+			return nil, errors.New("Unknown column " + name)
+		}
+	}
+	return merged.Interface(), nil
+}
+
+// setColumn sets the field of a column to a value, converting numbers to numbers and strings to strings.
+func setColumn(field reflect.Value, value interface{}) error {
+	source := reflect.ValueOf(value)
+	switch {
+	case !source.IsValid():
+		field.Set(reflect.Zero(field.Type()))
+	case source.Type().AssignableTo(field.Type()):
+		field.Set(source)
+	case isNumberKind(source.Kind()) && isNumberKind(field.Kind()), source.Kind() == reflect.String && field.Kind() == reflect.String:
+		field.Set(source.Convert(field.Type()))
+	default:
+		return errors.New("Invalid value for column")
+	}
+	return nil
+}
+
+// isNumberKind returns whether a kind is an integer or floating point number.
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
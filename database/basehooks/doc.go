@@ -0,0 +1,4 @@
+// Package basehooks registers the callbacks run around the writes of the storages of the controllers.
+// Before-hooks run ahead of an insert, update or deletion of a collection and may change its data or abort it,
+// after-hooks run once it succeeded, so behaviour like timestamping or slug generation is added without editing the storages.
+package basehooks
@@ -0,0 +1,196 @@
+package basehooks
+
+import (
+	"context"
+	"sync"
+	"websays/database/basetypes"
+)
+
+// Operation is a kind of write the hooks are registered for.
+type Operation string
+
+const (
+	ADD    Operation = "add"    // Add, every record of AddMany and the inserts of transactions.
+	UPDATE Operation = "update" // UpdateOne, every update of UpdateMany and the updates of transactions.
+	DELETE Operation = "delete" // DeleteOne, every deletion of DeleteMany and the deletions of transactions, moves to the trash included.
+)
+
+// AllDatabases registers a hook for the writes of every database, those named by the X-Database header included.
+// It isn't the empty name, so a controller configured without a database name only hooks the writes of its own.
+const AllDatabases basetypes.DBName = "*"
+
+// AllCollections registers a hook for the writes of every collection.
+const AllCollections basetypes.CollectionName = ""
+
+// Event is a write passed to the hooks of its collection and operation.
+type Event struct {
+	Operation      Operation                // The kind of write.
+	DBName         basetypes.DBName         // The database written to.
+	CollectionName basetypes.CollectionName // The collection written to.
+	Query          interface{}              // The query of an update or deletion, nil for an insert.
+	Data           interface{}              // The record inserted or the data of an update, nil for a deletion. Before-hooks may replace it.
+	Upsert         bool                     // Whether an update inserts Data when no record matches Query.
+	ID             basetypes.ID             // The ID of the record inserted, only set for the after-hooks of inserts.
+}
+
+// BeforeHook runs before a write. It may change the data of the event, and returning an error aborts the write with it.
+type BeforeHook func(ctx context.Context, event *Event) error
+
+// AfterHook runs once a write succeeded, in a transaction once it is committed.
+type AfterHook func(ctx context.Context, event Event)
+
+// hookKey names the hooks of an operation on a collection of a database.
+type hookKey struct {
+	dbName         basetypes.DBName
+	collectionName basetypes.CollectionName
+	operation      Operation
+}
+
+// namedBefore is a before-hook registered under a name.
+type namedBefore struct {
+	name string
+	hook BeforeHook
+}
+
+// namedAfter is an after-hook registered under a name.
+type namedAfter struct {
+	name string
+	hook AfterHook
+}
+
+// Registry holds the hooks of the writes by database, collection and operation, so the hooks of a database
+// don't run for the writes of the other databases kept by the same storage. The hooks run in the order they were
+// registered, those registered for AllDatabases first, then those registered for AllCollections. Registering a hook
+// under a name already registered for the same database, collection and operation replaces it in place,
+// so the controllers can register their hooks each time they index their collection.
+type Registry struct {
+	lock   sync.RWMutex
+	before map[hookKey][]namedBefore // The before-hooks of each database, collection and operation.
+	after  map[hookKey][]namedAfter  // The after-hooks of each database, collection and operation.
+}
+
+var instance *Registry
+var once sync.Once
+
+// GetInstance returns the registry of the hooks around the storages of the controllers.
+func GetInstance() *Registry {
+	once.Do(func() {
+		instance = NewRegistry()
+	})
+	return instance
+}
+
+// NewRegistry returns a registry without hooks.
+func NewRegistry() *Registry {
+	return &Registry{before: make(map[hookKey][]namedBefore), after: make(map[hookKey][]namedAfter)}
+}
+
+// Before registers a hook under name to run before the writes of an operation on a collection of a database.
+func (u *Registry) Before(dbName basetypes.DBName, collectionName basetypes.CollectionName, operation Operation, name string, hook BeforeHook) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	key := hookKey{dbName: dbName, collectionName: collectionName, operation: operation}
+	for i := range u.before[key] {
+		if u.before[key][i].name == name {
+			u.before[key][i].hook = hook
+			return
+		}
+	}
+	u.before[key] = append(u.before[key], namedBefore{name: name, hook: hook})
+}
+
+// After registers a hook under name to run once the writes of an operation on a collection of a database succeeded.
+func (u *Registry) After(dbName basetypes.DBName, collectionName basetypes.CollectionName, operation Operation, name string, hook AfterHook) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	key := hookKey{dbName: dbName, collectionName: collectionName, operation: operation}
+	for i := range u.after[key] {
+		if u.after[key][i].name == name {
+			u.after[key][i].hook = hook
+			return
+		}
+	}
+	u.after[key] = append(u.after[key], namedAfter{name: name, hook: hook})
+}
+
+// Remove unregisters the before and after hooks registered under name for an operation on a collection of a database.
+func (u *Registry) Remove(dbName basetypes.DBName, collectionName basetypes.CollectionName, operation Operation, name string) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	key := hookKey{dbName: dbName, collectionName: collectionName, operation: operation}
+	before := u.before[key][:0:0]
+	for _, hook := range u.before[key] {
+		if hook.name != name {
+			before = append(before, hook)
+		}
+	}
+	u.before[key] = before
+	after := u.after[key][:0:0]
+	for _, hook := range u.after[key] {
+		if hook.name != name {
+			after = append(after, hook)
+		}
+	}
+	u.after[key] = after
+}
+
+// RunBefore runs the before-hooks of the database, collection and operation of an event, stopping at the first error, which it returns.
+func (u *Registry) RunBefore(ctx context.Context, event *Event) error {
+	for _, hook := range u.beforeHooks(event.DBName, event.CollectionName, event.Operation) {
+		if err := hook.hook(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunAfter runs the after-hooks of the database, collection and operation of an event.
+func (u *Registry) RunAfter(ctx context.Context, event Event) {
+	for _, hook := range u.afterHooks(event.DBName, event.CollectionName, event.Operation) {
+		hook.hook(ctx, event)
+	}
+}
+
+// beforeHooks returns the before-hooks of an operation on a collection of a database, those of every database first,
+// then those of every collection.
+func (u *Registry) beforeHooks(dbName basetypes.DBName, collectionName basetypes.CollectionName, operation Operation) []namedBefore {
+	u.lock.RLock()
+	defer u.lock.RUnlock()
+
+	hooks := []namedBefore{}
+	for _, key := range hookKeys(dbName, collectionName, operation) {
+		hooks = append(hooks, u.before[key]...)
+	}
+	return hooks
+}
+
+// afterHooks returns the after-hooks of an operation on a collection of a database, those of every database first,
+// then those of every collection.
+func (u *Registry) afterHooks(dbName basetypes.DBName, collectionName basetypes.CollectionName, operation Operation) []namedAfter {
+	u.lock.RLock()
+	defer u.lock.RUnlock()
+
+	hooks := []namedAfter{}
+	for _, key := range hookKeys(dbName, collectionName, operation) {
+		hooks = append(hooks, u.after[key]...)
+	}
+	return hooks
+}
+
+// hookKeys returns the keys the hooks of an operation on a collection of a database are registered under, in the order they run.
+func hookKeys(dbName basetypes.DBName, collectionName basetypes.CollectionName, operation Operation) []hookKey {
+	keys := []hookKey{{dbName: AllDatabases, collectionName: AllCollections, operation: operation}}
+	if collectionName != AllCollections {
+		keys = append(keys, hookKey{dbName: AllDatabases, collectionName: collectionName, operation: operation})
+	}
+	if dbName != AllDatabases {
+		keys = append(keys, hookKey{dbName: dbName, collectionName: AllCollections, operation: operation})
+		if collectionName != AllCollections {
+			keys = append(keys, hookKey{dbName: dbName, collectionName: collectionName, operation: operation})
+		}
+	}
+	return keys
+}
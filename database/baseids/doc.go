@@ -0,0 +1,4 @@
+// Package baseids generates the IDs of the records added through the controllers.
+// An IDGenerator is chosen per controller among the strategies: a sequence per collection handed out by the storage,
+// random UUIDv4 keys, time ordered UUIDv7 and ULID keys, and snowflake integers unique across the nodes of a cluster.
+package baseids
@@ -0,0 +1,45 @@
+package baseids
+
+import (
+	"context"
+	"errors"
+	"websays/config"
+	"websays/database/basetypes"
+)
+
+// Strategy names how an IDGenerator makes the IDs of the records, as set per controller in the config.
+type Strategy string
+
+const (
+	SEQUENCE  Strategy = "sequence"  // Integers from a sequence per collection, the default.
+	UUIDV4    Strategy = "uuidv4"    // Random UUIDs.
+	UUIDV7    Strategy = "uuidv7"    // UUIDs ordered by their creation time.
+	ULID      Strategy = "ulid"      // ULIDs, ordered by their creation time.
+	SNOWFLAKE Strategy = "snowflake" // Integers ordered by their creation time, unique across nodes with distinct node IDs.
+)
+
+// IDGenerator makes the ID of a record before it is added to a collection.
+type IDGenerator interface {
+	// NextID returns the ID of the next record added to a collection of a database.
+	// The empty ID leaves the ID to the storage, like the AUTO_INCREMENT columns of the sql storages.
+	NextID(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName) (basetypes.ID, error)
+}
+
+// New returns the IDGenerator of a strategy for the records of storage, the storage of a controller.
+// The empty strategy is the sequence, which is the only one relying on the storage.
+// It returns an error for an unknown strategy or a snowflake node ID out of range.
+func New(strategy Strategy, storage interface{}) (IDGenerator, error) {
+	switch strategy {
+	case "", SEQUENCE:
+		return NewSequence(storage), nil
+	case UUIDV4:
+		return &UUIDv4{}, nil
+	case UUIDV7:
+		return &UUIDv7{}, nil
+	case ULID:
+		return &ULIDs{}, nil
+	case SNOWFLAKE:
+		return NewSnowflake(config.GetInstance().IDs.NodeID)
+	}
+	return nil, errors.New("Unknown ID strategy " + string(strategy))
+}
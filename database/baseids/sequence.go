@@ -0,0 +1,32 @@
+package baseids
+
+import (
+	"context"
+	"websays/database/basefunctions"
+	"websays/database/basetypes"
+)
+
+// Sequence hands out the integers of the sequence of each collection kept by the storage.
+// With a storage not keeping sequences, like the sql storages assigning the IDs on insert,
+// it returns the empty ID so the storage picks the ID on insert.
+type Sequence struct {
+	sequences basefunctions.SequenceInterface // The sequences of the storage, nil if it doesn't keep any.
+}
+
+// NewSequence returns the Sequence generator of a storage.
+func NewSequence(storage interface{}) *Sequence {
+	sequences, _ := storage.(basefunctions.SequenceInterface)
+	return &Sequence{sequences: sequences}
+}
+
+// NextID returns the next integer of the sequence of the collection, or the empty ID if the storage assigns the IDs.
+func (u *Sequence) NextID(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName) (basetypes.ID, error) {
+	if u.sequences == nil {
+		return "", ctx.Err()
+	}
+	id, err := u.sequences.NextSequenceContext(ctx, dbName, collectionName)
+	if err != nil {
+		return "", err
+	}
+	return basetypes.IntID(id), nil
+}
@@ -0,0 +1,66 @@
+package baseids
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+	"websays/database/basetypes"
+)
+
+// Layout of a snowflake ID: 41 bits of milliseconds since snowflakeEpoch, 10 bits of node ID and 12 bits of sequence.
+const (
+	snowflakeEpoch        = 1704067200000 // 2024-01-01T00:00:00Z in milliseconds since the Unix epoch.
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+	maxSnowflakeNode      = 1<<snowflakeNodeBits - 1
+	maxSnowflakeSequence  = 1<<snowflakeSequenceBits - 1
+)
+
+// Snowflake makes 63 bit integer IDs ordered by creation time, unique across up to 1024 nodes
+// as long as each node is configured with its own node ID.
+// Up to 4096 IDs are made per millisecond, after which it waits for the next millisecond.
+// IDs above 2^53-1, which is all of them, are encoded in JSON as strings.
+type Snowflake struct {
+	lock          sync.Mutex
+	node          int64 // The node ID of this process.
+	lastTimestamp int64 // Millisecond of the last ID, since snowflakeEpoch.
+	sequence      int64 // Sequence of the last ID within its millisecond.
+}
+
+// NewSnowflake returns the Snowflake generator of a node, or an error if the node ID is out of range.
+func NewSnowflake(node int) (*Snowflake, error) {
+	if node < 0 || node > maxSnowflakeNode {
+		return nil, errors.New("Snowflake node ID must be between 0 and " + strconv.Itoa(maxSnowflakeNode))
+	}
+	return &Snowflake{node: int64(node)}, nil
+}
+
+// NextID returns a new snowflake ID greater than the previous ones.
+func (u *Snowflake) NextID(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName) (basetypes.ID, error) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	timestamp := time.Now().UnixMilli() - snowflakeEpoch
+	if timestamp < u.lastTimestamp {
+		// The clock went back, keep counting in the last millisecond
+		timestamp = u.lastTimestamp
+	}
+	if timestamp == u.lastTimestamp {
+		u.sequence = (u.sequence + 1) & maxSnowflakeSequence
+		for u.sequence == 0 && timestamp <= u.lastTimestamp {
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
+			time.Sleep(100 * time.Microsecond)
+			timestamp = time.Now().UnixMilli() - snowflakeEpoch
+		}
+	} else {
+		u.sequence = 0
+	}
+	u.lastTimestamp = timestamp
+
+	id := timestamp<<(snowflakeNodeBits+snowflakeSequenceBits) | u.node<<snowflakeSequenceBits | u.sequence
+	return basetypes.IntID(id), nil
+}
@@ -0,0 +1,76 @@
+package baseids
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"sync"
+	"time"
+	"websays/database/basetypes"
+)
+
+// crockfordAlphabet is the Crockford base32 alphabet of the ULIDs, leaving out I, L, O and U.
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDs makes ULIDs, 26 characters encoding the milliseconds since the Unix epoch followed by 80 random bits,
+// like "01HZX3K8Q9V6T2M4N7P5R8S0WY". They are monotonic: a ULID made in the same millisecond as the previous one
+// increments its random bits instead of drawing new ones, so they sort by creation order.
+type ULIDs struct {
+	lock          sync.Mutex
+	lastTimestamp int64    // Millisecond of the last ULID.
+	lastEntropy   [10]byte // Random bits of the last ULID.
+}
+
+// NextID returns a new ULID sorting after the previous ones.
+func (u *ULIDs) NextID(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName) (basetypes.ID, error) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	timestamp := time.Now().UnixMilli()
+	if timestamp > u.lastTimestamp {
+		if _, err := rand.Read(u.lastEntropy[:]); err != nil {
+			return "", err
+		}
+		u.lastTimestamp = timestamp
+	} else if !increment(u.lastEntropy[:]) {
+		return "", errors.New("ULID entropy exhausted for the millisecond")
+	}
+
+	var ulid [16]byte
+	for i := 0; i < 6; i++ {
+		ulid[i] = byte(u.lastTimestamp >> (40 - 8*i))
+	}
+	copy(ulid[6:], u.lastEntropy[:])
+	return encodeULID(ulid), ctx.Err()
+}
+
+// increment adds one to the big endian number in bytes. It returns false if the number overflowed.
+func increment(bytes []byte) bool {
+	for i := len(bytes) - 1; i >= 0; i-- {
+		bytes[i]++
+		if bytes[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeULID returns the 26 Crockford base32 characters of the 128 bits of a ULID, 5 bits per character
+// after the 2 leading padding bits.
+func encodeULID(ulid [16]byte) basetypes.ID {
+	text := make([]byte, 26)
+	for i := range text {
+		// Bit offset of the character, counting the 2 padding bits
+		bit := i*5 - 2
+		var value int
+		for j := 0; j < 5; j++ {
+			position := bit + j
+			value <<= 1
+			if position >= 0 && ulid[position/8]&(0x80>>(position%8)) != 0 {
+				value |= 1
+			}
+		}
+		text[i] = crockfordAlphabet[value]
+	}
+	return basetypes.ID(text)
+}
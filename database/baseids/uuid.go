@@ -0,0 +1,76 @@
+package baseids
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"sync"
+	"time"
+	"websays/database/basetypes"
+)
+
+// UUIDv4 makes random version 4 UUIDs, like "9b2f6c1e-3d4a-4f8b-a2c7-5e1d0f9a8b7c".
+type UUIDv4 struct{}
+
+// NextID returns a new random UUID.
+func (u *UUIDv4) NextID(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName) (basetypes.ID, error) {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		return "", err
+	}
+	return formatUUID(uuid, 4), ctx.Err()
+}
+
+// UUIDv7 makes version 7 UUIDs, starting with the milliseconds since the Unix epoch so they sort by creation time.
+// The 12 bits following the timestamp count the UUIDs made in the same millisecond, keeping them in order too.
+type UUIDv7 struct {
+	lock          sync.Mutex
+	lastTimestamp int64  // Millisecond of the last UUID.
+	counter       uint16 // Counter of the last UUID within its millisecond.
+}
+
+// NextID returns a new UUID sorting after the previous ones.
+func (u *UUIDv7) NextID(ctx context.Context, dbName basetypes.DBName, collectionName basetypes.CollectionName) (basetypes.ID, error) {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[6:]); err != nil {
+		return "", err
+	}
+
+	u.lock.Lock()
+	timestamp := time.Now().UnixMilli()
+	if timestamp > u.lastTimestamp {
+		// Start from a random counter below half its range, leaving room for the UUIDs of the same millisecond
+		u.lastTimestamp = timestamp
+		u.counter = binary.BigEndian.Uint16(uuid[6:8]) & 0x7ff
+	} else if u.counter++; u.counter > 0xfff {
+		// The counter overflowed, borrow the next millisecond
+		u.lastTimestamp++
+		u.counter = 0
+	}
+	timestamp, counter := u.lastTimestamp, u.counter
+	u.lock.Unlock()
+
+	var stamp [8]byte
+	binary.BigEndian.PutUint64(stamp[:], uint64(timestamp))
+	copy(uuid[:6], stamp[2:])
+	binary.BigEndian.PutUint16(uuid[6:8], counter)
+	return formatUUID(uuid, 7), ctx.Err()
+}
+
+// formatUUID sets the version and the RFC 9562 variant of uuid and returns its hyphenated lowercase form.
+func formatUUID(uuid [16]byte, version byte) basetypes.ID {
+	uuid[6] = uuid[6]&0x0f | version<<4
+	uuid[8] = uuid[8]&0x3f | 0x80
+	text := make([]byte, 36)
+	hex.Encode(text[0:8], uuid[0:4])
+	text[8] = '-'
+	hex.Encode(text[9:13], uuid[4:6])
+	text[13] = '-'
+	hex.Encode(text[14:18], uuid[6:8])
+	text[18] = '-'
+	hex.Encode(text[19:23], uuid[8:10])
+	text[23] = '-'
+	hex.Encode(text[24:], uuid[10:])
+	return basetypes.ID(text)
+}
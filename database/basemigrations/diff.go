@@ -0,0 +1,170 @@
+package basemigrations
+
+import (
+	"database/sql"
+	"regexp"
+	"sort"
+	"strings"
+	"websays/database/basedialects"
+)
+
+// liveColumnsQuery reads the columns of a table of the current MySQL database in table order.
+const liveColumnsQuery = "SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, EXTRA, COLUMN_KEY FROM information_schema.COLUMNS " +
+	"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION"
+
+// integerWidth matches the display width MySQL 5 reports for integer types, like the (11) of int(11).
+var integerWidth = regexp.MustCompile(`^(TINYINT|SMALLINT|MEDIUMINT|INT|BIGINT)\(\d+\)`)
+
+// mysqlTypeAliases maps the type names MySQL reports differently from how they can be written.
+var mysqlTypeAliases = map[string]string{
+	"INTEGER": "INT",
+	"BOOL":    "TINYINT(1)",
+	"BOOLEAN": "TINYINT(1)",
+}
+
+// LiveColumns reads the columns of a table of the current MySQL database from information_schema.
+// A missing table has no columns.
+func LiveColumns(db *sql.DB, table string) ([]basedialects.Column, error) {
+	rows, err := db.Query(liveColumnsQuery, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make([]basedialects.Column, 0)
+	for rows.Next() {
+		var name, columnType, nullable, extra, key string
+		err = rows.Scan(&name, &columnType, &nullable, &extra, &key)
+		if err != nil {
+			return nil, err
+		}
+		column := basedialects.Column{
+			Name:          name,
+			Type:          strings.ToUpper(columnType),
+			Constraints:   []string{},
+			AutoIncrement: strings.Contains(strings.ToLower(extra), "auto_increment"),
+			PrimaryKey:    key == "PRI",
+		}
+		if nullable == "NO" && !column.PrimaryKey {
+			column.Constraints = append(column.Constraints, "NOT NULL")
+		}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
+
+// Diff returns the up and down statements bringing the live columns of a MySQL table in line with the `db` tags of a model.
+// No live columns mean a missing table, which is created. Columns are added, dropped, or modified when their type,
+// nullability or auto increment differ. Default values, unique keys and primary key changes are left to handwritten migrations.
+func Diff(table string, live []basedialects.Column, model interface{}) (up []string, down []string, err error) {
+	builder := basedialects.Builder{Dialect: basedialects.MySQL{}}
+	quotedTable := builder.Dialect.Quote(table)
+	if len(live) == 0 {
+		create, err := builder.CreateTable(table, model)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []string{create.Query}, []string{"DROP TABLE " + quotedTable}, nil
+	}
+
+	wanted, err := basedialects.Columns(model)
+	if err != nil {
+		return nil, nil, err
+	}
+	liveByName := make(map[string]basedialects.Column)
+	for _, column := range live {
+		liveByName[column.Name] = column
+	}
+
+	up = make([]string, 0)
+	down = make([]string, 0)
+	alter := "ALTER TABLE " + quotedTable
+	for _, column := range wanted {
+		current, ok := liveByName[column.Name]
+		delete(liveByName, column.Name)
+		switch {
+		case !ok:
+			up = append(up, alter+" ADD COLUMN "+builder.Dialect.ColumnDefinition(column))
+			down = append(down, alter+" DROP COLUMN "+builder.Dialect.Quote(column.Name))
+		case !sameColumn(column, current):
+			up = append(up, alter+" MODIFY COLUMN "+modifyDefinition(builder.Dialect, column))
+			down = append(down, alter+" MODIFY COLUMN "+modifyDefinition(builder.Dialect, current))
+		}
+	}
+	for _, column := range live {
+		if _, ok := liveByName[column.Name]; ok {
+			up = append(up, alter+" DROP COLUMN "+builder.Dialect.Quote(column.Name))
+			down = append(down, alter+" ADD COLUMN "+builder.Dialect.ColumnDefinition(column))
+		}
+	}
+
+	// The changes are reverted in the opposite order
+	for i, j := 0, len(down)-1; i < j; i, j = i+1, j-1 {
+		down[i], down[j] = down[j], down[i]
+	}
+	return up, down, nil
+}
+
+// DiffTables returns the migration bringing the live MySQL tables in line with the `db` tags of their models,
+// with the tables in alphabetical order. The migration has no statements when everything is up to date.
+func DiffTables(db *sql.DB, version int64, models map[string]interface{}) (Migration, error) {
+	tables := make([]string, 0, len(models))
+	for table := range models {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	migration := Migration{Version: version, Name: "diff", Up: []string{}, Down: []string{}}
+	for _, table := range tables {
+		live, err := LiveColumns(db, table)
+		if err != nil {
+			return Migration{}, err
+		}
+		up, down, err := Diff(table, live, models[table])
+		if err != nil {
+			return Migration{}, err
+		}
+		migration.Up = append(migration.Up, up...)
+		migration.Down = append(down, migration.Down...)
+	}
+	return migration, nil
+}
+
+// sameColumn reports whether a live column matches the column of a model.
+func sameColumn(wanted basedialects.Column, live basedialects.Column) bool {
+	return mysqlType(wanted.Type) == mysqlType(live.Type) &&
+		notNull(wanted) == notNull(live) &&
+		wanted.AutoIncrement == live.AutoIncrement
+}
+
+// mysqlType normalizes a MySQL type for comparison.
+func mysqlType(columnType string) string {
+	columnType = strings.ToUpper(strings.TrimSpace(columnType))
+	if alias, ok := mysqlTypeAliases[columnType]; ok {
+		return alias
+	}
+	if columnType == "TINYINT(1)" {
+		return columnType
+	}
+	return integerWidth.ReplaceAllString(columnType, "$1")
+}
+
+// notNull reports whether a column rejects NULL values, primary keys always do.
+func notNull(column basedialects.Column) bool {
+	if column.PrimaryKey {
+		return true
+	}
+	for _, constraint := range column.Constraints {
+		if strings.EqualFold(constraint, "NOT NULL") {
+			return true
+		}
+	}
+	return false
+}
+
+// modifyDefinition returns the definition of a column in a MODIFY COLUMN clause,
+// which keeps the primary key of the table as it is.
+func modifyDefinition(dialect basedialects.Dialect, column basedialects.Column) string {
+	column.PrimaryKey = false
+	return dialect.ColumnDefinition(column)
+}
@@ -0,0 +1,5 @@
+// Package basemigrations evolves the schema of the sql storages with ordered, versioned migrations.
+// Migrations are pairs of `<version>_<name>.up.sql` and `<version>_<name>.down.sql` files, the applied
+// versions are tracked in the schema_migrations table. Diff generates the migration bringing a live
+// MySQL table in line with the `db` tags of its model, by comparing them against information_schema.
+package basemigrations
@@ -0,0 +1,141 @@
+package basemigrations
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// File name suffixes of the two halves of a migration.
+const (
+	upSuffix   = ".up.sql"
+	downSuffix = ".down.sql"
+)
+
+// Migration is one versioned change of the schema.
+type Migration struct {
+	Version int64    // Orders the migrations, a timestamp like 20240101120000 for generated ones.
+	Name    string   // Describes the change, the part of the file name after the version.
+	Up      []string // Statements applying the change.
+	Down    []string // Statements reverting the change, empty if it can't be reverted.
+}
+
+// FileName returns the name of the up or down file of the migration without its suffix.
+func (u Migration) FileName() string {
+	return strconv.FormatInt(u.Version, 10) + "_" + u.Name
+}
+
+// Load reads the migrations of a directory ordered by version.
+// A missing directory holds no migrations.
+func Load(path string) ([]Migration, error) {
+	entries, err := os.ReadDir(path)
+	if os.IsNotExist(err) {
+		return []Migration{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		var suffix string
+		switch {
+		case strings.HasSuffix(name, upSuffix):
+			suffix = upSuffix
+		case strings.HasSuffix(name, downSuffix):
+			suffix = downSuffix
+		default:
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimSuffix(name, suffix), "_", 2)
+		version, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil || len(parts) != 2 {
+			return nil, fmt.Errorf("Invalid migration file name %s, expected <version>_<name>%s", name, suffix)
+		}
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: parts[1]}
+			byVersion[version] = migration
+		}
+		if migration.Name != parts[1] {
+			return nil, fmt.Errorf("Migration %d has two names, %s and %s", version, migration.Name, parts[1])
+		}
+
+		content, err := os.ReadFile(filepath.Join(path, name))
+		if err != nil {
+			return nil, err
+		}
+		if suffix == upSuffix {
+			migration.Up = splitStatements(string(content))
+		} else {
+			migration.Down = splitStatements(string(content))
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if len(migration.Up) == 0 {
+			return nil, fmt.Errorf("Migration %d has no up statements", migration.Version)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Write stores a migration as its up and down files in a directory.
+func Write(path string, migration Migration) error {
+	if len(migration.Up) == 0 {
+		return errors.New("Nothing to migrate")
+	}
+	err := os.MkdirAll(path, 0755)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(filepath.Join(path, migration.FileName()+upSuffix), []byte(joinStatements(migration.Up)), 0644)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(path, migration.FileName()+downSuffix), []byte(joinStatements(migration.Down)), 0644)
+}
+
+// splitStatements splits the content of a migration file into statements.
+// A statement ends with a semicolon at the end of a line, lines starting with -- are comments.
+func splitStatements(content string) []string {
+	statements := make([]string, 0)
+	current := make([]string, 0)
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		if strings.HasSuffix(trimmed, ";") {
+			current = append(current, strings.TrimSuffix(trimmed, ";"))
+			statements = append(statements, strings.Join(current, "\n"))
+			current = current[:0]
+			continue
+		}
+		current = append(current, trimmed)
+	}
+	if len(current) > 0 {
+		statements = append(statements, strings.Join(current, "\n"))
+	}
+	return statements
+}
+
+// joinStatements writes statements one per line, each ended by a semicolon.
+func joinStatements(statements []string) string {
+	var builder strings.Builder
+	for _, statement := range statements {
+		builder.WriteString(statement + ";\n")
+	}
+	return builder.String()
+}
@@ -0,0 +1,145 @@
+package basemigrations
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+	"websays/config"
+	"websays/database/baseconnections"
+	"websays/database/basedialects"
+	"websays/database/basetypes"
+)
+
+// migrationsObject is a singleton holding the models of the MySQL tables and running the migrations of the configured directory.
+type migrationsObject struct {
+	lock   sync.Mutex
+	models map[string]interface{} // Models of the MySQL tables by table name, compared by the diff.
+}
+
+var (
+	instance *migrationsObject
+	once     sync.Once
+)
+
+// GetInstance returns the singleton instance of the migrationsObject.
+func GetInstance() *migrationsObject {
+	once.Do(func() {
+		instance = &migrationsObject{models: make(map[string]interface{})}
+	})
+	return instance
+}
+
+// RegisterModel records the model of a MySQL table, so the diff can compare the table against its `db` tags.
+func (u *migrationsObject) RegisterModel(table string, model interface{}) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	u.models[table] = model
+}
+
+// Models returns a copy of the registered models by table name.
+func (u *migrationsObject) Models() map[string]interface{} {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	models := make(map[string]interface{}, len(u.models))
+	for table, model := range u.models {
+		models[table] = model
+	}
+	return models
+}
+
+// ManagesTables reports whether the MySQL tables are left to the migrations, once a migrations directory is configured.
+// The storages then don't create or alter the tables themselves, so a table never gets ahead of its migrations.
+func (u *migrationsObject) ManagesTables() bool {
+	return config.GetInstance().Migrations.Path != ""
+}
+
+// GetMigrator returns a Migrator for the MySQL database with the migrations of the configured directory.
+func (u *migrationsObject) GetMigrator() (*Migrator, error) {
+	migrations, err := Load(config.GetInstance().Migrations.Path)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := u.getConn()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{DB: conn, Builder: basedialects.Builder{Dialect: basedialects.MySQL{}}, Migrations: migrations}, nil
+}
+
+// RunCommand runs a migrations command against the MySQL database and prints its outcome:
+//   - up: applies the pending migrations.
+//   - down [steps]: reverts the last steps migrations, one by default.
+//   - status: lists the migrations and whether they are applied.
+//   - diff: writes a migration bringing the tables of the registered models in line with their `db` tags.
+func (u *migrationsObject) RunCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("Usage: migrate up|down [steps]|status|diff")
+	}
+	migrator, err := u.GetMigrator()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		for _, migration := range applied {
+			fmt.Println("Applied", migration.FileName())
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return errors.New("Steps must be a positive number")
+			}
+		}
+		reverted, err := migrator.Down(steps)
+		for _, migration := range reverted {
+			fmt.Println("Reverted", migration.FileName())
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied"
+			}
+			fmt.Println(status.FileName(), state)
+		}
+		return nil
+	case "diff":
+		version, _ := strconv.ParseInt(time.Now().UTC().Format("20060102150405"), 10, 64)
+		migration, err := DiffTables(migrator.DB, version, u.Models())
+		if err != nil {
+			return err
+		}
+		if len(migration.Up) == 0 {
+			fmt.Println("The tables are up to date")
+			return nil
+		}
+		err = Write(config.GetInstance().Migrations.Path, migration)
+		if err == nil {
+			fmt.Println("Wrote", migration.FileName())
+		}
+		return err
+	}
+	return fmt.Errorf("Unknown migrate command %q", args[0])
+}
+
+// getConn returns the MySQL connection pool, or the error of connecting to it.
+func (u *migrationsObject) getConn() (*sql.DB, error) {
+	connection, err := baseconnections.GetInstance().GetConnection(basetypes.MYSQL)
+	if err != nil {
+		return nil, err
+	}
+	return connection.GetDB(basetypes.MYSQL).(*sql.DB), nil
+}
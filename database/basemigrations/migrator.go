@@ -0,0 +1,166 @@
+package basemigrations
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+	"websays/database/basedialects"
+	"websays/database/basefilters"
+)
+
+// schemaMigrationsTable is the table tracking the applied migrations.
+const schemaMigrationsTable = "schema_migrations"
+
+// schemaMigration is a row of the schema_migrations table.
+type schemaMigration struct {
+	Version   int64  `db:"version,BIGINT,PRIMARY KEY"` // Version of the applied migration.
+	Name      string `db:"name,VARCHAR(255),NOT NULL"` // Name of the applied migration.
+	AppliedAt int64  `db:"applied_at,BIGINT,NOT NULL"` // Unix time the migration was applied at.
+}
+
+// MigrationStatus tells whether a migration is applied.
+type MigrationStatus struct {
+	Migration
+	Applied bool // The version is recorded in schema_migrations.
+}
+
+// Migrator applies and reverts migrations on a database, recording them in the schema_migrations table.
+// Each migration runs in a transaction with its record. MySQL commits DDL statements implicitly,
+// so a migration failing halfway there has to be fixed by hand before it is applied again.
+type Migrator struct {
+	DB         *sql.DB              // The database to migrate.
+	Builder    basedialects.Builder // Generates the statements on schema_migrations in the syntax of the database.
+	Migrations []Migration          // The known migrations ordered by version.
+}
+
+// Up applies every pending migration in version order and returns the applied ones.
+// It stops at the first failing migration, the ones before it stay applied.
+func (u *Migrator) Up() ([]Migration, error) {
+	applied, err := u.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	done := make([]Migration, 0)
+	for _, migration := range u.Migrations {
+		if applied[migration.Version] {
+			continue
+		}
+		record := schemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now().Unix()}
+		insert, err := u.Builder.Insert(schemaMigrationsTable, record)
+		if err != nil {
+			return done, err
+		}
+		err = u.run(migration.Up, insert)
+		if err != nil {
+			return done, fmt.Errorf("Migration %s failed: %v", migration.FileName(), err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns the reverted ones.
+func (u *Migrator) Down(steps int) ([]Migration, error) {
+	applied, err := u.applied()
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]int64, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i] > versions[j]
+	})
+	if steps < len(versions) {
+		versions = versions[:steps]
+	}
+
+	known := make(map[int64]Migration)
+	for _, migration := range u.Migrations {
+		known[migration.Version] = migration
+	}
+
+	done := make([]Migration, 0)
+	for _, version := range versions {
+		migration, ok := known[version]
+		if !ok {
+			return done, fmt.Errorf("Migration %d is applied but its files are missing", version)
+		}
+		if len(migration.Down) == 0 {
+			return done, fmt.Errorf("Migration %s can't be reverted, it has no down statements", migration.FileName())
+		}
+		filter := basefilters.Eq("version", version)
+		err = u.run(migration.Down, u.Builder.DeleteOne(schemaMigrationsTable, &filter))
+		if err != nil {
+			return done, fmt.Errorf("Reverting migration %s failed: %v", migration.FileName(), err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Status returns every known migration and whether it is applied.
+func (u *Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := u.applied()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]MigrationStatus, 0, len(u.Migrations))
+	for _, migration := range u.Migrations {
+		statuses = append(statuses, MigrationStatus{Migration: migration, Applied: applied[migration.Version]})
+	}
+	return statuses, nil
+}
+
+// applied creates the schema_migrations table if needed and returns the applied versions.
+func (u *Migrator) applied() (map[int64]bool, error) {
+	create, err := u.Builder.CreateTable(schemaMigrationsTable, schemaMigration{})
+	if err != nil {
+		return nil, err
+	}
+	_, err = u.DB.Exec(create.Query, create.Values...)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := u.DB.Query("SELECT " + u.Builder.Dialect.Quote("version") + " FROM " + u.Builder.Dialect.Quote(schemaMigrationsTable))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]bool)
+	for rows.Next() {
+		var version int64
+		err = rows.Scan(&version)
+		if err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// run executes the statements of a migration and the statement recording it in one transaction.
+func (u *Migrator) run(statements []string, record basedialects.Statement) error {
+	tx, err := u.DB.Begin()
+	if err != nil {
+		return err
+	}
+	for _, statement := range statements {
+		_, err = tx.Exec(statement)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	_, err = tx.Exec(record.Query, record.Values...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -5,7 +5,9 @@ type DBName string
 type DbType int
 
 const (
-	MYSQL  DbType = 1
-	FILE   DbType = 2
-	MEMORY DbType = 3
+	MYSQL       DbType = 1
+	FILE        DbType = 2
+	MEMORY      DbType = 3
+	SQLITE      DbType = 4
+	WRITEBEHIND DbType = 5
 )
@@ -0,0 +1,50 @@
+package basetypes
+
+// BulkUpdate is one update of an UpdateMany call, taking the same arguments as UpdateOne.
+type BulkUpdate struct {
+	Query  interface{} // The query of the record to update, as for UpdateOne.
+	Data   interface{} // The data to update the record with.
+	Upsert bool        // Whether to insert Data, a model carrying its ID, when no record matches Query.
+}
+
+// BulkItemResult is the outcome of one item of a bulk operation.
+type BulkItemResult struct {
+	Index int    `json:"index"`           // Position of the item in the request.
+	ID    ID     `json:"id,omitempty"`    // ID of the record inserted by AddMany, if known.
+	Error string `json:"error,omitempty"` // Why the item failed, empty if it succeeded.
+}
+
+// BulkResult reports the outcome of every item of a bulk operation, in the order of the request.
+// The items are applied independently, so some can fail while the others succeed.
+type BulkResult struct {
+	Items     []BulkItemResult `json:"items"`     // Outcome of each item.
+	Succeeded int              `json:"succeeded"` // Number of items applied.
+	Failed    int              `json:"failed"`    // Number of items that failed.
+}
+
+// NewBulkResult returns the result of a bulk operation on size items, none of them reported yet.
+func NewBulkResult(size int) BulkResult {
+	items := make([]BulkItemResult, size)
+	for i := range items {
+		items[i].Index = i
+	}
+	return BulkResult{Items: items}
+}
+
+// Set reports the outcome of the item at index, the ID of the record it inserted, if any, or the error it failed with.
+func (u *BulkResult) Set(index int, id ID, err error) {
+	if err != nil {
+		u.Items[index].Error = err.Error()
+		u.Failed++
+		return
+	}
+	u.Items[index].ID = id
+	u.Succeeded++
+}
+
+// FailFrom reports every item from index on as failed with err, once the operation gave up before applying them.
+func (u *BulkResult) FailFrom(index int, err error) {
+	for i := index; i < len(u.Items); i++ {
+		u.Set(i, "", err)
+	}
+}
@@ -0,0 +1,12 @@
+package basetypes
+
+// CacheStats reports the activity of the cache of a storage since it was created.
+type CacheStats struct {
+	Hits          int64 `json:"hits"`          // Reads answered by the cache, the cached misses included.
+	NegativeHits  int64 `json:"negativeHits"`  // Reads answered by a cached miss, without a record.
+	Misses        int64 `json:"misses"`        // Reads passed on to the storage, as nothing fresh was cached for them.
+	Evictions     int64 `json:"evictions"`     // Entries dropped to keep the cache within its size.
+	Invalidations int64 `json:"invalidations"` // Writes invalidating the cached reads of a collection or database.
+	Entries       int   `json:"entries"`       // Entries held by the cache, the stale ones not dropped yet included.
+	Size          int   `json:"size"`          // Maximum number of entries held by the cache.
+}
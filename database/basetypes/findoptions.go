@@ -0,0 +1,68 @@
+package basetypes
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultLimit = 20  // Page size used when FindOptions.Limit is not set.
+	MaxLimit     = 100 // Largest page size a FindMany call will return.
+)
+
+// FindOptions controls the paging of a FindMany call.
+// A Cursor returned by a previous call takes precedence over Offset.
+type FindOptions struct {
+	Limit  int    // Maximum number of records to return, DefaultLimit if zero.
+	Offset int    // Number of matching records to skip.
+	Cursor string // Opaque cursor pointing to the next page.
+}
+
+// FindResult is a single page of records returned by FindMany.
+type FindResult struct {
+	Data       []interface{} `json:"data"`                 // Records of the current page.
+	Total      int           `json:"total"`                // Total number of records matching the query.
+	NextCursor string        `json:"nextCursor,omitempty"` // Cursor of the next page, empty on the last page.
+}
+
+// GetLimit returns the effective page size bounded by MaxLimit.
+func (u FindOptions) GetLimit() int {
+	if u.Limit <= 0 {
+		return DefaultLimit
+	}
+	if u.Limit > MaxLimit {
+		return MaxLimit
+	}
+	return u.Limit
+}
+
+// GetOffset returns the effective offset, decoding the cursor when one is present.
+func (u FindOptions) GetOffset() (int, error) {
+	if u.Cursor == "" {
+		if u.Offset < 0 {
+			return 0, errors.New("Offset can't be negative")
+		}
+		return u.Offset, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(u.Cursor)
+	if err != nil || !strings.HasPrefix(string(decoded), "o:") {
+		return 0, errors.New("Invalid cursor")
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(decoded), "o:"))
+	if err != nil || offset < 0 {
+		return 0, errors.New("Invalid cursor")
+	}
+	return offset, nil
+}
+
+// NextCursor returns the cursor of the page following the one at offset,
+// or an empty string if there are no more records.
+func (u FindOptions) NextCursor(offset int, total int) string {
+	next := offset + u.GetLimit()
+	if next >= total {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte("o:" + strconv.Itoa(next)))
+}
@@ -0,0 +1,12 @@
+package basetypes
+
+// FlushStats reports the writes of a write-behind storage acknowledged in memory and their persistence.
+type FlushStats struct {
+	Pending    int     `json:"pending"`             // Records written in memory and not persisted yet.
+	Flushed    int64   `json:"flushed"`             // Records persisted since the storage was created.
+	Batches    int64   `json:"batches"`             // Batches of records persisted since the storage was created.
+	Failures   int64   `json:"failures"`            // Records whose persistence failed and was retried.
+	LagSeconds float64 `json:"lagSeconds"`          // Seconds the oldest pending record has been waiting, 0 when none is.
+	LastFlush  int64   `json:"lastFlush"`           // Unix time of the last batch persisted, 0 before the first one.
+	LastError  string  `json:"lastError,omitempty"` // Error of the last failed flush, empty once a flush succeeds.
+}
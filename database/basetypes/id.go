@@ -0,0 +1,138 @@
+package basetypes
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+)
+
+// maxJSONInteger is the largest integer a JSON number holds exactly in JavaScript, 2^53-1.
+const maxJSONInteger = 1<<53 - 1
+
+// idPattern matches the valid IDs: a letter or digit followed by up to 63 letters, digits or hyphens.
+// Underscores are left out as the memory storage keeps its records under "<id>_<collection>".
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]{0,63}$`)
+
+// ID is the primary key of a record. It holds the decimal form of an integer ID, handed out by a sequence
+// or a snowflake generator, or a string key like a UUID or a ULID. The empty ID is unset.
+// Integer IDs are encoded in JSON as numbers up to 2^53-1 and as strings above, like the other keys,
+// as larger JSON numbers lose precision in JavaScript. Both forms are accepted when decoding.
+type ID string
+
+// IntID returns the ID of an integer, the empty ID for 0.
+func IntID(id int64) ID {
+	if id == 0 {
+		return ""
+	}
+	return ID(strconv.FormatInt(id, 10))
+}
+
+// ParseID returns the ID of s, as found in a route, or an error if it isn't a valid ID.
+func ParseID(s string) (ID, error) {
+	id := ID(s)
+	if !id.Valid() {
+		return "", errors.New("Invalid ID")
+	}
+	return id, nil
+}
+
+// Int returns the integer of an integer ID. ok is false for the empty ID and the string keys.
+func (u ID) Int() (int64, bool) {
+	if u == "" || (u[0] == '0' && len(u) > 1) {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(string(u), 10, 64)
+	return id, err == nil && id > 0
+}
+
+// IsInt reports whether the ID is an integer ID.
+func (u ID) IsInt() bool {
+	_, ok := u.Int()
+	return ok
+}
+
+// Valid reports whether the ID is a positive integer or a key matching idPattern.
+// A key made of digits only must be the canonical form of a positive integer, so "7" and "007" never name different records.
+func (u ID) Valid() bool {
+	if !idPattern.MatchString(string(u)) {
+		return false
+	}
+	for _, c := range u {
+		if c < '0' || c > '9' {
+			return true
+		}
+	}
+	return u.IsInt()
+}
+
+// Less reports whether the ID sorts before other: integer IDs in numeric order before the string keys in byte order.
+func (u ID) Less(other ID) bool {
+	id, isInt := u.Int()
+	otherID, otherIsInt := other.Int()
+	switch {
+	case isInt && otherIsInt:
+		return id < otherID
+	case isInt != otherIsInt:
+		return isInt
+	}
+	return u < other
+}
+
+// MarshalJSON encodes an integer ID up to 2^53-1 as a JSON number and any other ID as a string, the empty ID as 0.
+func (u ID) MarshalJSON() ([]byte, error) {
+	if u == "" {
+		return []byte("0"), nil
+	}
+	if id, ok := u.Int(); ok && id <= maxJSONInteger {
+		return []byte(u), nil
+	}
+	return json.Marshal(string(u))
+}
+
+// UnmarshalJSON decodes an ID from a JSON string or integer number, 0 and null decoding to the empty ID.
+func (u *ID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*u = ""
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var key string
+		if err := json.Unmarshal(data, &key); err != nil {
+			return err
+		}
+		*u = ID(key)
+		return nil
+	}
+	id, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return errors.New("ID must be an integer or a string")
+	}
+	*u = IntID(id)
+	return nil
+}
+
+// Scan reads an ID from an integer or a text column.
+func (u *ID) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case nil:
+		*u = ""
+	case int64:
+		*u = IntID(value)
+	case []byte:
+		*u = ID(value)
+	case string:
+		*u = ID(value)
+	default:
+		return errors.New("Unsupported ID column")
+	}
+	return nil
+}
+
+// Value writes every ID as a string, the form the text key columns store it in. Compared to a text column,
+// an integer bound instead would convert every row of it, missing its index and matching "07" as well as "7".
+// The integer columns convert the string themselves.
+func (u ID) Value() (driver.Value, error) {
+	return string(u), nil
+}
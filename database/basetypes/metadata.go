@@ -0,0 +1,25 @@
+package basetypes
+
+import "context"
+
+// Metadata records when and by whom a record was created and last updated.
+type Metadata struct {
+	CreatedAt int64  // Unix time the record was created at, 0 if unknown.
+	UpdatedAt int64  // Unix time the record was last updated at, its creation time until it is updated.
+	CreatedBy string // The principal that created the record, empty if unknown.
+	UpdatedBy string // The principal that last updated the record, empty if unknown.
+}
+
+// principalKey is the context key of the principal of a request.
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal on whose behalf the storage calls made with it write.
+func WithPrincipal(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns the principal carried by ctx, empty if none is known.
+func PrincipalFrom(ctx context.Context) string {
+	principal, _ := ctx.Value(principalKey{}).(string)
+	return principal
+}
@@ -3,8 +3,12 @@ module websays
 go 1.18
 
 require (
-	github.com/go-sql-driver/mysql v1.7.1 // indirect
-	github.com/gorilla/mux v1.8.0 // indirect
+	github.com/go-sql-driver/mysql v1.7.1
+	github.com/gorilla/mux v1.8.0
+	github.com/mattn/go-sqlite3 v1.14.17
+)
+
+require (
 	github.com/gorilla/securecookie v1.1.1 // indirect
 	github.com/gorilla/sessions v1.2.1 // indirect
 )
@@ -1,11 +1,15 @@
 package basecontrollers
 
 import (
+	"log"
 	"sync"
+	"time"
 	"websays/app/controllers"
 	"websays/app/validators"
 	"websays/config"
 	"websays/database/basefunctions"
+	"websays/database/basehooks"
+	"websays/database/baseids"
 	"websays/database/basetypes"
 	"websays/httpHandler/basecontrollers/baseinterfaces"
 )
@@ -16,6 +20,7 @@ var once sync.Once
 // controllersObject is a singleton factory responsible for creating and managing controller instances.
 type controllersObject struct {
 	controllers map[string]baseinterfaces.Controller
+	flushes     map[string]basefunctions.FlushInterface // The write-behind storage of each controller with one, before any cache.
 }
 
 // GetInstance returns a single instance of the controllersObject.
@@ -25,6 +30,7 @@ func GetInstance() *controllersObject {
 	once.Do(func() {
 		instance = &controllersObject{}
 		instance.controllers = make(map[string]baseinterfaces.Controller)
+		instance.flushes = make(map[string]basefunctions.FlushInterface)
 	})
 	return instance
 }
@@ -45,16 +51,62 @@ func (c *controllersObject) GetController(controllerType string) (baseinterfaces
  * Although this is a lazy flyweight factory, it doesn't work as a lazy factory for web servers.
  * It will register all the controllers defined in the config for web, but it will still be flyweight.
  * Don't call the RegisterControllers method if it's not intended for web use.
+ * It also starts purging the expired trash of the registered controllers, see startTrashPurge,
+ * and registers the APIs of the databases of their storages, see registerDatabaseApis, of their caches, see registerCacheApis,
+ * and of their write-behind storages, see registerFlushApis.
  */
 func (c *controllersObject) RegisterControllers() {
 	localControllers := config.GetInstance().Controllers
 	for i := range localControllers {
 		c.registerControllers(localControllers[i], true)
 	}
+	c.startTrashPurge()
+	c.registerDatabaseApis()
+	c.registerCacheApis()
+	c.registerFlushApis()
+}
+
+// registerFlushApis registers the API reading the statistics of the write-behind storages of the registered controllers.
+func (c *controllersObject) registerFlushApis() {
+	flushes := &controllers.Flushes{Storages: c.flushes}
+	flushes.RegisterApis()
+}
+
+// registerCacheApis registers the API reading the statistics of the caches of the registered controllers.
+func (c *controllersObject) registerCacheApis() {
+	caches := &controllers.Caches{Caches: make(map[string]basefunctions.CacheInterface)}
+	for key, controller := range c.controllers {
+		if cache, ok := controller.GetFunctions().(basefunctions.CacheInterface); ok {
+			caches.Caches[key] = cache
+		}
+	}
+	caches.RegisterApis()
+}
+
+// registerDatabaseApis registers the APIs listing, creating and dropping the databases
+// of the storages of the registered controllers that keep databases apart, each storage once.
+func (c *controllersObject) registerDatabaseApis() {
+	databases := &controllers.Databases{}
+	for _, controller := range c.controllers {
+		storage, ok := controller.GetFunctions().(basefunctions.DatabaseInterface)
+		if !ok {
+			continue
+		}
+		known := false
+		for _, registered := range databases.Storages {
+			known = known || registered == storage
+		}
+		if !known {
+			databases.Storages = append(databases.Storages, storage)
+		}
+	}
+	databases.RegisterApis()
 }
 
 // registerControllers creates and registers a specific controller based on the provided key.
-// It sets the controller's base functions, performs indexing, and registers APIs if needed.
+// It sets the controller's base functions, written behind a memory front if the write-behind mode is configured for it,
+// running the hooks registered with basehooks around their writes and behind a read-through cache if one is configured for it,
+// and the ID generator of its configured strategy, performs indexing, and registers APIs if needed.
 func (c *controllersObject) registerControllers(key string, registerApis bool) {
 	var funcs *basefunctions.BaseFucntionsInterface
 	switch key {
@@ -66,11 +118,52 @@ func (c *controllersObject) registerControllers(key string, registerApis bool) {
 		funcs, _ = basefunctions.GetInstance().GetFunctions(basetypes.FILE, c.controllers[key].GetDBName())
 	case Product:
 		c.controllers[key] = &controllers.Product{BaseControllerFactory: c, ValidatorInterface: &validators.ProductValidator{}}
-		funcs, _ = basefunctions.GetInstance().GetFunctions(basetypes.MYSQL, c.controllers[key].GetDBName())
+		funcs, _ = basefunctions.GetInstance().GetFunctions(writeBehind(key, basetypes.MYSQL), c.controllers[key].GetDBName())
 	}
-	c.controllers[key].SetBaseFunctions(*funcs)
+	if flusher, ok := (*funcs).(basefunctions.FlushInterface); ok {
+		c.flushes[key] = flusher
+	}
+	functions := cached(key, basefunctions.NewHookedFunctions(*funcs, basehooks.GetInstance()))
+	c.controllers[key].SetBaseFunctions(functions)
+	c.setIDGenerator(key, functions)
 	c.controllers[key].DoIndexing()
 	if registerApis {
 		c.controllers[key].RegisterApis()
 	}
 }
+
+// writeBehind returns the write-behind storage type if the write-behind mode is configured for a controller,
+// or the type of its storage otherwise. Only a MySQL storage can be written behind.
+func writeBehind(key string, dbType basetypes.DbType) basetypes.DbType {
+	if dbType != basetypes.MYSQL {
+		return dbType
+	}
+	for _, controller := range config.GetInstance().WriteBehind.Controllers {
+		if controller == key {
+			return basetypes.WRITEBEHIND
+		}
+	}
+	return dbType
+}
+
+// cached returns the storage of a controller behind the read-through cache configured for it,
+// or the storage itself if the controller has no cache.
+func cached(key string, funcs basefunctions.BaseFucntionsInterface) basefunctions.BaseFucntionsInterface {
+	cacheConfig, ok := config.GetInstance().Caches[key]
+	if !ok || cacheConfig.Size <= 0 {
+		return funcs
+	}
+	return basefunctions.NewCachedFunctions(funcs, cacheConfig.Size, time.Duration(cacheConfig.TTL)*time.Second, time.Duration(cacheConfig.MissTTL)*time.Second)
+}
+
+// setIDGenerator sets the ID generator of the strategy configured for a controller.
+// An unknown strategy is logged and the controller keeps the sequence of its storage.
+func (c *controllersObject) setIDGenerator(key string, funcs basefunctions.BaseFucntionsInterface) {
+	strategy := baseids.Strategy(config.GetInstance().IDs.Strategies[key])
+	generator, err := baseids.New(strategy, funcs)
+	if err != nil {
+		log.Println("Error setting the ID strategy of", key, "controller:", err)
+		return
+	}
+	c.controllers[key].SetIDGenerator(generator)
+}
@@ -2,6 +2,7 @@ package baseinterfaces
 
 import (
 	"websays/database/basefunctions"
+	"websays/database/baseids"
 	"websays/database/basetypes"
 	"websays/httpHandler/basevalidators"
 )
@@ -21,13 +22,18 @@ type Controller interface {
 	// SetBaseFunctions sets the base database functions that are used by this controller, enabling it to interact with the database.
 	SetBaseFunctions(basefunctions.BaseFucntionsInterface)
 
+	// SetIDGenerator sets the generator of the IDs of the records created by this controller,
+	// following the ID strategy configured for it. SetBaseFunctions defaults it to the sequence of the storage.
+	SetIDGenerator(baseids.IDGenerator)
+
 	// GetCollectionName returns the name of the database collection associated with this controller,
 	// facilitating the identification of the data store for this controller.
 	GetCollectionName() basetypes.CollectionName
 
 	// DoIndexing is responsible for handling indexing operations specific to the controller's data,
 	// optimizing data retrieval and query performance.
-	// It may create or update indexes in the underlying database.
+	// It may create or update indexes in the underlying database,
+	// and register the basehooks run around the writes of its collection; registering them by name keeps it repeatable.
 	DoIndexing() error
 
 	// RegisterApis is used to define and register API endpoints associated with this controller.
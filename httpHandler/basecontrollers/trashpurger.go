@@ -0,0 +1,64 @@
+package basecontrollers
+
+import (
+	"log"
+	"time"
+	"websays/config"
+	"websays/database/basefunctions"
+	"websays/database/basetypes"
+)
+
+// trashTarget is the collection of a controller whose storage keeps a trash.
+type trashTarget struct {
+	trash          basefunctions.TrashInterface
+	databases      basefunctions.DatabaseInterface // The databases of the storage, nil if it doesn't keep them apart.
+	dbName         basetypes.DBName
+	collectionName basetypes.CollectionName
+}
+
+// dbNames returns the databases whose collection is purged, every database of the storage if it keeps them apart.
+func (t trashTarget) dbNames() []basetypes.DBName {
+	if t.databases == nil {
+		return []basetypes.DBName{t.dbName}
+	}
+	dbNames, err := t.databases.ListDatabases()
+	if err != nil {
+		log.Println("Error listing the databases of", t.collectionName, ":", err)
+		return []basetypes.DBName{t.dbName}
+	}
+	return dbNames
+}
+
+// startTrashPurge purges the expired trash of the registered controllers every purgeInterval seconds,
+// permanently removing the records trashed for longer than the retention window of the config.
+// The collections are purged in every database of the storages keeping databases apart. It does nothing when the retention or the interval is 0.
+func (c *controllersObject) startTrashPurge() {
+	trashConfig := config.GetInstance().Trash
+	if trashConfig.Retention <= 0 || trashConfig.PurgeInterval <= 0 {
+		return
+	}
+
+	targets := make([]trashTarget, 0, len(c.controllers))
+	for _, controller := range c.controllers {
+		if trash, ok := controller.GetFunctions().(basefunctions.TrashInterface); ok {
+			databases, _ := controller.GetFunctions().(basefunctions.DatabaseInterface)
+			targets = append(targets, trashTarget{trash: trash, databases: databases, dbName: controller.GetDBName(), collectionName: controller.GetCollectionName()})
+		}
+	}
+	retention := time.Duration(trashConfig.Retention) * time.Second
+	go func() {
+		for range time.Tick(time.Duration(trashConfig.PurgeInterval) * time.Second) {
+			before := time.Now().Add(-retention)
+			for _, target := range targets {
+				for _, dbName := range target.dbNames() {
+					purged, err := target.trash.PurgeTrash(dbName, target.collectionName, before)
+					if err != nil {
+						log.Println("Error purging the trash of", dbName, target.collectionName, ":", err)
+					} else if purged > 0 {
+						log.Println("Purged", purged, "expired records from the trash of", dbName, target.collectionName)
+					}
+				}
+			}
+		}
+	}()
+}
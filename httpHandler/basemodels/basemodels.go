@@ -1,11 +1,48 @@
 package basemodels
 
+import "websays/database/basetypes"
+
 // BaseModels is an interface that defines a common method for obtaining the unique identifier (ID) of a model.
 // It serves as a contract for any data structure or object that represents a model in the application.
-// The GetID method should return an integer representing the unique identifier of the model.
+// The GetID method should return the unique identifier of the model, an integer ID or a string key like a UUID,
+// see basetypes.ID. The empty ID means the model doesn't carry one yet.
 // This interface enables consistent access to IDs across different model types and is often used in database operations
 // and interactions with models.
 type BaseModels interface {
 	// GetID returns the unique identifier (ID) of the model.
-	GetID() int
+	GetID() basetypes.ID
+}
+
+// VersionedModels is an interface for models guarded by optimistic concurrency control.
+// The storages start a versioned record at version 1 and increment its version on every update.
+// An update or delete supplying a version other than the stored one is rejected, so two clients
+// updating the same record can't silently overwrite each other. A version of 0 skips the check.
+type VersionedModels interface {
+	BaseModels
+	// GetVersion returns the version of the record the model was read from.
+	GetVersion() int
+	// WithVersion returns a copy of the model at the given version.
+	WithVersion(version int) interface{}
+}
+
+// SoftDeletableModels is an interface for models whose records are moved to a trash when they are deleted.
+// The storages mark a trashed record with the Unix time it was deleted at and no longer find it,
+// until it is restored, purged, or purged automatically once it was trashed for longer than the retention window.
+type SoftDeletableModels interface {
+	BaseModels
+	// GetDeletedAt returns the Unix time the record was trashed at, 0 if it is not trashed.
+	GetDeletedAt() int64
+	// WithDeletedAt returns a copy of the model trashed at the given Unix time, or restored for 0.
+	WithDeletedAt(deletedAt int64) interface{}
+}
+
+// TimestampedModels is an interface for models recording when and by whom their record was created and last updated.
+// The storages fill the metadata in on every insert and update, with the principal of the context of the call if one is known,
+// and keep the creation of the record they replace, so the metadata supplied by a client is ignored.
+type TimestampedModels interface {
+	BaseModels
+	// GetMetadata returns the creation and last update of the record.
+	GetMetadata() basetypes.Metadata
+	// WithMetadata returns a copy of the model with the given creation and last update.
+	WithMetadata(metadata basetypes.Metadata) interface{}
 }
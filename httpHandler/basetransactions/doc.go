@@ -0,0 +1,2 @@
+//Package basetransactions provides helpers to run http handlers inside a storage transaction
+package basetransactions
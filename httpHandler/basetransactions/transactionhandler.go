@@ -0,0 +1,71 @@
+package basetransactions
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"websays/database/basefunctions"
+	"websays/httpHandler/responses"
+)
+
+// TransactionHandler handles a request using the storage transaction it is given.
+// It returns the response code and data to send on success. Returning an error rolls the
+// transaction back and sends the error with the returned response code.
+type TransactionHandler func(tx basefunctions.TransactionInterface, r *http.Request) (int, interface{}, error)
+
+// WithTransaction returns an http handler running handler inside a transaction of the storage.
+//
+// Parameters:
+//   - functions: The storage of the controller, it must implement basefunctions.TransactionalInterface.
+//   - handler:   The handler doing the reads and writes through the transaction.
+//
+// Behavior:
+//   - Begins a transaction on the storage under the request's context, responding with TRANSACTION_FAILED if it can't be started.
+//   - Calls the handler with the transaction.
+//   - Responds with the handler's code and error if the handler fails.
+//   - Commits the transaction and responds with the handler's code and data, or TRANSACTION_FAILED if the commit fails.
+//   - Rolls the transaction back unless it was committed, also when the handler panics.
+//
+// Example Usage:
+//
+//	router.HandleFunc("/api/moveProduct", basetransactions.WithTransaction(pro, pro.HandleMoveProduct)).Methods("PUT")
+func WithTransaction(functions basefunctions.BaseFucntionsInterface, handler TransactionHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		transactional, ok := functions.GetFunctions().(basefunctions.TransactionalInterface)
+		if !ok {
+			responses.GetInstance().WriteJsonResponse(w, r, responses.TRANSACTION_FAILED, errors.New("Storage doesn't support transactions"), nil)
+			return
+		}
+
+		tx, err := transactional.BeginContext(r.Context())
+		if err != nil {
+			responses.GetInstance().WriteJsonResponse(w, r, responses.TRANSACTION_FAILED, err, nil)
+			return
+		}
+		committed := false
+		// A failing or panicking handler still leaves no transaction open
+		defer func() {
+			if committed {
+				return
+			}
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Println("Error rolling back transaction:", rollbackErr)
+			}
+		}()
+
+		code, data, err := handler(tx, r)
+		if err != nil {
+			responses.GetInstance().WriteJsonResponse(w, r, code, err, nil)
+			return
+		}
+
+		err = tx.Commit()
+		// A commit that fails ends the transaction too, having applied nothing
+		committed = true
+		if err != nil {
+			responses.GetInstance().WriteJsonResponse(w, r, responses.TRANSACTION_FAILED, err, nil)
+			return
+		}
+		responses.GetInstance().WriteJsonResponse(w, r, code, nil, data)
+	}
+}
@@ -1,11 +1,15 @@
 package httpHandler
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"sync"
+	"time"
 	"websays/app/middlewares"
 	"websays/config"
+	"websays/database/baseconnections"
+	"websays/database/basefunctions"
 	"websays/httpHandler/basecontrollers"
 	"websays/httpHandler/baserouter"
 	"websays/httpHandler/responses"
@@ -15,9 +19,18 @@ import (
 
 // muxServer is a struct that represents the Mux server.
 type muxServer struct {
-	base *mux.Router // Mux router instance.
+	base    *mux.Router   // Mux router instance.
+	server  *http.Server  // HTTP server serving the router, kept to shut it down.
+	stopped chan struct{} // Closed once Stop drained the storages and closed the connections.
+	stop    sync.Once     // Ensures Stop runs once.
 }
 
+// shutdownTimeout is how long Stop waits for the running requests to finish.
+const shutdownTimeout = 15 * time.Second
+
+// drainTimeout is how long Stop waits for the pending writes of the write-behind storages to be persisted.
+const drainTimeout = 30 * time.Second
+
 var (
 	instance *muxServer // Singleton instance of muxServer.
 	once     sync.Once  // Used for ensuring singleton behavior.
@@ -42,11 +55,19 @@ func (u *muxServer) HandleBlank(w http.ResponseWriter, r *http.Request) {
 
 // setup configures the Mux router and sets up necessary middleware.
 func (u *muxServer) setup() {
-	corsMiddleware := middlewares.CORSMiddleware{} // Initialize CORS middleware.
-	u.base = &mux.Router{}                         // Initialize the Mux router.
+	corsMiddleware := middlewares.CORSMiddleware{}           // Initialize CORS middleware.
+	timeoutMiddleware := middlewares.TimeoutMiddleware{}     // Initialize timeout middleware.
+	principalMiddleware := middlewares.PrincipalMiddleware{} // Initialize principal middleware.
+	u.base = &mux.Router{}                                   // Initialize the Mux router.
+	u.server = &http.Server{}                                // Initialize the HTTP server serving the default mux.
+	u.stopped = make(chan struct{})                          // Closed by Stop once it is done.
 
 	// Use CORS middleware for all routes handled by this router.
 	u.base.Use(corsMiddleware.GetHandlerFunc)
+	// Bound the context of every route by its configured timeout.
+	u.base.Use(timeoutMiddleware.GetHandlerFunc)
+	// Put the principal making each request in its context, so the storages record who wrote the records.
+	u.base.Use(principalMiddleware.GetHandlerFunc)
 
 	// Define a route for the root path ("/") and associate it with HandleBlank.
 	u.base.HandleFunc("/", u.HandleBlank).Methods("GET")
@@ -56,6 +77,7 @@ func (u *muxServer) setup() {
 }
 
 // Start initializes the server, registers controllers, and starts listening.
+// Once Stop is called it returns when Stop is done, so the pending writes are persisted before the process exits.
 func (u *muxServer) Start() {
 	// Log server startup message with the configured address and port.
 	log.Println("Server listening on ", config.GetInstance().Server.Address, ":", config.GetInstance().Server.Port)
@@ -66,9 +88,43 @@ func (u *muxServer) Start() {
 	// Handle requests using the configured Mux router.
 	http.Handle("/", u.base)
 
-	// Start the HTTP server and listen on the configured address and port.
-	err := http.ListenAndServe(config.GetInstance().Server.Address+":"+config.GetInstance().Server.Port, nil)
-	if err != nil {
+	// Start the HTTP server and listen on the configured address and port until it is stopped.
+	u.server.Addr = config.GetInstance().Server.Address + ":" + config.GetInstance().Server.Port
+	err := u.server.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
 		log.Println("Error in running server:", err)
+		return
+	}
+	<-u.stopped
+}
+
+// Stop shuts the server down gracefully, waiting up to shutdownTimeout for the running requests,
+// persists the pending writes of the write-behind storages, waiting up to drainTimeout,
+// then closes the database connections. Start returns once Stop is done.
+func (u *muxServer) Stop() {
+	u.stop.Do(u.shutdown)
+}
+
+// shutdown stops the server, drains the storages and closes the connections, see Stop.
+func (u *muxServer) shutdown() {
+	defer close(u.stopped)
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err := u.server.Shutdown(ctx)
+	if err != nil {
+		log.Println("Error in stopping server:", err)
+	}
+
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer drainCancel()
+	err = basefunctions.GetInstance().Drain(drainCtx)
+	if err != nil {
+		log.Println("Error in persisting pending writes:", err)
+	}
+
+	err = baseconnections.GetInstance().Close()
+	if err != nil {
+		log.Println("Error in closing connections:", err)
 	}
 }
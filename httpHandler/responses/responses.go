@@ -26,10 +26,31 @@ const (
 	UPDATE_PRODUCT_SUCCESS  = 1016
 	DELETE_PRODUCT_SUCCESS  = 1017
 	NO_PRDUCT_FOUND         = 1018
+	LIST_ARTICLE_SUCCESS    = 1019
+	LIST_CATEGORY_SUCCESS   = 1020
+	LIST_PRODUCT_SUCCESS    = 1021
+	TRANSACTION_FAILED      = 1022
+	REQUEST_TIMEOUT         = 1023
+	VERSION_CONFLICT        = 1024
+	LIST_TRASH_SUCCESS      = 1025
+	RESTORE_SUCCESS         = 1026
+	PURGE_SUCCESS           = 1027
+	BULK_ADD_SUCCESS        = 1028
+	BULK_UPDATE_SUCCESS     = 1029
+	BULK_DELETE_SUCCESS     = 1030
+	BULK_PARTIAL_FAILURE    = 1031
+	LIST_DATABASES_SUCCESS  = 1032
+	CREATE_DATABASE_SUCCESS = 1033
+	DROP_DATABASE_SUCCESS   = 1034
+	DATABASE_NOT_FOUND      = 1035
+	DATABASE_EXISTS         = 1036
+	CACHE_STATS_SUCCESS     = 1037
+	FLUSH_STATS_SUCCESS     = 1038
 )
 
 type Responses struct {
 	responses map[int]string
+	statuses  map[int]int // HTTP status of the failure codes not answered with 406.
 }
 
 var (
@@ -69,6 +90,31 @@ func (u *Responses) InitResponses() {
 	u.responses[UPDATE_PRODUCT_SUCCESS] = "Updating product success"
 	u.responses[DELETE_PRODUCT_SUCCESS] = "Deleting product success"
 	u.responses[NO_PRDUCT_FOUND] = "No product found"
+	u.responses[LIST_ARTICLE_SUCCESS] = "Listing articles success"
+	u.responses[LIST_CATEGORY_SUCCESS] = "Listing categories success"
+	u.responses[LIST_PRODUCT_SUCCESS] = "Listing products success"
+	u.responses[TRANSACTION_FAILED] = "Transaction failed"
+	u.responses[REQUEST_TIMEOUT] = "Request timed out"
+	u.responses[VERSION_CONFLICT] = "The record was changed since it was read"
+	u.responses[LIST_TRASH_SUCCESS] = "Listing trash success"
+	u.responses[RESTORE_SUCCESS] = "Restoring from trash success"
+	u.responses[PURGE_SUCCESS] = "Purging from trash success"
+	u.responses[BULK_ADD_SUCCESS] = "Bulk adding success"
+	u.responses[BULK_UPDATE_SUCCESS] = "Bulk updating success"
+	u.responses[BULK_DELETE_SUCCESS] = "Bulk deleting success"
+	u.responses[BULK_PARTIAL_FAILURE] = "Some items of the bulk operation failed"
+	u.responses[LIST_DATABASES_SUCCESS] = "Listing databases success"
+	u.responses[CREATE_DATABASE_SUCCESS] = "Creating database success"
+	u.responses[DROP_DATABASE_SUCCESS] = "Dropping database success"
+	u.responses[DATABASE_NOT_FOUND] = "Database not found"
+	u.responses[DATABASE_EXISTS] = "Database already exists"
+	u.responses[CACHE_STATS_SUCCESS] = "Reading cache statistics success"
+	u.responses[FLUSH_STATS_SUCCESS] = "Reading flush statistics success"
+
+	u.statuses = make(map[int]int)
+	u.statuses[VERSION_CONFLICT] = http.StatusPreconditionFailed
+	u.statuses[DATABASE_NOT_FOUND] = http.StatusNotFound
+	u.statuses[DATABASE_EXISTS] = http.StatusConflict
 }
 
 // GetResponse returns the message for the particular response code
@@ -92,8 +138,9 @@ func (u *Responses) getResponse(code int) map[string]interface{} {
 //
 // Note:
 //   - The 'err' parameter is used to indicate if there is an error associated with the response, and it affects the
-//     HTTP status code. If 'err' is not nil, the status code is set to StatusNotAcceptable (406); otherwise, it's
-//     set to StatusOK (200).
+//     HTTP status code. If 'err' is not nil, the status code is set to StatusNotAcceptable (406), or to
+//     StatusPreconditionFailed (412) for VERSION_CONFLICT, StatusNotFound (404) for DATABASE_NOT_FOUND and
+//     StatusConflict (409) for DATABASE_EXISTS; otherwise, it's set to StatusOK (200).
 //   - The response format is JSON with appropriate headers.
 //   - If encoding the JSON response encounters an error, it responds with an internal server error (HTTP status 500).
 //
@@ -107,6 +154,9 @@ func (u *Responses) WriteJsonResponse(w http.ResponseWriter, r *http.Request, co
 	status := http.StatusOK
 	if err != nil {
 		status = http.StatusNotAcceptable
+		if codeStatus, ok := u.statuses[code]; ok {
+			status = codeStatus
+		}
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Cache-Control", "no-store")
@@ -2,15 +2,59 @@ package main
 
 import (
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"websays/app/models"
 	"websays/config"
+	"websays/database/basemigrations"
 	"websays/httpHandler"
 )
 
 //The main function to start the app
 func main() {
-	log.Println("starting server...")
 	//Read the config file
 	config.GetInstance().Setup("setup/prod.json")
+
+	//Run a migrations command instead of the server, like `main migrate up`
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		migrate(os.Args[2:])
+		return
+	}
+
+	log.Println("starting server...")
+	//Apply the pending migrations before the controllers use their tables
+	if config.GetInstance().Migrations.ApplyOnStartup {
+		migrator, err := basemigrations.GetInstance().GetMigrator()
+		if err == nil {
+			_, err = migrator.Up()
+		}
+		if err != nil {
+			log.Println("Error applying migrations:", err)
+		}
+	}
+	//Stop the server and close the connections on interrupt or termination
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+		log.Println("stopping server...")
+		httpHandler.GetInstance().Stop()
+	}()
 	//Start the mux server
 	httpHandler.GetInstance().Start()
 }
+
+//migrate runs a migrations command and exits with an error status if it fails
+func migrate(args []string) {
+	//The diff compares the tables against their models, without starting the controllers
+	if len(args) > 0 && args[0] == "diff" {
+		for table, model := range models.Tables {
+			basemigrations.GetInstance().RegisterModel(table, model)
+		}
+	}
+	err := basemigrations.GetInstance().RunCommand(args)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,4 @@
+ALTER TABLE `products` ADD COLUMN `created_at` BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE `products` ADD COLUMN `updated_at` BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE `products` ADD COLUMN `created_by` VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE `products` ADD COLUMN `updated_by` VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- Fails once a product is keyed by a string
+ALTER TABLE `products` MODIFY COLUMN `id` INT AUTO_INCREMENT NOT NULL;
+DELETE FROM `id_sequences` WHERE `collection` = 'products';
@@ -0,0 +1,6 @@
+-- Products take the IDs of every strategy, the storage hands out the sequence IDs from id_sequences
+CREATE TABLE IF NOT EXISTS `id_sequences` (
+  `collection` VARCHAR(255) PRIMARY KEY,
+  `last` BIGINT NOT NULL
+);
+ALTER TABLE `products` MODIFY COLUMN `id` VARCHAR(64) NOT NULL;
@@ -5,7 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
-	"strconv"
+	"net/url"
 	"testing"
 	"websays/app/controllers"
 	"websays/app/models"
@@ -70,7 +70,7 @@ func TestFindArticle(t *testing.T) {
 	articleData := models.Article{
 		Title: "First Article",
 		Body:  "Articles body",
-		ID:    articleController.GetNextID(),
+		ID:    nextID(t, articleController, articleController.GetDBName(), articleController.GetCollectionName()),
 	}
 	articleController.GetFunctions()
 	articleController.Add(articleController.GetDBName(), articleController.GetCollectionName(), articleData)
@@ -86,7 +86,7 @@ func TestFindArticle(t *testing.T) {
 	defer server.Close()
 
 	// Generate a valid article ID (replace with your logic to generate an ID)
-	articleID := strconv.FormatInt(int64(articleData.ID), 10)
+	articleID := string(articleData.ID)
 
 	// Create the URL with the article ID
 	url := server.URL + "/api/readArticle/" + articleID
@@ -126,7 +126,7 @@ func TestDeleteArticle(t *testing.T) {
 	articleData := models.Article{
 		Title: "First Article",
 		Body:  "Articles body",
-		ID:    articleController.GetNextID(),
+		ID:    nextID(t, articleController, articleController.GetDBName(), articleController.GetCollectionName()),
 	}
 	articleController.GetFunctions()
 	articleController.Add(articleController.GetDBName(), articleController.GetCollectionName(), articleData)
@@ -142,7 +142,7 @@ func TestDeleteArticle(t *testing.T) {
 	defer server.Close()
 
 	// Generate a valid article ID (replace with your logic to generate an ID)
-	articleID := strconv.FormatInt(int64(articleData.ID), 10)
+	articleID := string(articleData.ID)
 
 	// Create the URL with the article ID
 	url := server.URL + "/api/deleteArticle/" + articleID
@@ -185,7 +185,7 @@ func TestUpdateArticle(t *testing.T) {
 	articleData := models.Article{
 		Title: "First Article",
 		Body:  "Articles body",
-		ID:    articleController.GetNextID(),
+		ID:    nextID(t, articleController, articleController.GetDBName(), articleController.GetCollectionName()),
 	}
 	articleController.GetFunctions()
 	articleController.Add(articleController.GetDBName(), articleController.GetCollectionName(), articleData)
@@ -234,3 +234,91 @@ func TestUpdateArticle(t *testing.T) {
 		t.Errorf("Expected message '%s'; got '%s'", expectedMessage, responseJSON["message"])
 	}
 }
+
+func TestListArticles(t *testing.T) {
+	controller, _ := basecontrollers.GetInstance().GetController("Article")
+	articleController := controller.(*controllers.Article)
+
+	for i := 0; i < 3; i++ {
+		articleData := models.Article{
+			Title: "Listed Article",
+			Body:  "Articles body",
+			ID:    nextID(t, articleController, articleController.GetDBName(), articleController.GetCollectionName()),
+		}
+		articleController.Add(articleController.GetDBName(), articleController.GetCollectionName(), articleData)
+	}
+
+	// Request the first page with a page size of two
+	req, err := http.NewRequest("GET", "/api/articles?limit=2", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	articleController.HandleListArticles(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status %d; got %d", http.StatusOK, rr.Code)
+	}
+
+	var responseJSON map[string]interface{}
+	err = json.NewDecoder(rr.Body).Decode(&responseJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page := responseJSON["data"].(map[string]interface{})
+	if len(page["data"].([]interface{})) != 2 {
+		t.Errorf("Expected 2 articles; got %v", page["data"])
+	}
+	if page["total"].(float64) < 3 {
+		t.Errorf("Expected at least 3 articles in total; got %v", page["total"])
+	}
+
+	// Follow the cursor to the next page
+	req, err = http.NewRequest("GET", "/api/articles?limit=2&cursor="+page["nextCursor"].(string), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr = httptest.NewRecorder()
+	articleController.HandleListArticles(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status %d; got %d", http.StatusOK, rr.Code)
+	}
+}
+
+func TestFilterArticles(t *testing.T) {
+	controller, _ := basecontrollers.GetInstance().GetController("Article")
+	articleController := controller.(*controllers.Article)
+
+	articleData := models.Article{
+		Title: "Filtered Article",
+		Body:  "Articles body",
+		ID:    nextID(t, articleController, articleController.GetDBName(), articleController.GetCollectionName()),
+	}
+	articleController.Add(articleController.GetDBName(), articleController.GetCollectionName(), articleData)
+
+	// Filter on the title instead of the ID
+	filter := url.QueryEscape(`{"op":"and","filters":[{"op":"like","field":"title","value":"filtered%"},{"op":"ne","field":"body","value":""}]}`)
+	req, err := http.NewRequest("GET", "/api/articles?filter="+filter, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	articleController.HandleListArticles(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status %d; got %d", http.StatusOK, rr.Code)
+	}
+
+	var responseJSON map[string]interface{}
+	err = json.NewDecoder(rr.Body).Decode(&responseJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page := responseJSON["data"].(map[string]interface{})
+	if page["total"].(float64) != 1 {
+		t.Errorf("Expected 1 filtered article; got %v", page["data"])
+	}
+}
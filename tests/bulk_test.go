@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"websays/app/controllers"
+	"websays/app/models"
+	"websays/app/validators"
+	"websays/config"
+	"websays/database/basefunctions"
+	"websays/database/basetypes"
+)
+
+func TestBulkCategories(t *testing.T) {
+	functions := useFileStorage(t)
+	categoryController := &controllers.Category{ValidatorInterface: &validators.CategoryValidator{}}
+	categoryController.SetBaseFunctions(functions)
+
+	serve := func(method string, handler http.HandlerFunc, body interface{}) (int, basetypes.BulkResult) {
+		t.Helper()
+		payload, _ := json.Marshal(body)
+		req, err := http.NewRequest(method, "/api/categories/bulk", bytes.NewBuffer(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		var response struct {
+			Code int                  `json:"code"`
+			Data basetypes.BulkResult `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		return response.Code, response.Data
+	}
+
+	code, result := serve("POST", categoryController.HandleBulkAddCategories, []models.Category{{Name: "maps"}, {}, {Name: "globes"}})
+	if code != 1031 || result.Succeeded != 2 || result.Failed != 1 || result.Items[1].Error == "" {
+		t.Fatalf("Expected the unnamed category alone to fail; got %d %+v", code, result)
+	}
+	maps, globes := result.Items[0].ID, result.Items[2].ID
+	if maps == "" || globes == "" {
+		t.Fatalf("Expected the IDs of the created categories; got %+v", result)
+	}
+
+	code, result = serve("PATCH", categoryController.HandleBulkUpdateCategories, []models.Category{{ID: maps, Name: "atlases"}, {ID: globes, Name: "spheres", Version: 1}})
+	if code != 1029 || result.Succeeded != 2 {
+		t.Fatalf("Expected both categories to be updated; got %d %+v", code, result)
+	}
+	stored, err := functions.FindOne(categoryController.GetDBName(), categoryController.GetCollectionName(), models.Category{ID: maps})
+	if err != nil || stored.(map[string]interface{})["name"] != "atlases" {
+		t.Errorf("Expected the atlases category; got %+v, %v", stored, err)
+	}
+
+	code, result = serve("DELETE", categoryController.HandleBulkDeleteCategories, []map[string]interface{}{{"id": maps}, {"id": globes, "version": 1}, {"id": 0}})
+	if code != 1031 || result.Succeeded != 1 || result.Items[1].Error == "" || result.Items[2].Error == "" {
+		t.Fatalf("Expected the stale and invalid deletions to fail; got %d %+v", code, result)
+	}
+	if _, err := functions.FindOne(categoryController.GetDBName(), categoryController.GetCollectionName(), models.Category{ID: maps}); err == nil {
+		t.Error("Expected the deleted category to be trashed")
+	}
+}
+
+func TestSqliteBulk(t *testing.T) {
+	config.GetInstance().Sqlite.FileName = t.TempDir() + "/websays.sqlite"
+	sqlite, err := basefunctions.GetInstance().GetFunctions(basetypes.SQLITE, "websays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = (*sqlite).EnsureIndex("websays", "products", models.Product{}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := (*sqlite).AddMany("websays", "products", []interface{}{models.Product{Name: "desk"}, models.Product{Name: "chair"}, models.Product{Name: "lamp"}})
+	if err != nil || result.Succeeded != 3 {
+		t.Fatalf("Expected the products to be added; got %+v (%v)", result, err)
+	}
+	for _, item := range result.Items {
+		product, err := (*sqlite).FindOne("websays", "products", map[string]interface{}{"id": item.ID})
+		if err != nil || product.(models.Product).Name != []string{"desk", "chair", "lamp"}[item.Index] {
+			t.Errorf("Expected item %d to report the ID of its product; got %+v (%v)", item.Index, product, err)
+		}
+	}
+
+	// The duplicate ID fails the multi-row statement, its rows are then inserted one by one
+	desk := result.Items[0].ID
+	result, err = (*sqlite).AddMany("websays", "products", []interface{}{models.Product{ID: "900", Name: "shelf"}, models.Product{ID: desk, Name: "stool"}, models.Product{ID: "901", Name: "bench"}})
+	if err != nil || result.Succeeded != 2 || result.Items[1].Error == "" || result.Items[2].ID != "901" {
+		t.Fatalf("Expected the duplicate alone to fail; got %+v (%v)", result, err)
+	}
+
+	result, err = (*sqlite).UpdateMany("websays", "products", []basetypes.BulkUpdate{
+		{Query: map[string]interface{}{"id": desk}, Data: map[string]interface{}{"name": "table"}},
+		{Query: map[string]interface{}{"id": 900}, Data: map[string]interface{}{"name": "rack", "version": 2}},
+	})
+	if err != nil || result.Succeeded != 1 || result.Items[1].Error != basefunctions.ErrVersionConflict.Error() {
+		t.Errorf("Expected the stale update alone to fail; got %+v (%v)", result, err)
+	}
+
+	// The SQLite database is shared with the other tests, so every product added is deleted
+	queries := []interface{}{map[string]interface{}{"id": 900}, map[string]interface{}{"id": 901}}
+	first, _ := desk.Int()
+	for id := first; id < first+3; id++ {
+		queries = append(queries, map[string]interface{}{"id": id})
+	}
+	result, err = (*sqlite).DeleteMany("websays", "products", queries)
+	if err != nil || result.Succeeded != 5 {
+		t.Errorf("Expected the products to be deleted; got %+v (%v)", result, err)
+	}
+	if _, err = (*sqlite).FindOne("websays", "products", map[string]interface{}{"id": 900}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected the deleted product to be gone; got %v", err)
+	}
+}
+
+func TestBulkAddWritesOnce(t *testing.T) {
+	memoryConfig := config.GetInstance().Memory
+	t.Cleanup(func() { config.GetInstance().Memory = memoryConfig })
+	files := useFileStorage(t)
+	config.GetInstance().Memory.Persistence = true
+	config.GetInstance().Memory.WalFileName = ".memory.wal"
+	config.GetInstance().Memory.SnapshotFileName = ".memory.snapshot"
+
+	// The memory storage logs the accepted records of a batch as a single entry
+	memory := &basefunctions.MemoryFunctions{}
+	memory.GetFunctions()
+	articles := []interface{}{models.Article{ID: "1", Title: "One"}, models.Article{ID: "1", Title: "Again"}, models.Article{ID: "2", Title: "Two"}}
+	result, err := memory.AddMany("websays", "articles", articles)
+	if err != nil || result.Succeeded != 2 || result.Items[1].Error == "" {
+		t.Fatalf("Expected the repeated ID alone to fail; got %+v (%v)", result, err)
+	}
+	wal, _ := os.ReadFile(config.GetInstance().FilePath + "/.memory.wal")
+	if lines := bytes.Count(wal, []byte("\n")); lines != 1 {
+		t.Errorf("Expected a single log entry for the batch; got %d", lines)
+	}
+	restarted := &basefunctions.MemoryFunctions{}
+	restarted.GetFunctions()
+	if found, err := restarted.FindMany("websays", "articles", nil, basetypes.FindOptions{}); err != nil || found.Total != 2 {
+		t.Errorf("Expected the batch to be restored; got %+v (%v)", found, err)
+	}
+
+	// The file storage adds the written records to the index at once
+	if _, err := files.Add("websays", "categories", models.Category{ID: "1", Name: "first"}); err != nil {
+		t.Fatal(err)
+	}
+	result, err = files.AddMany("websays", "categories", []interface{}{models.Category{ID: "2", Name: "second"}, models.Category{ID: "1", Name: "again"}, models.Category{ID: "3", Name: "third"}})
+	if err != nil || result.Succeeded != 2 || result.Items[1].Error == "" {
+		t.Fatalf("Expected the existing ID alone to fail; got %+v (%v)", result, err)
+	}
+	if log, _ := os.ReadFile(config.GetInstance().FilePath + "/websays/categories/.index.log"); !bytes.HasSuffix(log, []byte("+2\n+3\n")) {
+		t.Errorf("Expected the written IDs in the index log; got %q", log)
+	}
+	if found, err := files.FindMany("websays", "categories", nil, basetypes.FindOptions{}); err != nil || found.Total != 3 {
+		t.Errorf("Expected the three categories; got %+v (%v)", found, err)
+	}
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"testing"
+	"time"
+	"websays/app/models"
+	"websays/database/basefunctions"
+	"websays/database/basetypes"
+)
+
+func TestCachedFunctions(t *testing.T) {
+	memory := &basefunctions.MemoryFunctions{}
+	functions := basefunctions.NewCachedFunctions(memory.GetFunctions(), 2, time.Minute, 0)
+	cache := functions.(basefunctions.CacheInterface)
+	dbName, collectionName := basetypes.DBName("websays"), basetypes.CollectionName("cachedArticles")
+
+	// The wrapper keeps the interfaces of the memory storage the controllers rely on
+	if _, ok := functions.GetFunctions().(basefunctions.SequenceInterface); !ok {
+		t.Error("Expected the cache to keep the sequences of the storage")
+	}
+	if _, ok := functions.GetFunctions().(basefunctions.DatabaseInterface); !ok {
+		t.Error("Expected the cache to keep the databases of the storage")
+	}
+
+	if _, err := functions.Add(dbName, collectionName, models.Article{ID: "1", Title: "First", Body: "Body"}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := functions.FindOne(dbName, collectionName, models.Article{ID: "1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := cache.CacheStats(); stats.Misses != 1 || stats.Hits != 2 {
+		t.Errorf("Expected the record read once from the storage; got %+v", stats)
+	}
+
+	// An update invalidates the cached record
+	if err := functions.UpdateOne(dbName, collectionName, nil, models.Article{ID: "1", Title: "Updated", Body: "Body"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := functions.FindOne(dbName, collectionName, models.Article{ID: "1"}); err != nil || found.(models.Article).Title != "Updated" {
+		t.Errorf("Expected the updated record; got %+v (%v)", found, err)
+	}
+
+	// A miss is cached until a write to the collection
+	for i := 0; i < 2; i++ {
+		if _, err := functions.FindOne(dbName, collectionName, models.Article{ID: "2"}); err == nil {
+			t.Fatal("Expected no record with ID 2")
+		}
+	}
+	if stats := cache.CacheStats(); stats.NegativeHits != 1 {
+		t.Errorf("Expected the second miss answered by the cache; got %+v", stats)
+	}
+	if _, err := functions.Add(dbName, collectionName, models.Article{ID: "2", Title: "Second", Body: "Body"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := functions.FindOne(dbName, collectionName, models.Article{ID: "2"}); err != nil {
+		t.Errorf("Expected the added record instead of the cached miss; got %v", err)
+	}
+
+	// A delete invalidates the cached record, and the size bound evicts the least recently used reads
+	if err := functions.DeleteOne(dbName, collectionName, models.Article{ID: "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := functions.FindOne(dbName, collectionName, models.Article{ID: "2"}); err == nil {
+		t.Error("Expected the deleted record to be gone")
+	}
+	functions.FindOne(dbName, collectionName, models.Article{ID: "1"})
+	functions.FindOne(dbName, collectionName, models.Article{ID: "3"})
+	if stats := cache.CacheStats(); stats.Evictions != 1 || stats.Entries != 2 {
+		t.Errorf("Expected one read evicted to keep two entries; got %+v", stats)
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	memory := &basefunctions.MemoryFunctions{}
+	functions := basefunctions.NewCachedFunctions(memory.GetFunctions(), 10, 50*time.Millisecond, 0)
+	dbName, collectionName := basetypes.DBName("websays"), basetypes.CollectionName("expiringArticles")
+
+	if _, err := functions.Add(dbName, collectionName, models.Article{ID: "1", Title: "First", Body: "Body"}); err != nil {
+		t.Fatal(err)
+	}
+	functions.FindOne(dbName, collectionName, models.Article{ID: "1"})
+
+	// A write around the cache is only seen once the cached record expires
+	if err := memory.UpdateOne(dbName, collectionName, nil, models.Article{ID: "1", Title: "Changed", Body: "Body"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if found, _ := functions.FindOne(dbName, collectionName, models.Article{ID: "1"}); found.(models.Article).Title != "First" {
+		t.Errorf("Expected the cached record before it expires; got %+v", found)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if found, _ := functions.FindOne(dbName, collectionName, models.Article{ID: "1"}); found.(models.Article).Title != "Changed" {
+		t.Errorf("Expected the record read again once expired; got %+v", found)
+	}
+
+	// A committed transaction invalidates the collections it wrote to
+	tx, err := functions.GetFunctions().(basefunctions.TransactionalInterface).Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.UpdateOne(dbName, collectionName, nil, models.Article{ID: "1", Title: "Committed", Body: "Body"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if found, _ := functions.FindOne(dbName, collectionName, models.Article{ID: "1"}); found.(models.Article).Title != "Committed" {
+		t.Errorf("Expected the record written by the transaction; got %+v", found)
+	}
+}
@@ -22,7 +22,7 @@ func TestCreateCategory(t *testing.T) {
 	// Create a sample JSON payload
 	requestData := map[string]interface{}{
 		"name": "firstCategory",
-		"id":   categoryController.GetNextID(),
+		"id":   nextID(t, categoryController, categoryController.GetDBName(), categoryController.GetCollectionName()),
 	}
 
 	// Convert the JSON payload to a byte slice
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"testing"
+	"time"
+	"websays/config"
+	"websays/database/baseconnections"
+	"websays/database/basefunctions"
+	"websays/database/basetypes"
+)
+
+func TestUnreachableMysqlReturnsErrors(t *testing.T) {
+	database := config.GetInstance().Database
+	t.Cleanup(func() {
+		config.GetInstance().Database = database
+	})
+	config.GetInstance().Database.Host = "127.0.0.1"
+	config.GetInstance().Database.Port = "1"
+	config.GetInstance().Database.ConnectTimeout = 1
+	config.GetInstance().Database.ConnectRetries = 1
+	config.GetInstance().Database.ConnectBackoff = 10
+
+	connection, err := baseconnections.GetInstance().GetConnection(basetypes.MYSQL)
+	if err == nil || connection != nil {
+		t.Fatalf("Expected an error connecting to a closed port; got %v", connection)
+	}
+
+	functions, err := basefunctions.GetInstance().GetFunctions(basetypes.MYSQL, "websays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = (*functions).FindOne("websays", "products", map[string]interface{}{"id": 1}); err == nil {
+		t.Error("Expected FindOne to report the connection error")
+	}
+}
+
+func TestUnreachableMysqlDoesNotBlock(t *testing.T) {
+	database := config.GetInstance().Database
+	t.Cleanup(func() {
+		config.GetInstance().Database = database
+	})
+	config.GetInstance().Database.Host = "127.0.0.1"
+	config.GetInstance().Database.Port = "1"
+	config.GetInstance().Database.ConnectTimeout = 1
+	config.GetInstance().Database.ConnectRetries = 3
+	config.GetInstance().Database.ConnectBackoff = 200
+	config.GetInstance().Database.StartupTimeout = 0
+
+	// The other storages are served while MySQL is being connected to
+	connecting := make(chan error)
+	go func() {
+		_, err := baseconnections.GetInstance().GetConnection(basetypes.MYSQL)
+		connecting <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	start := time.Now()
+	if _, err := baseconnections.GetInstance().GetConnection(basetypes.MEMORY); err != nil {
+		t.Fatal(err)
+	}
+	if waited := time.Since(start); waited > 100*time.Millisecond {
+		t.Errorf("Expected the memory connection without waiting for MySQL; waited %v", waited)
+	}
+	if err := <-connecting; err == nil {
+		t.Fatal("Expected an error connecting to a closed port")
+	}
+
+	// Once started, a connection fails at its first ping instead of retrying
+	start = time.Now()
+	if _, err := baseconnections.GetInstance().GetConnection(basetypes.MYSQL); err == nil {
+		t.Fatal("Expected an error connecting to a closed port")
+	}
+	if waited := time.Since(start); waited > 150*time.Millisecond {
+		t.Errorf("Expected a single ping after startup; waited %v", waited)
+	}
+}
@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"websays/app/controllers"
+	"websays/app/middlewares"
+	"websays/app/models"
+	"websays/app/validators"
+	"websays/config"
+	"websays/database/basefunctions"
+	"websays/database/basetypes"
+	"websays/httpHandler/responses"
+
+	"github.com/gorilla/mux"
+)
+
+func TestCancelledContextStopsStorage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	funcs, err := basefunctions.GetInstance().GetFunctions(basetypes.MEMORY, "websays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = (*funcs).AddContext(ctx, "websays", "contexts", models.Article{ID: "1", Title: "cancelled"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the memory storage to report the cancellation; got %v", err)
+	}
+
+	config.GetInstance().Sqlite.FileName = t.TempDir() + "/websays.sqlite"
+	funcs, err = basefunctions.GetInstance().GetFunctions(basetypes.SQLITE, "websays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	productController := &controllers.Product{ValidatorInterface: &validators.ProductValidator{}}
+	productController.SetBaseFunctions(*funcs)
+	productController.DoIndexing()
+
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	req, _ := http.NewRequestWithContext(expired, "GET", "/api/products", nil)
+	rr := httptest.NewRecorder()
+	productController.HandleListProducts(rr, req)
+
+	var response struct {
+		Code int `json:"code"`
+	}
+	json.NewDecoder(rr.Body).Decode(&response)
+	if response.Code != responses.REQUEST_TIMEOUT {
+		t.Errorf("Expected code %d for an expired request; got %d", responses.REQUEST_TIMEOUT, response.Code)
+	}
+}
+
+func TestTimeoutMiddlewareUsesRouteTimeouts(t *testing.T) {
+	server := config.GetInstance().Server
+	t.Cleanup(func() {
+		config.GetInstance().Server = server
+	})
+	config.GetInstance().Server.RequestTimeout = 5
+	config.GetInstance().Server.RouteTimeouts = map[string]int{"/api/slow": 60, "/api/unbounded": 0}
+
+	deadlines := make(map[string]time.Duration)
+	router := mux.NewRouter()
+	timeoutMiddleware := middlewares.TimeoutMiddleware{}
+	router.Use(timeoutMiddleware.GetHandlerFunc)
+	for _, path := range []string{"/api/fast", "/api/slow", "/api/unbounded"} {
+		path := path
+		router.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			if deadline, ok := r.Context().Deadline(); ok {
+				deadlines[path] = time.Until(deadline)
+			}
+		})
+	}
+	for _, path := range []string{"/api/fast", "/api/slow", "/api/unbounded"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if deadline := deadlines["/api/fast"]; deadline <= 0 || deadline > 5*time.Second {
+		t.Errorf("Expected the default timeout of 5s; got %v", deadline)
+	}
+	if deadline := deadlines["/api/slow"]; deadline <= 5*time.Second || deadline > 60*time.Second {
+		t.Errorf("Expected the route timeout of 60s; got %v", deadline)
+	}
+	if _, ok := deadlines["/api/unbounded"]; ok {
+		t.Error("Expected no deadline for a route timeout of 0")
+	}
+}
@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"websays/app/controllers"
+	"websays/app/models"
+	"websays/config"
+	"websays/database/basefunctions"
+	"websays/database/basetypes"
+
+	"github.com/gorilla/mux"
+)
+
+func TestMemoryDatabases(t *testing.T) {
+	filePath := config.GetInstance().FilePath
+	memoryConfig := config.GetInstance().Memory
+	dbName := config.GetInstance().Database.DBName
+	t.Cleanup(func() {
+		config.GetInstance().FilePath = filePath
+		config.GetInstance().Memory = memoryConfig
+		config.GetInstance().Database.DBName = dbName
+	})
+	config.GetInstance().Database.DBName = "websays"
+	config.GetInstance().FilePath = t.TempDir()
+	config.GetInstance().Memory.Persistence = true
+	config.GetInstance().Memory.WalFileName = ".memory.wal"
+	config.GetInstance().Memory.SnapshotFileName = ".memory.snapshot"
+
+	// A snapshot taken before databases were kept apart belongs to the configured database
+	legacy := `{"id": 3, "data": {"3_articles": {"id": 3, "title": "Legacy", "version": 1}}}`
+	if err := os.WriteFile(filepath.Join(config.GetInstance().FilePath, ".memory.snapshot"), []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	first := &basefunctions.MemoryFunctions{}
+	first.GetFunctions()
+	configured := basetypes.DBName(config.GetInstance().Database.DBName)
+	if _, err := first.FindOne(configured, "articles", models.Article{ID: "3"}); err != nil {
+		t.Fatalf("Expected the legacy article in the configured database; got %v", err)
+	}
+
+	if err := first.CreateDatabase("staging"); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.CreateDatabase("staging"); !errors.Is(err, basefunctions.ErrDatabaseExists) {
+		t.Errorf("Expected the second creation to fail; got %v", err)
+	}
+	if _, err := first.Add("staging", "articles", models.Article{ID: "3", Title: "Staging"}); err != nil {
+		t.Fatalf("Expected the same ID to be free in another database; got %v", err)
+	}
+	first.Add("test", "articles", models.Article{ID: "4", Title: "Test"})
+	if _, err := first.FindOne(configured, "articles", models.Article{ID: "4"}); err == nil {
+		t.Error("Expected the article of the test database to stay out of the configured one")
+	}
+
+	databases, err := first.ListDatabases()
+	if expected := []basetypes.DBName{"staging", "test", "websays"}; err != nil || !reflect.DeepEqual(databases, expected) {
+		t.Errorf("Expected %v; got %v (%v)", expected, databases, err)
+	}
+	if err := first.DropDatabase("test"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new store simulates a restart of the process, replaying the drop from the log
+	second := &basefunctions.MemoryFunctions{}
+	second.GetFunctions()
+	stored, err := second.FindOne("staging", "articles", models.Article{ID: "3"})
+	if err != nil || stored.(map[string]interface{})["title"] != "Staging" {
+		t.Errorf("Expected the staging article; got %v (%v)", stored, err)
+	}
+	if ok, _ := second.HasDatabase("test"); ok {
+		t.Error("Expected the dropped database to stay dropped")
+	}
+	if err := second.DropDatabase("test"); !errors.Is(err, basefunctions.ErrDatabaseNotFound) {
+		t.Errorf("Expected the dropped database to be missing; got %v", err)
+	}
+}
+
+func TestDatabasesApi(t *testing.T) {
+	functions := useFileStorage(t)
+	dbName := config.GetInstance().Database.DBName
+	t.Cleanup(func() { config.GetInstance().Database.DBName = dbName })
+	config.GetInstance().Database.DBName = "websays"
+	databases := &controllers.Databases{Storages: []basefunctions.DatabaseInterface{functions}}
+
+	serve := func(method string, handler http.HandlerFunc, body string, vars map[string]string) (int, int) {
+		t.Helper()
+		req, err := http.NewRequest(method, "/api/databases", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, vars)
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		var response struct {
+			Code int `json:"code"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		return rr.Code, response.Code
+	}
+
+	if _, code := serve("POST", databases.HandleCreateDatabase, `{"name": "staging"}`, nil); code != 1033 {
+		t.Fatalf("Expected the database to be created; got %d", code)
+	}
+	if status, code := serve("POST", databases.HandleCreateDatabase, `{"name": "staging"}`, nil); status != http.StatusConflict || code != 1036 {
+		t.Errorf("Expected the duplicate to be refused; got %d %d", status, code)
+	}
+	if _, code := serve("POST", databases.HandleCreateDatabase, `{"name": "../outside"}`, nil); code != 1004 {
+		t.Errorf("Expected the invalid name to be refused; got %d", code)
+	}
+
+	configured := basetypes.DBName(config.GetInstance().Database.DBName)
+	functions.Add(configured, "categories", models.Category{ID: "1", Name: "configured"})
+	functions.Add("staging", "categories", models.Category{ID: "1", Name: "staging"})
+	stored, err := functions.FindOne("staging", "categories", models.Category{ID: "1"})
+	if err != nil || stored.(map[string]interface{})["name"] != "staging" {
+		t.Errorf("Expected the category of the staging database; got %v (%v)", stored, err)
+	}
+	listed, err := functions.ListDatabases()
+	if expected := []basetypes.DBName{"staging", "websays"}; err != nil || !reflect.DeepEqual(listed, expected) {
+		t.Errorf("Expected %v; got %v (%v)", expected, listed, err)
+	}
+
+	if _, code := serve("DELETE", databases.HandleDropDatabase, "", map[string]string{"name": string(configured)}); code != 1004 {
+		t.Errorf("Expected the configured database to be kept; got %d", code)
+	}
+	if _, code := serve("DELETE", databases.HandleDropDatabase, "", map[string]string{"name": "staging"}); code != 1034 {
+		t.Fatalf("Expected the database to be dropped; got %d", code)
+	}
+	if status, code := serve("DELETE", databases.HandleDropDatabase, "", map[string]string{"name": "staging"}); status != http.StatusNotFound || code != 1035 {
+		t.Errorf("Expected the dropped database to be missing; got %d %d", status, code)
+	}
+	if _, err := functions.FindOne(configured, "categories", models.Category{ID: "1"}); err != nil {
+		t.Errorf("Expected the category of the configured database to survive; got %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+	"websays/database/basedialects"
+	"websays/database/basefilters"
+	"websays/database/basetypes"
+)
+
+// serialProduct is a product keyed by an AUTO_INCREMENT column, covering the IDs generated by the databases.
+type serialProduct struct {
+	ID        basetypes.ID `db:"id,INT,AUTO_INCREMENT,PRIMARY KEY"`
+	Name      string       `db:"name,VARCHAR(255),NOT NULL"`
+	Version   int          `db:"version,INT,NOT NULL,DEFAULT 1"`
+	DeletedAt int64        `db:"deleted_at,BIGINT,NOT NULL,DEFAULT 0"`
+	CreatedAt int64        `db:"created_at,BIGINT,NOT NULL,DEFAULT 0"`
+	UpdatedAt int64        `db:"updated_at,BIGINT,NOT NULL,DEFAULT 0"`
+	CreatedBy string       `db:"created_by,VARCHAR(255),NOT NULL,DEFAULT ''"`
+	UpdatedBy string       `db:"updated_by,VARCHAR(255),NOT NULL,DEFAULT ''"`
+}
+
+// dialectGolden lists the statements a dialect is expected to generate for the products table.
+type dialectGolden struct {
+	dialect     basedialects.Dialect
+	createTable string
+	insert      string
+	insertMany  string
+	returnsID   bool
+	upsert      string
+	page        string
+	pageAfter   string
+	updateOne   string
+	deleteOne   string
+	delete      string
+	like        string
+}
+
+func TestDialectStatements(t *testing.T) {
+	goldens := map[string]dialectGolden{
+		"mysql": {
+			dialect:     basedialects.MySQL{},
+			createTable: "CREATE TABLE IF NOT EXISTS `products` (`id` INT AUTO_INCREMENT PRIMARY KEY, `name` VARCHAR(255) NOT NULL, `version` INT NOT NULL DEFAULT 1, `deleted_at` BIGINT NOT NULL DEFAULT 0, `created_at` BIGINT NOT NULL DEFAULT 0, `updated_at` BIGINT NOT NULL DEFAULT 0, `created_by` VARCHAR(255) NOT NULL DEFAULT '', `updated_by` VARCHAR(255) NOT NULL DEFAULT '')",
+			insert:      "INSERT INTO `products` (`name`, `version`, `deleted_at`, `created_at`, `updated_at`, `created_by`, `updated_by`) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			insertMany:  "INSERT INTO `products` (`name`, `version`, `deleted_at`, `created_at`, `updated_at`, `created_by`, `updated_by`) VALUES (?, ?, ?, ?, ?, ?, ?), (?, ?, ?, ?, ?, ?, ?)",
+			upsert:      "INSERT INTO `products` (`id`, `name`, `version`, `deleted_at`, `created_at`, `updated_at`, `created_by`, `updated_by`) VALUES (?, ?, ?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), `deleted_at` = VALUES(`deleted_at`), `created_at` = VALUES(`created_at`), `updated_at` = VALUES(`updated_at`), `created_by` = VALUES(`created_by`), `updated_by` = VALUES(`updated_by`), `version` = `version` + 1",
+			page:        "SELECT * FROM `products` WHERE (`name` = ? AND `id` IN (?, ?)) ORDER BY 1 LIMIT ? OFFSET ?",
+			pageAfter:   "SELECT * FROM `products` WHERE `id` > ? ORDER BY `id` LIMIT ?",
+			updateOne:   "UPDATE `products` SET `name` = ?, `version` = `version` + ? WHERE `id` = ? LIMIT 1",
+			deleteOne:   "DELETE FROM `products` WHERE `id` = ? LIMIT 1",
+			delete:      "DELETE FROM `products` WHERE `deleted_at` < ?",
+			like:        "SELECT * FROM `products` WHERE `name` LIKE ?",
+		},
+		"postgres": {
+			dialect:     basedialects.PostgreSQL{},
+			createTable: `CREATE TABLE IF NOT EXISTS "products" ("id" SERIAL PRIMARY KEY, "name" VARCHAR(255) NOT NULL, "version" INTEGER NOT NULL DEFAULT 1, "deleted_at" BIGINT NOT NULL DEFAULT 0, "created_at" BIGINT NOT NULL DEFAULT 0, "updated_at" BIGINT NOT NULL DEFAULT 0, "created_by" VARCHAR(255) NOT NULL DEFAULT '', "updated_by" VARCHAR(255) NOT NULL DEFAULT '')`,
+			insert:      `INSERT INTO "products" ("name", "version", "deleted_at", "created_at", "updated_at", "created_by", "updated_by") VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING "id"`,
+			insertMany:  `INSERT INTO "products" ("name", "version", "deleted_at", "created_at", "updated_at", "created_by", "updated_by") VALUES ($1, $2, $3, $4, $5, $6, $7), ($8, $9, $10, $11, $12, $13, $14) RETURNING "id"`,
+			returnsID:   true,
+			upsert:      `INSERT INTO "products" ("id", "name", "version", "deleted_at", "created_at", "updated_at", "created_by", "updated_by") VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name", "deleted_at" = EXCLUDED."deleted_at", "created_at" = EXCLUDED."created_at", "updated_at" = EXCLUDED."updated_at", "created_by" = EXCLUDED."created_by", "updated_by" = EXCLUDED."updated_by", "version" = "products"."version" + 1`,
+			page:        `SELECT * FROM "products" WHERE ("name" = $1 AND "id" IN ($2, $3)) ORDER BY 1 LIMIT $4 OFFSET $5`,
+			pageAfter:   `SELECT * FROM "products" WHERE "id" > $1 ORDER BY "id" LIMIT $2`,
+			updateOne:   `UPDATE "products" SET "name" = $1, "version" = "version" + $2 WHERE ctid IN (SELECT ctid FROM "products" WHERE "id" = $3 LIMIT 1)`,
+			deleteOne:   `DELETE FROM "products" WHERE ctid IN (SELECT ctid FROM "products" WHERE "id" = $1 LIMIT 1)`,
+			delete:      `DELETE FROM "products" WHERE "deleted_at" < $1`,
+			like:        `SELECT * FROM "products" WHERE "name" ILIKE $1`,
+		},
+		"sqlite": {
+			dialect:     basedialects.SQLite{},
+			createTable: `CREATE TABLE IF NOT EXISTS "products" ("id" INTEGER PRIMARY KEY AUTOINCREMENT, "name" VARCHAR(255) NOT NULL, "version" INT NOT NULL DEFAULT 1, "deleted_at" BIGINT NOT NULL DEFAULT 0, "created_at" BIGINT NOT NULL DEFAULT 0, "updated_at" BIGINT NOT NULL DEFAULT 0, "created_by" VARCHAR(255) NOT NULL DEFAULT '', "updated_by" VARCHAR(255) NOT NULL DEFAULT '')`,
+			insert:      `INSERT INTO "products" ("name", "version", "deleted_at", "created_at", "updated_at", "created_by", "updated_by") VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			insertMany:  `INSERT INTO "products" ("name", "version", "deleted_at", "created_at", "updated_at", "created_by", "updated_by") VALUES (?, ?, ?, ?, ?, ?, ?), (?, ?, ?, ?, ?, ?, ?)`,
+			upsert:      `INSERT INTO "products" ("id", "name", "version", "deleted_at", "created_at", "updated_at", "created_by", "updated_by") VALUES (?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name", "deleted_at" = EXCLUDED."deleted_at", "created_at" = EXCLUDED."created_at", "updated_at" = EXCLUDED."updated_at", "created_by" = EXCLUDED."created_by", "updated_by" = EXCLUDED."updated_by", "version" = "products"."version" + 1`,
+			page:        `SELECT * FROM "products" WHERE ("name" = ? AND "id" IN (?, ?)) ORDER BY 1 LIMIT ? OFFSET ?`,
+			pageAfter:   `SELECT * FROM "products" WHERE "id" > ? ORDER BY "id" LIMIT ?`,
+			updateOne:   `UPDATE "products" SET "name" = ?, "version" = "version" + ? WHERE rowid IN (SELECT rowid FROM "products" WHERE "id" = ? LIMIT 1)`,
+			deleteOne:   `DELETE FROM "products" WHERE rowid IN (SELECT rowid FROM "products" WHERE "id" = ? LIMIT 1)`,
+			delete:      `DELETE FROM "products" WHERE "deleted_at" < ?`,
+			like:        `SELECT * FROM "products" WHERE "name" LIKE ?`,
+		},
+	}
+
+	byID := basefilters.Eq("id", 3)
+	expired := basefilters.Lt("deleted_at", 100)
+	likeName := basefilters.Like("name", "desk%")
+	byNameAndIDs := basefilters.And(basefilters.Eq("name", "desk"), basefilters.In("id", 1, 2))
+
+	for name, golden := range goldens {
+		t.Run(name, func(t *testing.T) {
+			builder := basedialects.Builder{Dialect: golden.dialect}
+			check := func(statement basedialects.Statement, query string, values ...interface{}) {
+				t.Helper()
+				if statement.Query != query {
+					t.Errorf("Expected %s; got %s", query, statement.Query)
+				}
+				if len(statement.Values) != len(values) || (len(values) > 0 && !reflect.DeepEqual(statement.Values, values)) {
+					t.Errorf("Expected values %v; got %v", values, statement.Values)
+				}
+			}
+
+			statement, err := builder.CreateTable("products", serialProduct{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			check(statement, golden.createTable)
+
+			statement, err = builder.Insert("products", serialProduct{Name: "desk"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			check(statement, golden.insert, "desk", 0, int64(0), int64(0), int64(0), "", "")
+			if statement.ReturnsID != golden.returnsID {
+				t.Errorf("Expected ReturnsID %v; got %v", golden.returnsID, statement.ReturnsID)
+			}
+
+			statement, err = builder.InsertMany("products", []interface{}{serialProduct{Name: "desk"}, serialProduct{Name: "chair"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			check(statement, golden.insertMany, "desk", 0, int64(0), int64(0), int64(0), "", "", "chair", 0, int64(0), int64(0), int64(0), "", "")
+			if _, err = builder.InsertMany("products", []interface{}{serialProduct{Name: "desk"}, serialProduct{ID: "3", Name: "chair"}}); err == nil {
+				t.Error("Expected models setting different columns to be rejected")
+			}
+
+			statement, err = builder.Upsert("products", serialProduct{ID: "3", Name: "desk"}, "version")
+			if err != nil {
+				t.Fatal(err)
+			}
+			check(statement, golden.upsert, basetypes.ID("3"), "desk", 0, int64(0), int64(0), int64(0), "", "")
+
+			check(builder.Page("products", &byNameAndIDs, 10, 20), golden.page, "desk", 1, 2, 10, 20)
+			check(builder.PageAfter("products", "id", 3, 10), golden.pageAfter, 3, 10)
+
+			statement, err = builder.UpdateOne("products", &byID, map[string]interface{}{"name": "chair", "version": basedialects.Increment(1)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			check(statement, golden.updateOne, "chair", 1, 3)
+
+			check(builder.DeleteOne("products", &byID), golden.deleteOne, 3)
+			check(builder.Delete("products", &expired), golden.delete, 100)
+			// LIKE ignores case in every dialect, like the like filters of the memory and file storages
+			check(builder.Select("products", &likeName), golden.like, "desk%")
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+	"websays/app/models"
+	"websays/config"
+	"websays/database/basefilters"
+	"websays/database/basefunctions"
+	"websays/database/baseids"
+	"websays/database/basetypes"
+)
+
+// useFileStorage points the file storage at an empty directory for the duration of the test.
+func useFileStorage(t *testing.T) *basefunctions.FileFunctions {
+	filePath := config.GetInstance().FilePath
+	runningFileName := config.GetInstance().RunningFileName
+	t.Cleanup(func() {
+		config.GetInstance().FilePath = filePath
+		config.GetInstance().RunningFileName = runningFileName
+	})
+	config.GetInstance().FilePath = t.TempDir()
+	config.GetInstance().RunningFileName = ".runningNumber"
+	return &basefunctions.FileFunctions{}
+}
+
+// Run with -race to check the record and index locking.
+func TestConcurrentCategoryWriters(t *testing.T) {
+	functions := useFileStorage(t)
+	const writers, perWriter = 16, 20
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*perWriter*3)
+	for w := 0; w < writers; w++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				category := models.Category{ID: nextID(t, baseids.NewSequence(functions), "websays", "categories"), Name: "category"}
+				if _, err := functions.Add("websays", "categories", category); err != nil {
+					errs <- err
+					continue
+				}
+				category.Name = "renamed"
+				if err := functions.UpdateOne("websays", "categories", nil, category, false); err != nil {
+					errs <- err
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				if _, err := functions.FindMany("websays", "categories", basefilters.Eq("name", "renamed"), basetypes.FindOptions{Limit: basetypes.MaxLimit}); err != nil {
+					errs <- err
+				}
+				functions.FindOne("websays", "categories", models.Category{ID: basetypes.IntID(int64(i + 1))})
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	result, err := functions.FindMany("websays", "categories", nil, basetypes.FindOptions{Limit: basetypes.MaxLimit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != writers*perWriter {
+		t.Errorf("Expected %d categories; got %d", writers*perWriter, result.Total)
+	}
+}
+
+func TestConcurrentAddSameID(t *testing.T) {
+	functions := useFileStorage(t)
+	const writers = 32
+
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	added := 0
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := functions.Add("websays", "categories", models.Category{ID: "1", Name: "contended"}); err == nil {
+				lock.Lock()
+				added++
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if added != 1 {
+		t.Errorf("Expected exactly one writer to add the ID; got %d", added)
+	}
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"os"
+	"testing"
+	"websays/app/models"
+	"websays/config"
+	"websays/database/basefilters"
+	"websays/database/basefunctions"
+	"websays/database/basetypes"
+)
+
+func TestMigrateFlatLayout(t *testing.T) {
+	filePath := config.GetInstance().FilePath
+	shardLength := config.GetInstance().FileShardLength
+	t.Cleanup(func() {
+		config.GetInstance().FilePath = filePath
+		config.GetInstance().FileShardLength = shardLength
+	})
+
+	directory := t.TempDir()
+	config.GetInstance().FilePath = directory
+	config.GetInstance().FileShardLength = 2
+
+	for name, content := range map[string]string{
+		"8_categories": `{"id":8,"name":"books"}`,
+		"2_categories": `{"id":2,"name":"games"}`,
+	} {
+		if err := os.WriteFile(directory+"/"+name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	functions := &basefunctions.FileFunctions{}
+	migrated, err := functions.MigrateFlatLayout("websays")
+	if err != nil || migrated != 2 {
+		t.Fatalf("Expected 2 migrated records; got %d (%v)", migrated, err)
+	}
+	if _, err := os.Stat(directory + "/8_categories"); !os.IsNotExist(err) {
+		t.Errorf("Expected the flat file to be moved; got %v", err)
+	}
+
+	// A second run has nothing left to migrate
+	migrated, err = functions.MigrateFlatLayout("websays")
+	if err != nil || migrated != 0 {
+		t.Errorf("Expected nothing to migrate; got %d (%v)", migrated, err)
+	}
+
+	result, err := functions.FindMany("websays", "categories", basefilters.Eq("name", "books"), basetypes.FindOptions{})
+	if err != nil || result.Total != 1 {
+		t.Fatalf("Expected to find the migrated record; got %+v (%v)", result, err)
+	}
+
+	if _, err := functions.Add("websays", "categories", models.Category{ID: "9", Name: "music"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := functions.DeleteOne("websays", "categories", models.Category{ID: "2"}); err != nil {
+		t.Fatal(err)
+	}
+	// Deleting moves the record to the trash, purging it removes it from the index
+	if err := functions.Purge("websays", "categories", models.Category{ID: "2"}); err != nil {
+		t.Fatal(err)
+	}
+	// The index log is folded into the index by the recovery pass
+	if _, err := functions.Recover(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(directory + "/websays/categories/.index.log"); !os.IsNotExist(err) {
+		t.Errorf("Expected the index log to be folded into the index; got %v", err)
+	}
+	index, _ := os.ReadFile(directory + "/websays/categories/.index")
+	if string(index) != "[8,9]\n" {
+		t.Errorf("Expected index [8,9]; got %s", index)
+	}
+}
+
+func TestShardLengthKeptByCollection(t *testing.T) {
+	functions := useFileStorage(t)
+	shardLength := config.GetInstance().FileShardLength
+	t.Cleanup(func() { config.GetInstance().FileShardLength = shardLength })
+	config.GetInstance().FileShardLength = 2
+
+	for _, id := range []basetypes.ID{"1", "2", "3"} {
+		if _, err := functions.Add("websays", "categories", models.Category{ID: id, Name: "sharded"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	layout, _ := os.ReadFile(config.GetInstance().FilePath + "/websays/categories/.layout")
+	if string(layout) != "{\"shardLength\":2}\n" {
+		t.Errorf("Expected the shard length to be persisted; got %s", layout)
+	}
+
+	// A restart with another shard length still reaches the records of the collection, new collections use it
+	config.GetInstance().FileShardLength = 0
+	restarted := &basefunctions.FileFunctions{}
+	if found, err := restarted.FindOne("websays", "categories", models.Category{ID: "2"}); err != nil || found == nil {
+		t.Fatalf("Expected to find the sharded record; got %v (%v)", found, err)
+	}
+	if _, err := restarted.Add("websays", "tags", models.Category{ID: "1", Name: "flat"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(config.GetInstance().FilePath + "/websays/tags/1.json"); err != nil {
+		t.Errorf("Expected the new collection to be flat; got %v", err)
+	}
+
+	// A collection written before layouts were persisted keeps the layout of its record files
+	os.Remove(config.GetInstance().FilePath + "/websays/categories/.layout")
+	if found, err := (&basefunctions.FileFunctions{}).FindOne("websays", "categories", models.Category{ID: "3"}); err != nil || found == nil {
+		t.Errorf("Expected to detect the sharded layout; got %v (%v)", found, err)
+	}
+}
@@ -0,0 +1,103 @@
+//go:build linux
+
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/exec"
+	"strconv"
+	"testing"
+	"websays/app/models"
+	"websays/config"
+	"websays/database/basefunctions"
+	"websays/database/baseids"
+	"websays/database/basetypes"
+)
+
+// Environment variables passed to the child processes of TestFileProcessesShareStorage.
+const (
+	processFilesPathEnv = "WEBSAYS_TEST_FILES_PATH"
+	processCountEnv     = "WEBSAYS_TEST_CATEGORY_COUNT"
+)
+
+func TestFileProcessesShareStorage(t *testing.T) {
+	const processes, perProcess = 6, 25
+	directory := t.TempDir()
+
+	children := make([]*exec.Cmd, 0, processes)
+	outputs := make([]*bytes.Buffer, 0, processes)
+	for i := 0; i < processes; i++ {
+		child := exec.Command(os.Args[0], "-test.run=^TestFileProcessCreateCategories$", "-test.count=1")
+		child.Env = append(os.Environ(), processFilesPathEnv+"="+directory, processCountEnv+"="+strconv.Itoa(perProcess))
+		output := &bytes.Buffer{}
+		child.Stdout = output
+		child.Stderr = output
+		if err := child.Start(); err != nil {
+			t.Fatal(err)
+		}
+		children = append(children, child)
+		outputs = append(outputs, output)
+	}
+
+	seen := make(map[int]bool)
+	for i, child := range children {
+		if err := child.Wait(); err != nil {
+			t.Fatalf("Child process failed: %v\n%s", err, outputs[i])
+		}
+		scanner := bufio.NewScanner(outputs[i])
+		for scanner.Scan() {
+			id, err := strconv.Atoi(scanner.Text())
+			if err != nil {
+				continue
+			}
+			if seen[id] {
+				t.Errorf("ID %d was handed out twice", id)
+			}
+			seen[id] = true
+		}
+	}
+	if len(seen) != processes*perProcess {
+		t.Errorf("Expected %d IDs; got %d", processes*perProcess, len(seen))
+	}
+
+	filePath := config.GetInstance().FilePath
+	runningFileName := config.GetInstance().RunningFileName
+	t.Cleanup(func() {
+		config.GetInstance().FilePath = filePath
+		config.GetInstance().RunningFileName = runningFileName
+	})
+	config.GetInstance().FilePath = directory
+	config.GetInstance().RunningFileName = ".runningNumber"
+
+	functions := &basefunctions.FileFunctions{}
+	result, err := functions.FindMany("websays", "categories", nil, basetypes.FindOptions{Limit: basetypes.MaxLimit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != processes*perProcess {
+		t.Errorf("Expected %d categories in the index; got %d", processes*perProcess, result.Total)
+	}
+}
+
+// TestFileProcessCreateCategories runs in the child processes of TestFileProcessesShareStorage,
+// creating categories in the shared directory and printing their IDs.
+func TestFileProcessCreateCategories(t *testing.T) {
+	directory := os.Getenv(processFilesPathEnv)
+	if directory == "" {
+		t.Skip("Only runs as a child process of TestFileProcessesShareStorage")
+	}
+	count, _ := strconv.Atoi(os.Getenv(processCountEnv))
+	config.GetInstance().FilePath = directory
+	config.GetInstance().RunningFileName = ".runningNumber"
+
+	functions := &basefunctions.FileFunctions{}
+	for i := 0; i < count; i++ {
+		id := nextID(t, baseids.NewSequence(functions), "websays", "categories")
+		if _, err := functions.Add("websays", "categories", models.Category{ID: id, Name: "shared"}); err != nil {
+			t.Fatal(err)
+		}
+		os.Stdout.WriteString(string(id) + "\n")
+	}
+}
@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"websays/config"
+	"websays/database/basefunctions"
+	"websays/database/baseids"
+)
+
+func TestFileRecovery(t *testing.T) {
+	filePath := config.GetInstance().FilePath
+	runningFileName := config.GetInstance().RunningFileName
+	t.Cleanup(func() {
+		config.GetInstance().FilePath = filePath
+		config.GetInstance().RunningFileName = runningFileName
+	})
+
+	directory := t.TempDir()
+	config.GetInstance().FilePath = directory
+	config.GetInstance().RunningFileName = ".runningNumber"
+
+	// Leave behind what a crash in the middle of writes would
+	files := map[string]string{
+		"websays/categories/5.json":             `{"id":5,"name":"intact"}`,
+		"websays/categories/3.json":             `{"id":3,"na`,
+		"websays/categories/4.json":             ``,
+		"websays/categories/1.json.4711.tmp":    `{"id":1,"name":"half written"}`,
+		"websays/categories/.index":             `[3,4,5,6]`,
+		"websays/categories/.sequence":          `garb`,
+		"websays/categories/.sequence.4712.tmp": `6`,
+		"websays/categories/notARecord.md":      `ignored`,
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(directory, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(directory, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	functions := &basefunctions.FileFunctions{}
+	report, err := functions.Recover()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.QuarantinedRecords) != 2 {
+		t.Errorf("Expected 2 quarantined records; got %v", report.QuarantinedRecords)
+	}
+	if len(report.RemovedTemporaryFiles) != 2 {
+		t.Errorf("Expected 2 removed temporary files; got %v", report.RemovedTemporaryFiles)
+	}
+	if sequence, _ := os.ReadFile(directory + "/websays/categories/.sequence"); len(report.RepairedSequences) != 1 || string(sequence) != "5" {
+		t.Errorf("Expected sequence repaired to 5; got %s (%+v)", sequence, report)
+	}
+	if _, err := os.Stat(directory + "/websays/categories/3.json.corrupt"); err != nil {
+		t.Errorf("Expected truncated record to be quarantined; got %v", err)
+	}
+	if index, _ := os.ReadFile(directory + "/websays/categories/.index"); strings.TrimSpace(string(index)) != "[5]" {
+		t.Errorf("Expected index rebuilt to [5]; got %s (%v)", index, report.RebuiltIndexes)
+	}
+	if id := nextID(t, baseids.NewSequence(functions), "websays", "categories"); id != "6" {
+		t.Errorf("Expected next ID 6; got %s", id)
+	}
+
+	// A second pass has nothing left to repair
+	report, err = functions.Recover()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.HasRepairs() {
+		t.Errorf("Expected no repairs on second pass; got %+v", report)
+	}
+}
@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"websays/app/models"
+	"websays/config"
+	"websays/database/basefilters"
+	"websays/database/basefunctions"
+	"websays/database/basetypes"
+)
+
+func TestFiltersAcrossStorages(t *testing.T) {
+	config.GetInstance().Sqlite.FileName = t.TempDir() + "/websays.sqlite"
+	sqlite, err := basefunctions.GetInstance().GetFunctions(basetypes.SQLITE, "websays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	memory := &basefunctions.MemoryFunctions{}
+	storages := map[string]basefunctions.BaseFucntionsInterface{
+		"memory": memory.GetFunctions(),
+		"file":   useFileStorage(t),
+		"sqlite": *sqlite,
+	}
+	dbName, collectionName := basetypes.DBName("websays"), basetypes.CollectionName("filteredProducts")
+
+	for name, storage := range storages {
+		if err = storage.EnsureIndex(dbName, collectionName, models.Product{}); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for _, product := range []models.Product{{ID: "1", Name: "Desk"}, {ID: "2", Name: "chair"}, {ID: "3", Name: "lamp"}} {
+			if _, err = storage.Add(dbName, collectionName, product); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+		// The document storages trash the records deleted by a model, the sql ones those of a soft deletable table
+		var lamp interface{} = models.Product{ID: "3"}
+		if name == "sqlite" {
+			lamp = map[string]interface{}{"id": 3}
+		}
+		if err = storage.DeleteOne(dbName, collectionName, lamp); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		// A filter uses the JSON names of the fields in every storage, the sql ones map them to their columns
+		result, err := storage.FindMany(dbName, collectionName, basefilters.Gt("createdAt", 0), basetypes.FindOptions{})
+		if err != nil || result.Total != 2 {
+			t.Errorf("%s: Expected both live products created at a time; got %+v (%v)", name, result, err)
+		}
+		trash := storage.(basefunctions.TrashInterface)
+		result, err = trash.FindTrash(dbName, collectionName, basefilters.Gt("deletedAt", 0), basetypes.FindOptions{})
+		if err != nil || result.Total != 1 {
+			t.Errorf("%s: Expected the trashed lamp; got %+v (%v)", name, result, err)
+		}
+
+		// Like ignores case everywhere
+		result, err = storage.FindMany(dbName, collectionName, basefilters.Like("name", "desk%"), basetypes.FindOptions{})
+		if err != nil || result.Total != 1 {
+			t.Errorf("%s: Expected the desk whatever its case; got %+v (%v)", name, result, err)
+		}
+
+		// A field the model doesn't have, like a column name, is rejected everywhere
+		for _, field := range []string{"created_at", "color"} {
+			_, err = storage.FindMany(dbName, collectionName, basefilters.Eq(field, 0), basetypes.FindOptions{})
+			if err == nil || !strings.Contains(err.Error(), "Unknown filter field") {
+				t.Errorf("%s: Expected the unknown field %s to be rejected; got %v", name, field, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"websays/app/controllers"
+	"websays/app/models"
+	"websays/app/validators"
+	"websays/database/basefunctions"
+	"websays/database/basehooks"
+	"websays/database/basetypes"
+)
+
+func TestHookedFunctions(t *testing.T) {
+	memory := &basefunctions.MemoryFunctions{}
+	hooks := basehooks.NewRegistry()
+	functions := basefunctions.NewHookedFunctions(memory.GetFunctions(), hooks)
+	dbName, collectionName := basetypes.DBName("websays"), basetypes.CollectionName("hookedArticles")
+
+	// The wrapper keeps the interfaces of the memory storage the controllers rely on
+	if _, ok := functions.GetFunctions().(basefunctions.SequenceInterface); !ok {
+		t.Error("Expected the hooks to keep the sequences of the storage")
+	}
+	if _, ok := functions.GetFunctions().(basefunctions.DatabaseInterface); !ok {
+		t.Error("Expected the hooks to keep the databases of the storage")
+	}
+
+	// A before-hook changes the data or aborts the write, the after-hooks only see the writes that succeeded
+	hooks.Before(dbName, collectionName, basehooks.ADD, "title", func(ctx context.Context, event *basehooks.Event) error {
+		article := event.Data.(models.Article)
+		if article.Title == "" {
+			return errors.New("Title required")
+		}
+		article.Title = strings.ToUpper(article.Title)
+		event.Data = article
+		return nil
+	})
+	added := make([]basetypes.ID, 0)
+	hooks.After(dbName, collectionName, basehooks.ADD, "added", func(ctx context.Context, event basehooks.Event) {
+		added = append(added, event.ID)
+	})
+	// Registering under the same name replaces the hook instead of running it twice
+	hooks.After(dbName, collectionName, basehooks.ADD, "added", func(ctx context.Context, event basehooks.Event) {
+		added = append(added, event.ID)
+	})
+
+	if _, err := functions.Add(dbName, collectionName, models.Article{ID: "1", Title: "first", Body: "Body"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := functions.Add(dbName, collectionName, models.Article{ID: "2", Body: "Body"}); err == nil || err.Error() != "Title required" {
+		t.Errorf("Expected the hook to abort the insert; got %v", err)
+	}
+	if _, err := functions.Add(dbName, collectionName, models.Article{ID: "1", Title: "again", Body: "Body"}); err == nil {
+		t.Error("Expected the duplicate insert to fail")
+	}
+	if found, err := functions.FindOne(dbName, collectionName, models.Article{ID: "1"}); err != nil || found.(models.Article).Title != "FIRST" {
+		t.Errorf("Expected the title changed by the hook; got %+v (%v)", found, err)
+	}
+	if len(added) != 1 || added[0] != "1" {
+		t.Errorf("Expected the after-hook to run once for the inserted article; got %v", added)
+	}
+
+	// Every item of a bulk write is hooked on its own
+	result, err := functions.AddMany(dbName, collectionName, []interface{}{
+		models.Article{ID: "2", Title: "second", Body: "Body"},
+		models.Article{ID: "3", Body: "Body"},
+		models.Article{ID: "4", Title: "fourth", Body: "Body"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Succeeded != 2 || result.Items[1].Error != "Title required" || result.Items[2].ID != "4" {
+		t.Errorf("Expected the untitled article to fail alone; got %+v", result)
+	}
+	if len(added) != 3 {
+		t.Errorf("Expected the after-hooks of the two inserted articles; got %v", added)
+	}
+
+	// The after-hooks of a transaction run once it is committed, and not at all when it is rolled back
+	deleted := 0
+	hooks.After(dbName, collectionName, basehooks.DELETE, "deleted", func(ctx context.Context, event basehooks.Event) {
+		deleted++
+	})
+	tx, err := functions.GetFunctions().(basefunctions.TransactionalInterface).Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.DeleteOne(dbName, collectionName, models.Article{ID: "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	tx, err = functions.GetFunctions().(basefunctions.TransactionalInterface).Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.DeleteOne(dbName, collectionName, models.Article{ID: "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 0 {
+		t.Error("Expected no after-hook before the commit")
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Errorf("Expected the after-hook to run once committed; got %d", deleted)
+	}
+}
+
+func TestHooksScopedByDatabase(t *testing.T) {
+	memory := &basefunctions.MemoryFunctions{}
+	hooks := basehooks.NewRegistry()
+	functions := basefunctions.NewHookedFunctions(memory.GetFunctions(), hooks)
+	collectionName := basetypes.CollectionName("scopedArticles")
+
+	// A hook of a database doesn't run for the other databases of the same storage
+	ran := make([]basetypes.DBName, 0)
+	hooks.After("tenant", collectionName, basehooks.ADD, "tenant", func(ctx context.Context, event basehooks.Event) {
+		ran = append(ran, event.DBName)
+	})
+	everywhere := 0
+	hooks.After(basehooks.AllDatabases, collectionName, basehooks.ADD, "everywhere", func(ctx context.Context, event basehooks.Event) {
+		everywhere++
+	})
+	for _, dbName := range []basetypes.DBName{"websays", "tenant"} {
+		if _, err := functions.Add(dbName, collectionName, models.Article{ID: "1", Title: "Title", Body: "Body"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(ran) != 1 || ran[0] != "tenant" {
+		t.Errorf("Expected the hook of the tenant database to run for it alone; got %v", ran)
+	}
+	if everywhere != 2 {
+		t.Errorf("Expected the hook of every database to run for both; got %d", everywhere)
+	}
+}
+
+func TestCategoryNameHook(t *testing.T) {
+	categoryController := &controllers.Category{ValidatorInterface: &validators.CategoryValidator{}}
+	categoryController.SetBaseFunctions(basefunctions.NewHookedFunctions(useFileStorage(t), basehooks.GetInstance()))
+	if err := categoryController.DoIndexing(); err != nil {
+		t.Fatal(err)
+	}
+	dbName, collectionName := categoryController.GetDBName(), categoryController.GetCollectionName()
+	// The file storage reads the categories back as documents
+	name := func(found interface{}) interface{} {
+		document, _ := found.(map[string]interface{})
+		return document["name"]
+	}
+
+	// The controller trims the names of the categories
+	if _, err := categoryController.Add(dbName, collectionName, models.Category{ID: "1", Name: "  books "}); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := categoryController.FindOne(dbName, collectionName, models.Category{ID: "1"}); err != nil || name(found) != "books" {
+		t.Errorf("Expected the name trimmed on insert; got %+v (%v)", found, err)
+	}
+	if err := categoryController.UpdateOne(dbName, collectionName, "", models.Category{ID: "1", Name: "novels\n"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := categoryController.FindOne(dbName, collectionName, models.Category{ID: "1"}); err != nil || name(found) != "novels" {
+		t.Errorf("Expected the name trimmed on update; got %+v (%v)", found, err)
+	}
+
+	// A name of spaces only is refused by the validation and by the hook
+	if err := categoryController.Validate("/api/createCategory", models.Category{Name: "   "}); err == nil {
+		t.Error("Expected a blank name to fail the validation")
+	}
+	if _, err := categoryController.Add(dbName, collectionName, models.Category{ID: "2", Name: "   "}); err == nil {
+		t.Error("Expected the hook to refuse a blank name")
+	}
+
+	// The categories of the databases named by the X-Database header are trimmed too
+	if _, err := categoryController.Add("tenant", collectionName, models.Category{ID: "1", Name: " books"}); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := categoryController.FindOne("tenant", collectionName, models.Category{ID: "1"}); err != nil || name(found) != "books" {
+		t.Errorf("Expected the name of the tenant category trimmed; got %+v (%v)", found, err)
+	}
+}
@@ -0,0 +1,240 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"websays/app/controllers"
+	"websays/app/models"
+	"websays/app/validators"
+	"websays/config"
+	"websays/database/basefunctions"
+	"websays/database/baseids"
+	"websays/database/basetypes"
+
+	"github.com/gorilla/mux"
+)
+
+// nextID returns the next ID of a generator for a collection, failing the test if it can't make one.
+func nextID(t *testing.T, generator baseids.IDGenerator, dbName basetypes.DBName, collectionName basetypes.CollectionName) basetypes.ID {
+	t.Helper()
+	id, err := generator.NextID(context.Background(), dbName, collectionName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// keyedNote is a model of a table keyed by strings, as the sql storages need a text column for them.
+type keyedNote struct {
+	ID   basetypes.ID `db:"id,VARCHAR(64),PRIMARY KEY" json:"id"`
+	Text string       `db:"text,VARCHAR(255)" json:"text"`
+}
+
+func (u keyedNote) GetID() basetypes.ID {
+	return u.ID
+}
+
+func TestIDGenerators(t *testing.T) {
+	if _, err := baseids.New("autoincrement", nil); err == nil {
+		t.Error("Expected an unknown strategy to be refused")
+	}
+	if _, err := baseids.NewSnowflake(1024); err == nil {
+		t.Error("Expected a node ID out of range to be refused")
+	}
+
+	for _, strategy := range []baseids.Strategy{baseids.UUIDV4, baseids.UUIDV7, baseids.ULID, baseids.SNOWFLAKE} {
+		generator, err := baseids.New(strategy, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen := make(map[basetypes.ID]bool)
+		var previous basetypes.ID
+		for i := 0; i < 5000; i++ {
+			id := nextID(t, generator, "websays", "articles")
+			if !id.Valid() || seen[id] {
+				t.Fatalf("Expected a new valid %s ID; got %q", strategy, id)
+			}
+			seen[id] = true
+			// The time ordered strategies keep the IDs of one process in creation order
+			if strategy != baseids.UUIDV4 && previous != "" && !previous.Less(id) {
+				t.Fatalf("Expected the %s IDs in order; got %q after %q", strategy, id, previous)
+			}
+			previous = id
+		}
+		if strategy == baseids.SNOWFLAKE {
+			// Snowflake IDs don't fit in a JavaScript number, so they are encoded as strings
+			if encoded, _ := json.Marshal(previous); encoded[0] != '"' {
+				t.Errorf("Expected the snowflake ID as a JSON string; got %s", encoded)
+			}
+		}
+	}
+
+	uuid := nextID(t, &baseids.UUIDv7{}, "websays", "articles")
+	if len(uuid) != 36 || uuid[14] != '7' || !strings.ContainsAny(string(uuid[19]), "89ab") {
+		t.Errorf("Expected a version 7 UUID; got %s", uuid)
+	}
+	if ulid := nextID(t, &baseids.ULIDs{}, "websays", "articles"); len(ulid) != 26 {
+		t.Errorf("Expected a ULID of 26 characters; got %s", ulid)
+	}
+}
+
+func TestIDEncoding(t *testing.T) {
+	var decoded []basetypes.ID
+	if err := json.Unmarshal([]byte(`[7, "7", "01HZX3K8Q9V6T2M4N7P5R8S0WY", 0, null]`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded[0] != "7" || decoded[1] != "7" || !decoded[0].IsInt() || decoded[2].IsInt() || decoded[3] != "" || decoded[4] != "" {
+		t.Errorf("Expected integer and string IDs; got %q", decoded)
+	}
+	if err := json.Unmarshal([]byte(`[1.5]`), &decoded); err == nil {
+		t.Error("Expected a fractional ID to be refused")
+	}
+	if encoded, _ := json.Marshal([]basetypes.ID{"7", "abc", "9007199254740993"}); string(encoded) != `[7,"abc","9007199254740993"]` {
+		t.Errorf("Expected small integers as numbers and the rest as strings; got %s", encoded)
+	}
+	// The IDs are bound as strings, so a text key column is compared as text and "07" never matches "7"
+	if value, err := basetypes.IntID(7).Value(); err != nil || value != "7" {
+		t.Errorf("Expected the integer ID bound as a string; got %#v (%v)", value, err)
+	}
+	for _, invalid := range []string{"", "007", "-1", "../etc", "a_b", strings.Repeat("a", 65)} {
+		if _, err := basetypes.ParseID(invalid); err == nil {
+			t.Errorf("Expected %q to be refused", invalid)
+		}
+	}
+}
+
+func TestMemoryStringKeys(t *testing.T) {
+	functions := &basefunctions.MemoryFunctions{}
+	functions.GetFunctions()
+	articleController := &controllers.Article{ValidatorInterface: &validators.ArticleValidator{}}
+	articleController.SetBaseFunctions(functions)
+	articleController.SetIDGenerator(&baseids.ULIDs{})
+
+	payload, _ := json.Marshal(models.Article{Title: "Keyed", Body: "Body"})
+	req, err := http.NewRequest("POST", "/api/createArticle", bytes.NewBuffer(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	articleController.HandleAddArticle(rr, req)
+	var response struct {
+		Data models.Article `json:"data"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil || len(response.Data.ID) != 26 {
+		t.Fatalf("Expected the article under a ULID; got %+v (%v)", response.Data, err)
+	}
+	defer functions.Purge(articleController.GetDBName(), articleController.GetCollectionName(), response.Data)
+
+	req, _ = http.NewRequest("GET", "/api/readArticle/"+string(response.Data.ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": string(response.Data.ID)})
+	rr = httptest.NewRecorder()
+	articleController.HandleReadArticle(rr, req)
+	if rr.Code != http.StatusOK || !bytes.Contains(rr.Body.Bytes(), []byte(`"`+string(response.Data.ID)+`"`)) {
+		t.Errorf("Expected to read the article by its ULID; got %d: %s", rr.Code, rr.Body)
+	}
+}
+
+func TestFileStringKeys(t *testing.T) {
+	functions := useFileStorage(t)
+	for _, id := range []basetypes.ID{"10", "beta", "2", "alpha"} {
+		if _, err := functions.Add("websays", "categories", models.Category{ID: id, Name: string(id)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := functions.Add("websays", "categories", models.Category{ID: "../escape", Name: "outside"}); err == nil {
+		t.Error("Expected an invalid ID to be refused")
+	}
+
+	result, err := functions.FindMany("websays", "categories", nil, basetypes.FindOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, record := range result.Data {
+		names = append(names, record.(map[string]interface{})["name"].(string))
+	}
+	if strings.Join(names, ",") != "2,10,alpha,beta" {
+		t.Errorf("Expected the integer IDs in order before the string keys; got %v", names)
+	}
+	// The writes are logged next to the index until the recovery pass folds them into it
+	if _, err := functions.Recover(); err != nil {
+		t.Fatal(err)
+	}
+	if index, _ := os.ReadFile(config.GetInstance().FilePath + "/websays/categories/.index"); strings.TrimSpace(string(index)) != `[2,10,"alpha","beta"]` {
+		t.Errorf("Expected the integer IDs to stay numbers in the index; got %s", index)
+	}
+
+	// The string keys leave the sequence of the collection alone
+	if id := nextID(t, baseids.NewSequence(functions), "websays", "categories"); id != "11" {
+		t.Errorf("Expected the sequence to follow the highest integer ID; got %s", id)
+	}
+}
+
+func TestSqliteStringKeys(t *testing.T) {
+	config.GetInstance().Sqlite.FileName = t.TempDir() + "/websays.sqlite"
+	sqlite, err := basefunctions.GetInstance().GetFunctions(basetypes.SQLITE, "websays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = (*sqlite).EnsureIndex("websays", "notes", keyedNote{}); err != nil {
+		t.Fatal(err)
+	}
+
+	id := nextID(t, &baseids.UUIDv4{}, "websays", "notes")
+	added, err := (*sqlite).Add("websays", "notes", keyedNote{ID: id, Text: "keyed"})
+	if err != nil || added != id {
+		t.Fatalf("Expected the note under its UUID; got %q (%v)", added, err)
+	}
+	defer (*sqlite).DeleteOne("websays", "notes", map[string]interface{}{"id": id})
+
+	stored, err := (*sqlite).FindOne("websays", "notes", map[string]interface{}{"id": id})
+	if err != nil || stored != (keyedNote{ID: id, Text: "keyed"}) {
+		t.Errorf("Expected the note read back by its UUID; got %+v (%v)", stored, err)
+	}
+	if id := nextID(t, baseids.NewSequence(*sqlite), "websays", "notes"); id != "" {
+		t.Errorf("Expected the sequence to leave the IDs to the database; got %s", id)
+	}
+}
+
+func TestSqliteProductKeys(t *testing.T) {
+	config.GetInstance().Sqlite.FileName = t.TempDir() + "/websays.sqlite"
+	sqlite, err := basefunctions.GetInstance().GetFunctions(basetypes.SQLITE, "websays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = (*sqlite).EnsureIndex("websays", "keyedProducts", models.Product{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A product keyed by a UUID reads back under it
+	uuid := nextID(t, &baseids.UUIDv4{}, "websays", "keyedProducts")
+	if added, err := (*sqlite).Add("websays", "keyedProducts", models.Product{ID: uuid, Name: "desk"}); err != nil || added != uuid {
+		t.Fatalf("Expected the product under its UUID; got %q (%v)", added, err)
+	}
+	stored, err := (*sqlite).FindOne("websays", "keyedProducts", map[string]interface{}{"id": uuid})
+	if product, ok := stored.(models.Product); err != nil || !ok || product.ID != uuid || product.Name != "desk" || product.Version != 1 {
+		t.Errorf("Expected the desk read back by its UUID; got %+v (%v)", stored, err)
+	}
+
+	// The products without an ID take the sequence of the table, which skips the integer IDs stored in the meantime
+	ids := []basetypes.ID{}
+	for _, name := range []string{"chair", "lamp"} {
+		id, err := (*sqlite).Add("websays", "keyedProducts", models.Product{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+		if err := (*sqlite).UpdateOne("websays", "keyedProducts", nil, models.Product{ID: "2", Name: "upserted"}, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(ids) != 2 || ids[0] != "1" || ids[1] != "3" {
+		t.Errorf("Expected the sequence IDs 1 and 3; got %v", ids)
+	}
+}
@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"websays/app/models"
+	"websays/config"
+	"websays/database/basefunctions"
+	"websays/database/baseids"
+	"websays/database/basetypes"
+)
+
+// useMemoryPersistence persists the memory stores created by the test to a temporary directory.
+func useMemoryPersistence(t *testing.T) {
+	filePath := config.GetInstance().FilePath
+	memoryConfig := config.GetInstance().Memory
+	t.Cleanup(func() {
+		config.GetInstance().FilePath = filePath
+		config.GetInstance().Memory = memoryConfig
+	})
+
+	config.GetInstance().FilePath = t.TempDir()
+	config.GetInstance().Memory.Persistence = true
+	config.GetInstance().Memory.WalFileName = ".memory.wal"
+	config.GetInstance().Memory.SnapshotFileName = ".memory.snapshot"
+	config.GetInstance().Memory.SnapshotInterval = 0
+}
+
+// appendToMemoryLog writes text at the end of the write-ahead log.
+func appendToMemoryLog(t *testing.T, text string) {
+	file, err := os.OpenFile(filepath.Join(config.GetInstance().FilePath, ".memory.wal"), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(text); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMemoryPersistenceRestore(t *testing.T) {
+	useMemoryPersistence(t)
+
+	var dbName basetypes.DBName = "websays"
+	var collection basetypes.CollectionName = "articles"
+
+	first := &basefunctions.MemoryFunctions{}
+	first.GetFunctions()
+
+	kept := models.Article{ID: nextID(t, baseids.NewSequence(first), dbName, collection), Title: "Kept", Body: "Body"}
+	deleted := models.Article{ID: nextID(t, baseids.NewSequence(first), dbName, collection), Title: "Deleted", Body: "Body"}
+	first.Add(dbName, collection, kept)
+	first.Add(dbName, collection, deleted)
+
+	// Compact what was written so far, then keep writing to the log only
+	err := first.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kept.Title = "Kept and updated"
+	first.UpdateOne(dbName, collection, nil, kept, false)
+	first.DeleteOne(dbName, collection, deleted)
+	logged := models.Article{ID: nextID(t, baseids.NewSequence(first), dbName, collection), Title: "Logged", Body: "Body"}
+	first.Add(dbName, collection, logged)
+
+	// A new store simulates a restart of the process
+	second := &basefunctions.MemoryFunctions{}
+	second.GetFunctions()
+
+	data, err := second.FindOne(dbName, collection, kept)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.(map[string]interface{})["title"] != "Kept and updated" {
+		t.Errorf("Expected updated title; got %v", data)
+	}
+	if _, err := second.FindOne(dbName, collection, deleted); err == nil {
+		t.Errorf("Expected deleted article to stay deleted")
+	}
+	if _, err := second.FindOne(dbName, collection, logged); err != nil {
+		t.Errorf("Expected article from the log; got %v", err)
+	}
+	if id := nextID(t, baseids.NewSequence(second), dbName, collection); !logged.ID.Less(id) {
+		t.Errorf("Expected next ID above %s; got %s", logged.ID, id)
+	}
+}
+
+func TestMemoryPersistenceTruncatedLastLine(t *testing.T) {
+	useMemoryPersistence(t)
+
+	var dbName basetypes.DBName = "websays"
+	var collection basetypes.CollectionName = "articles"
+
+	first := &basefunctions.MemoryFunctions{}
+	first.GetFunctions()
+	logged := models.Article{ID: nextID(t, baseids.NewSequence(first), dbName, collection), Title: "Logged", Body: "Body"}
+	first.Add(dbName, collection, logged)
+	first.Close()
+
+	// A crash in the middle of a write leaves half a line at the end of the log
+	appendToMemoryLog(t, `{"op":"add","db`)
+
+	second := &basefunctions.MemoryFunctions{}
+	second.GetFunctions()
+	if _, err := second.FindOne(dbName, collection, logged); err != nil {
+		t.Fatalf("Expected article from the log; got %v", err)
+	}
+	added := models.Article{ID: nextID(t, baseids.NewSequence(second), dbName, collection), Title: "Added", Body: "Body"}
+	if _, err := second.Add(dbName, collection, added); err != nil {
+		t.Fatal(err)
+	}
+	second.Close()
+
+	// The half line was cut off, so the entry written after it is readable
+	third := &basefunctions.MemoryFunctions{}
+	third.GetFunctions()
+	defer third.Close()
+	if _, err := third.FindOne(dbName, collection, added); err != nil {
+		t.Errorf("Expected article written after the truncated line; got %v", err)
+	}
+}
+
+func TestMemoryPersistenceUnreadableLine(t *testing.T) {
+	useMemoryPersistence(t)
+
+	var dbName basetypes.DBName = "websays"
+	var collection basetypes.CollectionName = "articles"
+
+	first := &basefunctions.MemoryFunctions{}
+	first.GetFunctions()
+	before := models.Article{ID: nextID(t, baseids.NewSequence(first), dbName, collection), Title: "Before", Body: "Body"}
+	first.Add(dbName, collection, before)
+	first.Close()
+
+	// An entry follows the unreadable line, so it isn't a write cut short by a crash
+	logged, err := os.ReadFile(filepath.Join(config.GetInstance().FilePath, ".memory.wal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendToMemoryLog(t, "not an entry\n"+string(logged))
+
+	// The log can't be replayed past the unreadable line, so nothing is restored
+	second := &basefunctions.MemoryFunctions{}
+	second.GetFunctions()
+	defer second.Close()
+	if _, err := second.FindOne(dbName, collection, before); err == nil {
+		t.Errorf("Expected nothing restored from a log with an unreadable line")
+	}
+	if err := second.Snapshot(); err == nil {
+		t.Errorf("Expected persistence disabled after a failed restore")
+	}
+}
+
+func TestMemoryPersistenceCloseStopsSnapshots(t *testing.T) {
+	useMemoryPersistence(t)
+	config.GetInstance().Memory.SnapshotInterval = 1
+
+	var dbName basetypes.DBName = "websays"
+	var collection basetypes.CollectionName = "articles"
+
+	functions := &basefunctions.MemoryFunctions{}
+	functions.GetFunctions()
+	article := models.Article{ID: nextID(t, baseids.NewSequence(functions), dbName, collection), Title: "Title", Body: "Body"}
+	functions.Add(dbName, collection, article)
+	if err := functions.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+	if _, err := os.Stat(filepath.Join(config.GetInstance().FilePath, ".memory.snapshot")); !os.IsNotExist(err) {
+		t.Errorf("Expected no snapshot after Close; got %v", err)
+	}
+	if _, err := functions.Add(dbName, collection, article); err == nil {
+		t.Errorf("Expected writes to fail after Close")
+	}
+}
@@ -0,0 +1,217 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"websays/app/controllers"
+	"websays/app/middlewares"
+	"websays/app/models"
+	"websays/app/validators"
+	"websays/config"
+	"websays/database/basefunctions"
+	"websays/database/basetypes"
+	"websays/httpHandler/basemodels"
+
+	"github.com/gorilla/mux"
+)
+
+// withoutMetadata returns a stored record with its metadata cleared, for comparing records whose timestamps vary.
+func withoutMetadata(record interface{}) interface{} {
+	if timestamped, ok := record.(basemodels.TimestampedModels); ok {
+		return timestamped.WithMetadata(basetypes.Metadata{})
+	}
+	return record
+}
+
+func TestRecordMetadata(t *testing.T) {
+	config.GetInstance().Sqlite.FileName = t.TempDir() + "/websays.sqlite"
+	sqlite, err := basefunctions.GetInstance().GetFunctions(basetypes.SQLITE, "websays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = (*sqlite).EnsureIndex("websays", "metadataProducts", models.Product{}); err != nil {
+		t.Fatal(err)
+	}
+	memory := &basefunctions.MemoryFunctions{}
+	storages := []struct {
+		name       string
+		functions  basefunctions.BaseFucntionsInterface
+		collection basetypes.CollectionName
+		record     func(id basetypes.ID, name string) interface{}
+		update     func(id basetypes.ID, name string) interface{}
+		condition  func(id basetypes.ID) interface{}
+	}{
+		{"memory", memory.GetFunctions(), "metadataArticles",
+			func(id basetypes.ID, name string) interface{} { return models.Article{ID: id, Title: name} },
+			func(id basetypes.ID, name string) interface{} {
+				return models.Article{ID: id, Title: name, CreatedAt: 1, CreatedBy: "mallory"}
+			},
+			func(id basetypes.ID) interface{} { return models.Article{ID: id} }},
+		{"file", useFileStorage(t), "metadataCategories",
+			func(id basetypes.ID, name string) interface{} { return models.Category{ID: id, Name: name} },
+			func(id basetypes.ID, name string) interface{} {
+				return models.Category{ID: id, Name: name, CreatedAt: 1, CreatedBy: "mallory"}
+			},
+			func(id basetypes.ID) interface{} { return models.Category{ID: id} }},
+		{"sqlite", *sqlite, "metadataProducts",
+			func(id basetypes.ID, name string) interface{} { return models.Product{ID: id, Name: name} },
+			func(id basetypes.ID, name string) interface{} { return map[string]interface{}{"name": name} },
+			func(id basetypes.ID) interface{} { return map[string]interface{}{"id": id} }},
+	}
+
+	alice := basetypes.WithPrincipal(context.Background(), "alice")
+	bob := basetypes.WithPrincipal(context.Background(), "bob")
+	before := time.Now().Unix()
+	for _, storage := range storages {
+		// The creation is recorded on Add, whatever the data carries
+		collection := storage.collection
+		if _, err = storage.functions.AddContext(alice, "websays", collection, storage.record("7", "created")); err != nil {
+			t.Fatalf("%s: %v", storage.name, err)
+		}
+		found, err := storage.functions.FindOne("websays", collection, storage.condition("7"))
+		if err != nil {
+			t.Fatalf("%s: %v", storage.name, err)
+		}
+		created := basefunctions.RecordMetadata(found)
+		if created.CreatedAt < before || created.UpdatedAt != created.CreatedAt || created.CreatedBy != "alice" || created.UpdatedBy != "alice" {
+			t.Errorf("%s: Expected the record created now by alice; got %+v", storage.name, created)
+		}
+
+		// An update records its principal and keeps the creation of the record
+		if err = storage.functions.UpdateOneContext(bob, "websays", collection, storage.condition("7"), storage.update("7", "updated"), false); err != nil {
+			t.Fatalf("%s: %v", storage.name, err)
+		}
+		found, err = storage.functions.FindOne("websays", collection, storage.condition("7"))
+		if err != nil {
+			t.Fatalf("%s: %v", storage.name, err)
+		}
+		updated := basefunctions.RecordMetadata(found)
+		if updated.CreatedAt != created.CreatedAt || updated.CreatedBy != "alice" || updated.UpdatedAt < created.UpdatedAt || updated.UpdatedBy != "bob" {
+			t.Errorf("%s: Expected the record created by alice and updated by bob; got %+v", storage.name, updated)
+		}
+	}
+
+	// An upsert replacing a record keeps its creation too
+	if err = (*sqlite).UpdateOneContext(bob, "websays", "metadataProducts", nil, models.Product{ID: "7", Name: "upserted"}, true); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := (*sqlite).FindOne("websays", "metadataProducts", map[string]interface{}{"id": "7"}); err != nil || basefunctions.RecordMetadata(found).CreatedBy != "alice" || basefunctions.RecordMetadata(found).UpdatedBy != "bob" {
+		t.Errorf("Expected the upserted product created by alice and updated by bob; got %+v (%v)", found, err)
+	}
+}
+
+func TestCategoryIfModifiedSince(t *testing.T) {
+	header, proxies := config.GetInstance().Server.PrincipalHeader, config.GetInstance().Server.TrustedProxies
+	defer func() {
+		config.GetInstance().Server.PrincipalHeader, config.GetInstance().Server.TrustedProxies = header, proxies
+	}()
+	config.GetInstance().Server.PrincipalHeader = "X-Forwarded-User"
+	config.GetInstance().Server.TrustedProxies = []string{"10.0.0.0/8"}
+
+	categoryController := &controllers.Category{ValidatorInterface: &validators.CategoryValidator{}}
+	categoryController.SetBaseFunctions(useFileStorage(t))
+	principal := &middlewares.PrincipalMiddleware{}
+
+	// The principal set by the proxy is recorded as the creator of the category
+	payload, _ := json.Marshal(models.Category{Name: "books"})
+	req, _ := http.NewRequest("POST", "/api/createCategory", bytes.NewBuffer(payload))
+	req.Header.Set("X-Forwarded-User", "alice")
+	req.RemoteAddr = "10.1.2.3:4567"
+	rr := httptest.NewRecorder()
+	principal.GetHandlerFunc(http.HandlerFunc(categoryController.HandleCreateCategory)).ServeHTTP(rr, req)
+	var created struct {
+		Data models.Category `json:"data"`
+	}
+	json.NewDecoder(rr.Body).Decode(&created)
+	lastModified := rr.Header().Get("Last-Modified")
+	if created.Data.CreatedBy != "alice" || created.Data.CreatedAt == 0 || lastModified == "" {
+		t.Fatalf("Expected the category created by alice with Last-Modified; got %q, %+v", lastModified, created.Data)
+	}
+
+	read := func(ifModifiedSince string) *httptest.ResponseRecorder {
+		t.Helper()
+		req, _ := http.NewRequest("GET", "/api/readCategory", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": string(created.Data.ID)})
+		if ifModifiedSince != "" {
+			req.Header.Set("If-Modified-Since", ifModifiedSince)
+		}
+		rr := httptest.NewRecorder()
+		categoryController.HandleReadCategory(rr, req)
+		return rr
+	}
+	if rr = read(lastModified); rr.Code != http.StatusNotModified || rr.Body.Len() != 0 || rr.Header().Get("ETag") != `"1"` {
+		t.Errorf("Expected 304 for an unchanged category; got %d %q: %s", rr.Code, rr.Header().Get("ETag"), rr.Body)
+	}
+	if rr = read(time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)); rr.Code != http.StatusOK || rr.Header().Get("Last-Modified") != lastModified {
+		t.Errorf("Expected the category modified since an hour ago; got %d %q", rr.Code, rr.Header().Get("Last-Modified"))
+	}
+	if rr = read("yesterday"); rr.Code != http.StatusOK {
+		t.Errorf("Expected a malformed If-Modified-Since to be ignored; got %d", rr.Code)
+	}
+}
+
+func TestPrincipalFromTrustedProxy(t *testing.T) {
+	header, proxies := config.GetInstance().Server.PrincipalHeader, config.GetInstance().Server.TrustedProxies
+	defer func() {
+		config.GetInstance().Server.PrincipalHeader, config.GetInstance().Server.TrustedProxies = header, proxies
+	}()
+	config.GetInstance().Server.PrincipalHeader = "X-Forwarded-User"
+	config.GetInstance().Server.TrustedProxies = []string{"10.0.0.0/8", "192.0.2.7"}
+
+	principal := &middlewares.PrincipalMiddleware{}
+	serve := func(remoteAddr string) string {
+		t.Helper()
+		var found string
+		req, _ := http.NewRequest("GET", "/api/readCategory", nil)
+		req.Header.Set("X-Forwarded-User", "alice")
+		req.RemoteAddr = remoteAddr
+		principal.GetHandlerFunc(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			found = basetypes.PrincipalFrom(r.Context())
+		})).ServeHTTP(httptest.NewRecorder(), req)
+		return found
+	}
+	if found := serve("10.1.2.3:4567"); found != "alice" {
+		t.Errorf("Expected the principal from a trusted range; got %q", found)
+	}
+	if found := serve("192.0.2.7:4567"); found != "alice" {
+		t.Errorf("Expected the principal from a trusted address; got %q", found)
+	}
+	// Any other client could have set the header itself
+	if found := serve("198.51.100.1:4567"); found != "" {
+		t.Errorf("Expected no principal from an untrusted peer; got %q", found)
+	}
+	config.GetInstance().Server.TrustedProxies = nil
+	if found := serve("10.1.2.3:4567"); found != "" {
+		t.Errorf("Expected no principal without trusted proxies; got %q", found)
+	}
+}
+
+func TestTransactionRecordsPrincipal(t *testing.T) {
+	memory := &basefunctions.MemoryFunctions{}
+	memory.GetFunctions()
+	storages := map[string]basefunctions.TransactionalInterface{"memory": memory, "file": useFileStorage(t)}
+	for name, storage := range storages {
+		tx, err := storage.BeginContext(basetypes.WithPrincipal(context.Background(), "alice"))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if _, err := tx.Add("websays", "principalCategories", models.Category{ID: "1", Name: "books"}); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		found, err := storage.(basefunctions.BaseFucntionsInterface).FindOne("websays", "principalCategories", models.Category{ID: "1"})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if metadata := basefunctions.RecordMetadata(found); metadata.CreatedBy != "alice" || metadata.UpdatedBy != "alice" {
+			t.Errorf("%s: Expected the record written by alice; got %+v", name, metadata)
+		}
+	}
+}
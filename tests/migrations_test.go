@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"websays/app/models"
+	"websays/config"
+	"websays/database/basedialects"
+	"websays/database/basefunctions"
+	"websays/database/basemigrations"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestMigratorUpAndDown(t *testing.T) {
+	directory := t.TempDir()
+	files := map[string]string{
+		"1_create_products.up.sql":     "CREATE TABLE products (\n  id INTEGER PRIMARY KEY,\n  name VARCHAR(255) NOT NULL\n);\n",
+		"1_create_products.down.sql":   "DROP TABLE products;\n",
+		"2_add_product_price.up.sql":   "-- prices are in cents\nALTER TABLE products ADD COLUMN price INT;\nCREATE INDEX products_price ON products (price);\n",
+		"2_add_product_price.down.sql": "DROP INDEX products_price;\nALTER TABLE products DROP COLUMN price;\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(directory, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	migrations, err := basemigrations.Load(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 2 || migrations[1].Name != "add_product_price" || len(migrations[1].Up) != 2 {
+		t.Fatalf("Expected two ordered migrations; got %+v", migrations)
+	}
+
+	db, err := sql.Open("sqlite3", filepath.Join(directory, "migrations.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	migrator := &basemigrations.Migrator{DB: db, Builder: basedialects.Builder{Dialect: basedialects.SQLite{}}, Migrations: migrations}
+
+	applied, err := migrator.Up()
+	if err != nil || len(applied) != 2 {
+		t.Fatalf("Expected both migrations to apply; got %v, %v", applied, err)
+	}
+	if _, err = db.Exec("INSERT INTO products (name, price) VALUES ('desk', 100)"); err != nil {
+		t.Fatal(err)
+	}
+	if applied, err = migrator.Up(); err != nil || len(applied) != 0 {
+		t.Errorf("Expected nothing left to apply; got %v, %v", applied, err)
+	}
+
+	reverted, err := migrator.Down(1)
+	if err != nil || len(reverted) != 1 || reverted[0].Version != 2 {
+		t.Fatalf("Expected migration 2 to be reverted; got %v, %v", reverted, err)
+	}
+	if _, err = db.Exec("INSERT INTO products (name, price) VALUES ('chair', 100)"); err == nil {
+		t.Error("Expected the price column to be dropped")
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !statuses[0].Applied || statuses[1].Applied {
+		t.Errorf("Expected only migration 1 to be applied; got %+v", statuses)
+	}
+}
+
+func TestMigrationDiff(t *testing.T) {
+	type product struct {
+		ID    int    `db:"id,INT,AUTO_INCREMENT,PRIMARY KEY"`
+		Name  string `db:"name,VARCHAR(255),NOT NULL"`
+		Price int    `db:"price,INT,NOT NULL"`
+	}
+	live := []basedialects.Column{
+		{Name: "id", Type: "INT(11)", Constraints: []string{}, AutoIncrement: true, PrimaryKey: true},
+		{Name: "name", Type: "VARCHAR(100)", Constraints: []string{"NOT NULL"}},
+		{Name: "legacy", Type: "TEXT", Constraints: []string{}},
+	}
+
+	up, down, err := basemigrations.Diff("products", live, product{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedUp := []string{
+		"ALTER TABLE `products` MODIFY COLUMN `name` VARCHAR(255) NOT NULL",
+		"ALTER TABLE `products` ADD COLUMN `price` INT NOT NULL",
+		"ALTER TABLE `products` DROP COLUMN `legacy`",
+	}
+	expectedDown := []string{
+		"ALTER TABLE `products` ADD COLUMN `legacy` TEXT",
+		"ALTER TABLE `products` DROP COLUMN `price`",
+		"ALTER TABLE `products` MODIFY COLUMN `name` VARCHAR(100) NOT NULL",
+	}
+	if !reflect.DeepEqual(up, expectedUp) {
+		t.Errorf("Expected up %q; got %q", expectedUp, up)
+	}
+	if !reflect.DeepEqual(down, expectedDown) {
+		t.Errorf("Expected down %q; got %q", expectedDown, down)
+	}
+
+	up, down, err = basemigrations.Diff("products", nil, models.Product{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(up) != 1 || up[0] != "CREATE TABLE IF NOT EXISTS `products` (`id` VARCHAR(64) PRIMARY KEY, `name` VARCHAR(255) NOT NULL, `version` INT NOT NULL DEFAULT 1, `deleted_at` BIGINT NOT NULL DEFAULT 0, `created_at` BIGINT NOT NULL DEFAULT 0, `updated_at` BIGINT NOT NULL DEFAULT 0, `created_by` VARCHAR(255) NOT NULL DEFAULT '', `updated_by` VARCHAR(255) NOT NULL DEFAULT '')" || down[0] != "DROP TABLE `products`" {
+		t.Errorf("Expected the missing table to be created; got %q, %q", up, down)
+	}
+}
+
+func TestMigrationsManageTables(t *testing.T) {
+	path := config.GetInstance().Migrations.Path
+	t.Cleanup(func() { config.GetInstance().Migrations.Path = path })
+	config.GetInstance().Migrations.Path = t.TempDir()
+
+	// Indexing only registers the model once the tables are left to the migrations, without reaching the database
+	if err := (&basefunctions.MySqlFunctions{}).EnsureIndex("websays", "migratedProducts", models.Product{}); err != nil {
+		t.Fatalf("Expected indexing to leave the table alone; got %v", err)
+	}
+	if model := basemigrations.GetInstance().Models()["migratedProducts"]; model != (models.Product{}) {
+		t.Errorf("Expected the product model registered for the diff; got %v", model)
+	}
+	if _, ok := models.Tables["products"].(models.Product); !ok {
+		t.Error("Expected the products table listed for the diff")
+	}
+}
@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"websays/app/controllers"
+	"websays/app/models"
+	"websays/app/validators"
+	"websays/config"
+	"websays/database/baseconnections"
+	"websays/database/basefunctions"
+	"websays/database/basetypes"
+	"websays/httpHandler/responses"
+
+	"github.com/gorilla/mux"
+)
+
+func TestSqliteProductController(t *testing.T) {
+	config.GetInstance().Sqlite.FileName = t.TempDir() + "/websays.sqlite"
+
+	funcs, err := basefunctions.GetInstance().GetFunctions(basetypes.SQLITE, "websays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	productController := &controllers.Product{ValidatorInterface: &validators.ProductValidator{}}
+	productController.SetBaseFunctions(*funcs)
+	productController.DoIndexing()
+
+	ids := make([]basetypes.ID, 0)
+	for _, name := range []string{"keyboard", "mouse"} {
+		payload, _ := json.Marshal(models.Product{Name: name})
+		req, err := http.NewRequest("POST", "/api/createProduct", bytes.NewBuffer(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		productController.HandleCreateProduct(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status %d; got %d: %s", http.StatusOK, rr.Code, rr.Body)
+		}
+
+		var response struct {
+			Data models.Product `json:"data"`
+		}
+		json.NewDecoder(rr.Body).Decode(&response)
+		ids = append(ids, response.Data.ID)
+	}
+	if first, _ := ids[0].Int(); first == 0 || ids[1] != basetypes.IntID(first+1) {
+		t.Fatalf("Expected generated consecutive IDs; got %v", ids)
+	}
+
+	req, _ := http.NewRequest("GET", "/api/readProduct/"+string(ids[1]), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": string(ids[1])})
+	rr := httptest.NewRecorder()
+	productController.HandleReadProduct(rr, req)
+	if rr.Code != http.StatusOK || !bytes.Contains(rr.Body.Bytes(), []byte("mouse")) {
+		t.Errorf("Expected to read the mouse; got %d: %s", rr.Code, rr.Body)
+	}
+
+	err = productController.UpdateOne(productController.GetDBName(), productController.GetCollectionName(), map[string]interface{}{"id": ids[0]}, map[string]interface{}{"name": "trackball"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = productController.DeleteOne(productController.GetDBName(), productController.GetCollectionName(), map[string]interface{}{"id": ids[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := productController.FindMany(productController.GetDBName(), productController.GetCollectionName(), nil, basetypes.FindOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 1 || result.Data[0].(models.Product).Name != "trackball" {
+		t.Errorf("Expected only the renamed trackball; got %+v", result)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/readProduct/"+string(ids[1]), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": string(ids[1])})
+	rr = httptest.NewRecorder()
+	productController.HandleReadProduct(rr, req)
+	if !bytes.Contains(rr.Body.Bytes(), []byte(strconv.Itoa(responses.NO_PRDUCT_FOUND))) {
+		t.Errorf("Expected the deleted mouse to be missing; got %d: %s", rr.Code, rr.Body)
+	}
+
+	iterator, err := productController.BaseFucntionsInterface.(basefunctions.IterableInterface).Iterate(context.Background(), productController.GetDBName(), productController.GetCollectionName(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !iterator.Next() || withoutMetadata(iterator.Record()) != (models.Product{ID: ids[0], Name: "trackball", Version: 2}) {
+		t.Errorf("Expected to iterate over the trackball; got %+v, %v", iterator.Record(), iterator.Err())
+	}
+	iterator.Close()
+
+	connection, err := baseconnections.GetInstance().GetConnection(basetypes.SQLITE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := connection.GetDB(basetypes.SQLITE).(*sql.DB)
+	if inUse := db.Stats().InUse; inUse != 0 {
+		t.Errorf("Expected every connection to be released; %d are in use", inUse)
+	}
+}
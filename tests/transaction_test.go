@@ -0,0 +1,210 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"websays/app/controllers"
+	"websays/app/models"
+	"websays/database/basefunctions"
+	"websays/httpHandler/basecontrollers"
+	"websays/httpHandler/basetransactions"
+	"websays/httpHandler/responses"
+)
+
+func TestTransactionCommit(t *testing.T) {
+	controller, _ := basecontrollers.GetInstance().GetController("Article")
+	articleController := controller.(*controllers.Article)
+
+	first := models.Article{ID: nextID(t, articleController, articleController.GetDBName(), articleController.GetCollectionName()), Title: "First", Body: "Body"}
+	second := models.Article{ID: nextID(t, articleController, articleController.GetDBName(), articleController.GetCollectionName()), Title: "Second", Body: "Body"}
+
+	handler := basetransactions.WithTransaction(articleController, func(tx basefunctions.TransactionInterface, r *http.Request) (int, interface{}, error) {
+		if _, err := tx.Add(articleController.GetDBName(), articleController.GetCollectionName(), first); err != nil {
+			return responses.ADDING_DB_FAILED, nil, err
+		}
+		if _, err := tx.Add(articleController.GetDBName(), articleController.GetCollectionName(), second); err != nil {
+			return responses.ADDING_DB_FAILED, nil, err
+		}
+		// Writes are visible inside the transaction but not outside of it before the commit
+		if _, err := tx.FindOne(articleController.GetDBName(), articleController.GetCollectionName(), first); err != nil {
+			t.Errorf("Expected article inside transaction; got %v", err)
+		}
+		if _, err := articleController.FindOne(articleController.GetDBName(), articleController.GetCollectionName(), first); err == nil {
+			t.Errorf("Expected article to be invisible before commit")
+		}
+		return responses.ADD_ARTICLE_SUCCESS, nil, nil
+	})
+
+	req, err := http.NewRequest("POST", "/api/createArticles", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	handler(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status %d; got %d", http.StatusOK, rr.Code)
+	}
+	for _, article := range []models.Article{first, second} {
+		if _, err := articleController.FindOne(articleController.GetDBName(), articleController.GetCollectionName(), article); err != nil {
+			t.Errorf("Expected committed article %s; got %v", article.ID, err)
+		}
+	}
+}
+
+func TestTransactionRollback(t *testing.T) {
+	controller, _ := basecontrollers.GetInstance().GetController("Article")
+	articleController := controller.(*controllers.Article)
+
+	article := models.Article{ID: nextID(t, articleController, articleController.GetDBName(), articleController.GetCollectionName()), Title: "Rolled back", Body: "Body"}
+
+	handler := basetransactions.WithTransaction(articleController, func(tx basefunctions.TransactionInterface, r *http.Request) (int, interface{}, error) {
+		if _, err := tx.Add(articleController.GetDBName(), articleController.GetCollectionName(), article); err != nil {
+			return responses.ADDING_DB_FAILED, nil, err
+		}
+		return responses.ADDING_DB_FAILED, nil, errors.New("failing on purpose")
+	})
+
+	req, err := http.NewRequest("POST", "/api/createArticles", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	handler(rr, req)
+
+	if rr.Code != http.StatusNotAcceptable {
+		t.Errorf("Expected status %d; got %d", http.StatusNotAcceptable, rr.Code)
+	}
+	if _, err := articleController.FindOne(articleController.GetDBName(), articleController.GetCollectionName(), article); err == nil {
+		t.Errorf("Expected rolled back article to be absent")
+	}
+}
+
+func TestMemoryTransactionLostUpdate(t *testing.T) {
+	memory := &basefunctions.MemoryFunctions{}
+	memory.GetFunctions()
+	note := keyedNote{ID: "1", Text: "Read"}
+	if _, err := memory.Add("notes", "notes", note); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := memory.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.FindOne("notes", "notes", note); err != nil {
+		t.Fatal(err)
+	}
+	// The record isn't versioned, so only what the transaction read tells the concurrent update apart
+	if err := memory.UpdateOne("notes", "notes", note, keyedNote{ID: "1", Text: "Concurrent"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.UpdateOne("notes", "notes", note, keyedNote{ID: "1", Text: "Transaction"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); !errors.Is(err, basefunctions.ErrVersionConflict) {
+		t.Fatalf("Expected a version conflict; got %v", err)
+	}
+
+	stored, err := memory.FindOne("notes", "notes", note)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.(keyedNote).Text != "Concurrent" {
+		t.Errorf("Expected the concurrent update to be kept; got %q", stored.(keyedNote).Text)
+	}
+}
+
+func TestMemoryTransactionFilterSeesWrites(t *testing.T) {
+	memory := &basefunctions.MemoryFunctions{}
+	memory.GetFunctions()
+	if _, err := memory.Add("notes", "notes", keyedNote{ID: "2", Text: "Stored"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := memory.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Add("notes", "notes", keyedNote{ID: "1", Text: "Stored"}); err != nil {
+		t.Fatal(err)
+	}
+	// The record added by the transaction comes first in the order of the IDs
+	found, err := tx.FindOne("notes", "notes", map[string]interface{}{"text": "Stored"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := found.(keyedNote).ID; id != "1" {
+		t.Errorf("Expected the added note first; got %s", id)
+	}
+
+	if err := tx.DeleteOne("notes", "notes", keyedNote{ID: "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.DeleteOne("notes", "notes", keyedNote{ID: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.FindOne("notes", "notes", map[string]interface{}{"text": "Stored"}); err == nil {
+		t.Errorf("Expected the deleted notes to be skipped by the filter")
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTransactionRequestContext(t *testing.T) {
+	controller, _ := basecontrollers.GetInstance().GetController("Article")
+	articleController := controller.(*controllers.Article)
+
+	article := models.Article{ID: nextID(t, articleController, articleController.GetDBName(), articleController.GetCollectionName()), Title: "Cancelled", Body: "Body"}
+
+	handler := basetransactions.WithTransaction(articleController, func(tx basefunctions.TransactionInterface, r *http.Request) (int, interface{}, error) {
+		_, err := tx.Add(articleController.GetDBName(), articleController.GetCollectionName(), article)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected the transaction to give up with the request; got %v", err)
+		}
+		return responses.ADDING_DB_FAILED, nil, err
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, err := http.NewRequestWithContext(ctx, "POST", "/api/createArticles", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler(httptest.NewRecorder(), req)
+
+	if _, err := articleController.FindOne(articleController.GetDBName(), articleController.GetCollectionName(), article); err == nil {
+		t.Errorf("Expected article of the cancelled request to be absent")
+	}
+}
+
+func TestTransactionRollbackOnPanic(t *testing.T) {
+	controller, _ := basecontrollers.GetInstance().GetController("Article")
+	articleController := controller.(*controllers.Article)
+
+	var begun basefunctions.TransactionInterface
+	handler := basetransactions.WithTransaction(articleController, func(tx basefunctions.TransactionInterface, r *http.Request) (int, interface{}, error) {
+		begun = tx
+		panic("failing on purpose")
+	})
+
+	req, err := http.NewRequest("POST", "/api/createArticles", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	func() {
+		defer func() { recover() }()
+		handler(httptest.NewRecorder(), req)
+	}()
+
+	if begun == nil {
+		t.Fatal("Expected the handler to run")
+	}
+	if err := begun.Rollback(); err == nil {
+		t.Errorf("Expected the transaction to be rolled back already")
+	}
+}
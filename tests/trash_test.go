@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"websays/app/controllers"
+	"websays/app/models"
+	"websays/app/validators"
+	"websays/config"
+	"websays/database/basefunctions"
+	"websays/database/basetypes"
+
+	"github.com/gorilla/mux"
+)
+
+func TestCategoryTrash(t *testing.T) {
+	functions := useFileStorage(t)
+	categoryController := &controllers.Category{ValidatorInterface: &validators.CategoryValidator{}}
+	categoryController.SetBaseFunctions(functions)
+	dbName, collectionName := categoryController.GetDBName(), categoryController.GetCollectionName()
+
+	if _, err := functions.Add(dbName, collectionName, models.Category{ID: "60", Name: "atlases"}); err != nil {
+		t.Fatal(err)
+	}
+
+	serve := func(method string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+		t.Helper()
+		req, err := http.NewRequest(method, "/api/categories/trash/60", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"id": "60"})
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	if rr := serve("DELETE", categoryController.HandleDeleteCategory); rr.Code != http.StatusOK {
+		t.Fatalf("Expected the category to be trashed; got %d: %s", rr.Code, rr.Body)
+	}
+	if _, err := functions.FindOne(dbName, collectionName, models.Category{ID: "60"}); err == nil {
+		t.Error("Expected the trashed category to be hidden")
+	}
+	if _, err := functions.Add(dbName, collectionName, models.Category{ID: "60", Name: "maps"}); err == nil {
+		t.Error("Expected the ID of the trashed category to stay taken")
+	}
+
+	req, _ := http.NewRequest("GET", "/api/categories/trash", nil)
+	rr := httptest.NewRecorder()
+	categoryController.HandleListCategoryTrash(rr, req)
+	var response struct {
+		Data basetypes.FindResult `json:"data"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil || response.Data.Total != 1 {
+		t.Fatalf("Expected one trashed category; got %+v (%v)", response.Data, err)
+	}
+
+	// Trashing and restoring are both changes, so the restored category is at version 3
+	if rr := serve("POST", categoryController.HandleRestoreCategory); rr.Code != http.StatusOK || rr.Header().Get("ETag") != `"3"` {
+		t.Fatalf("Expected the category to be restored at version 3; got %d %q: %s", rr.Code, rr.Header().Get("ETag"), rr.Body)
+	}
+	if rr := serve("DELETE", categoryController.HandlePurgeCategory); rr.Code == http.StatusOK {
+		t.Error("Expected a live category not to be purged")
+	}
+
+	serve("DELETE", categoryController.HandleDeleteCategory)
+	if rr := serve("DELETE", categoryController.HandlePurgeCategory); rr.Code != http.StatusOK {
+		t.Fatalf("Expected the trashed category to be purged; got %d: %s", rr.Code, rr.Body)
+	}
+	if _, err := functions.Add(dbName, collectionName, models.Category{ID: "60", Name: "maps"}); err != nil {
+		t.Errorf("Expected the ID of the purged category to be free; got %v", err)
+	}
+}
+
+func TestTrashStorages(t *testing.T) {
+	memory, err := basefunctions.GetInstance().GetFunctions(basetypes.MEMORY, "websays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trash := (*memory).(basefunctions.TrashInterface)
+	article := models.Article{ID: "900200", Title: "trashed"}
+	if _, err = (*memory).Add("websays", "articles", article); err != nil {
+		t.Fatal(err)
+	}
+	if err = (*memory).DeleteOne("websays", "articles", models.Article{ID: article.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = (*memory).FindOne("websays", "articles", models.Article{ID: article.ID}); err == nil {
+		t.Error("Expected the trashed article to be hidden")
+	}
+	if err = trash.Restore("websays", "articles", models.Article{ID: article.ID}); err != nil {
+		t.Fatal(err)
+	}
+	restored, _ := (*memory).FindOne("websays", "articles", models.Article{ID: article.ID})
+	if withoutMetadata(restored) != (models.Article{ID: article.ID, Title: "trashed", Version: 3}) {
+		t.Errorf("Expected the restored article at version 3; got %+v", restored)
+	}
+	(*memory).DeleteOne("websays", "articles", models.Article{ID: article.ID})
+	if purged, err := trash.PurgeTrash("websays", "articles", time.Now().Add(-time.Hour)); err != nil || purged != 0 {
+		t.Errorf("Expected the recently trashed article to be kept; got %d (%v)", purged, err)
+	}
+	if purged, err := trash.PurgeTrash("websays", "articles", time.Now().Add(time.Hour)); err != nil || purged < 1 {
+		t.Errorf("Expected the trashed article to be purged; got %d (%v)", purged, err)
+	}
+
+	config.GetInstance().Sqlite.FileName = t.TempDir() + "/websays.sqlite"
+	sqlite, err := basefunctions.GetInstance().GetFunctions(basetypes.SQLITE, "websays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = (*sqlite).EnsureIndex("websays", "products", models.Product{}); err != nil {
+		t.Fatal(err)
+	}
+	trash = (*sqlite).(basefunctions.TrashInterface)
+	byID := map[string]interface{}{"id": 70}
+	if err = (*sqlite).UpdateOne("websays", "products", byID, models.Product{ID: "70", Name: "lamp"}, true); err != nil {
+		t.Fatal(err)
+	}
+	if err = (*sqlite).DeleteOne("websays", "products", byID); err != nil {
+		t.Fatal(err)
+	}
+	if result, err := (*sqlite).FindMany("websays", "products", byID, basetypes.FindOptions{}); err != nil || result.Total != 0 {
+		t.Errorf("Expected the trashed product to be hidden; got %+v (%v)", result, err)
+	}
+	if result, err := trash.FindTrash("websays", "products", byID, basetypes.FindOptions{}); err != nil || result.Total != 1 {
+		t.Errorf("Expected the trashed product to be listed; got %+v (%v)", result, err)
+	}
+	if err = trash.Restore("websays", "products", byID); err != nil {
+		t.Fatal(err)
+	}
+	if err = trash.Purge("websays", "products", byID); err == nil {
+		t.Error("Expected a live product not to be purged")
+	}
+	(*sqlite).DeleteOne("websays", "products", byID)
+	if err = trash.Purge("websays", "products", byID); err != nil {
+		t.Fatal(err)
+	}
+	if err = trash.Restore("websays", "products", byID); err == nil {
+		t.Error("Expected the purged product not to be restored")
+	}
+}
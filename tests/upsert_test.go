@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"websays/app/controllers"
+	"websays/app/models"
+	"websays/app/validators"
+	"websays/config"
+	"websays/database/basefunctions"
+	"websays/database/baseids"
+	"websays/database/basetypes"
+
+	"github.com/gorilla/mux"
+)
+
+func TestReplaceCategory(t *testing.T) {
+	functions := useFileStorage(t)
+	categoryController := &controllers.Category{ValidatorInterface: &validators.CategoryValidator{}}
+	categoryController.SetBaseFunctions(functions)
+
+	replace := func(id string, ifMatch string, category models.Category) *httptest.ResponseRecorder {
+		t.Helper()
+		payload, _ := json.Marshal(category)
+		req, err := http.NewRequest("PUT", "/api/categories/"+id, bytes.NewBuffer(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		req = mux.SetURLVars(req, map[string]string{"id": id})
+		rr := httptest.NewRecorder()
+		categoryController.HandleReplaceCategory(rr, req)
+		return rr
+	}
+
+	if rr := replace("40", "", models.Category{Name: "maps"}); rr.Code != http.StatusOK || rr.Header().Get("ETag") != `"1"` {
+		t.Fatalf("Expected the category to be created at version 1; got %d %q: %s", rr.Code, rr.Header().Get("ETag"), rr.Body)
+	}
+	if id := nextID(t, baseids.NewSequence(functions), categoryController.GetDBName(), categoryController.GetCollectionName()); !basetypes.ID("40").Less(id) {
+		t.Errorf("Expected the next ID to follow the upserted one; got %s", id)
+	}
+	if rr := replace("40", `"1"`, models.Category{Name: "atlases"}); rr.Code != http.StatusOK || rr.Header().Get("ETag") != `"2"` {
+		t.Fatalf("Expected the category to be replaced at version 2; got %d %q: %s", rr.Code, rr.Header().Get("ETag"), rr.Body)
+	}
+	if rr := replace("40", `"1"`, models.Category{Name: "globes"}); rr.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected the stale replacement to fail with 412; got %d: %s", rr.Code, rr.Body)
+	}
+	if rr := replace("40", "", models.Category{ID: "41", Name: "globes"}); rr.Code != http.StatusNotAcceptable {
+		t.Errorf("Expected a body ID other than the URL one to be rejected; got %d: %s", rr.Code, rr.Body)
+	}
+
+	stored, err := functions.FindOne(categoryController.GetDBName(), categoryController.GetCollectionName(), models.Category{ID: "40"})
+	if err != nil || stored.(map[string]interface{})["name"] != "atlases" {
+		t.Errorf("Expected the atlases category; got %+v, %v", stored, err)
+	}
+}
+
+func TestUpsertStorages(t *testing.T) {
+	memory, err := basefunctions.GetInstance().GetFunctions(basetypes.MEMORY, "websays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	article := models.Article{ID: "900100", Title: "upserted"}
+	if err = (*memory).UpdateOne("websays", "articles", "", article, true); err != nil {
+		t.Fatal(err)
+	}
+	defer (*memory).DeleteOne("websays", "articles", models.Article{ID: article.ID})
+	if err = (*memory).UpdateOne("websays", "articles", "", article, false); err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := (*memory).FindOne("websays", "articles", models.Article{ID: article.ID})
+	if withoutMetadata(stored) != (models.Article{ID: article.ID, Title: "upserted", Version: 2}) {
+		t.Errorf("Expected the upserted article at version 2; got %+v", stored)
+	}
+	if id := nextID(t, baseids.NewSequence(*memory), "websays", "articles"); !article.ID.Less(id) {
+		t.Errorf("Expected the next memory ID to follow the upserted one; got %s", id)
+	}
+
+	config.GetInstance().Sqlite.FileName = t.TempDir() + "/websays.sqlite"
+	sqlite, err := basefunctions.GetInstance().GetFunctions(basetypes.SQLITE, "websays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = (*sqlite).EnsureIndex("websays", "products", models.Product{}); err != nil {
+		t.Fatal(err)
+	}
+	byID := map[string]interface{}{"id": 50}
+	if err = (*sqlite).UpdateOne("websays", "products", byID, models.Product{ID: "50", Name: "shelf"}, true); err != nil {
+		t.Fatal(err)
+	}
+	if err = (*sqlite).UpdateOne("websays", "products", byID, models.Product{ID: "50", Name: "bookshelf"}, true); err != nil {
+		t.Fatal(err)
+	}
+	if err = (*sqlite).UpdateOne("websays", "products", byID, models.Product{ID: "50", Name: "cabinet", Version: 1}, true); !errors.Is(err, basefunctions.ErrVersionConflict) {
+		t.Errorf("Expected a conflict upserting at a stale version; got %v", err)
+	}
+	product, err := (*sqlite).FindOne("websays", "products", byID)
+	if err != nil || withoutMetadata(product) != (models.Product{ID: "50", Name: "bookshelf", Version: 2}) {
+		t.Errorf("Expected the bookshelf at version 2; got %+v, %v", product, err)
+	}
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"websays/app/controllers"
+	"websays/app/models"
+	"websays/app/validators"
+	"websays/config"
+	"websays/database/basefunctions"
+	"websays/database/basetypes"
+
+	"github.com/gorilla/mux"
+)
+
+func TestCategoryIfMatch(t *testing.T) {
+	categoryController := &controllers.Category{ValidatorInterface: &validators.CategoryValidator{}}
+	categoryController.SetBaseFunctions(useFileStorage(t))
+
+	send := func(method string, path string, ifMatch string, body interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		payload, _ := json.Marshal(body)
+		req, err := http.NewRequest(method, path, bytes.NewBuffer(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		rr := httptest.NewRecorder()
+		switch method {
+		case "POST":
+			categoryController.HandleCreateCategory(rr, req)
+		case "PUT":
+			categoryController.HandleUpdateCategory(rr, req)
+		case "DELETE":
+			req = mux.SetURLVars(req, map[string]string{"id": string(body.(models.Category).ID)})
+			categoryController.HandleDeleteCategory(rr, req)
+		}
+		return rr
+	}
+
+	rr := send("POST", "/api/createCategory", "", models.Category{Name: "books"})
+	var created struct {
+		Data models.Category `json:"data"`
+	}
+	json.NewDecoder(rr.Body).Decode(&created)
+	if rr.Header().Get("ETag") != `"1"` || created.Data.Version != 1 {
+		t.Fatalf("Expected a new category at version 1; got %q, %+v", rr.Header().Get("ETag"), created.Data)
+	}
+	category := created.Data
+
+	// Both clients read version 1, the first update wins
+	category.Name = "novels"
+	if rr = send("PUT", "/api/updateCategory", `"1"`, category); rr.Code != http.StatusOK || rr.Header().Get("ETag") != `"2"` {
+		t.Fatalf("Expected the first update to move to version 2; got %d %q: %s", rr.Code, rr.Header().Get("ETag"), rr.Body)
+	}
+	category.Name = "poems"
+	if rr = send("PUT", "/api/updateCategory", `"1"`, category); rr.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected the stale update to fail with 412; got %d: %s", rr.Code, rr.Body)
+	}
+	if rr = send("PUT", "/api/updateCategory", "", category); rr.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected the stale version of the body to fail with 412; got %d: %s", rr.Code, rr.Body)
+	}
+	if rr = send("PUT", "/api/updateCategory", "W/1", category); rr.Code != http.StatusNotAcceptable {
+		t.Errorf("Expected a malformed If-Match to be rejected; got %d: %s", rr.Code, rr.Body)
+	}
+
+	if rr = send("DELETE", "/api/deleteCategory", `"1"`, category); rr.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected the stale delete to fail with 412; got %d: %s", rr.Code, rr.Body)
+	}
+	if rr = send("DELETE", "/api/deleteCategory", `"2"`, category); rr.Code != http.StatusOK {
+		t.Errorf("Expected the delete at the current version to succeed; got %d: %s", rr.Code, rr.Body)
+	}
+}
+
+func TestVersionedStorages(t *testing.T) {
+	memory, err := basefunctions.GetInstance().GetFunctions(basetypes.MEMORY, "websays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	article := models.Article{ID: "900001", Title: "versioned"}
+	if _, err = (*memory).Add("websays", "articles", article); err != nil {
+		t.Fatal(err)
+	}
+	article.Version = 1
+	if err = (*memory).UpdateOne("websays", "articles", "", article, false); err != nil {
+		t.Fatal(err)
+	}
+	if err = (*memory).UpdateOne("websays", "articles", "", article, false); !errors.Is(err, basefunctions.ErrVersionConflict) {
+		t.Errorf("Expected a conflict updating the memory storage at a stale version; got %v", err)
+	}
+	stored, _ := (*memory).FindOne("websays", "articles", models.Article{ID: article.ID})
+	if stored.(models.Article).Version != 2 {
+		t.Errorf("Expected the article at version 2; got %+v", stored)
+	}
+	(*memory).DeleteOne("websays", "articles", models.Article{ID: article.ID})
+
+	config.GetInstance().Sqlite.FileName = t.TempDir() + "/websays.sqlite"
+	sqlite, err := basefunctions.GetInstance().GetFunctions(basetypes.SQLITE, "websays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = (*sqlite).EnsureIndex("websays", "products", models.Product{}); err != nil {
+		t.Fatal(err)
+	}
+	id, err := (*sqlite).Add("websays", "products", models.Product{Name: "lamp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := map[string]interface{}{"id": id}
+	if err = (*sqlite).UpdateOne("websays", "products", byID, map[string]interface{}{"name": "desk lamp", "version": 1}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err = (*sqlite).UpdateOne("websays", "products", byID, map[string]interface{}{"name": "floor lamp", "version": 1}, false); !errors.Is(err, basefunctions.ErrVersionConflict) {
+		t.Errorf("Expected a conflict updating the sql storage at a stale version; got %v", err)
+	}
+	if err = (*sqlite).UpdateOne("websays", "products", byID, map[string]interface{}{"name": "floor lamp"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err = (*sqlite).DeleteOne("websays", "products", map[string]interface{}{"id": id, "version": 2}); !errors.Is(err, basefunctions.ErrVersionConflict) {
+		t.Errorf("Expected a conflict deleting at a stale version; got %v", err)
+	}
+	product, err := (*sqlite).FindOne("websays", "products", byID)
+	if err != nil || withoutMetadata(product) != (models.Product{ID: id, Name: "floor lamp", Version: 3}) {
+		t.Errorf("Expected the floor lamp at version 3; got %+v, %v", product, err)
+	}
+}
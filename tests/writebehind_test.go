@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+	"websays/app/models"
+	"websays/config"
+	"websays/database/baseconnections"
+	"websays/database/basefunctions"
+	"websays/database/basetypes"
+)
+
+func TestWriteBehindFunctions(t *testing.T) {
+	config.GetInstance().Sqlite.FileName = t.TempDir() + "/websays.sqlite"
+	funcs, err := basefunctions.GetInstance().GetFunctions(basetypes.SQLITE, "websays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	back := *funcs
+	dbName, collectionName := basetypes.DBName("websays"), basetypes.CollectionName("behindProducts")
+
+	if _, err = basefunctions.NewWriteBehindFunctions(&basefunctions.MemoryFunctions{}, basefunctions.WriteBehindOptions{}); err == nil {
+		t.Error("Expected write-behind to require a sql storage")
+	}
+	functions, err := basefunctions.NewWriteBehindFunctions(back, basefunctions.WriteBehindOptions{FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = functions.EnsureIndex(dbName, collectionName, models.Product{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The writes are acknowledged in memory and only reach the table once flushed
+	for i, name := range []string{"keyboard", "mouse"} {
+		if _, err = functions.Add(dbName, collectionName, models.Product{ID: basetypes.IntID(int64(i + 1)), Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = functions.UpdateOne(dbName, collectionName, map[string]interface{}{"id": "1"}, map[string]interface{}{"name": "trackball"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err = functions.DeleteOne(dbName, collectionName, map[string]interface{}{"id": "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := functions.FindOne(dbName, collectionName, map[string]interface{}{"id": "1"}); err != nil || found.(models.Product).Name != "trackball" || found.(models.Product).Version != 2 {
+		t.Errorf("Expected the renamed trackball at version 2 from memory; got %+v (%v)", found, err)
+	}
+	if _, err = back.FindOne(dbName, collectionName, map[string]interface{}{"id": "1"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected nothing persisted before the flush; got %v", err)
+	}
+	if stats := functions.FlushStats(); stats.Pending != 2 || stats.LagSeconds <= 0 {
+		t.Errorf("Expected the two records pending; got %+v", stats)
+	}
+
+	if err = functions.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := back.FindOne(dbName, collectionName, map[string]interface{}{"id": "1"}); err != nil || found.(models.Product).Name != "trackball" || found.(models.Product).Version != 2 {
+		t.Errorf("Expected the trackball persisted at version 2; got %+v (%v)", found, err)
+	}
+	if stats := functions.FlushStats(); stats.Pending != 0 || stats.Flushed != 2 || stats.Batches != 1 || stats.LagSeconds != 0 {
+		t.Errorf("Expected both records flushed in one batch; got %+v", stats)
+	}
+
+	// A version in the condition is checked against the record in memory
+	err = functions.DeleteOne(dbName, collectionName, map[string]interface{}{"id": "1", "version": 1})
+	if !errors.Is(err, basefunctions.ErrVersionConflict) {
+		t.Errorf("Expected a version conflict; got %v", err)
+	}
+
+	// Close persists the pending writes, and a new storage loads the table back
+	if _, err = functions.Add(dbName, collectionName, models.Product{ID: "3", Name: "screen"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = functions.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := basefunctions.NewWriteBehindFunctions(back, basefunctions.WriteBehindOptions{FlushInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reloaded.Close(context.Background())
+	if err = reloaded.EnsureIndex(dbName, collectionName, models.Product{}); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := reloaded.FindOne(dbName, collectionName, map[string]interface{}{"id": "3"}); err != nil || found.(models.Product).Name != "screen" {
+		t.Errorf("Expected the screen loaded from the table; got %+v (%v)", found, err)
+	}
+	if _, err = reloaded.FindOne(dbName, collectionName, map[string]interface{}{"id": "2"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected the deleted mouse to stay deleted; got %v", err)
+	}
+
+	// The background flushes persist the writes, with the sequence following the loaded IDs
+	if next, err := reloaded.NextSequence(dbName, collectionName); err != nil || next != 4 {
+		t.Fatalf("Expected the sequence to follow the loaded IDs; got %v (%v)", next, err)
+	}
+	if _, err = reloaded.Add(dbName, collectionName, models.Product{ID: "4", Name: "speaker"}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for reloaded.FlushStats().Pending > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if found, err := back.FindOne(dbName, collectionName, map[string]interface{}{"id": "4"}); err != nil || found.(models.Product).Name != "speaker" {
+		t.Errorf("Expected the speaker persisted by a background flush; got %+v (%v)", found, err)
+	}
+}
+
+// looseProduct is a product whose key column has no type affinity in SQLite, so like in MySQL a number bound
+// for the key is compared to the keys as a number, while ORDER BY sorts them as text.
+type looseProduct struct {
+	ID   basetypes.ID `db:"id,BLOB,PRIMARY KEY" json:"id"`
+	Name string       `db:"name,VARCHAR(255)" json:"name"`
+}
+
+func (u looseProduct) GetID() basetypes.ID {
+	return u.ID
+}
+
+func TestWriteBehindLoadsInPages(t *testing.T) {
+	config.GetInstance().Sqlite.FileName = t.TempDir() + "/websays.sqlite"
+	funcs, err := basefunctions.GetInstance().GetFunctions(basetypes.SQLITE, "websays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	back := *funcs
+	dbName, collectionName := basetypes.DBName("websays"), basetypes.CollectionName("pagedProducts")
+	if err = back.EnsureIndex(dbName, collectionName, models.Product{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = back.EnsureIndex(dbName, "looseProducts", looseProduct{}); err != nil {
+		t.Fatal(err)
+	}
+	connection, err := baseconnections.GetInstance().GetConnection(basetypes.SQLITE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := connection.GetDB(basetypes.SQLITE).(*sql.DB)
+
+	// The keys sort as text as 1, 10, 2, ..., so a page ending at 10 is followed by the keys below it
+	ids := []basetypes.ID{"1", "2", "3", "4", "5", "10"}
+	for _, id := range ids {
+		if _, err = back.Add(dbName, collectionName, models.Product{ID: id, Name: "product " + string(id)}); err != nil {
+			t.Fatal(err)
+		}
+		// The loose keys are stored as text, as a VARCHAR column of MySQL stores them
+		if _, err = db.Exec(`INSERT INTO "looseProducts" ("id", "name") VALUES (?, ?)`, string(id), "product "+string(id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Pages of two records cover the table, whatever the order of the keys
+	functions, err := basefunctions.NewWriteBehindFunctions(back, basefunctions.WriteBehindOptions{FlushInterval: time.Hour, BatchSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer functions.Close(context.Background())
+	if err = functions.EnsureIndex(dbName, collectionName, models.Product{}); err != nil {
+		t.Fatal(err)
+	}
+	// A key bound as a number would page over 1 and 10 again and again
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err = functions.EnsureIndexContext(ctx, dbName, "looseProducts", looseProduct{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, collection := range []basetypes.CollectionName{collectionName, "looseProducts"} {
+		for _, id := range ids {
+			if _, err := functions.FindOne(dbName, collection, map[string]interface{}{"id": id}); err != nil {
+				t.Errorf("Expected %s %s loaded from the table; got %v", collection, id, err)
+			}
+		}
+	}
+
+	// Updating the columns of a missing record fails like reading it
+	err = functions.UpdateOne(dbName, collectionName, map[string]interface{}{"id": "11"}, map[string]interface{}{"name": "missing"}, false)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected no rows for a missing product; got %v", err)
+	}
+}